@@ -0,0 +1,218 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package archivefs exposes zip and tar archives as fs.FS.
+//
+// Files are served straight from an io.ReaderAt of the archive, without
+// extraction. Uncompressed members (stored zip entries and every tar entry)
+// support Seek and ReadAt through an io.SectionReader. Compressed zip members
+// are streamed (the opened file is then neither an io.Seeker nor an
+// io.ReaderAt), or, if a decompression cache is configured, decompressed once
+// into an objcache.Group and then served from memory with random access.
+package archivefs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+type opener = func() (fs.File, error)
+
+type node struct {
+	info     fs.FileInfo
+	open     opener  // nil for directories
+	children []*node // sorted by name, for directories
+}
+
+// FS is a read-only file system over an archive.
+type FS struct {
+	nodes map[string]*node
+}
+
+func newFS() *FS {
+	root := &node{info: dirInfo(".")}
+	return &FS{nodes: map[string]*node{".": root}}
+}
+
+// add registers a file (open != nil) or a directory (open == nil) at name,
+// creating missing parent directories. Later members override earlier ones:
+// a file replacing a directory, or a directory replacing a file, drops the
+// old subtree.
+func (p *FS) add(name string, info fs.FileInfo, open opener) {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if name == "." || !fs.ValidPath(name) {
+		return
+	}
+	if n, ok := p.nodes[name]; ok {
+		if (open == nil) != (n.open == nil) {
+			p.drop(name)
+			n.children = nil
+		}
+		n.info, n.open = info, open
+		return
+	}
+	n := &node{info: info, open: open}
+	parent := p.parent(name)
+	p.nodes[name] = n
+	parent.children = append(parent.children, n)
+}
+
+func (p *FS) parent(name string) *node {
+	dir := path.Dir(name)
+	if n, ok := p.nodes[dir]; ok && n.open == nil {
+		return n
+	}
+	p.add(dir, dirInfo(path.Base(dir)), nil)
+	return p.nodes[dir]
+}
+
+// drop removes the nodes under the directory name.
+func (p *FS) drop(name string) {
+	prefix := name + "/"
+	for key := range p.nodes {
+		if strings.HasPrefix(key, prefix) {
+			delete(p.nodes, key)
+		}
+	}
+}
+
+func (p *FS) done() {
+	for _, n := range p.nodes {
+		if n.open == nil {
+			sort.Slice(n.children, func(i, j int) bool {
+				return n.children[i].info.Name() < n.children[j].info.Name()
+			})
+		}
+	}
+}
+
+// Open implements fs.FS.
+func (p *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	n, ok := p.nodes[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if n.open == nil {
+		return &dir{node: n}, nil
+	}
+	f, err := n.open()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return f, nil
+}
+
+// Stat implements fs.StatFS.
+func (p *FS) Stat(name string) (fs.FileInfo, error) {
+	if n, ok := p.nodes[name]; ok && fs.ValidPath(name) {
+		return n.info, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (p *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	n, ok := p.nodes[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	if n.open != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return dirEntries(n.children), nil
+}
+
+func dirEntries(nodes []*node) []fs.DirEntry {
+	ents := make([]fs.DirEntry, len(nodes))
+	for i, n := range nodes {
+		ents[i] = fs.FileInfoToDirEntry(n.info)
+	}
+	return ents
+}
+
+// -----------------------------------------------------------------------------
+
+type dirInfo string
+
+func (p dirInfo) Name() string       { return string(p) }
+func (p dirInfo) Size() int64        { return 0 }
+func (p dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (p dirInfo) ModTime() time.Time { return time.Time{} }
+func (p dirInfo) IsDir() bool        { return true }
+func (p dirInfo) Sys() interface{}   { return nil }
+
+type dir struct {
+	node   *node
+	offset int
+}
+
+func (p *dir) Stat() (fs.FileInfo, error) { return p.node.info, nil }
+func (p *dir) Close() error               { return nil }
+
+func (p *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: p.node.info.Name(), Err: errors.New("is a directory")}
+}
+
+func (p *dir) ReadDir(count int) ([]fs.DirEntry, error) {
+	rest := p.node.children[p.offset:]
+	if count > 0 {
+		if len(rest) == 0 {
+			return nil, io.EOF
+		}
+		if count < len(rest) {
+			rest = rest[:count]
+		}
+	}
+	p.offset += len(rest)
+	return dirEntries(rest), nil
+}
+
+// -----------------------------------------------------------------------------
+
+type readSeekReaderAt interface {
+	io.ReadSeeker
+	io.ReaderAt
+}
+
+// file is a seekable regular file.
+type file struct {
+	readSeekReaderAt
+	info fs.FileInfo
+}
+
+func (p *file) Stat() (fs.FileInfo, error) { return p.info, nil }
+func (p *file) Close() error               { return nil }
+
+// stream is a regular file that can only be read sequentially: it
+// implements neither io.Seeker nor io.ReaderAt.
+type stream struct {
+	io.ReadCloser
+	info fs.FileInfo
+}
+
+func (p *stream) Stat() (fs.FileInfo, error) { return p.info, nil }
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,146 @@
+package archivefs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/qiniu/x/objcache"
+)
+
+var members = []struct {
+	name string
+	data string
+}{
+	{"a.txt", "hello"},
+	{"dir/b.txt", "hello, world"},
+	{"dir/sub/c.txt", "c"},
+}
+
+func makeZip(t *testing.T, method uint16) *bytes.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, m := range members {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: m.name, Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, m.data)
+	}
+	zw.Close()
+	return bytes.NewReader(buf.Bytes())
+}
+
+func checkFS(t *testing.T, fsys fs.FS, seekable bool) {
+	if err := fstest.TestFS(fsys, "a.txt", "dir/b.txt", "dir/sub/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fsys.Open("dir/b.txt")
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	defer f.Close()
+	ra, ok := f.(io.ReaderAt)
+	if ok != seekable {
+		t.Fatal("seekable:", ok)
+	}
+	if !ok {
+		return
+	}
+	b := make([]byte, 5)
+	if _, err = ra.ReadAt(b, 7); err != nil || string(b) != "world" {
+		t.Fatal("ReadAt:", err, string(b))
+	}
+}
+
+func TestZipStore(t *testing.T) {
+	r := makeZip(t, zip.Store)
+	fsys, err := NewZip(r, r.Size(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFS(t, fsys, true)
+}
+
+func TestZipDeflate(t *testing.T) {
+	r := makeZip(t, zip.Deflate)
+	fsys, err := NewZip(r, r.Size(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFS(t, fsys, false)
+}
+
+func TestZipDeflateCached(t *testing.T) {
+	r := makeZip(t, zip.Deflate)
+	cache := NewCache("archivefs-test", 16)
+	t.Cleanup(func() { objcache.RemoveGroup("archivefs-test") })
+	fsys, err := NewZip(r, r.Size(), &Options{Cache: cache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFS(t, fsys, true)
+	if stats := cache.CacheStats(); stats.Items != 3 {
+		t.Fatal("cached items:", stats.Items)
+	}
+}
+
+func TestTar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, m := range members {
+		tw.WriteHeader(&tar.Header{Name: m.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(m.data))})
+		io.WriteString(tw, m.data)
+	}
+	tw.Close()
+	r := bytes.NewReader(buf.Bytes())
+	fsys, err := NewTar(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFS(t, fsys, true)
+}
+
+func TestTarOverlay(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	writeFile := func(name, data string) {
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))})
+		io.WriteString(tw, data)
+	}
+	writeFile("a/old.txt", "old")
+	writeFile("a", "a is a file now")
+	writeFile("b", "b is a directory now")
+	tw.WriteHeader(&tar.Header{Name: "b/", Typeflag: tar.TypeDir, Mode: 0755})
+	writeFile("b/new.txt", "new")
+	writeFile("c", "c is an implicit directory now")
+	writeFile("c/new.txt", "new")
+	tw.Close()
+	r := bytes.NewReader(buf.Bytes())
+	fsys, err := NewTar(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fs.Stat(fsys, "a/old.txt"); err == nil {
+		t.Fatal("child of a replaced directory reachable")
+	}
+	if data, err := fs.ReadFile(fsys, "a"); err != nil || string(data) != "a is a file now" {
+		t.Fatal("file over a directory:", string(data), err)
+	}
+	for _, name := range []string{"b", "c"} {
+		if fi, err := fs.Stat(fsys, name); err != nil || !fi.IsDir() {
+			t.Fatal("directory over a file:", name, err)
+		}
+		if data, err := fs.ReadFile(fsys, name+"/new.txt"); err != nil || string(data) != "new" {
+			t.Fatal("child of a directory over a file:", name, string(data), err)
+		}
+	}
+	if err = fstest.TestFS(fsys, "a", "b/new.txt", "c/new.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package archivefs
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+)
+
+// NewTar returns a file system over the uncompressed tar archive r of the
+// given size. The archive is scanned once to index the offsets of all
+// members; only directories and regular files are exposed.
+func NewTar(r io.ReaderAt, size int64) (*FS, error) {
+	sr := io.NewSectionReader(r, 0, size)
+	tr := tar.NewReader(sr)
+	p := newFS()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		info := hdr.FileInfo()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			p.add(hdr.Name, info, nil)
+		case tar.TypeReg:
+			off, err := sr.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, err
+			}
+			p.add(hdr.Name, info, tarOpener(r, off, hdr.Size, info))
+		}
+	}
+	p.done()
+	return p, nil
+}
+
+func tarOpener(r io.ReaderAt, off, size int64, info fs.FileInfo) opener {
+	return func() (fs.File, error) {
+		return &file{io.NewSectionReader(r, off, size), info}, nil
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package archivefs
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/qiniu/x/objcache"
+)
+
+// Options configures an archive file system.
+type Options struct {
+	// Cache, if not nil, holds decompressed members of zip archives. It must
+	// be created by NewCache, and may be shared by many archives.
+	Cache *objcache.Group
+}
+
+// NewCache creates an objcache group that holds at most cacheNum
+// decompressed zip members.
+func NewCache(groupName string, cacheNum int) *objcache.Group {
+	return objcache.NewGroup(groupName, cacheNum, loadMember)
+}
+
+func loadMember(ctx objcache.Context, key objcache.Key) (val objcache.Value, err error) {
+	rc, err := ctx.(*zip.File).Open()
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+var zipID int64
+
+// NewZip returns a file system over the zip archive r of the given size.
+func NewZip(r io.ReaderAt, size int64, opts *Options) (*FS, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var cache *objcache.Group
+	if opts != nil {
+		cache = opts.Cache
+	}
+	prefix := strconv.FormatInt(atomic.AddInt64(&zipID, 1), 36) + ":"
+	p := newFS()
+	for _, zf := range zr.File {
+		info := zf.FileInfo()
+		if strings.HasSuffix(zf.Name, "/") {
+			p.add(zf.Name, info, nil)
+			continue
+		}
+		p.add(zf.Name, info, zipOpener(r, zf, info, cache, prefix))
+	}
+	p.done()
+	return p, nil
+}
+
+func zipOpener(r io.ReaderAt, zf *zip.File, info fs.FileInfo, cache *objcache.Group, prefix string) opener {
+	if zf.Method == zip.Store {
+		return func() (fs.File, error) {
+			off, err := zf.DataOffset()
+			if err != nil {
+				return nil, err
+			}
+			return &file{io.NewSectionReader(r, off, int64(zf.UncompressedSize64)), info}, nil
+		}
+	}
+	if cache != nil {
+		key := prefix + zf.Name
+		return func() (fs.File, error) {
+			v, err := cache.Get(zf, key)
+			if err != nil {
+				return nil, err
+			}
+			return &file{bytes.NewReader(v.([]byte)), info}, nil
+		}
+	}
+	return func() (fs.File, error) {
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		return &stream{rc, info}, nil
+	}
+}
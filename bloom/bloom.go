@@ -0,0 +1,205 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package bloom implements space-efficient probabilistic membership filters.
+//
+// A Filter is a classic bloom filter: it never reports a false negative, and
+// reports false positives at a rate chosen when it is created. A Cuckoo filter
+// additionally supports deleting items. Both are not safe for concurrent use
+// and can be serialized with MarshalBinary/UnmarshalBinary.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/qiniu/x/hashx"
+)
+
+var (
+	// ErrInvalidData is returned when unmarshaling malformed data.
+	ErrInvalidData = errors.New("bloom: invalid data")
+)
+
+// -----------------------------------------------------------------------------
+
+// Items are hashed by FNV-1a followed by the murmur3 finalizer, which spreads
+// the entropy of FNV to all bits, since bloom filters use the low and high
+// halves independently.
+
+func hashBytes(b []byte) uint64 {
+	return hashx.Mix64(hashx.FNV1a(0).Sum64(b))
+}
+
+func hashString(s string) uint64 {
+	return hashx.Mix64(hashx.FNV1a(0).Sum64String(s))
+}
+
+// -----------------------------------------------------------------------------
+
+// Filter is a bloom filter.
+type Filter struct {
+	m    uint64 // number of bits
+	k    uint64 // number of hash functions
+	n    uint64 // number of added items
+	bits []uint64
+}
+
+// EstimateParameters returns the number of bits m and of hash functions k of
+// a filter holding n items with a false positive rate of fp.
+func EstimateParameters(n uint64, fp float64) (m, k uint64) {
+	if n == 0 {
+		n = 1
+	}
+	m = uint64(math.Ceil(-float64(n) * math.Log(fp) / (math.Ln2 * math.Ln2)))
+	k = uint64(math.Ceil(math.Ln2 * float64(m) / float64(n)))
+	if k == 0 {
+		k = 1
+	}
+	return
+}
+
+// New creates a filter of m bits using k hash functions.
+func New(m, k uint64) *Filter {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+	return &Filter{m: m, k: k, bits: make([]uint64, (m+63)/64)}
+}
+
+// NewWithEstimates creates a filter sized to hold n items with a false
+// positive rate of fp.
+func NewWithEstimates(n uint64, fp float64) *Filter {
+	return New(EstimateParameters(n, fp))
+}
+
+// Cap returns the number of bits of f.
+func (f *Filter) Cap() uint64 { return f.m }
+
+// K returns the number of hash functions of f.
+func (f *Filter) K() uint64 { return f.k }
+
+// Len returns the number of items added to f.
+func (f *Filter) Len() uint64 { return f.n }
+
+// Add adds data to f.
+func (f *Filter) Add(data []byte) { f.add(hashBytes(data)) }
+
+// AddString adds s to f.
+func (f *Filter) AddString(s string) { f.add(hashString(s)) }
+
+// Test reports whether data may be in f.
+func (f *Filter) Test(data []byte) bool { return f.test(hashBytes(data)) }
+
+// TestString reports whether s may be in f.
+func (f *Filter) TestString(s string) bool { return f.test(hashString(s)) }
+
+// TestAndAdd reports whether data may be in f, and adds it.
+func (f *Filter) TestAndAdd(data []byte) bool {
+	h := hashBytes(data)
+	ok := f.test(h)
+	f.add(h)
+	return ok
+}
+
+// TestAndAddString reports whether s may be in f, and adds it.
+func (f *Filter) TestAndAddString(s string) bool {
+	h := hashString(s)
+	ok := f.test(h)
+	f.add(h)
+	return ok
+}
+
+// AddHash adds an item by its 64-bit hash.
+func (f *Filter) AddHash(h uint64) { f.add(h) }
+
+// TestHash reports whether an item may be in f by its 64-bit hash.
+func (f *Filter) TestHash(h uint64) bool { return f.test(h) }
+
+// Clear removes all items from f.
+func (f *Filter) Clear() {
+	for i := range f.bits {
+		f.bits[i] = 0
+	}
+	f.n = 0
+}
+
+// EstimateFalsePositiveRate returns the false positive rate of f given the
+// number of items added so far.
+func (f *Filter) EstimateFalsePositiveRate() float64 {
+	return math.Pow(1-math.Exp(-float64(f.k)*float64(f.n)/float64(f.m)), float64(f.k))
+}
+
+// double hashing: the i-th hash function is h1 + i*h2 (Kirsch-Mitzenmacher).
+func (f *Filter) add(h uint64) {
+	h1, h2 := h&0xffffffff, h>>32
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		f.bits[pos>>6] |= 1 << (pos & 63)
+	}
+	f.n++
+}
+
+func (f *Filter) test(h uint64) bool {
+	h1, h2 := h&0xffffffff, h>>32
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		if f.bits[pos>>6]&(1<<(pos&63)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+const filterMagic = "BLM1"
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 4+3*8+8*len(f.bits))
+	copy(b, filterMagic)
+	binary.LittleEndian.PutUint64(b[4:], f.m)
+	binary.LittleEndian.PutUint64(b[12:], f.k)
+	binary.LittleEndian.PutUint64(b[20:], f.n)
+	for i, w := range f.bits {
+		binary.LittleEndian.PutUint64(b[28+8*i:], w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *Filter) UnmarshalBinary(b []byte) error {
+	if len(b) < 28 || string(b[:4]) != filterMagic {
+		return ErrInvalidData
+	}
+	m := binary.LittleEndian.Uint64(b[4:])
+	k := binary.LittleEndian.Uint64(b[12:])
+	words := (m + 63) / 64
+	if m == 0 || k == 0 || uint64(len(b)-28) != 8*words {
+		return ErrInvalidData
+	}
+	f.m, f.k, f.n = m, k, binary.LittleEndian.Uint64(b[20:])
+	f.bits = make([]uint64, words)
+	for i := range f.bits {
+		f.bits[i] = binary.LittleEndian.Uint64(b[28+8*i:])
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
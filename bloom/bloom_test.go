@@ -0,0 +1,95 @@
+package bloom
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	const n = 10000
+	f := NewWithEstimates(n, 0.01)
+	for i := 0; i < n; i++ {
+		f.AddString(strconv.Itoa(i))
+	}
+	for i := 0; i < n; i++ {
+		if !f.Test([]byte(strconv.Itoa(i))) {
+			t.Fatal("false negative:", i)
+		}
+	}
+	fp := 0
+	for i := n; i < 2*n; i++ {
+		if f.TestString(strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / n; rate > 0.02 {
+		t.Fatal("false positive rate:", rate)
+	}
+
+	b, _ := f.MarshalBinary()
+	var f2 Filter
+	if err := f2.UnmarshalBinary(b); err != nil {
+		t.Fatal("UnmarshalBinary:", err)
+	}
+	if f2.Len() != n || f2.K() != f.K() || !f2.TestString("42") {
+		t.Fatal("unmarshaled filter differs")
+	}
+	if err := f2.UnmarshalBinary(b[:len(b)-1]); err != ErrInvalidData {
+		t.Fatal("UnmarshalBinary truncated:", err)
+	}
+	if f.TestAndAddString("new") || !f.TestAndAddString("new") {
+		t.Fatal("TestAndAddString")
+	}
+}
+
+func TestCuckoo(t *testing.T) {
+	const n = 4000
+	c := NewCuckoo(n)
+	for i := 0; i < n; i++ {
+		if !c.InsertString(strconv.Itoa(i)) {
+			t.Fatal("Insert failed:", i)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if !c.LookupString(strconv.Itoa(i)) {
+			t.Fatal("false negative:", i)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		if !c.Delete([]byte(strconv.Itoa(i))) {
+			t.Fatal("Delete failed:", i)
+		}
+	}
+	if c.Len() != n/2 {
+		t.Fatal("Len:", c.Len())
+	}
+	for i := 1; i < n; i += 2 {
+		if !c.LookupString(strconv.Itoa(i)) {
+			t.Fatal("false negative after delete:", i)
+		}
+	}
+
+	b, _ := c.MarshalBinary()
+	var c2 Cuckoo
+	if err := c2.UnmarshalBinary(b); err != nil {
+		t.Fatal("UnmarshalBinary:", err)
+	}
+	if c2.Len() != c.Len() || !c2.LookupString("1") {
+		t.Fatal("unmarshaled filter differs")
+	}
+}
+
+func TestCuckooFull(t *testing.T) {
+	c := NewCuckoo(8)
+	i := 0
+	for ; i < 1000 && c.InsertString(strconv.Itoa(i)); i++ {
+	}
+	if i == 1000 {
+		t.Fatal("filter never gets full")
+	}
+	for j := 0; j < i; j++ {
+		if !c.LookupString(strconv.Itoa(j)) {
+			t.Fatal("false negative in full filter:", j)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bloom
+
+import (
+	"encoding/binary"
+)
+
+const (
+	bucketSize  = 4
+	maxKicks    = 500
+	cuckooMagic = "CKO1"
+
+	// magic | buckets(uint64) | count(uint64) | victimIdx(uint64) | victimFp(uint16)
+	cuckooHdrSize = 30
+)
+
+type bucket [bucketSize]uint16
+
+// Cuckoo is a cuckoo filter: a membership filter that supports deletion. It
+// stores a 16-bit fingerprint per item in buckets of 4, which gives a false
+// positive rate of about 0.01%.
+type Cuckoo struct {
+	buckets []bucket
+	mask    uint64
+	count   uint64
+	rnd     uint64
+
+	// victim is the fingerprint left homeless when an insertion ran out of
+	// kicks; once it is set, the filter is full.
+	victimFp  uint16
+	victimIdx uint64
+}
+
+// NewCuckoo creates a cuckoo filter that can hold at least capacity items.
+// Buckets are kept below 90% full, where insertions start to fail.
+func NewCuckoo(capacity uint64) *Cuckoo {
+	n := uint64(1)
+	for n*bucketSize*9/10 < capacity {
+		n <<= 1
+	}
+	return &Cuckoo{buckets: make([]bucket, n), mask: n - 1, rnd: 0x9e3779b97f4a7c15}
+}
+
+// Len returns the number of items in c.
+func (c *Cuckoo) Len() uint64 { return c.count }
+
+// Insert adds data to c. It returns false if c is full.
+func (c *Cuckoo) Insert(data []byte) bool { return c.insert(hashBytes(data)) }
+
+// InsertString adds s to c. It returns false if c is full.
+func (c *Cuckoo) InsertString(s string) bool { return c.insert(hashString(s)) }
+
+// Lookup reports whether data may be in c.
+func (c *Cuckoo) Lookup(data []byte) bool { return c.lookup(hashBytes(data)) }
+
+// LookupString reports whether s may be in c.
+func (c *Cuckoo) LookupString(s string) bool { return c.lookup(hashString(s)) }
+
+// Delete removes data from c. Only items that were inserted may be deleted.
+func (c *Cuckoo) Delete(data []byte) bool { return c.delete(hashBytes(data)) }
+
+// DeleteString removes s from c. Only items that were inserted may be deleted.
+func (c *Cuckoo) DeleteString(s string) bool { return c.delete(hashString(s)) }
+
+// Clear removes all items from c.
+func (c *Cuckoo) Clear() {
+	for i := range c.buckets {
+		c.buckets[i] = bucket{}
+	}
+	c.count = 0
+	c.victimFp = 0
+}
+
+func (c *Cuckoo) indexes(h uint64) (fp uint16, i1, i2 uint64) {
+	fp = uint16(h >> 48)
+	if fp == 0 {
+		fp = 1 // zero marks an empty slot
+	}
+	i1 = h & c.mask
+	i2 = c.altIndex(i1, fp)
+	return
+}
+
+func (c *Cuckoo) altIndex(i uint64, fp uint16) uint64 {
+	return (i ^ (uint64(fp) * 0x5bd1e995)) & c.mask
+}
+
+func (b *bucket) insert(fp uint16) bool {
+	for i, v := range b {
+		if v == 0 {
+			b[i] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) index(fp uint16) int {
+	for i, v := range b {
+		if v == fp {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Cuckoo) insert(h uint64) bool {
+	if c.victimFp != 0 {
+		return false
+	}
+	fp, i1, i2 := c.indexes(h)
+	if c.buckets[i1].insert(fp) || c.buckets[i2].insert(fp) {
+		c.count++
+		return true
+	}
+	i := i1
+	if c.random()&1 == 1 {
+		i = i2
+	}
+	for n := 0; n < maxKicks; n++ {
+		slot := c.random() % bucketSize
+		fp, c.buckets[i][slot] = c.buckets[i][slot], fp
+		i = c.altIndex(i, fp)
+		if c.buckets[i].insert(fp) {
+			c.count++
+			return true
+		}
+	}
+	c.victimFp, c.victimIdx = fp, i
+	c.count++
+	return true
+}
+
+func (c *Cuckoo) lookup(h uint64) bool {
+	fp, i1, i2 := c.indexes(h)
+	if c.victimFp == fp && (c.victimIdx == i1 || c.victimIdx == i2) {
+		return true
+	}
+	return c.buckets[i1].index(fp) >= 0 || c.buckets[i2].index(fp) >= 0
+}
+
+func (c *Cuckoo) delete(h uint64) bool {
+	fp, i1, i2 := c.indexes(h)
+	for _, i := range [2]uint64{i1, i2} {
+		if j := c.buckets[i].index(fp); j >= 0 {
+			c.buckets[i][j] = 0
+			c.count--
+			c.reinsertVictim()
+			return true
+		}
+	}
+	if c.victimFp == fp && (c.victimIdx == i1 || c.victimIdx == i2) {
+		c.victimFp = 0
+		c.count--
+		return true
+	}
+	return false
+}
+
+// reinsertVictim tries to find a home for the victim after a deletion.
+func (c *Cuckoo) reinsertVictim() {
+	if fp := c.victimFp; fp != 0 {
+		i := c.victimIdx
+		if c.buckets[i].insert(fp) || c.buckets[c.altIndex(i, fp)].insert(fp) {
+			c.victimFp = 0
+		}
+	}
+}
+
+// xorshift64
+func (c *Cuckoo) random() uint64 {
+	c.rnd ^= c.rnd << 13
+	c.rnd ^= c.rnd >> 7
+	c.rnd ^= c.rnd << 17
+	return c.rnd
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (c *Cuckoo) MarshalBinary() ([]byte, error) {
+	b := make([]byte, cuckooHdrSize+2*bucketSize*len(c.buckets))
+	copy(b, cuckooMagic)
+	binary.LittleEndian.PutUint64(b[4:], uint64(len(c.buckets)))
+	binary.LittleEndian.PutUint64(b[12:], c.count)
+	binary.LittleEndian.PutUint64(b[20:], c.victimIdx)
+	binary.LittleEndian.PutUint16(b[28:], c.victimFp)
+	off := cuckooHdrSize
+	for _, bk := range c.buckets {
+		for _, fp := range bk {
+			binary.LittleEndian.PutUint16(b[off:], fp)
+			off += 2
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (c *Cuckoo) UnmarshalBinary(b []byte) error {
+	if len(b) < cuckooHdrSize || string(b[:4]) != cuckooMagic {
+		return ErrInvalidData
+	}
+	n := binary.LittleEndian.Uint64(b[4:])
+	if n == 0 || n&(n-1) != 0 || uint64(len(b)-cuckooHdrSize) != 2*bucketSize*n {
+		return ErrInvalidData
+	}
+	c.buckets = make([]bucket, n)
+	c.mask = n - 1
+	c.count = binary.LittleEndian.Uint64(b[12:])
+	c.victimIdx = binary.LittleEndian.Uint64(b[20:]) & c.mask
+	c.victimFp = binary.LittleEndian.Uint16(b[28:])
+	if c.rnd == 0 {
+		c.rnd = 0x9e3779b97f4a7c15
+	}
+	off := cuckooHdrSize
+	for i := range c.buckets {
+		for j := range c.buckets[i] {
+			c.buckets[i][j] = binary.LittleEndian.Uint16(b[off:])
+			off += 2
+		}
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package breaker implements a circuit breaker.
+//
+// A Breaker watches the outcome of calls to a backend over a rolling window.
+// When too many of them fail it opens, and calls fail fast with ErrOpen
+// instead of piling up on a backend that is down. After OpenTimeout it lets
+// a few probe calls through (half-open): if they succeed the breaker closes
+// again, otherwise it reopens.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOpen is returned when the breaker is open.
+	ErrOpen = errors.New("breaker: circuit open")
+
+	// ErrTooManyProbes is returned when the breaker is half-open and all its
+	// probes are in flight.
+	ErrTooManyProbes = errors.New("breaker: too many probes")
+)
+
+// State is the state of a breaker.
+type State int
+
+const (
+	// Closed lets all calls through.
+	Closed State = iota
+
+	// Open rejects all calls.
+	Open
+
+	// HalfOpen lets a limited number of probe calls through.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Options configures a Breaker. Zero fields take their defaults.
+type Options struct {
+	// Window is the length of the rolling window of outcomes. Default 10s.
+	Window time.Duration
+
+	// Buckets is the number of buckets of the rolling window. Default 10.
+	Buckets int
+
+	// MinRequests is the number of calls in the window below which the
+	// failure ratio doesn't trip the breaker. Default 10.
+	MinRequests int
+
+	// FailureRatio trips the breaker when the ratio of failed calls in the
+	// window reaches it. Default 0.5.
+	FailureRatio float64
+
+	// ConsecutiveFailures, if positive, also trips the breaker after that
+	// many failures in a row, regardless of MinRequests.
+	ConsecutiveFailures int
+
+	// OpenTimeout is how long the breaker stays open before probing.
+	// Default 5s.
+	OpenTimeout time.Duration
+
+	// HalfOpenProbes is the number of concurrent probes when half-open, and
+	// the number of successful probes that close the breaker. Default 1.
+	HalfOpenProbes int
+
+	// IsFailure decides whether the error of a call counts as a failure.
+	// Default: any error except context.Canceled.
+	IsFailure func(err error) bool
+
+	// OnStateChange, if set, is called after each state transition.
+	OnStateChange func(from, to State)
+}
+
+// Counts are the statistics of the rolling window.
+type Counts struct {
+	Requests            int
+	Failures            int
+	ConsecutiveFailures int
+}
+
+type bucket struct {
+	requests, failures int
+}
+
+// -----------------------------------------------------------------------------
+
+// Breaker is a circuit breaker. It is safe for concurrent use.
+type Breaker struct {
+	opts Options
+
+	mu          sync.Mutex
+	state       State
+	generation  uint64 // incremented on each transition
+	openedAt    time.Time
+	buckets     []bucket
+	cur         int       // the current bucket
+	curStart    time.Time // start time of the current bucket
+	consecutive int
+	probes      int // probes in flight
+	successes   int // successful probes
+	now         func() time.Time
+}
+
+// New creates a breaker. opts may be nil.
+func New(opts *Options) *Breaker {
+	b := &Breaker{now: time.Now}
+	if opts != nil {
+		b.opts = *opts
+	}
+	o := &b.opts
+	if o.Window <= 0 {
+		o.Window = 10 * time.Second
+	}
+	if o.Buckets <= 0 {
+		o.Buckets = 10
+	}
+	if o.MinRequests <= 0 {
+		o.MinRequests = 10
+	}
+	if o.FailureRatio <= 0 {
+		o.FailureRatio = 0.5
+	}
+	if o.OpenTimeout <= 0 {
+		o.OpenTimeout = 5 * time.Second
+	}
+	if o.HalfOpenProbes <= 0 {
+		o.HalfOpenProbes = 1
+	}
+	if o.IsFailure == nil {
+		o.IsFailure = isFailure
+	}
+	b.buckets = make([]bucket, o.Buckets)
+	b.curStart = b.now()
+	return b
+}
+
+func isFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// Do calls fn if the breaker allows it, and records its outcome. It returns
+// ErrOpen or ErrTooManyProbes without calling fn if it doesn't, and
+// ctx.Err() if ctx is already done.
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			done(errPanic)
+			panic(e)
+		}
+	}()
+	err = fn(ctx)
+	done(err)
+	return err
+}
+
+var errPanic = errors.New("breaker: panic")
+
+// Allow reports whether a call may proceed. If it may, the caller must call
+// done exactly once with the outcome of the call.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	now := b.now()
+	from := b.state
+	if b.state == Open && now.Sub(b.openedAt) >= b.opts.OpenTimeout {
+		b.setStateLocked(HalfOpen, now)
+	}
+	switch b.state {
+	case Open:
+		err = ErrOpen
+	case HalfOpen:
+		if b.probes >= b.opts.HalfOpenProbes {
+			err = ErrTooManyProbes
+		} else {
+			b.probes++
+		}
+	}
+	gen, to := b.generation, b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return func(err error) { b.record(gen, b.opts.IsFailure(err)) }, nil
+}
+
+func (b *Breaker) record(gen uint64, failed bool) {
+	b.mu.Lock()
+	from := b.state
+	if gen != b.generation { // the outcome of a call of a past state
+		b.mu.Unlock()
+		return
+	}
+	now := b.now()
+	switch b.state {
+	case Closed:
+		bk := b.bucketLocked(now)
+		bk.requests++
+		if failed {
+			bk.failures++
+			b.consecutive++
+		} else {
+			b.consecutive = 0
+		}
+		if failed && b.shouldTripLocked() {
+			b.setStateLocked(Open, now)
+		}
+	case HalfOpen:
+		b.probes--
+		if failed {
+			b.setStateLocked(Open, now)
+		} else if b.successes++; b.successes >= b.opts.HalfOpenProbes {
+			b.setStateLocked(Closed, now)
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *Breaker) shouldTripLocked() bool {
+	if n := b.opts.ConsecutiveFailures; n > 0 && b.consecutive >= n {
+		return true
+	}
+	c := b.countsLocked()
+	return c.Requests >= b.opts.MinRequests &&
+		float64(c.Failures) >= b.opts.FailureRatio*float64(c.Requests)
+}
+
+func (b *Breaker) setStateLocked(state State, now time.Time) {
+	b.state = state
+	b.generation++
+	b.probes, b.successes, b.consecutive = 0, 0, 0
+	switch state {
+	case Open:
+		b.openedAt = now
+	case Closed:
+		for i := range b.buckets {
+			b.buckets[i] = bucket{}
+		}
+		b.curStart = now
+	}
+}
+
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(from, to)
+	}
+}
+
+// bucketLocked rolls the window forward to now and returns the current
+// bucket.
+func (b *Breaker) bucketLocked(now time.Time) *bucket {
+	width := b.opts.Window / time.Duration(len(b.buckets))
+	for n := 0; now.Sub(b.curStart) >= width; n++ {
+		if n >= len(b.buckets) { // idle for a whole window
+			for i := range b.buckets {
+				b.buckets[i] = bucket{}
+			}
+			b.curStart = now
+			break
+		}
+		b.cur = (b.cur + 1) % len(b.buckets)
+		b.buckets[b.cur] = bucket{}
+		b.curStart = b.curStart.Add(width)
+	}
+	return &b.buckets[b.cur]
+}
+
+func (b *Breaker) countsLocked() (c Counts) {
+	for _, bk := range b.buckets {
+		c.Requests += bk.requests
+		c.Failures += bk.failures
+	}
+	c.ConsecutiveFailures = b.consecutive
+	return
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	from := b.state
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.opts.OpenTimeout {
+		b.setStateLocked(HalfOpen, b.now())
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return to
+}
+
+// Counts returns the statistics of the rolling window.
+func (b *Breaker) Counts() Counts {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucketLocked(b.now())
+	return b.countsLocked()
+}
+
+// Reset closes the breaker and clears its statistics.
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	from := b.state
+	b.setStateLocked(Closed, b.now())
+	b.mu.Unlock()
+	b.notify(from, Closed)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,142 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestBreaker(opts *Options) (*Breaker, *clock) {
+	c := &clock{t: time.Unix(1e9, 0)}
+	b := New(opts)
+	b.now = c.now
+	b.curStart = c.t
+	return b, c
+}
+
+var errTest = errors.New("test error")
+
+func fail(ctx context.Context) error    { return errTest }
+func succeed(ctx context.Context) error { return nil }
+
+func TestBreaker(t *testing.T) {
+	var trans []string
+	b, c := newTestBreaker(&Options{
+		MinRequests: 4, OpenTimeout: time.Second, HalfOpenProbes: 2,
+		OnStateChange: func(from, to State) { trans = append(trans, from.String()+">"+to.String()) },
+	})
+	ctx := context.Background()
+	b.Do(ctx, succeed)
+	b.Do(ctx, succeed)
+	b.Do(ctx, fail)
+	if b.State() != Closed {
+		t.Fatal("tripped below MinRequests")
+	}
+	b.Do(ctx, fail)
+	if b.State() != Open {
+		t.Fatal("not tripped:", b.Counts())
+	}
+	if err := b.Do(ctx, succeed); err != ErrOpen {
+		t.Fatal("Do when open:", err)
+	}
+
+	c.t = c.t.Add(time.Second)
+	done1, err1 := b.Allow()
+	done2, err2 := b.Allow()
+	if _, err := b.Allow(); err1 != nil || err2 != nil || err != ErrTooManyProbes {
+		t.Fatal("half-open probes:", err1, err2, err)
+	}
+	done1(nil)
+	done2(errTest)
+	if b.State() != Open {
+		t.Fatal("failed probe doesn't reopen")
+	}
+
+	c.t = c.t.Add(time.Second)
+	b.Do(ctx, succeed)
+	b.Do(ctx, succeed)
+	if b.State() != Closed {
+		t.Fatal("probes don't close")
+	}
+	want := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if len(trans) != len(want) {
+		t.Fatal("transitions:", trans)
+	}
+	for i := range want {
+		if trans[i] != want[i] {
+			t.Fatal("transitions:", trans)
+		}
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := b.Do(cctx, succeed); err != context.Canceled {
+		t.Fatal("Do with canceled ctx:", err)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	b, c := newTestBreaker(&Options{Window: 10 * time.Second, MinRequests: 3, ConsecutiveFailures: 5})
+	ctx := context.Background()
+	b.Do(ctx, succeed)
+	b.Do(ctx, fail)
+	c.t = c.t.Add(5 * time.Second)
+	b.Do(ctx, succeed)
+	if cnt := b.Counts(); cnt.Requests != 3 || cnt.Failures != 1 {
+		t.Fatal("counts:", cnt)
+	}
+	c.t = c.t.Add(6 * time.Second)
+	if cnt := b.Counts(); cnt.Requests != 1 || cnt.Failures != 0 {
+		t.Fatal("counts after rolling:", cnt)
+	}
+	c.t = c.t.Add(time.Minute)
+	if cnt := b.Counts(); cnt.Requests != 0 {
+		t.Fatal("counts after idle:", cnt)
+	}
+
+	b, _ = newTestBreaker(&Options{MinRequests: 100, ConsecutiveFailures: 3})
+	for i := 0; i < 20; i++ {
+		b.Do(ctx, succeed)
+	}
+	b.Do(ctx, fail)
+	b.Do(ctx, fail)
+	b.Do(ctx, func(context.Context) error { return context.Canceled }) // not a failure
+	b.Do(ctx, fail)
+	b.Do(ctx, fail)
+	if b.State() != Closed {
+		t.Fatal("tripped early")
+	}
+	b.Do(ctx, fail)
+	if b.State() != Open {
+		t.Fatal("consecutive failures don't trip")
+	}
+	b.Reset()
+	if b.State() != Closed || b.Counts().Requests != 0 {
+		t.Fatal("Reset")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+	}))
+	defer ts.Close()
+	b := New(&Options{ConsecutiveFailures: 2, OpenTimeout: time.Hour})
+	client := &http.Client{Transport: &Transport{Breaker: b}}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(ts.URL)
+		if err != nil || resp.StatusCode != 503 {
+			t.Fatal(resp, err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get(ts.URL); !errors.Is(err, ErrOpen) {
+		t.Fatal("Get when open:", err)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package breaker
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Transport is an http.RoundTripper that guards Base with a Breaker. A
+// request fails fast with ErrOpen while the breaker is open.
+type Transport struct {
+	Base    http.RoundTripper // nil means http.DefaultTransport
+	Breaker *Breaker
+
+	// IsFailure decides whether a response counts as a failure. Default: a
+	// transport error or a 5xx status code.
+	IsFailure func(resp *http.Response, err error) bool
+}
+
+var errServer = errors.New("breaker: server error")
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	done, err := t.Breaker.Allow()
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	failed := err != nil || resp.StatusCode >= 500
+	if t.IsFailure != nil {
+		failed = t.IsFailure(resp, err)
+	}
+	switch {
+	case !failed:
+		done(nil)
+	case err != nil:
+		done(err)
+	default:
+		done(errServer)
+	}
+	return resp, err
+}
+
+// NestedObject returns the base transport, so that request cancelation of
+// the rpc package reaches it.
+func (t *Transport) NestedObject() interface{} {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
@@ -0,0 +1,108 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bufiox
+
+import (
+	"bytes"
+	"net"
+)
+
+// -----------------------------------------------------------------------------
+
+// PeekConn is a net.Conn whose leading bytes can be peeked before they are
+// read, for protocol sniffing: the peeked bytes are replayed by Read, so
+// the connection can then be handed to the real handler as if nothing had
+// been read from it.
+type PeekConn struct {
+	net.Conn
+	buf []byte // peeked, not yet read
+}
+
+// NewPeekConn returns a PeekConn reading from c.
+func NewPeekConn(c net.Conn) *PeekConn {
+	return &PeekConn{Conn: c}
+}
+
+// Peek returns the next n bytes without consuming them, reading from the
+// connection as needed; n has no limit. If fewer than n bytes could be read,
+// it returns them with the read error. The returned slice is valid until
+// the next call of Peek or Read. Use SetReadDeadline to bound the wait.
+func (c *PeekConn) Peek(n int) ([]byte, error) {
+	if cap(c.buf) < n {
+		buf := make([]byte, len(c.buf), n)
+		copy(buf, c.buf)
+		c.buf = buf
+	}
+	for len(c.buf) < n {
+		nn, err := c.Conn.Read(c.buf[len(c.buf):n])
+		c.buf = c.buf[:len(c.buf)+nn]
+		if err != nil {
+			return c.buf, err
+		}
+	}
+	return c.buf[:n], nil
+}
+
+// Buffered returns the number of peeked bytes not read yet.
+func (c *PeekConn) Buffered() int {
+	return len(c.buf)
+}
+
+// Read reads the peeked bytes first, and then from the connection.
+func (c *PeekConn) Read(p []byte) (n int, err error) {
+	if len(c.buf) > 0 {
+		n = copy(p, c.buf)
+		c.buf = c.buf[n:]
+		if len(c.buf) == 0 {
+			c.buf = nil
+		}
+		return
+	}
+	return c.Conn.Read(p)
+}
+
+// -----------------------------------------------------------------------------
+
+// IsTLS reports whether prefix, the first 3 bytes (or more) of a
+// connection, starts a TLS handshake.
+func IsTLS(prefix []byte) bool {
+	// record type handshake, protocol version 3.x
+	return len(prefix) >= 3 && prefix[0] == 0x16 && prefix[1] == 3 && prefix[2] <= 4
+}
+
+var httpMethods = []string{
+	"GET ", "HEAD ", "POST ", "PUT ", "DELETE ", "CONNECT ", "OPTIONS ", "TRACE ", "PATCH ",
+}
+
+// IsHTTP reports whether prefix, the first 8 bytes (or more) of a
+// connection, starts an HTTP/1.x request.
+func IsHTTP(prefix []byte) bool {
+	for _, m := range httpMethods {
+		if bytes.HasPrefix(prefix, []byte(m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHTTP2 reports whether prefix, the first 24 bytes of a connection, is the
+// HTTP/2 client preface (prior knowledge h2c).
+func IsHTTP2(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"))
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,44 @@
+package bufiox
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+func TestPeekConn(t *testing.T) {
+	client, server := net.Pipe()
+	go func() {
+		client.Write([]byte("GET / HTTP/1.1\r\n"))
+		client.Write([]byte("Host: x\r\n\r\n"))
+		client.Close()
+	}()
+	c := NewPeekConn(server)
+	prefix, err := c.Peek(8)
+	if err != nil || !IsHTTP(prefix) || IsTLS(prefix) || IsHTTP2(prefix) {
+		t.Fatalf("Peek(8) = %q, %v", prefix, err)
+	}
+	if prefix, err = c.Peek(20); err != nil || string(prefix) != "GET / HTTP/1.1\r\nHost" {
+		t.Fatalf("Peek(20) = %q, %v", prefix, err)
+	}
+	b := make([]byte, 4)
+	if n, _ := c.Read(b); string(b[:n]) != "GET " || c.Buffered() != 16 {
+		t.Fatalf("Read = %q, %d buffered", b[:n], c.Buffered())
+	}
+	rest, err := ioutil.ReadAll(c)
+	if err != nil || string(rest) != "/ HTTP/1.1\r\nHost: x\r\n\r\n" {
+		t.Fatalf("ReadAll = %q, %v", rest, err)
+	}
+	if prefix, err = c.Peek(1); err == nil || len(prefix) != 0 {
+		t.Fatal("Peek at EOF:", prefix, err)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	if !IsTLS([]byte{0x16, 3, 1, 2, 0}) || IsTLS([]byte{0x16, 2, 1}) {
+		t.Fatal("IsTLS")
+	}
+	if !IsHTTP2([]byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")) || IsHTTP([]byte("PRI * HT")) {
+		t.Fatal("IsHTTP2")
+	}
+}
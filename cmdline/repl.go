@@ -0,0 +1,493 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cmdline
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"unicode"
+)
+
+// ---------------------------------------------------------------------------
+
+var (
+	// ErrExit stops a REPL when it is returned by its Exec.
+	ErrExit = errors.New("exit")
+
+	// ErrInterrupt is returned by the line editor when Ctrl-C is typed.
+	ErrInterrupt = errors.New("interrupt")
+)
+
+// DefaultHistorySize is the number of history lines kept by default.
+const DefaultHistorySize = 1000
+
+// REPL is an interactive read-eval-print loop. Lines read are tokenized by
+// Parser; a command left incomplete (an unterminated string, or a line
+// ending with '\') continues on the next line. Each command is passed to
+// Exec, which typically dispatches it to a subcommand (see app.Run).
+//
+// When In is a terminal the lines are read by a line editor supporting the
+// usual Emacs keys, history (up/down) and completion (tab). Ctrl-C discards
+// the line being typed, and cancels the context of a running command.
+// Ctrl-D on an empty line ends the loop.
+type REPL struct {
+	// Exec executes a command.
+	Exec func(ctx context.Context, args []string) error
+
+	// Complete, if not nil, returns the completions of the text before the
+	// cursor. The completions replace that text.
+	Complete func(line string) []string
+
+	// Parser tokenizes the commands. Nil means NewParser().
+	Parser *Parser
+
+	// Prompt and ContPrompt are the prompts of the first and continuation
+	// lines of a command. They default to "> " and ". ".
+	Prompt, ContPrompt string
+
+	// HistoryFile, if not empty, is where the history is loaded from and
+	// saved to.
+	HistoryFile string
+
+	// HistorySize is the number of history lines kept. Zero means
+	// DefaultHistorySize.
+	HistorySize int
+
+	// In, Out and Err default to os.Stdin, os.Stdout and os.Stderr.
+	In       io.Reader
+	Out, Err io.Writer
+
+	history []string
+}
+
+// History returns the history lines, from the oldest one.
+func (r *REPL) History() []string {
+	return r.history
+}
+
+// Run runs the loop until the input ends, Exec returns ErrExit or ctx is
+// done. Errors of the commands are printed to Err and don't stop the loop.
+func (r *REPL) Run(ctx context.Context) error {
+	if r.In == nil {
+		r.In = os.Stdin
+	}
+	if r.Out == nil {
+		r.Out = os.Stdout
+	}
+	if r.Err == nil {
+		r.Err = os.Stderr
+	}
+	if r.Parser == nil {
+		r.Parser = NewParser()
+	}
+	if err := r.loadHistory(); err != nil {
+		return err
+	}
+	in := bufio.NewReader(r.In)
+	if f, ok := r.In.(*os.File); ok && isTerminal(int(f.Fd())) {
+		fd := int(f.Fd())
+		restore, err := makeRaw(fd)
+		if err != nil {
+			return err
+		}
+		err = r.loop(ctx, func(prompt string) (string, error) {
+			return r.editLine(in, prompt)
+		}, func() func() {
+			restore()
+			return func() {
+				if f, err := makeRaw(fd); err == nil {
+					restore = f
+				}
+			}
+		})
+		restore()
+		return r.finish(err)
+	}
+	return r.finish(r.loop(ctx, func(string) (string, error) {
+		return readLine(in)
+	}, nil))
+}
+
+func (r *REPL) finish(err error) error {
+	if e := r.saveHistory(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// loop runs the REPL with lines read by read. pause, if not nil, gives the
+// terminal back in its normal mode while a command runs, and returns the
+// function that resumes the line editing mode.
+func (r *REPL) loop(ctx context.Context, read func(prompt string) (string, error), pause func() func()) error {
+	prompt, contPrompt := r.Prompt, r.ContPrompt
+	if prompt == "" {
+		prompt = "> "
+	}
+	if contPrompt == "" {
+		contPrompt = ". "
+	}
+	var code string
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p := prompt
+		if code != "" {
+			p = contPrompt
+		}
+		line, err := read(p)
+		if err == ErrInterrupt {
+			code = ""
+			continue
+		}
+		if err == io.EOF {
+			if code != "" {
+				return io.ErrUnexpectedEOF
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.addHistory(line)
+		if n := len(line) - len(strings.TrimRight(line, `\`)); n%2 == 1 {
+			code += line[:len(line)-1] + " "
+			continue
+		}
+		code += line
+		cmds, err := r.parse(code)
+		if err != nil && strings.HasPrefix(err.Error(), "incomplete string") {
+			code += "\n"
+			continue
+		}
+		code = ""
+		if err != nil {
+			fmt.Fprintln(r.Err, err)
+			continue
+		}
+		for _, cmd := range cmds {
+			var resume func()
+			if pause != nil {
+				resume = pause()
+			}
+			err = r.exec(ctx, cmd)
+			if resume != nil {
+				resume()
+			}
+			if err == ErrExit {
+				return nil
+			}
+			if err != nil {
+				fmt.Fprintln(r.Err, err)
+			}
+		}
+	}
+}
+
+func (r *REPL) parse(code string) (cmds [][]string, err error) {
+	for {
+		cmd, next, err := r.Parser.ParseCode(code)
+		if len(cmd) > 0 {
+			cmds = append(cmds, cmd)
+		}
+		if err == io.EOF {
+			return cmds, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		code = next
+	}
+}
+
+// exec runs a command, canceling its context on SIGINT.
+func (r *REPL) exec(ctx context.Context, args []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return r.Exec(ctx, args)
+}
+
+func readLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// ---------------------------------------------------------------------------
+
+func (r *REPL) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(r.history); n > 0 && r.history[n-1] == line {
+		return
+	}
+	r.history = append(r.history, line)
+	if max := r.historySize(); len(r.history) > 2*max {
+		r.history = append(r.history[:0], r.history[len(r.history)-max:]...)
+	}
+}
+
+func (r *REPL) historySize() int {
+	if r.HistorySize > 0 {
+		return r.HistorySize
+	}
+	return DefaultHistorySize
+}
+
+func (r *REPL) loadHistory() error {
+	if r.HistoryFile == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(r.HistoryFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		r.addHistory(line)
+	}
+	return nil
+}
+
+func (r *REPL) saveHistory() error {
+	if r.HistoryFile == "" {
+		return nil
+	}
+	h := r.history
+	if max := r.historySize(); len(h) > max {
+		h = h[len(h)-max:]
+	}
+	var b strings.Builder
+	for _, line := range h {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return ioutil.WriteFile(r.HistoryFile, []byte(b.String()), 0600)
+}
+
+// ---------------------------------------------------------------------------
+
+// editLine reads a line from a terminal in raw mode.
+func (r *REPL) editLine(in *bufio.Reader, prompt string) (string, error) {
+	var buf []rune
+	pos := 0
+	hi, saved := len(r.history), ""
+	refresh := func() {
+		var b strings.Builder
+		b.WriteString("\r" + prompt + string(buf) + "\x1b[K")
+		if n := len(buf) - pos; n > 0 {
+			fmt.Fprintf(&b, "\x1b[%dD", n)
+		}
+		io.WriteString(r.Out, b.String())
+	}
+	setLine := func(s string) {
+		buf = []rune(s)
+		pos = len(buf)
+	}
+	refresh()
+	for {
+		c, _, err := in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		var key rune // of the keys also sent as escape sequences
+		switch c {
+		case '\r', '\n':
+			io.WriteString(r.Out, "\r\n")
+			return string(buf), nil
+		case 3: // Ctrl-C
+			io.WriteString(r.Out, "^C\r\n")
+			return "", ErrInterrupt
+		case 4: // Ctrl-D
+			if len(buf) == 0 {
+				io.WriteString(r.Out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(buf) {
+				buf = append(buf[:pos], buf[pos+1:]...)
+			}
+		case 1: // Ctrl-A
+			pos = 0
+		case 5: // Ctrl-E
+			pos = len(buf)
+		case 2: // Ctrl-B
+			if pos > 0 {
+				pos--
+			}
+		case 6: // Ctrl-F
+			if pos < len(buf) {
+				pos++
+			}
+		case 8, 127: // Backspace
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+			}
+		case 11: // Ctrl-K
+			buf = buf[:pos]
+		case 21: // Ctrl-U
+			buf = append([]rune(nil), buf[pos:]...)
+			pos = 0
+		case 23: // Ctrl-W
+			i := pos
+			for i > 0 && buf[i-1] == ' ' {
+				i--
+			}
+			for i > 0 && buf[i-1] != ' ' {
+				i--
+			}
+			buf = append(buf[:i], buf[pos:]...)
+			pos = i
+		case 12: // Ctrl-L
+			io.WriteString(r.Out, "\x1b[H\x1b[2J")
+		case 16: // Ctrl-P
+			key = 'A'
+		case 14: // Ctrl-N
+			key = 'B'
+		case '\t':
+			if r.Complete != nil {
+				buf, pos = r.completeLine(buf, pos)
+			}
+		case 27: // escape sequence
+			key = readEscape(in)
+		default:
+			if unicode.IsPrint(c) {
+				buf = append(buf, 0)
+				copy(buf[pos+1:], buf[pos:])
+				buf[pos] = c
+				pos++
+			}
+		}
+		switch key {
+		case 'A': // Up
+			if hi > 0 {
+				if hi == len(r.history) {
+					saved = string(buf)
+				}
+				hi--
+				setLine(r.history[hi])
+			}
+		case 'B': // Down
+			if hi < len(r.history) {
+				if hi++; hi == len(r.history) {
+					setLine(saved)
+				} else {
+					setLine(r.history[hi])
+				}
+			}
+		case 'C': // Right
+			if pos < len(buf) {
+				pos++
+			}
+		case 'D': // Left
+			if pos > 0 {
+				pos--
+			}
+		case 'H': // Home
+			pos = 0
+		case 'F': // End
+			pos = len(buf)
+		case 'X': // Delete
+			if pos < len(buf) {
+				buf = append(buf[:pos], buf[pos+1:]...)
+			}
+		}
+		refresh()
+	}
+}
+
+// readEscape reads the rest of an escape sequence and returns the key it
+// stands for: 'A' to 'D' for the arrows, 'H' and 'F' for Home and End, 'X'
+// for Delete, and 0 for the others.
+func readEscape(in *bufio.Reader) rune {
+	c, err := in.ReadByte()
+	if err != nil || (c != '[' && c != 'O') {
+		return 0
+	}
+	var param []byte
+	for {
+		c, err = in.ReadByte()
+		if err != nil {
+			return 0
+		}
+		if c >= 0x40 && c <= 0x7e {
+			break
+		}
+		param = append(param, c)
+	}
+	switch c {
+	case 'A', 'B', 'C', 'D', 'H', 'F':
+		return rune(c)
+	case '~':
+		switch string(param) {
+		case "1", "7":
+			return 'H'
+		case "4", "8":
+			return 'F'
+		case "3":
+			return 'X'
+		}
+	}
+	return 0
+}
+
+func (r *REPL) completeLine(buf []rune, pos int) ([]rune, int) {
+	head := string(buf[:pos])
+	cands := r.Complete(head)
+	if len(cands) == 0 {
+		io.WriteString(r.Out, "\a")
+		return buf, pos
+	}
+	prefix := cands[0]
+	for _, c := range cands[1:] {
+		i := 0
+		for i < len(prefix) && i < len(c) && prefix[i] == c[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	if len(cands) > 1 && len(prefix) <= len(head) {
+		io.WriteString(r.Out, "\r\n"+strings.Join(cands, "  ")+"\r\n")
+		return buf, pos
+	}
+	p := []rune(prefix)
+	return append(p, buf[pos:]...), len(p)
+}
+
+// ---------------------------------------------------------------------------
@@ -0,0 +1,37 @@
+//go:build !linux
+// +build !linux
+
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cmdline
+
+import "errors"
+
+// ---------------------------------------------------------------------------
+
+// The line editor is only supported on Linux: elsewhere a REPL reads plain
+// lines.
+
+func isTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (restore func(), err error) {
+	return nil, errors.New("cmdline: raw terminal mode not supported")
+}
+
+// ---------------------------------------------------------------------------
@@ -0,0 +1,128 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cmdline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+
+func TestREPL(t *testing.T) {
+	var got [][]string
+	var errOut bytes.Buffer
+	hist := filepath.Join(t.TempDir(), "history")
+	r := &REPL{
+		Exec: func(ctx context.Context, args []string) error {
+			switch args[0] {
+			case "fail":
+				return errors.New("failed")
+			case "exit":
+				return ErrExit
+			}
+			got = append(got, args)
+			return nil
+		},
+		In:          strings.NewReader("get a b; put c\nfail\necho 'x\ny'\nlong \\\nline\n\nexit\nnot reached\n"),
+		Out:         ioutil.Discard,
+		Err:         &errOut,
+		HistoryFile: hist,
+	}
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatal("Run:", err)
+	}
+	want := [][]string{{"get", "a", "b"}, {"put", "c"}, {"echo", "x\ny"}, {"long", "line"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatal("commands:", got)
+	}
+	if errOut.String() != "failed\n" {
+		t.Fatal("errors:", errOut.String())
+	}
+	b, err := ioutil.ReadFile(hist)
+	if err != nil {
+		t.Fatal("ReadFile:", err)
+	}
+	h := "get a b; put c\nfail\necho 'x\ny'\nlong \\\nline\nexit\n"
+	if string(b) != h {
+		t.Fatalf("history file: %q", b)
+	}
+
+	r2 := &REPL{Exec: r.Exec, In: strings.NewReader("echo 'x\n"), Out: ioutil.Discard, HistoryFile: hist, HistorySize: 2}
+	if err = r2.Run(context.Background()); err != io.ErrUnexpectedEOF {
+		t.Fatal("Run incomplete:", err)
+	}
+	if h := r2.History(); len(h) == 0 || h[len(h)-1] != "echo 'x" {
+		t.Fatal("History:", h)
+	}
+	if b, _ = ioutil.ReadFile(hist); string(b) != "exit\necho 'x\n" {
+		t.Fatalf("history file trimmed: %q", b)
+	}
+}
+
+func TestEditLine(t *testing.T) {
+	r := &REPL{
+		Out:     ioutil.Discard,
+		history: []string{"first", "second"},
+		Complete: func(line string) []string {
+			var cands []string
+			for _, c := range []string{"get", "getall", "put"} {
+				if strings.HasPrefix(c, line) {
+					cands = append(cands, c)
+				}
+			}
+			return cands
+		},
+	}
+	cases := []struct {
+		in, line string
+		err      error
+	}{
+		{"abc\x1b[D\x1b[DX\r", "aXbc", nil},
+		{"abc\x01X\x05Y\r", "XabcY", nil},
+		{"abc\x7f\x7f\r", "a", nil},
+		{"one two\x17\r", "one ", nil},
+		{"abcd\x02\x02\x0b\r", "ab", nil},
+		{"abcd\x02\x02\x15\r", "cd", nil},
+		{"abc\x01\x1b[3~\r", "bc", nil},
+		{"\x1b[A\x1b[A\r", "first", nil},
+		{"new\x1b[A\x1b[B\r", "new", nil},
+		{"\x10\x10\x0e\r", "second", nil},
+		{"p\t\r", "put", nil},
+		{"g\t\r", "get", nil},
+		{"getall\x01\x1b[C\x1b[C\x1b[C\x0b\t\r", "get", nil},
+		{"abc\x03", "", ErrInterrupt},
+		{"\x04", "", io.EOF},
+		{"ab\x01\x04\r", "b", nil},
+	}
+	for _, c := range cases {
+		line, err := r.editLine(bufio.NewReader(strings.NewReader(c.in)), "> ")
+		if line != c.line || err != c.err {
+			t.Fatalf("editLine(%q) = %q, %v", c.in, line, err)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
@@ -0,0 +1,343 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// A DecodeFunc decodes the content of a config file into v.
+type DecodeFunc = func(data []byte, v interface{}) error
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]DecodeFunc{
+		".json": decodeJSON,
+		".conf": decodeJSON,
+		".yaml": decodeYAML,
+		".yml":  decodeYAML,
+		".toml": decodeTOML,
+	}
+)
+
+func decodeJSON(data []byte, v interface{}) error {
+	return LoadBytes(v, data)
+}
+
+// RegisterDecoder registers the decoder of config files with the extension
+// ext (e.g. ".ini"), replacing the previous one. JSON (".json" and ".conf",
+// with # comments), YAML (".yaml" and ".yml") and TOML (".toml") are built
+// in, without depending on any third-party parser: the YAML decoder only
+// supports the subset configs use, see decodeYAML. An application can
+// register a full parser instead.
+func RegisterDecoder(ext string, decode DecodeFunc) {
+	decodersMu.Lock()
+	decoders[strings.ToLower(ext)] = decode
+	decodersMu.Unlock()
+}
+
+func decoderOf(name string) (DecodeFunc, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	decodersMu.RLock()
+	decode, ok := decoders[ext]
+	decodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("config: no decoder for %q files", ext)
+	}
+	return decode, nil
+}
+
+// A Validator is a config that checks itself after loading.
+type Validator interface {
+	Validate() error
+}
+
+// -----------------------------------------------------------------------------
+
+// Loader loads a config struct from several layers. Later layers override
+// earlier ones:
+//
+//  1. defaults, from `default:"..."` field tags;
+//  2. config files, in the order of Files;
+//  3. environment variables, from `env:"NAME"` field tags (prefixed by EnvPrefix);
+//  4. command-line flags that were explicitly set, from `flag:"name"` field tags.
+//
+// Nested structs are walked recursively. After loading, the config is
+// validated by its Validate method (if it is a Validator) and then by the
+// Validate hooks.
+type Loader struct {
+	// Files are the config files. A missing file is skipped unless it is
+	// the only one.
+	Files []string
+
+	// EnvPrefix is prepended to the names of env tags.
+	EnvPrefix string
+
+	// Flags provides the flags bound by flag tags. Nil means no flag layer.
+	Flags *flag.FlagSet
+
+	// Validate are extra validation hooks run after loading.
+	Validate []func(conf interface{}) error
+
+	// Getenv looks up environment variables. Nil means os.LookupEnv.
+	Getenv func(key string) (string, bool)
+
+	// WatchInterval is how often Watch polls the config files. Default
+	// one second.
+	WatchInterval time.Duration
+}
+
+// Load loads conf, which must be a pointer to a struct.
+func (p *Loader) Load(conf interface{}) error {
+	v := reflect.ValueOf(conf)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("config: conf must be a pointer to struct")
+	}
+	if err := walk(v.Elem(), "default", func(f reflect.Value, tag string) error {
+		return setValue(f, tag)
+	}); err != nil {
+		return err
+	}
+	if err := p.loadFiles(conf); err != nil {
+		return err
+	}
+	getenv := p.Getenv
+	if getenv == nil {
+		getenv = os.LookupEnv
+	}
+	if err := walk(v.Elem(), "env", func(f reflect.Value, tag string) error {
+		if s, ok := getenv(p.EnvPrefix + tag); ok {
+			if err := setValue(f, s); err != nil {
+				return fmt.Errorf("config: env %s: %v", p.EnvPrefix+tag, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if p.Flags != nil {
+		set := make(map[string]*flag.Flag)
+		p.Flags.Visit(func(f *flag.Flag) { set[f.Name] = f })
+		if err := walk(v.Elem(), "flag", func(f reflect.Value, tag string) error {
+			if fl, ok := set[tag]; ok {
+				if err := setValue(f, fl.Value.String()); err != nil {
+					return fmt.Errorf("config: flag -%s: %v", tag, err)
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if val, ok := conf.(Validator); ok {
+		if err := val.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, validate := range p.Validate {
+		if err := validate(conf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Loader) loadFiles(conf interface{}) error {
+	for _, name := range p.Files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			if os.IsNotExist(err) && len(p.Files) > 1 {
+				continue
+			}
+			return err
+		}
+		decode, err := decoderOf(name)
+		if err != nil {
+			return err
+		}
+		if err = decode(data, conf); err != nil {
+			return fmt.Errorf("config: parse %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// Watcher watches the config files of a Loader, see Loader.Watch.
+type Watcher struct {
+	done chan struct{}
+	once sync.Once
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
+}
+
+// Watch loads a fresh config of the same type as conf each time one of the
+// config files changes, and calls onReload with it (or with the error that
+// stopped it from loading). The files are polled by their modification time
+// and size, so that files replaced by rename or created later are noticed
+// too. Watching stops when the returned watcher is closed.
+func (p *Loader) Watch(conf interface{}, onReload func(conf interface{}, err error)) (*Watcher, error) {
+	typ := reflect.TypeOf(conf)
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config: conf must be a pointer to struct")
+	}
+	interval := p.WatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	w := &Watcher{done: make(chan struct{})}
+	last := p.stat()
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-t.C:
+			}
+			if cur := p.stat(); cur != last {
+				last = cur
+				conf := reflect.New(typ.Elem()).Interface()
+				onReload(conf, p.Load(conf))
+			}
+		}
+	}()
+	return w, nil
+}
+
+// stat returns the modification times and sizes of the config files.
+func (p *Loader) stat() string {
+	var b strings.Builder
+	for _, name := range p.Files {
+		if fi, err := os.Stat(name); err == nil {
+			fmt.Fprintf(&b, "%d/%d;", fi.ModTime().UnixNano(), fi.Size())
+		} else {
+			b.WriteString("-;")
+		}
+	}
+	return b.String()
+}
+
+// -----------------------------------------------------------------------------
+
+var (
+	typDuration        = reflect.TypeOf(time.Duration(0))
+	typTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// walk calls fn for each field of v tagged with key.
+func walk(v reflect.Value, key string, fn func(f reflect.Value, tag string) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		f := v.Field(i)
+		if tag, ok := sf.Tag.Lookup(key); ok && tag != "-" {
+			if err := fn(f, tag); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.Kind() == reflect.Struct && !reflect.PtrTo(f.Type()).Implements(typTextUnmarshaler) {
+			if err := walk(f, key, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setValue parses s into f.
+func setValue(f reflect.Value, s string) error {
+	if f.CanAddr() {
+		if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+	if f.Type() == typDuration {
+		d, err := time.ParseDuration(s)
+		if err == nil {
+			f.SetInt(int64(d))
+		}
+		return err
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 0, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Slice:
+		var items []string
+		if s != "" {
+			items = strings.Split(s, ",")
+		}
+		sl := reflect.MakeSlice(f.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(sl.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		f.Set(sl)
+	default:
+		return fmt.Errorf("unsupported field type %v", f.Type())
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type serverConf struct {
+	Addr    string        `json:"addr" default:":8080" env:"ADDR" flag:"addr"`
+	Workers int           `json:"workers" default:"4" env:"WORKERS"`
+	Timeout time.Duration `default:"3s" env:"TIMEOUT"`
+	Log     struct {
+		Level string   `json:"level" default:"info" flag:"log.level"`
+		Tags  []string `json:"tags" env:"LOG_TAGS"`
+	} `json:"log"`
+}
+
+func TestLoader(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.conf")
+	ioutil.WriteFile(name, []byte(`{
+		# comments are allowed
+		"addr": ":9000",
+		"workers": 8,
+		"log": {"level": "warn"}
+	}`), 0644)
+
+	env := map[string]string{"APP_WORKERS": "16", "APP_LOG_TAGS": "a, b"}
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags.String("addr", "", "")
+	flags.String("log.level", "", "")
+	flags.Parse([]string{"-log.level=debug"})
+
+	p := &Loader{
+		Files:     []string{name},
+		EnvPrefix: "APP_",
+		Flags:     flags,
+		Getenv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+	}
+	var conf serverConf
+	if err := p.Load(&conf); err != nil {
+		t.Fatal("Load:", err)
+	}
+	if conf.Addr != ":9000" || conf.Workers != 16 || conf.Timeout != 3*time.Second ||
+		conf.Log.Level != "debug" || len(conf.Log.Tags) != 2 || conf.Log.Tags[1] != "b" {
+		t.Fatalf("Load: %+v", conf)
+	}
+}
+
+func TestLoaderValidate(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	p := &Loader{
+		Files: []string{"not-found.json", "not-found.conf"},
+		Validate: []func(conf interface{}) error{
+			func(conf interface{}) error {
+				if conf.(*serverConf).Workers > 2 {
+					return errInvalid
+				}
+				return nil
+			},
+		},
+	}
+	var conf serverConf
+	if err := p.Load(&conf); err != errInvalid {
+		t.Fatal("Load:", err)
+	}
+}
+
+func TestLoaderNoDecoder(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.ini")
+	ioutil.WriteFile(name, []byte("addr = x"), 0644)
+	var conf serverConf
+	if err := (&Loader{Files: []string{name}}).Load(&conf); err == nil {
+		t.Fatal("Load: expect no decoder error")
+	}
+	t.Cleanup(func() {
+		decodersMu.Lock()
+		delete(decoders, ".ini")
+		decodersMu.Unlock()
+	})
+	RegisterDecoder(".ini", func(data []byte, v interface{}) error {
+		v.(*serverConf).Addr = string(data[len("addr = "):])
+		return nil
+	})
+	if err := (&Loader{Files: []string{name}}).Load(&conf); err != nil || conf.Addr != "x" {
+		t.Fatal("Load ini:", err, conf.Addr)
+	}
+}
+
+func TestLoaderFormats(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"app.yaml": "# yaml\naddr: ':9000'\nworkers: 8\nlog:\n  level: warn\n  tags: [a, b]\n",
+		"app.toml": "# toml\naddr = ':9000'\nworkers = 8\n\n[log]\nlevel = \"warn\"\ntags = [\"a\", \"b\"]\n",
+	} {
+		name = filepath.Join(dir, name)
+		ioutil.WriteFile(name, []byte(data), 0644)
+		var conf serverConf
+		if err := (&Loader{Files: []string{name}}).Load(&conf); err != nil {
+			t.Fatal("Load:", name, err)
+		}
+		if conf.Addr != ":9000" || conf.Workers != 8 || conf.Log.Level != "warn" || len(conf.Log.Tags) != 2 || conf.Log.Tags[1] != "b" {
+			t.Fatalf("Load %s: %+v", name, conf)
+		}
+	}
+}
+
+func TestLoaderWatch(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.json")
+	ioutil.WriteFile(name, []byte(`{"addr": ":1"}`), 0644)
+	p := &Loader{Files: []string{name}, WatchInterval: 5 * time.Millisecond}
+	reloaded := make(chan *serverConf, 1)
+	w, err := p.Watch(new(serverConf), func(conf interface{}, err error) {
+		if err != nil {
+			t.Error("reload:", err)
+			return
+		}
+		reloaded <- conf.(*serverConf)
+	})
+	if err != nil {
+		t.Fatal("Watch:", err)
+	}
+	defer w.Close()
+	ioutil.WriteFile(name, []byte(`{"addr": ":20"}`), 0644)
+	select {
+	case conf := <-reloaded:
+		if conf.Addr != ":20" || conf.Workers != 4 {
+			t.Fatalf("reloaded: %+v", conf)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("change not noticed")
+	}
+}
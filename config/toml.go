@@ -0,0 +1,460 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// -----------------------------------------------------------------------------
+
+// decodeTOML decodes a TOML config: the document is converted to JSON, and
+// decoded into v as a JSON config is, so that the fields are bound by their
+// json tags. Dates and times are decoded as strings.
+func decodeTOML(data []byte, v interface{}) error {
+	doc, err := parseTOML(string(data))
+	if err != nil {
+		return err
+	}
+	return decodeTree(doc, v)
+}
+
+type tomlParser struct {
+	s    string
+	pos  int
+	line int // 1-based
+}
+
+func parseTOML(src string) (map[string]interface{}, error) {
+	p := &tomlParser{s: src, line: 1}
+	root := make(map[string]interface{})
+	table := root
+	for {
+		p.skipSpace(true)
+		if p.pos == len(p.s) {
+			return root, nil
+		}
+		var err error
+		if p.s[p.pos] == '[' {
+			table, err = p.header(root)
+		} else {
+			err = p.keyValue(table)
+		}
+		if err == nil {
+			err = p.endOfLine()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips blanks and comments, and newlines too if newlines is set.
+func (p *tomlParser) skipSpace(newlines bool) {
+	for p.pos < len(p.s) {
+		switch c := p.s[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace(false)
+	if p.pos < len(p.s) && p.s[p.pos] != '\n' {
+		return p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return nil
+}
+
+func (p *tomlParser) consume(prefix string) bool {
+	if strings.HasPrefix(p.s[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+// header parses a [table] or [[array of tables]] header, and returns the
+// table the next key/value pairs go to.
+func (p *tomlParser) header(root map[string]interface{}) (map[string]interface{}, error) {
+	array := p.consume("[[")
+	if !array {
+		p.pos++
+	}
+	p.skipSpace(false)
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace(false)
+	if array && !p.consume("]]") || !array && !p.consume("]") {
+		return nil, p.errorf("unterminated table header")
+	}
+	parent, err := p.tableOf(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	name := keys[len(keys)-1]
+	table := make(map[string]interface{})
+	switch old := parent[name].(type) {
+	case nil:
+		if array {
+			parent[name] = []interface{}{table}
+		} else {
+			parent[name] = table
+		}
+	case []interface{}:
+		if !array {
+			return nil, p.errorf("%s is an array of tables", name)
+		}
+		parent[name] = append(old, table)
+	case map[string]interface{}:
+		if array {
+			return nil, p.errorf("%s is a table", name)
+		}
+		table = old
+	default:
+		return nil, p.errorf("%s is not a table", name)
+	}
+	return table, nil
+}
+
+// tableOf returns the table of the dotted keys under table, creating the
+// missing ones. An array of tables stands for its last table.
+func (p *tomlParser) tableOf(table map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		switch v := table[key].(type) {
+		case nil:
+			sub := make(map[string]interface{})
+			table[key] = sub
+			table = sub
+		case map[string]interface{}:
+			table = v
+		case []interface{}:
+			last, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("%s is not a table", key)
+			}
+			table = last
+		default:
+			return nil, p.errorf("%s is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+func (p *tomlParser) keyValue(table map[string]interface{}) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	if !p.consume("=") {
+		return p.errorf("expected = after key %s", strings.Join(keys, "."))
+	}
+	p.skipSpace(false)
+	v, err := p.value()
+	if err != nil {
+		return err
+	}
+	if table, err = p.tableOf(table, keys[:len(keys)-1]); err != nil {
+		return err
+	}
+	name := keys[len(keys)-1]
+	if _, dup := table[name]; dup {
+		return p.errorf("duplicate key %s", name)
+	}
+	table[name] = v
+	return nil
+}
+
+// key parses a possibly dotted key.
+func (p *tomlParser) key() (keys []string, err error) {
+	for {
+		var k string
+		switch {
+		case p.pos == len(p.s):
+			return nil, p.errorf("expected key")
+		case p.s[p.pos] == '"':
+			k, err = p.basicString()
+		case p.s[p.pos] == '\'':
+			k, err = p.literalString()
+		default:
+			start := p.pos
+			for p.pos < len(p.s) && isBareKeyChar(p.s[p.pos]) {
+				p.pos++
+			}
+			if k = p.s[start:p.pos]; k == "" {
+				return nil, p.errorf("expected key, got %q", p.s[p.pos])
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+		p.skipSpace(false)
+		if !p.consume(".") {
+			return keys, nil
+		}
+		p.skipSpace(false)
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	if p.pos == len(p.s) {
+		return nil, p.errorf("expected value")
+	}
+	switch c := p.s[p.pos]; {
+	case strings.HasPrefix(p.s[p.pos:], `"""`):
+		return p.multilineString(`"""`)
+	case strings.HasPrefix(p.s[p.pos:], "'''"):
+		return p.multilineString("'''")
+	case c == '"':
+		return p.basicString()
+	case c == '\'':
+		return p.literalString()
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.inlineTable()
+	}
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n#,]}", p.s[p.pos]) < 0 {
+		p.pos++
+	}
+	// a local date and time may be separated by a space
+	if p.pos+1 < len(p.s) && p.s[p.pos] == ' ' && isDate(p.s[start:p.pos]) && p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '9' {
+		for p.pos++; p.pos < len(p.s) && strings.IndexByte(" \t\r\n#,]}", p.s[p.pos]) < 0; p.pos++ {
+		}
+	}
+	tok := p.s[start:p.pos]
+	switch tok {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "":
+		return nil, p.errorf("expected value")
+	}
+	if isDate(tok) || len(tok) >= 8 && tok[2] == ':' && tok[5] == ':' {
+		return tok, nil
+	}
+	num := strings.Replace(tok, "_", "", -1)
+	base := 0
+	if len(num) > 1 && num[0] == '0' && num[1] >= '0' && num[1] <= '9' {
+		base = 10 // not octal
+	}
+	if n, err := strconv.ParseInt(num, base, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(num, 64); err == nil && !strings.ContainsAny(num, "xXiInN") {
+		return f, nil
+	}
+	return nil, p.errorf("bad value %q", tok)
+}
+
+func isDate(s string) bool {
+	return len(s) >= 10 && s[4] == '-' && s[7] == '-'
+}
+
+func (p *tomlParser) array() (interface{}, error) {
+	p.pos++
+	items := []interface{}{}
+	for {
+		p.skipSpace(true)
+		if p.consume("]") {
+			return items, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		p.skipSpace(true)
+		if !p.consume(",") {
+			p.skipSpace(true)
+			if !p.consume("]") {
+				return nil, p.errorf("expected , or ] in array")
+			}
+			return items, nil
+		}
+	}
+}
+
+func (p *tomlParser) inlineTable() (interface{}, error) {
+	p.pos++
+	table := make(map[string]interface{})
+	p.skipSpace(false)
+	if p.consume("}") {
+		return table, nil
+	}
+	for {
+		p.skipSpace(false)
+		if err := p.keyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpace(false)
+		if p.consume("}") {
+			return table, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+func (p *tomlParser) literalString() (string, error) {
+	end := strings.IndexAny(p.s[p.pos+1:], "'\n")
+	if end < 0 || p.s[p.pos+1+end] != '\'' {
+		return "", p.errorf("unterminated string")
+	}
+	s := p.s[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return s, nil
+}
+
+func (p *tomlParser) basicString() (string, error) {
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.s); {
+		c := p.s[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// multilineString parses a multi-line basic or literal string, delimited by
+// three double or single quotes. Its first newline is trimmed.
+func (p *tomlParser) multilineString(delim string) (string, error) {
+	p.pos += len(delim)
+	if p.consume("\r\n") || p.consume("\n") {
+		p.line++
+	}
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		if strings.HasPrefix(p.s[p.pos:], delim) {
+			// up to two quotes may end the content
+			for strings.HasPrefix(p.s[p.pos+1:], delim) {
+				b.WriteByte(delim[0])
+				p.pos++
+			}
+			p.pos += len(delim)
+			return b.String(), nil
+		}
+		c := p.s[p.pos]
+		if c == '\n' {
+			p.line++
+		}
+		if c == '\\' && delim == `"""` {
+			if rest := strings.TrimLeft(p.s[p.pos+1:], " \t\r"); strings.HasPrefix(rest, "\n") {
+				// a line ending backslash trims the whitespace up to the next text
+				p.pos = len(p.s) - len(rest)
+				for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+					if p.s[p.pos] == '\n' {
+						p.line++
+					}
+					p.pos++
+				}
+				continue
+			}
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// escape decodes the escape sequence at the current position into b.
+func (p *tomlParser) escape(b *strings.Builder) error {
+	if p.pos+1 == len(p.s) {
+		return p.errorf("unterminated string")
+	}
+	c := p.s[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte(0x1b)
+	case '"', '\\':
+		b.WriteByte(c)
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.s) {
+			return p.errorf("bad escape")
+		}
+		r, err := strconv.ParseUint(p.s[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return p.errorf("bad escape \\%c%s", c, p.s[p.pos:p.pos+n])
+		}
+		b.WriteRune(rune(r))
+		p.pos += n
+	default:
+		return p.errorf("bad escape \\%c", c)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	doc, err := parseTOML(`# a comment
+name = "web # not a comment"
+port = 8_080 # a comment
+ratio = 0.5
+when = 1979-05-27 07:32:00
+hex = 0xff
+"quoted key" = 'C:\path'
+escaped = "tab\there \u00e9"
+site.owner = "me"
+multi = """
+one \
+  two"""
+
+[server]
+hosts = [
+  "a", # first
+  "b",
+]
+limits = { rps = 10, burst = 20 }
+
+[server.tls]
+enabled = true
+
+[[backends]]
+addr = "x"
+
+[[backends]]
+addr = "y"
+`)
+	if err != nil {
+		t.Fatal("parseTOML:", err)
+	}
+	want := `{"backends":[{"addr":"x"},{"addr":"y"}],"escaped":"tab\there é","hex":255,"multi":"one two",` +
+		`"name":"web # not a comment","port":8080,"quoted key":"C:\\path","ratio":0.5,` +
+		`"server":{"hosts":["a","b"],"limits":{"burst":20,"rps":10},"tls":{"enabled":true}},` +
+		`"site":{"owner":"me"},"when":"1979-05-27 07:32:00"}`
+	if got := treeJSON(t, doc); got != want {
+		t.Fatal("parseTOML:", got)
+	}
+	for _, bad := range []string{
+		"a = 1\na = 2\n",
+		"a = \n",
+		"a = \"open\n",
+		"a = 1 b = 2\n",
+		"[t]\n[[t]]\n",
+		"a = [1, 2\n",
+		"a = nope\n",
+		"a = \"\\q\"\n",
+	} {
+		if _, err := parseTOML(bad); err == nil {
+			t.Fatalf("parseTOML(%q): no error", bad)
+		}
+	}
+}
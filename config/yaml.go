@@ -0,0 +1,347 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// decodeYAML decodes a YAML config: the document is converted to JSON, and
+// decoded into v as a JSON config is, so that the fields are bound by their
+// json tags.
+//
+// Only the subset of YAML that configs use is supported: block mappings and
+// sequences, flow sequences and mappings, plain and quoted scalars, and
+// comments. Anchors, tags, multi-line scalars and multiple documents are not.
+func decodeYAML(data []byte, v interface{}) error {
+	doc, err := parseYAML(string(data))
+	if err != nil {
+		return err
+	}
+	return decodeTree(doc, v)
+}
+
+// decodeTree decodes the tree of a config file into v, through JSON.
+func decodeTree(tree interface{}, v interface{}) error {
+	b, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+type yamlLine struct {
+	num    int // 1-based
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+func parseYAML(src string) (interface{}, error) {
+	p := new(yamlParser)
+	for num, line := range strings.Split(src, "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || (num == 0 || len(p.lines) == 0) && text == "---" {
+			continue
+		}
+		if text == "..." {
+			break
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs can't indent", num+1)
+		}
+		p.lines = append(p.lines, yamlLine{num: num + 1, indent: len(line) - len(text), text: text})
+	}
+	if len(p.lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	v, err := p.block(p.lines[0].indent)
+	if err == nil && p.i < len(p.lines) {
+		err = p.errorf("bad indentation")
+	}
+	return v, err
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", p.lines[p.i].num, fmt.Sprintf(format, args...))
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// block parses the mapping or sequence starting at the current line, whose
+// entries are indented by indent.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isSeqItem(p.lines[p.i].text) {
+		return p.seq(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) seq(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && isSeqItem(p.lines[p.i].text) {
+		l := p.lines[p.i]
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if rest == "" {
+			p.i++
+			item, err := p.nested(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		if _, _, ok := splitYAMLKey(rest); ok || isSeqItem(rest) {
+			// an entry of a block inlined in the item: "- key: value"
+			p.lines[p.i] = yamlLine{num: l.num, indent: indent + len(l.text) - len(rest), text: rest}
+			item, err := p.block(p.lines[p.i].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		item, err := yamlScalar(rest)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		items = append(items, item)
+		p.i++
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := make(map[string]interface{})
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent {
+		text := p.lines[p.i].text
+		if isSeqItem(text) {
+			return nil, p.errorf("sequence item in a mapping")
+		}
+		key, rest, ok := splitYAMLKey(text)
+		if !ok {
+			return nil, p.errorf("expected key: value, got %q", text)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		if rest == "" {
+			p.i++
+			v, err := p.nested(indent, true)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			continue
+		}
+		if rest[0] == '|' || rest[0] == '>' || rest[0] == '&' || rest[0] == '*' || rest[0] == '!' {
+			return nil, p.errorf("unsupported YAML: %q", rest)
+		}
+		v, err := yamlScalar(rest)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		m[key] = v
+		p.i++
+	}
+	return m, nil
+}
+
+// nested parses the block under an entry with no inline value, at indent:
+// null if there is none. The items of a sequence that is the value of a
+// mapping key may have the indent of the key.
+func (p *yamlParser) nested(indent int, inMapping bool) (interface{}, error) {
+	if p.i == len(p.lines) {
+		return nil, nil
+	}
+	l := p.lines[p.i]
+	if l.indent > indent || inMapping && l.indent == indent && isSeqItem(l.text) {
+		return p.block(l.indent)
+	}
+	return nil, nil
+}
+
+// splitYAMLKey splits "key: value" at the colon, outside quotes.
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text[0] == '[' || text[0] == '{' {
+		return "", "", false
+	}
+	var quote byte
+	if text[0] == '"' || text[0] == '\'' {
+		quote = text[0]
+	}
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			key = strings.TrimSpace(text[:i])
+			if key[0] == '"' || key[0] == '\'' {
+				k, err := yamlScalar(key)
+				if err != nil {
+					return "", "", false
+				}
+				key = fmt.Sprint(k)
+			}
+			return key, strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// stripYAMLComment cuts a comment off line: a # at its start or preceded by
+// a space, outside quoted scalars.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && opensQuote(line, i):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// opensQuote reports whether the quote at s[i] starts a quoted scalar, rather
+// than being part of a plain one, as in "don't".
+func opensQuote(s string, i int) bool {
+	return i == 0 || strings.IndexByte(" \t[{,:-", s[i-1]) >= 0
+}
+
+// yamlScalar parses an inline value: a quoted or plain scalar, or a flow
+// sequence or mapping.
+func yamlScalar(s string) (interface{}, error) {
+	switch s[0] {
+	case '"':
+		if !strings.HasSuffix(s, `"`) || len(s) < 2 {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return strconv.Unquote(s)
+	case '\'':
+		if !strings.HasSuffix(s, "'") || len(s) < 2 {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	case '[':
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence %s", s)
+		}
+		items := []interface{}{}
+		for _, item := range splitFlow(s[1 : len(s)-1]) {
+			v, err := yamlScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case '{':
+		if !strings.HasSuffix(s, "}") {
+			return nil, fmt.Errorf("unterminated flow mapping %s", s)
+		}
+		m := make(map[string]interface{})
+		for _, item := range splitFlow(s[1 : len(s)-1]) {
+			key, rest, ok := splitYAMLKey(item)
+			if !ok || rest == "" {
+				return nil, fmt.Errorf("bad flow mapping entry %q", item)
+			}
+			v, err := yamlScalar(rest)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	}
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	base := 0
+	if len(s) > 1 && s[0] == '0' && s[1] >= '0' && s[1] <= '9' {
+		base = 10 // not octal
+	}
+	if n, err := strconv.ParseInt(s, base, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "iInN") {
+		return f, nil
+	}
+	return s, nil
+}
+
+// splitFlow splits the entries of a flow collection at the commas outside
+// quotes and nested collections.
+func splitFlow(s string) (items []string) {
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && opensQuote(s, i):
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		items = append(items, last)
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func treeJSON(t *testing.T, tree interface{}) string {
+	b, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+	return string(b)
+}
+
+func TestParseYAML(t *testing.T) {
+	doc, err := parseYAML(`---
+# a comment
+name: "web # not a comment"
+port: 8080   # a comment
+ratio: 0.5
+mode: 010
+debug: true
+none: ~
+quote: don't
+tags: [a, 'b c', {k: v}]
+servers:
+  - host: a
+    weight: 2
+  - host: b
+paths:
+- /x
+- /y
+nested:
+  deep:
+    key: value
+empty:
+`)
+	if err != nil {
+		t.Fatal("parseYAML:", err)
+	}
+	want := `{"debug":true,"empty":null,"mode":10,"name":"web # not a comment","nested":{"deep":{"key":"value"}},` +
+		`"none":null,"paths":["/x","/y"],"port":8080,"quote":"don't","ratio":0.5,` +
+		`"servers":[{"host":"a","weight":2},{"host":"b"}],"tags":["a","b c",{"k":"v"}]}`
+	if got := treeJSON(t, doc); got != want {
+		t.Fatal("parseYAML:", got)
+	}
+	for _, bad := range []string{
+		"a: 1\n  b: 2\n",
+		"a: 1\na: 2\n",
+		"a: |\n  text\n",
+		"a: 1\n- b\n",
+		"a: [1, 2\n",
+		"just text\n",
+	} {
+		if _, err := parseYAML(bad); err == nil {
+			t.Fatalf("parseYAML(%q): no error", bad)
+		}
+	}
+}
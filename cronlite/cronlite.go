@@ -0,0 +1,324 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package cronlite is a small in-process job scheduler.
+//
+// Jobs are triggered by cron expressions or fixed intervals (see Parse and
+// Every). Each job chooses what happens when it is triggered while its
+// previous run is still going (see Overlap), may randomize its start times
+// by a jitter, and is protected against panics: a panicking job reports a
+// *PanicError and keeps its schedule.
+package cronlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrDuplicate is returned by Add when a job of the same name exists.
+	ErrDuplicate = errors.New("cronlite: duplicate job name")
+
+	// ErrNoSchedule is returned by Add when a job has no schedule or no Run
+	// function.
+	ErrNoSchedule = errors.New("cronlite: job without schedule or run function")
+)
+
+// Overlap is the policy of a job triggered while a previous run is still
+// going.
+type Overlap int
+
+const (
+	// OverlapSkip skips the activation.
+	OverlapSkip Overlap = iota
+
+	// OverlapQueue runs the job again as soon as the current run returns.
+	// Activations during a run are coalesced into one.
+	OverlapQueue
+
+	// OverlapAllow runs the job concurrently with the previous runs.
+	OverlapAllow
+)
+
+// Job is a scheduled job.
+type Job struct {
+	Name     string
+	Schedule Schedule
+
+	// Run runs the job. ctx is canceled when the scheduler stops.
+	Run func(ctx context.Context) error
+
+	Overlap Overlap
+
+	// Jitter delays each activation by a random duration in [0, Jitter),
+	// so that jobs of many processes don't all start at once.
+	Jitter time.Duration
+}
+
+// PanicError is the error of a job run that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("cronlite: job panicked: %v", e.Value)
+}
+
+// Entry describes the state of a job.
+type Entry struct {
+	Name string
+	Prev time.Time // start of the last activation, zero if none
+	Next time.Time // next activation, zero if none
+
+	Running int // number of runs in progress
+	Runs    int // number of finished runs
+	Skipped int // number of activations skipped by OverlapSkip
+
+	LastErr      error         // the error of the last finished run
+	LastDuration time.Duration // the duration of the last finished run
+}
+
+// -----------------------------------------------------------------------------
+
+type job struct {
+	Job
+	Entry   Entry
+	next    time.Time // next activation before jitter
+	pending bool      // a queued activation, see OverlapQueue
+	removed bool
+}
+
+// Scheduler runs jobs on their schedules. Jobs can be added and removed at
+// any time, including while Run is running.
+type Scheduler struct {
+	// OnError, if set, is called with the error of each failed run. It may
+	// be called concurrently.
+	OnError func(name string, err error)
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	ctx  context.Context // the context of Run, nil when not running
+	wake chan struct{}
+	wg   sync.WaitGroup
+	now  func() time.Time
+}
+
+// New creates a scheduler.
+func New() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*job), wake: make(chan struct{}, 1), now: time.Now}
+}
+
+// Add adds a job.
+func (p *Scheduler) Add(j Job) error {
+	if j.Schedule == nil || j.Run == nil {
+		return ErrNoSchedule
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.jobs[j.Name]; ok {
+		return ErrDuplicate
+	}
+	e := &job{Job: j}
+	e.Entry.Name = j.Name
+	p.scheduleLocked(e, p.now())
+	p.jobs[j.Name] = e
+	p.notify()
+	return nil
+}
+
+// AddFunc adds a job run by fn on the schedule spec (see Parse), with the
+// default OverlapSkip policy and no jitter.
+func (p *Scheduler) AddFunc(name, spec string, fn func(ctx context.Context) error) error {
+	sched, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	return p.Add(Job{Name: name, Schedule: sched, Run: fn})
+}
+
+// Remove removes a job. Runs in progress are not interrupted.
+func (p *Scheduler) Remove(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.jobs[name]
+	if ok {
+		e.removed = true
+		delete(p.jobs, name)
+		p.notify()
+	}
+	return ok
+}
+
+// Entry returns the state of the named job.
+func (p *Scheduler) Entry(name string) (ent Entry, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.jobs[name]
+	if ok {
+		ent = e.Entry
+	}
+	return
+}
+
+// Entries returns the states of all jobs, ordered by their next activation.
+func (p *Scheduler) Entries() []Entry {
+	p.mu.Lock()
+	ents := make([]Entry, 0, len(p.jobs))
+	for _, e := range p.jobs {
+		ents = append(ents, e.Entry)
+	}
+	p.mu.Unlock()
+	sort.Slice(ents, func(i, j int) bool {
+		a, b := ents[i].Next, ents[j].Next
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		return a.Before(b)
+	})
+	return ents
+}
+
+// Run runs the jobs until ctx is done. It then waits for the runs in
+// progress, whose contexts are canceled too, and returns ctx.Err(). Run must
+// not be called concurrently.
+func (p *Scheduler) Run(ctx context.Context) error {
+	p.mu.Lock()
+	p.ctx = ctx
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.ctx = nil
+		p.mu.Unlock()
+		p.wg.Wait()
+	}()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		// the select may pick the timer although ctx is done too
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		now := p.now()
+		p.mu.Lock()
+		var first time.Time
+		for _, e := range p.jobs {
+			if e.Entry.Next.IsZero() {
+				continue
+			}
+			if !e.Entry.Next.After(now) {
+				p.activateLocked(e, now)
+			}
+			if !e.Entry.Next.IsZero() && (first.IsZero() || e.Entry.Next.Before(first)) {
+				first = e.Entry.Next
+			}
+		}
+		p.mu.Unlock()
+
+		wait := time.Hour
+		if !first.IsZero() && first.Sub(now) < wait {
+			wait = first.Sub(now)
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *Scheduler) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// scheduleLocked computes the next activation of e after now.
+func (p *Scheduler) scheduleLocked(e *job, now time.Time) {
+	e.next = e.Schedule.Next(now)
+	e.Entry.Next = e.next
+	if !e.next.IsZero() && e.Jitter > 0 {
+		e.Entry.Next = e.next.Add(time.Duration(rand.Int63n(int64(e.Jitter))))
+	}
+}
+
+func (p *Scheduler) activateLocked(e *job, now time.Time) {
+	e.Entry.Prev = now
+	p.scheduleLocked(e, now)
+	if e.Entry.Running > 0 {
+		switch e.Overlap {
+		case OverlapSkip:
+			e.Entry.Skipped++
+			return
+		case OverlapQueue:
+			e.pending = true
+			return
+		}
+	}
+	p.startLocked(e)
+}
+
+func (p *Scheduler) startLocked(e *job) {
+	e.Entry.Running++
+	ctx := p.ctx
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		start := time.Now()
+		err := runJob(ctx, e.Run)
+		if err != nil && p.OnError != nil {
+			p.OnError(e.Name, err)
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		e.Entry.Running--
+		e.Entry.Runs++
+		e.Entry.LastErr, e.Entry.LastDuration = err, time.Since(start)
+		if e.pending {
+			e.pending = false
+			if !e.removed && p.ctx != nil && p.ctx.Err() == nil {
+				p.startLocked(e)
+			}
+		}
+	}()
+}
+
+func runJob(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return run(ctx)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,130 @@
+package cronlite
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 30, 15, 0, time.UTC) // a Saturday
+	cases := []struct {
+		spec string
+		next string
+	}{
+		{"* * * * *", "2026-03-14 10:31"},
+		{"*/15 * * * *", "2026-03-14 10:45"},
+		{"0 * * * *", "2026-03-14 11:00"},
+		{"@hourly", "2026-03-14 11:00"},
+		{"@daily", "2026-03-15 00:00"},
+		{"0 9 * * mon-fri", "2026-03-16 09:00"},
+		{"0 9 * * 7", "2026-03-15 09:00"},
+		{"30 2 1 * *", "2026-04-01 02:30"},
+		{"0 0 13 * fri", "2026-03-20 00:00"}, // day of month OR day of week
+		{"0 0 29 feb *", "2028-02-29 00:00"},
+		{"5,10-12 10 * * *", "2026-03-15 10:05"},
+		{"40/5 10 14 3 *", "2026-03-14 10:40"},
+		{"@every 1h", "2026-03-14 11:00"},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.spec)
+		if err != nil {
+			t.Fatal(c.spec, err)
+		}
+		if got := s.Next(base).Format("2006-01-02 15:04"); got != c.next {
+			t.Fatalf("%s: next = %s, want %s", c.spec, got, c.next)
+		}
+	}
+	if !MustParse("0 0 30 feb *").Next(base).IsZero() {
+		t.Fatal("Feb 30 matched")
+	}
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * foo *", "5-1 * * * *", "*/0 * * * *", "@every -1s", "TZ=Nowhere/Land * * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Fatalf("Parse(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestParseTZ(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	s := MustParse("TZ=Asia/Shanghai 0 8 * * *")
+	next := s.Next(time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 3, 15, 8, 0, 0, 0, loc); !next.Equal(want) || next.Location() != time.UTC {
+		t.Fatal("next:", next)
+	}
+}
+
+type interval time.Duration
+
+func (d interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
+
+func TestScheduler(t *testing.T) {
+	p := New()
+	var runs, slow, queued int32
+	var errs int32
+	p.OnError = func(name string, err error) { atomic.AddInt32(&errs, 1) }
+	check := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(p.Add(Job{Name: "fast", Schedule: interval(5 * time.Millisecond), Run: func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}}))
+	check(p.Add(Job{Name: "slow", Schedule: interval(5 * time.Millisecond), Run: func(ctx context.Context) error {
+		atomic.AddInt32(&slow, 1)
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	}}))
+	check(p.Add(Job{Name: "queue", Schedule: interval(5 * time.Millisecond), Overlap: OverlapQueue, Run: func(ctx context.Context) error {
+		atomic.AddInt32(&queued, 1)
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+	check(p.Add(Job{Name: "panic", Schedule: interval(5 * time.Millisecond), Jitter: time.Millisecond, Run: func(ctx context.Context) error {
+		panic("boom")
+	}}))
+	if p.Add(Job{Name: "fast", Schedule: Every(time.Second), Run: func(context.Context) error { return nil }}) != ErrDuplicate {
+		t.Fatal("duplicate job added")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := p.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatal("Run:", err)
+	}
+	if n := atomic.LoadInt32(&runs); n < 5 {
+		t.Fatal("fast runs:", n)
+	}
+	if n := atomic.LoadInt32(&slow); n < 2 || n > 4 {
+		t.Fatal("slow runs:", n)
+	}
+	if e, _ := p.Entry("slow"); e.Skipped == 0 || e.Running != 0 {
+		t.Fatalf("slow entry: %+v", e)
+	}
+	if n := atomic.LoadInt32(&queued); n != 1 {
+		t.Fatal("queued runs:", n)
+	}
+	e, ok := p.Entry("panic")
+	var pe *PanicError
+	if !ok || e.Runs == 0 || !errors.As(e.LastErr, &pe) || pe.Value != "boom" {
+		t.Fatalf("panic entry: %+v", e)
+	}
+	if atomic.LoadInt32(&errs) == 0 {
+		t.Fatal("OnError not called")
+	}
+	if ents := p.Entries(); len(ents) != 4 || ents[0].Next.After(ents[3].Next) {
+		t.Fatal("Entries:", ents)
+	}
+	if !p.Remove("fast") || p.Remove("fast") {
+		t.Fatal("Remove")
+	}
+}
@@ -0,0 +1,256 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cronlite
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Schedule computes the activation times of a job.
+type Schedule interface {
+	// Next returns the first activation time strictly after t, or the zero
+	// time if there is none.
+	Next(t time.Time) time.Time
+}
+
+// Every returns a schedule that activates every d, aligned to multiples of
+// d since the zero time (so Every(time.Hour) fires on the hour). It panics
+// if d isn't positive.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("cronlite: non-positive interval")
+	}
+	return every(d)
+}
+
+type every time.Duration
+
+func (d every) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(d)).Add(time.Duration(d))
+}
+
+// -----------------------------------------------------------------------------
+
+// cron is a schedule parsed from a cron expression. Each field is a bit set of
+// the allowed values.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	loc                           *time.Location // nil means the location of t
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    []string // names of values starting at min, e.g. "jan"
+}
+
+var fields = [...]field{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{"day of week", 0, 7, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+const starBit = 1 << 63 // the field is "*": any value matches
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a schedule. It accepts:
+//
+//   - standard 5-field cron expressions "minute hour day-of-month month
+//     day-of-week", where each field is "*", a value, a range "a-b", a step
+//     "*/n" or "a-b/n", or a comma-separated list of those. Months and days
+//     of week may be given by their three-letter English names; both 0 and 7
+//     mean Sunday. As in classic cron, when both day fields are restricted a
+//     day matches if either of them does;
+//   - the descriptors @yearly, @annually, @monthly, @weekly, @daily,
+//     @midnight and @hourly;
+//   - "@every <duration>", e.g. "@every 1h30m";
+//   - an optional "TZ=<location> " (or "CRON_TZ=") prefix that evaluates the
+//     expression in the named location instead of the location of the times
+//     passed to Next.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	var loc *time.Location
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, errors.New("cronlite: missing expression after time zone")
+		}
+		name := spec[strings.IndexByte(spec, '=')+1 : i]
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, errors.New("cronlite: bad time zone " + strconv.Quote(name) + ": " + err.Error())
+		}
+		loc, spec = l, strings.TrimSpace(spec[i:])
+	}
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, errors.New("cronlite: " + err.Error())
+		}
+		if d <= 0 {
+			return nil, errors.New("cronlite: @every needs a positive duration")
+		}
+		return Every(d), nil
+	}
+	if expr, ok := descriptors[spec]; ok {
+		spec = expr
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, errors.New("cronlite: expected 5 fields in " + strconv.Quote(spec))
+	}
+	var bits [len(fields)]uint64
+	for i, part := range parts {
+		b, err := parseField(part, &fields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 { // 7 is Sunday too
+		bits[4] |= 1
+	}
+	return &cron{minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4], loc: loc}, nil
+}
+
+// MustParse is like Parse but panics if spec can't be parsed.
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parseField(s string, f *field) (bits uint64, err error) {
+	for _, item := range strings.Split(s, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rng := item
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			rng = item[:i]
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step <= 0 {
+				return 0, fieldError(f, item)
+			}
+		}
+		switch {
+		case rng == "*":
+			if step == 1 {
+				bits |= starBit
+			}
+		default:
+			if i := strings.IndexByte(rng, '-'); i >= 0 {
+				if lo, err = parseValue(rng[:i], f); err != nil {
+					return
+				}
+				if hi, err = parseValue(rng[i+1:], f); err != nil {
+					return
+				}
+				if lo > hi {
+					return 0, fieldError(f, item)
+				}
+			} else {
+				if lo, err = parseValue(rng, f); err != nil {
+					return
+				}
+				if step != 1 { // "a/n" means "a-max/n"
+					hi = f.max
+				} else {
+					hi = lo
+				}
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return
+}
+
+func parseValue(s string, f *field) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fieldError(f, s)
+	}
+	return v, nil
+}
+
+func fieldError(f *field, s string) error {
+	return errors.New("cronlite: bad " + f.name + " " + strconv.Quote(s))
+}
+
+// Next implements the Schedule interface.
+func (c *cron) Next(t time.Time) time.Time {
+	orig := t.Location()
+	if c.loc != nil {
+		t = t.In(c.loc)
+	}
+	loc := t.Location()
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+
+	// give up after 5 years: the expression can't match (e.g. Feb 30).
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t.In(orig)
+	}
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.dom&starBit != 0 || c.dow&starBit != 0 {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,270 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package basex implements base58, base62 and other arbitrary-alphabet
+// encodings.
+//
+// A byte string is encoded as the big-endian big integer it represents,
+// written in the radix of the alphabet; each leading zero byte is kept as a
+// leading zero digit (the first letter of the alphabet), like Bitcoin's
+// base58. Unlike base64, the encoded text has no punctuation, so it can be
+// used in URLs, file names and identifiers as is.
+//
+// Encoding a byte string takes time quadratic in its length. Long or
+// unbounded data is better encoded with NewEncoder, which uses a blocked
+// variant.
+package basex
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// CorruptInputError is returned for an invalid character at that offset of
+// the input.
+type CorruptInputError int64
+
+func (e CorruptInputError) Error() string {
+	return "illegal basex data at input byte " + strconv.FormatInt(int64(e), 10)
+}
+
+// An Encoding is a radix encoding defined by an alphabet.
+type Encoding struct {
+	alphabet  string
+	decodeMap [256]byte
+	radix     uint32
+	ratio     float64 // digits per byte
+	blockLen  int     // digits of a block of 8 bytes, see NewEncoder
+}
+
+const invalid = 0xff
+
+const (
+	alphabet58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	alphabet62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	// Base58 is the Bitcoin base58 encoding, which leaves out the
+	// look-alikes 0, O, I and l.
+	Base58 = NewEncoding(alphabet58)
+
+	// Base62 is the base62 encoding with the alphabet 0-9A-Za-z.
+	Base62 = NewEncoding(alphabet62)
+)
+
+// NewEncoding returns an encoding with the given alphabet of 2 to 255
+// distinct ASCII characters.
+func NewEncoding(alphabet string) *Encoding {
+	if len(alphabet) < 2 || len(alphabet) > 255 {
+		panic("basex: invalid alphabet length")
+	}
+	e := &Encoding{alphabet: alphabet, radix: uint32(len(alphabet))}
+	for i := range e.decodeMap {
+		e.decodeMap[i] = invalid
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c >= 0x80 || e.decodeMap[c] != invalid {
+			panic("basex: invalid alphabet")
+		}
+		e.decodeMap[c] = byte(i)
+	}
+	e.ratio = math.Log(256) / math.Log(float64(e.radix))
+	e.blockLen = e.digits(blockSize)
+	return e
+}
+
+// digits returns the number of digits needed by a number of n bytes.
+func (e *Encoding) digits(n int) int {
+	return int(math.Ceil(float64(n)*e.ratio - 1e-9))
+}
+
+// EncodedMaxLen returns the maximum length of the encoding of n bytes.
+func (e *Encoding) EncodedMaxLen(n int) int {
+	return e.digits(n) + 1
+}
+
+// DecodedMaxLen returns the maximum length of the data of n encoded bytes.
+func (e *Encoding) DecodedMaxLen(n int) int {
+	return int(float64(n)/e.ratio) + 1
+}
+
+// -----------------------------------------------------------------------------
+
+// AppendEncode appends the encoding of src to dst and returns the extended
+// buffer. It allocates only if dst lacks capacity.
+func (e *Encoding) AppendEncode(dst, src []byte) []byte {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+	for i := 0; i < zeros; i++ {
+		dst = append(dst, e.alphabet[0])
+	}
+	// grow dst by the max digits of the rest, which are computed in place
+	// as little-endian digit values and then reversed into letters.
+	n := e.digits(len(src) - zeros)
+	base := len(dst)
+	dst = grow(dst, n)
+	digits := dst[base : base+n]
+	ndig := 0
+	for _, b := range src[zeros:] {
+		carry := uint32(b)
+		for j := 0; j < ndig; j++ {
+			carry += uint32(digits[j]) << 8
+			digits[j] = byte(carry % e.radix)
+			carry /= e.radix
+		}
+		for carry > 0 {
+			digits[ndig] = byte(carry % e.radix)
+			carry /= e.radix
+			ndig++
+		}
+	}
+	digits = digits[:ndig]
+	for i, j := 0, ndig-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	for i, d := range digits {
+		digits[i] = e.alphabet[d]
+	}
+	return dst[:base+ndig]
+}
+
+func grow(b []byte, n int) []byte {
+	if cap(b)-len(b) < n {
+		nb := make([]byte, len(b), len(b)+n)
+		copy(nb, b)
+		b = nb
+	}
+	return b[:len(b)+n]
+}
+
+// EncodeToString returns the encoding of src.
+func (e *Encoding) EncodeToString(src []byte) string {
+	return string(e.AppendEncode(make([]byte, 0, e.EncodedMaxLen(len(src))), src))
+}
+
+// AppendDecode appends the data decoded from src to dst and returns the
+// extended buffer.
+func (e *Encoding) AppendDecode(dst, src []byte) ([]byte, error) {
+	zero := e.alphabet[0]
+	zeros := 0
+	for zeros < len(src) && src[zeros] == zero {
+		zeros++
+	}
+	for i := 0; i < zeros; i++ {
+		dst = append(dst, 0)
+	}
+	n := e.DecodedMaxLen(len(src) - zeros)
+	base := len(dst)
+	dst = grow(dst, n)
+	out := dst[base : base+n] // little-endian bytes
+	nout := 0
+	for i := zeros; i < len(src); i++ {
+		d := e.decodeMap[src[i]]
+		if d == invalid {
+			return dst[:base], CorruptInputError(i)
+		}
+		carry := uint32(d)
+		for j := 0; j < nout; j++ {
+			carry += uint32(out[j]) * e.radix
+			out[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			out[nout] = byte(carry)
+			carry >>= 8
+			nout++
+		}
+	}
+	out = out[:nout]
+	for i, j := 0, nout-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return dst[:base+nout], nil
+}
+
+// DecodeString returns the data encoded by s.
+func (e *Encoding) DecodeString(s string) ([]byte, error) {
+	return e.AppendDecode(nil, []byte(s))
+}
+
+// -----------------------------------------------------------------------------
+
+// EncodeUint64 returns the encoding of v, without leading zero digits.
+func (e *Encoding) EncodeUint64(v uint64) string {
+	var buf [64]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = e.alphabet[v%uint64(e.radix)]
+		v /= uint64(e.radix)
+		if v == 0 {
+			return string(buf[i:])
+		}
+	}
+}
+
+// ErrOverflow is returned by DecodeUint64 when the value overflows uint64.
+var ErrOverflow = errors.New("basex: value out of range")
+
+// DecodeUint64 decodes a value encoded by EncodeUint64.
+func (e *Encoding) DecodeUint64(s string) (v uint64, err error) {
+	if s == "" {
+		return 0, CorruptInputError(0)
+	}
+	for i := 0; i < len(s); i++ {
+		d := e.decodeMap[s[i]]
+		if d == invalid {
+			return 0, CorruptInputError(i)
+		}
+		if v > (math.MaxUint64-uint64(d))/uint64(e.radix) {
+			return 0, ErrOverflow
+		}
+		v = v*uint64(e.radix) + uint64(d)
+	}
+	return
+}
+
+// EncodeInt returns the encoding of the non-negative integer x, without
+// leading zero digits. It panics if x is negative.
+func (e *Encoding) EncodeInt(x *big.Int) string {
+	if x.Sign() < 0 {
+		panic("basex: negative integer")
+	}
+	if x.Sign() == 0 {
+		return e.alphabet[:1]
+	}
+	return string(e.AppendEncode(nil, x.Bytes()))
+}
+
+// DecodeInt decodes an integer encoded by EncodeInt.
+func (e *Encoding) DecodeInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, CorruptInputError(0)
+	}
+	b, err := e.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,108 @@
+package basex
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	cases := []struct{ data, enc string }{
+		{"", ""},
+		{"\x00", "1"},
+		{"\x00\x00\x01", "112"},
+		{"hello world", "StV1DL6CwTryKyV"},
+		{"\x00\x00\x28\x7f\xb4\xcd", "11233QC4"},
+	}
+	for _, c := range cases {
+		if got := Base58.EncodeToString([]byte(c.data)); got != c.enc {
+			t.Fatalf("encode %q: %s, want %s", c.data, got, c.enc)
+		}
+		if got, err := Base58.DecodeString(c.enc); err != nil || string(got) != c.data {
+			t.Fatalf("decode %s: %q, %v", c.enc, got, err)
+		}
+	}
+	if _, err := Base58.DecodeString("abc0"); err != CorruptInputError(3) {
+		t.Fatal("decode invalid:", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, enc := range []*Encoding{Base58, Base62, NewEncoding("01")} {
+		for n := 0; n < 100; n++ {
+			data := make([]byte, n)
+			rand.Read(data)
+			if n > 2 && n%3 == 0 {
+				data[0], data[1] = 0, 0
+			}
+			s := enc.EncodeToString(data)
+			if len(s) > enc.EncodedMaxLen(n) {
+				t.Fatal("EncodedMaxLen")
+			}
+			got, err := enc.DecodeString(s)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("round trip of %x: %x, %v", data, got, err)
+			}
+		}
+	}
+	dst := make([]byte, 0, 64)
+	if allocs := testing.AllocsPerRun(100, func() {
+		Base62.AppendEncode(dst, []byte("0123456789abcdef"))
+	}); allocs != 0 {
+		t.Fatal("AppendEncode allocates:", allocs)
+	}
+}
+
+func TestInts(t *testing.T) {
+	for _, v := range []uint64{0, 1, 61, 62, 1 << 40, 1<<64 - 1} {
+		s := Base62.EncodeUint64(v)
+		if got, err := Base62.DecodeUint64(s); err != nil || got != v {
+			t.Fatal(v, s, got, err)
+		}
+	}
+	if _, err := Base62.DecodeUint64("zzzzzzzzzzzz"); err != ErrOverflow {
+		t.Fatal("overflow:", err)
+	}
+	x, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	s := Base58.EncodeInt(x)
+	if y, err := Base58.DecodeInt(s); err != nil || y.Cmp(x) != 0 {
+		t.Fatal(s, y, err)
+	}
+	if Base58.EncodeInt(new(big.Int)) != "1" {
+		t.Fatal("EncodeInt(0)")
+	}
+}
+
+func TestStream(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 8, 9, 100, 1000, 4099} {
+		data := make([]byte, n)
+		rand.Read(data)
+		var buf bytes.Buffer
+		w := Base58.NewEncoder(&buf)
+		for i := 0; i < n; i += 5 {
+			end := i + 5
+			if end > n {
+				end = n
+			}
+			w.Write(data[i:end])
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if want := n/8*11 + Base58.digits(n%8); buf.Len() != want {
+			t.Fatalf("encoded %d bytes to %d, want %d", n, buf.Len(), want)
+		}
+		got, err := ioutil.ReadAll(Base58.NewDecoder(&buf))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("stream round trip of %d bytes: %v", n, err)
+		}
+	}
+	if _, err := ioutil.ReadAll(Base62.NewDecoder(bytes.NewReader([]byte("0000")))); err == nil {
+		t.Fatal("bad final block accepted")
+	}
+	if _, err := ioutil.ReadAll(Base62.NewDecoder(bytes.NewReader([]byte("zzzzzzzzzzz")))); err == nil {
+		t.Fatal("overflowing block accepted")
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package basex
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// blockSize is the number of bytes of a block of the streaming encoding.
+const blockSize = 8
+
+// -----------------------------------------------------------------------------
+
+// NewEncoder returns a stream encoder writing to w. Data written to the
+// encoder must be flushed by Close.
+//
+// The stream encoding differs from EncodeToString: the data is cut into
+// blocks of 8 bytes, each encoded as a fixed number of digits (11 for base58
+// and base62), and the final partial block as the minimal number of digits
+// for its length. This keeps the cost linear, at the price of slightly longer
+// output. It is decoded by NewDecoder.
+func (e *Encoding) NewEncoder(w io.Writer) io.WriteCloser {
+	return &encoder{enc: e, w: w, out: make([]byte, 0, 32*e.blockLen)}
+}
+
+type encoder struct {
+	enc  *Encoding
+	w    io.Writer
+	buf  [blockSize]byte
+	nbuf int
+	out  []byte
+	err  error
+}
+
+func (p *encoder) Write(b []byte) (n int, err error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	n = len(b)
+	if p.nbuf > 0 {
+		m := copy(p.buf[p.nbuf:], b)
+		p.nbuf += m
+		b = b[m:]
+		if p.nbuf < blockSize {
+			return
+		}
+		p.out = p.enc.appendBlock(p.out, p.buf[:])
+		p.nbuf = 0
+	}
+	for len(b) >= blockSize {
+		p.out = p.enc.appendBlock(p.out, b[:blockSize])
+		b = b[blockSize:]
+		if len(p.out)+p.enc.blockLen > cap(p.out) {
+			if p.err = p.flush(); p.err != nil {
+				return 0, p.err
+			}
+		}
+	}
+	p.nbuf = copy(p.buf[:], b)
+	return n, p.flush()
+}
+
+func (p *encoder) flush() error {
+	if len(p.out) == 0 {
+		return nil
+	}
+	_, err := p.w.Write(p.out)
+	p.out = p.out[:0]
+	return err
+}
+
+// Close flushes the final partial block. It doesn't close the underlying
+// writer.
+func (p *encoder) Close() error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.nbuf > 0 {
+		p.out = p.enc.appendBlock(p.out, p.buf[:p.nbuf])
+		p.nbuf = 0
+	}
+	p.err = p.flush()
+	return p.err
+}
+
+// appendBlock appends the fixed-width encoding of a block of up to 8 bytes.
+func (e *Encoding) appendBlock(dst, block []byte) []byte {
+	var b [8]byte
+	copy(b[blockSize-len(block):], block)
+	v := binary.BigEndian.Uint64(b[:])
+	n := e.digits(len(block))
+	dst = grow(dst, n)
+	for i := len(dst) - 1; i >= len(dst)-n; i-- {
+		dst[i] = e.alphabet[v%uint64(e.radix)]
+		v /= uint64(e.radix)
+	}
+	return dst
+}
+
+// -----------------------------------------------------------------------------
+
+// NewDecoder returns a decoder of the stream encoding (see NewEncoder) read
+// from r.
+func (e *Encoding) NewDecoder(r io.Reader) io.Reader {
+	return &decoder{enc: e, r: r, in: make([]byte, 0, 32*e.blockLen)}
+}
+
+type decoder struct {
+	enc  *Encoding
+	r    io.Reader
+	in   []byte // undecoded input
+	out  []byte // decoded data not returned yet
+	off  int64  // offset of in in the input
+	eof  bool
+	err  error
+	obuf [32 * blockSize]byte
+}
+
+func (p *decoder) Read(b []byte) (n int, err error) {
+	for len(p.out) == 0 {
+		if p.err != nil {
+			return 0, p.err
+		}
+		p.fill()
+		p.decode()
+	}
+	n = copy(b, p.out)
+	p.out = p.out[n:]
+	return
+}
+
+func (p *decoder) fill() {
+	if p.eof {
+		return
+	}
+	m, err := p.r.Read(p.in[len(p.in):cap(p.in)])
+	p.in = p.in[:len(p.in)+m]
+	if err == io.EOF {
+		p.eof = true
+	} else if err != nil {
+		p.err = err
+	}
+}
+
+// decode decodes the complete blocks of in: a block is complete if more
+// input follows it, since the last one may be partial.
+func (p *decoder) decode() {
+	bl := p.enc.blockLen
+	out := p.obuf[:0]
+	i := 0
+	for ; len(p.in)-i > bl || (p.eof && len(p.in)-i == bl); i += bl {
+		if !p.decodeBlock(&out, p.in[i:i+bl], blockSize, p.off+int64(i)) {
+			break
+		}
+	}
+	if p.err == nil && p.eof && len(p.in)-i < bl && len(p.in) > i {
+		rest := p.in[i:]
+		k := 1
+		for k < blockSize && p.enc.digits(k) < len(rest) {
+			k++
+		}
+		if p.enc.digits(k) != len(rest) {
+			p.err = CorruptInputError(p.off + int64(len(p.in)))
+		} else if p.decodeBlock(&out, rest, k, p.off+int64(i)) {
+			i = len(p.in)
+		}
+	}
+	p.out = out
+	p.in = p.in[:copy(p.in, p.in[i:])]
+	p.off += int64(i)
+	if p.err == nil && p.eof && len(p.in) == 0 {
+		p.err = io.EOF
+	}
+}
+
+func (p *decoder) decodeBlock(out *[]byte, block []byte, k int, off int64) bool {
+	var v uint64
+	for j, c := range block {
+		d := p.enc.decodeMap[c]
+		if d == invalid {
+			p.err = CorruptInputError(off + int64(j))
+			return false
+		}
+		if v > (math.MaxUint64-uint64(d))/uint64(p.enc.radix) {
+			p.err = CorruptInputError(off)
+			return false
+		}
+		v = v*uint64(p.enc.radix) + uint64(d)
+	}
+	if k < blockSize && v>>(8*uint(k)) != 0 {
+		p.err = CorruptInputError(off)
+		return false
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	*out = append(*out, b[blockSize-k:]...)
+	return true
+}
+
+// -----------------------------------------------------------------------------
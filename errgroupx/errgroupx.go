@@ -0,0 +1,186 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package errgroupx runs groups of goroutines working on subtasks of a
+// common task.
+//
+// It is a variant of golang.org/x/sync/errgroup that limits the number of
+// active goroutines, turns panics into errors, can collect all the errors
+// instead of only the first one, and hands every task its own context, so
+// task-scoped values can be attached to it.
+package errgroupx
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/qiniu/x/errors"
+)
+
+// PanicError is the error of a task that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("errgroupx: task panicked: %v", e.Value)
+}
+
+// -----------------------------------------------------------------------------
+
+// A Group is a collection of goroutines. The zero value is a valid Group
+// that has no limit and doesn't cancel on errors.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	collect bool
+	mu      sync.Mutex
+	errs    errors.List
+}
+
+// WithContext returns a new Group and the context derived from ctx that is
+// canceled the first time a task fails (unless CollectAll is set) or when
+// Wait returns.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{ctx: ctx, cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of active goroutines to n. A negative value
+// means no limit. It must not be called while tasks are running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic("errgroupx: modify limit while goroutines are still active")
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// CollectAll makes Wait return all the errors of failed tasks, as an
+// errors.List, instead of the first one. Failures don't cancel the group's
+// context then, so the other tasks run to completion.
+func (g *Group) CollectAll() {
+	g.collect = true
+}
+
+// Go runs f in a new goroutine, blocking while the limit of active
+// goroutines is reached. f is passed the context of the group.
+func (g *Group) Go(f func(ctx context.Context) error) {
+	g.GoWith(nil, f)
+}
+
+// GoWithValue is like Go, but the context passed to f carries the value val
+// for key.
+func (g *Group) GoWithValue(key, val interface{}, f func(ctx context.Context) error) {
+	g.GoWith(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, key, val)
+	}, f)
+}
+
+// GoWith is like Go, but the context passed to f is derived from the context
+// of the group by wrap (if not nil).
+func (g *Group) GoWith(wrap func(ctx context.Context) context.Context, f func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(wrap, f)
+}
+
+// TryGo runs f in a new goroutine only if the limit of active goroutines
+// isn't reached, and reports whether it did.
+func (g *Group) TryGo(f func(ctx context.Context) error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(nil, f)
+	return true
+}
+
+func (g *Group) start(wrap func(ctx context.Context) context.Context, f func(ctx context.Context) error) {
+	ctx := g.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if wrap != nil {
+		ctx = wrap(ctx)
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		if err := run(ctx, f); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.collect {
+		g.errs.Add(err)
+		return
+	}
+	if len(g.errs) == 0 {
+		g.errs = errors.List{err}
+		if g.cancel != nil {
+			g.cancel()
+		}
+	}
+}
+
+func run(ctx context.Context, f func(ctx context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return f(ctx)
+}
+
+// Wait blocks until all tasks have returned, and then returns the first
+// error (or all errors, see CollectAll) of them.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.errs.ToError()
+}
+
+// -----------------------------------------------------------------------------
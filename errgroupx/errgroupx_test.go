@@ -0,0 +1,102 @@
+package errgroupx
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	xerrors "github.com/qiniu/x/errors"
+)
+
+func TestFirstError(t *testing.T) {
+	g, ctx := WithContext(context.Background())
+	errTest := errors.New("test")
+	g.Go(func(context.Context) error { return errTest })
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := g.Wait(); err != errTest {
+		t.Fatal("Wait:", err)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("ctx not canceled")
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	g, _ := WithContext(context.Background())
+	g.CollectAll()
+	for i := 0; i < 3; i++ {
+		g.Go(func(context.Context) error { return errors.New("fail") })
+	}
+	g.Go(func(context.Context) error { panic("boom") })
+	g.Go(func(context.Context) error { return nil })
+	err := g.Wait()
+	list, ok := err.(xerrors.List)
+	if !ok || len(list) != 4 {
+		t.Fatal("Wait:", err)
+	}
+	var pe *PanicError
+	found := false
+	for _, e := range list {
+		if errors.As(e, &pe) && pe.Value == "boom" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("panic not collected:", list)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	var g Group
+	g.SetLimit(2)
+	var active, peak int32
+	for i := 0; i < 10; i++ {
+		g.Go(func(context.Context) error {
+			n := atomic.AddInt32(&active, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			return nil
+		})
+	}
+	if g.Wait() != nil || peak > 2 {
+		t.Fatal("peak:", peak)
+	}
+
+	release := make(chan struct{})
+	g.SetLimit(1)
+	if !g.TryGo(func(context.Context) error { <-release; return nil }) {
+		t.Fatal("TryGo failed")
+	}
+	if g.TryGo(func(context.Context) error { return nil }) {
+		t.Fatal("TryGo exceeded the limit")
+	}
+	close(release)
+	g.Wait()
+}
+
+type ctxKey struct{}
+
+func TestValue(t *testing.T) {
+	var g Group
+	var sum int32
+	for i := 1; i <= 3; i++ {
+		g.GoWithValue(ctxKey{}, i, func(ctx context.Context) error {
+			atomic.AddInt32(&sum, int32(ctx.Value(ctxKey{}).(int)))
+			return nil
+		})
+	}
+	if g.Wait() != nil || sum != 6 {
+		t.Fatal("sum:", sum)
+	}
+}
@@ -0,0 +1,263 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// --------------------------------------------------------------------
+
+// Code is a canonical error code. The values are those of gRPC
+// (google.golang.org/grpc/codes), so a Code converts to and from
+// codes.Code by a plain conversion.
+type Code uint32
+
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFoundCode       Code = 5 // named so, as NotFound is the error type
+	AlreadyExists      Code = 6
+	PermissionDenied   Code = 7
+	ResourceExhausted  Code = 8
+	FailedPrecondition Code = 9
+	Aborted            Code = 10
+	OutOfRange         Code = 11
+	Unimplemented      Code = 12
+	Internal           Code = 13
+	Unavailable        Code = 14
+	DataLoss           Code = 15
+	Unauthenticated    Code = 16
+)
+
+var codeNames = [...]string{
+	"OK", "Canceled", "Unknown", "InvalidArgument", "DeadlineExceeded",
+	"NotFound", "AlreadyExists", "PermissionDenied", "ResourceExhausted",
+	"FailedPrecondition", "Aborted", "OutOfRange", "Unimplemented",
+	"Internal", "Unavailable", "DataLoss", "Unauthenticated",
+}
+
+func (c Code) String() string {
+	if int(c) < len(codeNames) {
+		return codeNames[c]
+	}
+	return "Code(" + strconv.FormatUint(uint64(c), 10) + ")"
+}
+
+var codeHTTP = [...]int{
+	http.StatusOK,
+	499, // client closed request
+	http.StatusInternalServerError,
+	http.StatusBadRequest,
+	http.StatusGatewayTimeout,
+	http.StatusNotFound,
+	http.StatusConflict,
+	http.StatusForbidden,
+	http.StatusTooManyRequests,
+	http.StatusBadRequest,
+	http.StatusConflict,
+	http.StatusBadRequest,
+	http.StatusNotImplemented,
+	http.StatusInternalServerError,
+	http.StatusServiceUnavailable,
+	http.StatusInternalServerError,
+	http.StatusUnauthorized,
+}
+
+// HTTPStatus returns the HTTP status code that corresponds to c.
+func (c Code) HTTPStatus() int {
+	if int(c) < len(codeHTTP) {
+		return codeHTTP[c]
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeFromHTTP returns the Code that corresponds to the HTTP status code.
+func CodeFromHTTP(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return InvalidArgument
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return NotFoundCode
+	case http.StatusConflict:
+		return AlreadyExists
+	case http.StatusPreconditionFailed:
+		return FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return OutOfRange
+	case http.StatusTooManyRequests:
+		return ResourceExhausted
+	case 499:
+		return Canceled
+	case http.StatusNotImplemented:
+		return Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusGatewayTimeout:
+		return DeadlineExceeded
+	}
+	switch {
+	case status < 400:
+		return OK
+	case status < 500:
+		return FailedPrecondition
+	}
+	return Unknown
+}
+
+// --------------------------------------------------------------------
+
+// Status is an error with a canonical code, a message and details. It
+// carries the content of a gRPC status: see FromGRPC and CodeOf.
+type Status struct {
+	Code    Code
+	Message string
+
+	// Details are additional error details, typically protobuf messages
+	// such as those of google.golang.org/genproto/googleapis/rpc/errdetails.
+	Details []interface{}
+
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Errorf returns a *Status error of code c with a formatted message.
+func Errorf(c Code, format string, args ...interface{}) *Status {
+	return &Status{Code: c, Message: fmt.Sprintf(format, args...)}
+}
+
+// WithCode wraps err in a *Status error of code c. It returns nil if err is
+// nil.
+func WithCode(err error, c Code) error {
+	if err == nil {
+		return nil
+	}
+	return &Status{Code: c, Message: err.Error(), Err: err}
+}
+
+// FromGRPC returns the *Status of a gRPC status, as in
+//
+//	st := status.Convert(err)
+//	e := errors.FromGRPC(uint32(st.Code()), st.Message(), st.Details()...)
+//
+// It returns nil for the OK code.
+func FromGRPC(code uint32, msg string, details ...interface{}) error {
+	if code == uint32(OK) {
+		return nil
+	}
+	return &Status{Code: Code(code), Message: msg, Details: details}
+}
+
+func (p *Status) Error() string {
+	if p.Message == "" {
+		return p.Code.String()
+	}
+	return p.Message
+}
+
+func (p *Status) Unwrap() error {
+	return p.Err
+}
+
+// HttpCode returns the HTTP status code of p, so that a *Status replies as
+// the other errors with an HttpCode method.
+func (p *Status) HttpCode() int {
+	return p.Code.HTTPStatus()
+}
+
+// GRPC returns the content of the gRPC status of p, as in
+//
+//	code, msg, details := e.GRPC()
+//	st, _ := status.New(codes.Code(code), msg).WithDetails(details...)
+func (p *Status) GRPC() (code uint32, msg string, details []interface{}) {
+	return uint32(p.Code), p.Message, p.Details
+}
+
+// WithDetails returns a copy of p with details appended.
+func (p *Status) WithDetails(details ...interface{}) *Status {
+	ret := *p
+	ret.Details = append(append([]interface{}(nil), p.Details...), details...)
+	return &ret
+}
+
+// --------------------------------------------------------------------
+
+// StatusOf returns the *Status in err's chain. An error without one is
+// converted by CodeOf, with its text as message. It returns nil if err is
+// nil.
+func StatusOf(err error) *Status {
+	if err == nil {
+		return nil
+	}
+	for e := err; e != nil; {
+		if st, ok := e.(*Status); ok {
+			return st
+		}
+		u, ok := e.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		e = u.Unwrap()
+	}
+	return &Status{Code: CodeOf(err), Message: err.Error(), Err: err}
+}
+
+// CodeOf returns the canonical code of err, from the first error in its
+// chain that is a *Status, has a GRPCCode() uint32 or HttpCode() int
+// method, is a *NotFound, or is a context error. It returns OK if err is
+// nil, and Unknown if nothing in the chain has a code.
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+	for e := err; e != nil; {
+		switch v := e.(type) {
+		case *Status:
+			return v.Code
+		case *NotFound:
+			return NotFoundCode
+		case interface{ GRPCCode() uint32 }:
+			return Code(v.GRPCCode())
+		case interface{ HttpCode() int }:
+			return CodeFromHTTP(v.HttpCode())
+		}
+		switch e {
+		case context.Canceled:
+			return Canceled
+		case context.DeadlineExceeded:
+			return DeadlineExceeded
+		}
+		u, ok := e.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		e = u.Unwrap()
+	}
+	return Unknown
+}
+
+// --------------------------------------------------------------------
@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type httpError int
+
+func (e httpError) Error() string { return "http error" }
+func (e httpError) HttpCode() int { return int(e) }
+
+func TestCodeOf(t *testing.T) {
+	cases := []struct {
+		err  error
+		code Code
+	}{
+		{nil, OK},
+		{New("plain"), Unknown},
+		{Errorf(PermissionDenied, "no access to %s", "x"), PermissionDenied},
+		{fmt.Errorf("wrapped: %w", WithCode(New("gone"), NotFoundCode)), NotFoundCode},
+		{NewWith(&NotFound{Category: "file"}, "Open()", 0, "Open"), NotFoundCode},
+		{fmt.Errorf("call: %w", context.DeadlineExceeded), DeadlineExceeded},
+		{httpError(429), ResourceExhausted},
+		{httpError(503), Unavailable},
+	}
+	for _, c := range cases {
+		if code := CodeOf(c.err); code != c.code {
+			t.Fatalf("CodeOf(%v) = %v, want %v", c.err, code, c.code)
+		}
+	}
+}
+
+func TestStatusGRPC(t *testing.T) {
+	detail := struct{ Field string }{"name"}
+	err := Errorf(InvalidArgument, "bad name").WithDetails(detail)
+	code, msg, details := err.GRPC()
+	back := FromGRPC(code, msg, details...)
+	st := StatusOf(fmt.Errorf("handler: %w", back))
+	if st.Code != InvalidArgument || st.Message != "bad name" || len(st.Details) != 1 || st.Details[0] != detail {
+		t.Fatalf("round trip: %+v", st)
+	}
+	if st.HttpCode() != 400 || FromGRPC(0, "") != nil {
+		t.Fatal("unexpected status")
+	}
+	if st = StatusOf(New("plain")); st.Code != Unknown || st.Message != "plain" {
+		t.Fatalf("StatusOf(plain) = %+v", st)
+	}
+	if Code(42).String() != "Code(42)" || DataLoss.String() != "DataLoss" {
+		t.Fatal("Code.String")
+	}
+	for _, c := range []Code{Canceled, DeadlineExceeded, NotFoundCode, AlreadyExists, PermissionDenied,
+		ResourceExhausted, Unimplemented, Unavailable, Unauthenticated} {
+		if CodeFromHTTP(c.HTTPStatus()) != c {
+			t.Fatalf("%v doesn't round trip through HTTP", c)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package filepathx extends the path/filepath package with double-star
+// globbing over an fs.FS and safe joining of untrusted paths.
+package filepathx
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath is returned by SecureJoin when a path escapes its base.
+var ErrUnsafePath = errors.New("filepathx: path escapes base directory")
+
+// -----------------------------------------------------------------------------
+
+// Match reports whether name matches the slash-separated pattern. Segments of
+// the pattern have the syntax of path.Match, and a segment "**" matches zero
+// or more segments of name: "a/**/*.go" matches "a/x.go" and "a/b/c/x.go".
+func Match(pattern, name string) (bool, error) {
+	return matchSegs(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegs(pat, name []string) (bool, error) {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for len(pat) > 0 && pat[0] == "**" {
+				pat = pat[1:]
+			}
+			if len(pat) == 0 {
+				return true, nil
+			}
+			for i := 0; i <= len(name); i++ {
+				if ok, err := matchSegs(pat, name[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(name) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pat[0], name[0])
+		if !ok || err != nil {
+			return false, err
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
+
+// Glob returns the names of the files of fsys matching pattern (see Match),
+// in lexical order, leaving out those that match any of excludes. A
+// directory matching an exclude pattern is not walked at all, so "**/.git"
+// prunes every .git directory.
+func Glob(fsys fs.FS, pattern string, excludes ...string) (matches []string, err error) {
+	if _, err = Match(pattern, ""); err != nil {
+		return
+	}
+	for _, ex := range excludes {
+		if _, err = Match(ex, ""); err != nil {
+			return
+		}
+	}
+	root := staticPrefix(pattern)
+	if _, err = fs.Stat(fsys, root); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	err = fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, ex := range excludes {
+			if ok, _ := Match(ex, name); ok {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+		}
+		if ok, _ := Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+		return nil
+	})
+	return
+}
+
+// staticPrefix returns the leading segments of pattern without any
+// metacharacters, where the walk of Glob starts.
+func staticPrefix(pattern string) string {
+	segs := strings.Split(pattern, "/")
+	i := 0
+	for i < len(segs)-1 && !strings.ContainsAny(segs[i], `*?[\`) {
+		i++
+	}
+	if i == 0 {
+		return "."
+	}
+	return strings.Join(segs[:i], "/")
+}
+
+// -----------------------------------------------------------------------------
+
+// SecureJoin joins base and the untrusted userPath, failing with
+// ErrUnsafePath if the result would lie outside of base. userPath may use
+// "/" or the OS separator; ".." elements are allowed as long as they don't
+// climb above base. The check is lexical: symbolic links are not followed,
+// see SecureJoinEval for that.
+func SecureJoin(base, userPath string) (string, error) {
+	rel, err := cleanRel(userPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, rel), nil
+}
+
+func cleanRel(userPath string) (string, error) {
+	if strings.IndexByte(userPath, 0) >= 0 {
+		return "", ErrUnsafePath
+	}
+	p := filepath.Clean(filepath.FromSlash(userPath))
+	if filepath.IsAbs(p) || filepath.VolumeName(p) != "" || strings.HasPrefix(p, string(filepath.Separator)) {
+		return "", ErrUnsafePath
+	}
+	if p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+		return "", ErrUnsafePath
+	}
+	return p, nil
+}
+
+const maxSymlinks = 255
+
+// SecureJoinEval is like SecureJoin, but also resolves the symbolic links
+// found under base, and fails if one of them points outside of base.
+// Components that don't exist are joined lexically.
+func SecureJoinEval(base, userPath string) (string, error) {
+	rel, err := cleanRel(userPath)
+	if err != nil {
+		return "", err
+	}
+	base = filepath.Clean(base)
+	var resolved []string // components under base
+	todo := strings.Split(rel, string(filepath.Separator))
+	links := 0
+	for len(todo) > 0 {
+		name := todo[0]
+		todo = todo[1:]
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) == 0 {
+				return "", ErrUnsafePath
+			}
+			resolved = resolved[:len(resolved)-1]
+			continue
+		}
+		cur := filepath.Join(append([]string{base}, append(resolved, name)...)...)
+		fi, err := os.Lstat(cur)
+		if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+			resolved = append(resolved, name)
+			continue
+		}
+		if links++; links > maxSymlinks {
+			return "", errors.New("filepathx: too many symbolic links")
+		}
+		target, err := os.Readlink(cur)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			r, err := filepath.Rel(base, target)
+			if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+				return "", ErrUnsafePath
+			}
+			resolved, target = nil, r
+		}
+		todo = append(strings.Split(filepath.Clean(target), string(filepath.Separator)), todo...)
+	}
+	return filepath.Join(append([]string{base}, resolved...)...), nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,105 @@
+package filepathx
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		ok            bool
+	}{
+		{"**/*.go", "a.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"a/**/*.go", "a/x.go", true},
+		{"a/**/*.go", "b/x.go", false},
+		{"a/**", "a/b/c", true},
+		{"a/**/b/**/c", "a/x/b/y/z/c", true},
+		{"a/*/c", "a/b/d/c", false},
+		{"*.txt", "a/b.txt", false},
+	}
+	for _, c := range cases {
+		if ok, err := Match(c.pattern, c.name); ok != c.ok || err != nil {
+			t.Fatal(c.pattern, c.name, ok, err)
+		}
+	}
+	if _, err := Match("[", "a"); err == nil {
+		t.Fatal("bad pattern accepted")
+	}
+}
+
+func TestGlob(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go":             {},
+		"README.md":           {},
+		"pkg/a.go":            {},
+		"pkg/a_test.go":       {},
+		"pkg/sub/b.go":        {},
+		"pkg/.git/hooks/x.go": {},
+		"vendor/v/v.go":       {},
+	}
+	got, err := Glob(fsys, "**/*.go", "**/.git", "vendor", "**/*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.Join(got, ","); s != "main.go,pkg/a.go,pkg/sub/b.go" {
+		t.Fatal(s)
+	}
+	got, _ = Glob(fsys, "pkg/**/*.go")
+	if len(got) != 4 {
+		t.Fatal(got)
+	}
+	if got, err = Glob(fsys, "nothing/**"); err != nil || got != nil {
+		t.Fatal(got, err)
+	}
+}
+
+func TestSecureJoin(t *testing.T) {
+	base := filepath.FromSlash("/srv/data")
+	for _, p := range []string{"a/b", "a/../b", "./a", ""} {
+		got, err := SecureJoin(base, p)
+		if err != nil || !strings.HasPrefix(got, base) {
+			t.Fatal(p, got, err)
+		}
+	}
+	for _, p := range []string{"..", "../etc/passwd", "a/../../b", "/etc/passwd", "a\x00b"} {
+		if got, err := SecureJoin(base, p); err != ErrUnsafePath {
+			t.Fatal(p, got, err)
+		}
+	}
+}
+
+func TestSecureJoinEval(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges")
+	}
+	base := t.TempDir()
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(base, "dir"), 0755)
+	os.Symlink("dir", filepath.Join(base, "in"))
+	os.Symlink("../..", filepath.Join(base, "dir", "up"))
+	os.Symlink(outside, filepath.Join(base, "out"))
+	os.Symlink(filepath.Join(base, "dir"), filepath.Join(base, "abs"))
+
+	cases := map[string]string{
+		"in/file":     "dir/file",
+		"abs/x":       "dir/x",
+		"missing/a/b": "missing/a/b",
+	}
+	for p, want := range cases {
+		got, err := SecureJoinEval(base, p)
+		if err != nil || got != filepath.Join(base, want) {
+			t.Fatal(p, got, err)
+		}
+	}
+	for _, p := range []string{"out/x", "dir/up/x", "in/up"} {
+		if got, err := SecureJoinEval(base, p); err != ErrUnsafePath {
+			t.Fatal(p, got, err)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package fsnotify provides portable, polling-based file system notifications.
+//
+// A Watcher periodically stats the files and directories added to it and
+// reports what changed through its Events channel. A watched directory reports
+// the creation, removal and modification of its direct entries.
+package fsnotify
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qiniu/x/hashx"
+)
+
+// Op describes a set of file operations.
+type Op uint32
+
+// The operations a Watcher reports. A polling watcher can't tell a rename
+// from a removal, so a renamed file is reported as Remove (and Create of the
+// new name, if it is watched); Rename is kept for API compatibility.
+const (
+	Create Op = 1 << iota
+	Write
+	Remove
+	Rename
+	Chmod
+)
+
+func (op Op) String() string {
+	var b []string
+	for i, name := range []string{"CREATE", "WRITE", "REMOVE", "RENAME", "CHMOD"} {
+		if op&(1<<uint(i)) != 0 {
+			b = append(b, name)
+		}
+	}
+	if b == nil {
+		return "[no events]"
+	}
+	return strings.Join(b, "|")
+}
+
+// Event represents a single file system notification.
+type Event struct {
+	Name string // path to the file or directory
+	Op   Op     // file operation that triggered the event
+}
+
+func (e Event) String() string {
+	return e.Op.String() + " " + e.Name
+}
+
+// ErrClosed is returned when a Watcher is used after Close.
+var ErrClosed = errors.New("fsnotify: watcher closed")
+
+// DefaultInterval is the polling interval of NewWatcher.
+const DefaultInterval = time.Second
+
+// -----------------------------------------------------------------------------
+
+type state struct {
+	size   int64
+	mtime  time.Time
+	mode   os.FileMode
+	hash   uint64
+	hashed bool
+}
+
+func stateOf(fi os.FileInfo) state {
+	return state{size: fi.Size(), mtime: fi.ModTime(), mode: fi.Mode()}
+}
+
+// Watcher watches a set of files and directories.
+type Watcher struct {
+	Events chan Event
+	Errors chan error
+
+	confirm bool
+
+	mu      sync.Mutex
+	watches map[string]map[string]state // watched name => (path => state)
+	done    chan struct{}
+	closed  bool
+}
+
+// Options configures a Watcher.
+type Options struct {
+	// Interval is the polling interval. Zero means DefaultInterval.
+	Interval time.Duration
+
+	// ConfirmContent suppresses the Write events of files whose content
+	// didn't change, such as those of editors and config managers that
+	// rewrite a file as it was: the watcher then keeps a hash of each
+	// watched regular file, and rehashes a file when its size or
+	// modification time changes.
+	ConfirmContent bool
+}
+
+// NewWatcher creates a watcher that polls every DefaultInterval.
+func NewWatcher() (*Watcher, error) {
+	return NewPollingWatcher(DefaultInterval), nil
+}
+
+// NewPollingWatcher creates a watcher that polls every interval.
+func NewPollingWatcher(interval time.Duration) *Watcher {
+	return NewWatcherWithOptions(&Options{Interval: interval})
+}
+
+// NewWatcherWithOptions creates a watcher configured by opts.
+func NewWatcherWithOptions(opts *Options) *Watcher {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	w := &Watcher{
+		Events:  make(chan Event),
+		Errors:  make(chan error),
+		confirm: opts.ConfirmContent,
+		watches: make(map[string]map[string]state),
+		done:    make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+// Add starts watching the named file or directory.
+func (w *Watcher) Add(name string) error {
+	name = filepath.Clean(name)
+	files, err := scan(name)
+	if err != nil {
+		return err
+	}
+	if w.confirm {
+		hashFiles(nil, files)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	w.watches[name] = files
+	return nil
+}
+
+// Remove stops watching the named file or directory.
+func (w *Watcher) Remove(name string) error {
+	name = filepath.Clean(name)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.watches[name]; !ok {
+		return errors.New("fsnotify: can't remove non-existent watch: " + name)
+	}
+	delete(w.watches, name)
+	return nil
+}
+
+// Close removes all watches and closes the Events and Errors channels.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	close(w.done)
+	return nil
+}
+
+func (w *Watcher) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer func() {
+		ticker.Stop()
+		close(w.Events)
+		close(w.Errors)
+	}()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		if !w.poll() {
+			return
+		}
+	}
+}
+
+// poll scans all watches once; it returns false if the watcher is closed.
+func (w *Watcher) poll() bool {
+	w.mu.Lock()
+	names := make([]string, 0, len(w.watches))
+	for name := range w.watches {
+		names = append(names, name)
+	}
+	w.mu.Unlock()
+
+	for _, name := range names {
+		files, err := scan(name)
+		if err != nil && !os.IsNotExist(err) {
+			if !w.send(nil, err) {
+				return false
+			}
+			continue
+		}
+		w.mu.Lock()
+		old, ok := w.watches[name]
+		w.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if w.confirm {
+			hashFiles(old, files)
+		}
+		w.mu.Lock()
+		if _, ok = w.watches[name]; ok {
+			w.watches[name] = files
+		}
+		w.mu.Unlock()
+		if !ok {
+			continue
+		}
+		for _, e := range diff(old, files) {
+			if !w.send(&e, nil) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (w *Watcher) send(e *Event, err error) bool {
+	if e != nil {
+		select {
+		case w.Events <- *e:
+			return true
+		case <-w.done:
+			return false
+		}
+	}
+	select {
+	case w.Errors <- err:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// scan returns the state of name and, if name is a directory, of its direct
+// entries. A missing name results in an empty set and os.ErrNotExist.
+func scan(name string) (files map[string]state, err error) {
+	files = make(map[string]state)
+	fi, err := os.Stat(name)
+	if err != nil {
+		return
+	}
+	if fi.IsDir() {
+		fis, err := ioutil.ReadDir(name)
+		if err != nil {
+			return files, err
+		}
+		for _, fi := range fis {
+			files[filepath.Join(name, fi.Name())] = stateOf(fi)
+		}
+		return files, nil
+	}
+	files[name] = stateOf(fi)
+	return
+}
+
+func diff(old, cur map[string]state) (events []Event) {
+	for name, s := range cur {
+		o, ok := old[name]
+		switch {
+		case !ok:
+			events = append(events, Event{Name: name, Op: Create})
+		case o.size != s.size || !o.mtime.Equal(s.mtime):
+			if o.hashed && s.hashed && o.hash == s.hash {
+				continue // same content
+			}
+			events = append(events, Event{Name: name, Op: Write})
+		case o.mode != s.mode:
+			events = append(events, Event{Name: name, Op: Chmod})
+		}
+	}
+	for name := range old {
+		if _, ok := cur[name]; !ok {
+			events = append(events, Event{Name: name, Op: Remove})
+		}
+	}
+	return
+}
+
+// hashFiles sets the content hash of the regular files of cur, reusing the
+// hash of old for the files whose size and modification time didn't change.
+// A file that can't be read is left unhashed.
+func hashFiles(old, cur map[string]state) {
+	for name, s := range cur {
+		if !s.mode.IsRegular() {
+			continue
+		}
+		if o, ok := old[name]; ok && o.hashed && o.size == s.size && o.mtime.Equal(s.mtime) {
+			s.hash, s.hashed = o.hash, true
+		} else if h, err := hashFile(name); err == nil {
+			s.hash, s.hashed = h, true
+		}
+		cur[name] = s
+	}
+}
+
+func hashFile(name string) (uint64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	h := hashx.XXH64(0).New()
+	if _, err = io.Copy(h, f); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
+
+// -----------------------------------------------------------------------------
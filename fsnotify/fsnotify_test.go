@@ -0,0 +1,76 @@
+package fsnotify
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func expect(t *testing.T, w *Watcher, name string, op Op) {
+	t.Helper()
+	select {
+	case e := <-w.Events:
+		if e.Name != name || e.Op != op {
+			t.Fatalf("got event %v; want %v %v", e, op, name)
+		}
+	case err := <-w.Errors:
+		t.Fatal("unexpected error:", err)
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for %v %v", op, name)
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "conf.json")
+	ioutil.WriteFile(name, []byte("{}"), 0644)
+
+	w := NewPollingWatcher(10 * time.Millisecond)
+	defer w.Close()
+	if err := w.Add(name); err != nil {
+		t.Fatal("Add:", err)
+	}
+	ioutil.WriteFile(name, []byte(`{"a": 1}`), 0644)
+	expect(t, w, name, Write)
+	os.Remove(name)
+	expect(t, w, name, Remove)
+	ioutil.WriteFile(name, []byte("{}"), 0644)
+	expect(t, w, name, Create)
+}
+
+func TestWatchDir(t *testing.T) {
+	dir := t.TempDir()
+	w := NewPollingWatcher(10 * time.Millisecond)
+	defer w.Close()
+	if err := w.Add(dir); err != nil {
+		t.Fatal("Add:", err)
+	}
+	name := filepath.Join(dir, "a")
+	ioutil.WriteFile(name, nil, 0644)
+	expect(t, w, name, Create)
+	if err := w.Remove(dir); err != nil {
+		t.Fatal("Remove:", err)
+	}
+}
+
+func TestConfirmContent(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "conf.json")
+	ioutil.WriteFile(name, []byte(`{"a": 1}`), 0644)
+
+	w := NewWatcherWithOptions(&Options{Interval: 10 * time.Millisecond, ConfirmContent: true})
+	defer w.Close()
+	if err := w.Add(name); err != nil {
+		t.Fatal("Add:", err)
+	}
+	later := time.Now().Add(time.Hour)
+	ioutil.WriteFile(name, []byte(`{"a": 1}`), 0644)
+	os.Chtimes(name, later, later)
+	select {
+	case e := <-w.Events:
+		t.Fatal("unexpected event:", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+	ioutil.WriteFile(name, []byte(`{"a": 2}`), 0644)
+	expect(t, w, name, Write)
+}
@@ -0,0 +1,11 @@
+package fsnotify
+
+import (
+	"testing"
+
+	"github.com/qiniu/x/ts"
+)
+
+func TestMain(m *testing.M) {
+	ts.VerifyTestMain(m)
+}
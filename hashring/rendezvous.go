@@ -0,0 +1,165 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package hashring implements rendezvous (highest random weight) hashing.
+//
+// Every key is owned by the node that has the highest score for it, where the
+// score is derived from a hash of the key and the node. Adding or removing a
+// node only moves the keys owned by that node, and no virtual nodes are
+// needed. Nodes may be weighted: a node of weight 2 owns about twice as many
+// keys as a node of weight 1.
+package hashring
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/qiniu/x/hashx"
+)
+
+// Hash is a 64-bit string hash function.
+type Hash = func(s string) uint64
+
+type node[T any] struct {
+	id     string
+	value  T
+	weight float64
+	hash   uint64
+}
+
+// Rendezvous is a set of weighted nodes of type T. It is safe for
+// concurrent use.
+type Rendezvous[T any] struct {
+	id   func(T) string
+	hash Hash
+
+	mu    sync.RWMutex
+	nodes []node[T]
+}
+
+// New creates an empty set of nodes identified by id.
+func New[T any](id func(T) string) *Rendezvous[T] {
+	return NewWithHash(id, nil)
+}
+
+// NewWithHash creates an empty set of nodes identified by id, which are
+// hashed by hash. Nil hash means a mixed FNV-1a.
+func NewWithHash[T any](id func(T) string, hash Hash) *Rendezvous[T] {
+	if hash == nil {
+		hash = defaultHash
+	}
+	return &Rendezvous[T]{id: id, hash: hash}
+}
+
+// NewStrings creates an empty set of string nodes, identified by themselves.
+func NewStrings() *Rendezvous[string] {
+	return New(func(s string) string { return s })
+}
+
+// Add adds node with the given weight, or updates it if a node with the same
+// id exists. Weights must be positive.
+func (r *Rendezvous[T]) Add(value T, weight float64) {
+	if weight <= 0 {
+		panic("hashring: weight must be positive")
+	}
+	id := r.id(value)
+	n := node[T]{id: id, value: value, weight: weight, hash: r.hash(id)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.nodes {
+		if r.nodes[i].id == id {
+			r.nodes[i] = n
+			return
+		}
+	}
+	r.nodes = append(r.nodes, n)
+}
+
+// Remove removes the node with the given id.
+func (r *Rendezvous[T]) Remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.nodes {
+		if r.nodes[i].id == id {
+			r.nodes = append(r.nodes[:i], r.nodes[i+1:]...)
+			return
+		}
+	}
+}
+
+// Len returns the number of nodes.
+func (r *Rendezvous[T]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.nodes)
+}
+
+// Get returns the node that owns key. It returns false if there are no nodes.
+func (r *Rendezvous[T]) Get(key string) (owner T, ok bool) {
+	kh := r.hash(key)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	best := math.Inf(-1)
+	for i := range r.nodes {
+		if s := score(kh, &r.nodes[i]); s > best {
+			best, owner, ok = s, r.nodes[i].value, true
+		}
+	}
+	return
+}
+
+// GetN returns up to n nodes for key, by decreasing score: the owner first,
+// then the nodes that would own key if the previous ones were removed. It is
+// suitable for placing replicas.
+func (r *Rendezvous[T]) GetN(key string, n int) []T {
+	kh := r.hash(key)
+	r.mu.RLock()
+	type scored struct {
+		score float64
+		value T
+	}
+	all := make([]scored, len(r.nodes))
+	for i := range r.nodes {
+		all[i] = scored{score(kh, &r.nodes[i]), r.nodes[i].value}
+	}
+	r.mu.RUnlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].score > all[j].score })
+	if n > len(all) {
+		n = len(all)
+	}
+	ret := make([]T, n)
+	for i := range ret {
+		ret[i] = all[i].value
+	}
+	return ret
+}
+
+// score is the weighted score of the logarithmic method: -w / ln(u), where u
+// is the hash of (key, node) mapped to (0, 1).
+func score[T any](kh uint64, n *node[T]) float64 {
+	h := hashx.Mix64(kh ^ n.hash)
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return -n.weight / math.Log(u)
+}
+
+// -----------------------------------------------------------------------------
+
+func defaultHash(s string) uint64 {
+	return hashx.Mix64(hashx.FNV1a(0).Sum64String(s))
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,63 @@
+package hashring
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRendezvous(t *testing.T) {
+	r := NewStrings()
+	if _, ok := r.Get("key"); ok {
+		t.Fatal("Get on empty set")
+	}
+	for _, n := range []string{"a", "b", "c", "d"} {
+		r.Add(n, 1)
+	}
+	const keys = 10000
+	owners := make(map[string]string, keys)
+	counts := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		key := strconv.Itoa(i)
+		owner, _ := r.Get(key)
+		owners[key] = owner
+		counts[owner]++
+	}
+	for n, c := range counts {
+		if c < keys/4*8/10 || c > keys/4*12/10 {
+			t.Fatalf("unbalanced: %s owns %d keys", n, c)
+		}
+	}
+
+	// removing a node only moves the keys it owned
+	r.Remove("b")
+	for key, old := range owners {
+		owner, _ := r.Get(key)
+		if old != "b" && owner != old {
+			t.Fatalf("key %s moved from %s to %s", key, old, owner)
+		}
+	}
+
+	owner, _ := r.Get("42")
+	if top := r.GetN("42", 5); len(top) != 3 || top[0] != owner {
+		t.Fatal("GetN:", top)
+	}
+}
+
+func TestWeights(t *testing.T) {
+	type peer struct {
+		addr string
+	}
+	r := New(func(p *peer) string { return p.addr })
+	big, small := &peer{"big"}, &peer{"small"}
+	r.Add(big, 3)
+	r.Add(small, 1)
+	n := 0
+	for i := 0; i < 10000; i++ {
+		if owner, _ := r.Get(strconv.Itoa(i)); owner == big {
+			n++
+		}
+	}
+	if n < 7000 || n > 8000 {
+		t.Fatal("weighted node owns:", n)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hashx
+
+import (
+	"hash"
+	"hash/crc32"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// CRC32C is CRC-32 with the Castagnoli polynomial (hardware accelerated on
+// most CPUs), starting from the seed instead of 0. Its 64-bit sums are the
+// 32-bit checksums zero-extended, so it is meant for checksums rather than
+// for hash tables.
+type CRC32C uint32
+
+// Sum64 implements the Hasher interface.
+func (seed CRC32C) Sum64(b []byte) uint64 {
+	return uint64(crc32.Update(uint32(seed), castagnoli, b))
+}
+
+// Sum64String implements the Hasher interface.
+func (seed CRC32C) Sum64String(s string) uint64 {
+	return seed.Sum64(bytesOf(s))
+}
+
+// New implements the Hasher interface.
+func (seed CRC32C) New() hash.Hash64 {
+	return &crcDigest{seed: uint32(seed), crc: uint32(seed)}
+}
+
+type crcDigest struct {
+	seed, crc uint32
+}
+
+func (d *crcDigest) Reset()         { d.crc = d.seed }
+func (d *crcDigest) Size() int      { return 4 }
+func (d *crcDigest) BlockSize() int { return 1 }
+func (d *crcDigest) Sum64() uint64  { return uint64(d.crc) }
+
+func (d *crcDigest) Write(b []byte) (int, error) {
+	d.crc = crc32.Update(d.crc, castagnoli, b)
+	return len(b), nil
+}
+
+func (d *crcDigest) Sum(b []byte) []byte {
+	return append(b, byte(d.crc>>24), byte(d.crc>>16), byte(d.crc>>8), byte(d.crc))
+}
+
+// -----------------------------------------------------------------------------
+
+var (
+	_ Hasher = XXH64(0)
+	_ Hasher = FNV1a(0)
+	_ Hasher = CRC32C(0)
+)
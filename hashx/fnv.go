@@ -0,0 +1,87 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hashx
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	fnvOffset64 uint64 = 14695981039346656037
+	fnvPrime64  uint64 = 1099511628211
+)
+
+// FNV1a is 64-bit FNV-1a whose offset basis is xored with the seed, so
+// FNV1a(0) is the standard FNV-1a of hash/fnv.
+type FNV1a uint64
+
+// Sum64 implements the Hasher interface.
+func (seed FNV1a) Sum64(b []byte) uint64 {
+	h := fnvOffset64 ^ uint64(seed)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// Sum64String implements the Hasher interface.
+func (seed FNV1a) Sum64String(s string) uint64 {
+	h := fnvOffset64 ^ uint64(seed)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// New implements the Hasher interface.
+func (seed FNV1a) New() hash.Hash64 {
+	d := &fnvDigest{seed: uint64(seed)}
+	d.Reset()
+	return d
+}
+
+type fnvDigest struct {
+	seed, h uint64
+}
+
+func (d *fnvDigest) Reset()         { d.h = fnvOffset64 ^ d.seed }
+func (d *fnvDigest) Size() int      { return 8 }
+func (d *fnvDigest) BlockSize() int { return 1 }
+func (d *fnvDigest) Sum64() uint64  { return d.h }
+
+func (d *fnvDigest) Write(b []byte) (int, error) {
+	h := d.h
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	d.h = h
+	return len(b), nil
+}
+
+func (d *fnvDigest) WriteString(s string) (int, error) {
+	return d.Write(bytesOf(s))
+}
+
+func (d *fnvDigest) Sum(b []byte) []byte {
+	var s [8]byte
+	binary.BigEndian.PutUint64(s[:], d.h)
+	return append(b, s[:]...)
+}
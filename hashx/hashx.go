@@ -0,0 +1,59 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package hashx provides the fast non-cryptographic hash functions used
+// across this module behind one seedable interface: XXH64, the 128-bit
+// XXH3 (XXH128), FNV-1a and CRC-32C. Every function has a string variant
+// that hashes the string in place, without converting it to a []byte.
+package hashx
+
+import (
+	"hash"
+	"unsafe"
+)
+
+// Hasher is a seeded 64-bit hash function.
+type Hasher interface {
+	// Sum64 returns the hash of b.
+	Sum64(b []byte) uint64
+
+	// Sum64String returns the hash of s. It doesn't copy s.
+	Sum64String(s string) uint64
+
+	// New returns a streaming hash.Hash64 with the same seed, whose Sum64
+	// equals Sum64 of all the data written to it.
+	New() hash.Hash64
+}
+
+// Mix64 is the finalizer of MurmurHash3. It spreads the entropy of all bits
+// of h to all bits of the result, which makes up for weak hashes such as
+// FNV when the low bits are used alone (e.g. to index buckets).
+func Mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// bytesOf returns the bytes of s without copying. They must not be modified.
+func bytesOf(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
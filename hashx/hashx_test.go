@@ -0,0 +1,140 @@
+package hashx
+
+import (
+	"hash/crc32"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestXXH64(t *testing.T) {
+	cases := []struct {
+		s    string
+		want uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"a", 0xd24ec4f1a98c6e5b},
+		{"as", 0x1c330fb2d66be179},
+		{"asd", 0x631c37ce72a97393},
+		{"asdf", 0x415872f599cea71e},
+		{"Call me Ishmael. Some years ago--never mind how long precisely-", 0x02a2e85470d6fd96},
+	}
+	for _, c := range cases {
+		if got := XXH64(0).Sum64String(c.s); got != c.want {
+			t.Fatalf("XXH64(%q) = %#x, want %#x", c.s, got, c.want)
+		}
+	}
+}
+
+func TestXXH128(t *testing.T) {
+	data := make([]byte, 3000)
+	for i := range data {
+		data[i] = byte((i*131 + 7) % 251)
+	}
+	cases := []struct {
+		n      int
+		seed   XXH128
+		hi, lo uint64
+	}{
+		{0, 0, 0x99aa06d3014798d8, 0x6001c324468d497f},
+		{1, 0, 0x495b62073ef70ca4, 0x4c5cca45d0f4811f},
+		{3, 0, 0xde0de73b78221781, 0xd079cb72d5dc8fe3},
+		{4, 0, 0x2ec1f0e0927bbd72, 0xa7f588a0488a2ce1},
+		{8, 0, 0x86352c64c51053a4, 0xf169e0e241e43d88},
+		{9, 0, 0x2da82c32992eb47c, 0xecaf34715ab252db},
+		{16, 0, 0x2ea9b26e0de3c129, 0x3ad9b09b55ff159d},
+		{17, 0, 0xe98962a9feec88ce, 0x2aded60943cd42fc},
+		{100, 0, 0x0e7925fc8a2ef778, 0xd33049b124024b75},
+		{128, 0, 0xc2dbaea325ed4288, 0xa4623912aab4c938},
+		{129, 0, 0xeac1039c6fbf7b8c, 0xf316461ff6b978d7},
+		{240, 0, 0x4536dcb050ba6144, 0x60a06c4f31468cc3},
+		{241, 0, 0xb4125661642a66f9, 0x246c5fe4ed1436a3},
+		{1024, 0, 0x4b8b4d51d1b23ed3, 0x0b662daec235d4ca},
+		{1025, 0, 0x1c489c9ad8646bff, 0x13d178f3e2b48e9e},
+		{3000, 0, 0xbd2108c31903ae1c, 0xa279279c194c2d82},
+		{0, 42, 0x16c20acd33f7af2f, 0x3c1d09e9fe249164},
+		{1, 42, 0x8f345f94f33c2b82, 0xc72384329881f542},
+		{3, 42, 0x4037a1c573458801, 0x4ee376736e0e4eb5},
+		{4, 42, 0x83a1a4ebb087fe3c, 0x03c368ef16c1ff20},
+		{8, 42, 0x47cc7bbd25d29607, 0xa2b636b61e4baea5},
+		{9, 42, 0xc1cc279010c7c76e, 0x7abf3e2ae83c5260},
+		{16, 42, 0xc270bd53305929d7, 0xd30fcd9b3e5a4c64},
+		{17, 42, 0x00f651d0a9891531, 0x9459afddaf75b2e3},
+		{100, 42, 0x207bf5bdf9d913a0, 0x0bc9335b1f2dacc7},
+		{128, 42, 0x1577e1786d9ff3ed, 0xafe6c08fdb41604f},
+		{129, 42, 0xb27fd00ba49b575b, 0x1cb6431af1105295},
+		{240, 42, 0xe3b90588005d3b3f, 0x7bc7a1ff6c280fac},
+		{241, 42, 0xf472f5e28ce421cf, 0xbca7d1cb6614d782},
+		{1024, 42, 0xf1ffa058474b3ba9, 0x0674099fc2d618a0},
+		{1025, 42, 0x32eb93e319c50558, 0x68cc779e024cdd41},
+		{3000, 42, 0x6a3926716a8ba03f, 0x625812f0487faf21},
+	}
+	for _, c := range cases {
+		if hi, lo := c.seed.Sum128(data[:c.n]); hi != c.hi || lo != c.lo {
+			t.Fatalf("XXH128(%d)(%d bytes) = %#x %#x, want %#x %#x", c.seed, c.n, hi, lo, c.hi, c.lo)
+		}
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	data := make([]byte, 3000)
+	rand.Read(data)
+	for _, h := range []Hasher{XXH64(0), XXH64(42), XXH128(0), XXH128(42), FNV1a(0), FNV1a(7), CRC32C(0), CRC32C(1)} {
+		for _, n := range []int{0, 3, 31, 32, 33, 64, 100, 240, 241, 256, 257, 320, 1000, 1024, 1025, 3000} {
+			for _, chunk := range []int{7, 300} {
+				d := h.New()
+				for i := 0; i < n; i += chunk {
+					end := i + chunk
+					if end > n {
+						end = n
+					}
+					d.Write(data[i:end])
+				}
+				if d.Sum64() != h.Sum64(data[:n]) || h.Sum64String(string(data[:n])) != h.Sum64(data[:n]) {
+					t.Fatalf("%T(%v): streaming sum of %d bytes in chunks of %d differs", h, h, n, chunk)
+				}
+			}
+		}
+	}
+	if XXH64(1).Sum64String("x") == XXH64(2).Sum64String("x") {
+		t.Fatal("seed ignored")
+	}
+	d := XXH128(42).New128()
+	d.Write(data)
+	hi, lo := d.Sum128()
+	if wantHi, wantLo := XXH128(42).Sum128(data); hi != wantHi || lo != wantLo || len(d.Sum(nil)) != 16 {
+		t.Fatal("XXH128: streaming Sum128 differs")
+	}
+}
+
+func TestStdlib(t *testing.T) {
+	s := strings.Repeat("hello, world", 10)
+	f := fnv.New64a()
+	f.Write([]byte(s))
+	if FNV1a(0).Sum64String(s) != f.Sum64() {
+		t.Fatal("FNV1a differs from hash/fnv")
+	}
+	if CRC32C(0).Sum64String(s) != uint64(crc32.Checksum([]byte(s), crc32.MakeTable(crc32.Castagnoli))) {
+		t.Fatal("CRC32C differs from hash/crc32")
+	}
+}
+
+func TestNoAlloc(t *testing.T) {
+	s := strings.Repeat("x", 100)
+	if n := testing.AllocsPerRun(100, func() { XXH64(0).Sum64String(s) }); n != 0 {
+		t.Fatal("Sum64String allocates:", n)
+	}
+	s = strings.Repeat("x", 1000)
+	if n := testing.AllocsPerRun(100, func() { XXH128(1).Sum128String(s) }); n != 0 {
+		t.Fatal("Sum128String allocates:", n)
+	}
+}
+
+func BenchmarkXXH64(b *testing.B) {
+	data := make([]byte, 1024)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		XXH64(0).Sum64(data)
+	}
+}
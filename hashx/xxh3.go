@@ -0,0 +1,349 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hashx
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// XXH128 is the 128-bit variant of XXH3 with the given seed. As a Hasher,
+// it returns the low 64 bits of the 128-bit hash.
+type XXH128 uint64
+
+// Sum128 returns the hash of b.
+func (seed XXH128) Sum128(b []byte) (hi, lo uint64) {
+	return xxh3Sum128(b, uint64(seed))
+}
+
+// Sum128String returns the hash of s. It doesn't copy s.
+func (seed XXH128) Sum128String(s string) (hi, lo uint64) {
+	return xxh3Sum128(bytesOf(s), uint64(seed))
+}
+
+// Sum64 implements the Hasher interface.
+func (seed XXH128) Sum64(b []byte) uint64 {
+	_, lo := xxh3Sum128(b, uint64(seed))
+	return lo
+}
+
+// Sum64String implements the Hasher interface.
+func (seed XXH128) Sum64String(s string) uint64 {
+	_, lo := xxh3Sum128(bytesOf(s), uint64(seed))
+	return lo
+}
+
+// New implements the Hasher interface.
+func (seed XXH128) New() hash.Hash64 {
+	return seed.New128()
+}
+
+// Hash128 is a streaming 128-bit hash. Its Sum appends the 16 bytes of the
+// hash, high half first.
+type Hash128 interface {
+	hash.Hash64
+
+	// Sum128 returns the hash of all the data written.
+	Sum128() (hi, lo uint64)
+}
+
+// New128 returns a streaming Hash128 with the same seed, whose Sum128 equals
+// Sum128 of all the data written to it.
+func (seed XXH128) New128() Hash128 {
+	d := &xxh3Digest{seed: uint64(seed), secret: xxh3CustomSecret(uint64(seed))}
+	d.Reset()
+	return d
+}
+
+// -----------------------------------------------------------------------------
+
+const (
+	xxh3StripeLen  = 64
+	xxh3SecretSize = 192
+	xxh3BlockLen   = xxh3StripeLen * xxh3BlockStripes
+	xxh3BufSize    = 256
+
+	// xxh3BlockStripes is the number of stripes between two scrambles: the
+	// secret is consumed at 8 bytes a stripe.
+	xxh3BlockStripes = (xxh3SecretSize - xxh3StripeLen) / 8
+
+	prime32_1 uint64 = 0x9E3779B1
+	prime32_2 uint64 = 0x85EBCA77
+	prime32_3 uint64 = 0xC2B2AE3D
+)
+
+var xxh3Secret = [xxh3SecretSize]byte{
+	0xb8, 0xfe, 0x6c, 0x39, 0x23, 0xa4, 0x4b, 0xbe, 0x7c, 0x01, 0x81, 0x2c, 0xf7, 0x21, 0xad, 0x1c,
+	0xde, 0xd4, 0x6d, 0xe9, 0x83, 0x90, 0x97, 0xdb, 0x72, 0x40, 0xa4, 0xa4, 0xb7, 0xb3, 0x67, 0x1f,
+	0xcb, 0x79, 0xe6, 0x4e, 0xcc, 0xc0, 0xe5, 0x78, 0x82, 0x5a, 0xd0, 0x7d, 0xcc, 0xff, 0x72, 0x21,
+	0xb8, 0x08, 0x46, 0x74, 0xf7, 0x43, 0x24, 0x8e, 0xe0, 0x35, 0x90, 0xe6, 0x81, 0x3a, 0x26, 0x4c,
+	0x3c, 0x28, 0x52, 0xbb, 0x91, 0xc3, 0x00, 0xcb, 0x88, 0xd0, 0x65, 0x8b, 0x1b, 0x53, 0x2e, 0xa3,
+	0x71, 0x64, 0x48, 0x97, 0xa2, 0x0d, 0xf9, 0x4e, 0x38, 0x19, 0xef, 0x46, 0xa9, 0xde, 0xac, 0xd8,
+	0xa8, 0xfa, 0x76, 0x3f, 0xe3, 0x9c, 0x34, 0x3f, 0xf9, 0xdc, 0xbb, 0xc7, 0xc7, 0x0b, 0x4f, 0x1d,
+	0x8a, 0x51, 0xe0, 0x4b, 0xcd, 0xb4, 0x59, 0x31, 0xc8, 0x9f, 0x7e, 0xc9, 0xd9, 0x78, 0x73, 0x64,
+	0xea, 0xc5, 0xac, 0x83, 0x34, 0xd3, 0xeb, 0xc3, 0xc5, 0x81, 0xa0, 0xff, 0xfa, 0x13, 0x63, 0xeb,
+	0x17, 0x0d, 0xdd, 0x51, 0xb7, 0xf0, 0xda, 0x49, 0xd3, 0x16, 0x55, 0x26, 0x29, 0xd4, 0x68, 0x9e,
+	0x2b, 0x16, 0xbe, 0x58, 0x7d, 0x47, 0xa1, 0xfc, 0x8f, 0xf8, 0xb8, 0xd1, 0x7a, 0xd0, 0x31, 0xce,
+	0x45, 0xcb, 0x3a, 0x8f, 0x95, 0x16, 0x04, 0x28, 0xaf, 0xd7, 0xfb, 0xca, 0xbb, 0x4b, 0x40, 0x7e,
+}
+
+var xxh3InitAcc = [8]uint64{prime32_3, prime1, prime2, prime3, prime4, prime32_2, prime5, prime32_1}
+
+// xxh3CustomSecret derives the secret of the long inputs from seed.
+func xxh3CustomSecret(seed uint64) (s [xxh3SecretSize]byte) {
+	for i := 0; i < xxh3SecretSize; i += 16 {
+		binary.LittleEndian.PutUint64(s[i:], u64(xxh3Secret[i:])+seed)
+		binary.LittleEndian.PutUint64(s[i+8:], u64(xxh3Secret[i+8:])-seed)
+	}
+	return
+}
+
+func xxh3Avalanche(h uint64) uint64 {
+	h ^= h >> 37
+	h *= 0x165667919E3779F9
+	return h ^ h>>32
+}
+
+func mul128Fold64(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func mix16(b, secret []byte, seed uint64) uint64 {
+	return mul128Fold64(u64(b)^(u64(secret)+seed), u64(b[8:])^(u64(secret[8:])-seed))
+}
+
+func mix32(lo, hi uint64, b1, b2, secret []byte, seed uint64) (uint64, uint64) {
+	lo += mix16(b1, secret, seed)
+	lo ^= u64(b2) + u64(b2[8:])
+	hi += mix16(b2, secret[16:], seed)
+	hi ^= u64(b1) + u64(b1[8:])
+	return lo, hi
+}
+
+func xxh3Sum128(b []byte, seed uint64) (hi, lo uint64) {
+	n := len(b)
+	s := xxh3Secret[:]
+	switch {
+	case n == 0:
+		return avalanche(seed ^ u64(s[80:]) ^ u64(s[88:])), avalanche(seed ^ u64(s[64:]) ^ u64(s[72:]))
+	case n <= 3:
+		in := uint32(b[0])<<16 | uint32(b[n>>1])<<24 | uint32(b[n-1]) | uint32(n)<<8
+		inHi := bits.RotateLeft32(bits.ReverseBytes32(in), 13)
+		flipLo := uint64(u32(s)^u32(s[4:])) + seed
+		flipHi := uint64(u32(s[8:])^u32(s[12:])) - seed
+		return avalanche(uint64(inHi) ^ flipHi), avalanche(uint64(in) ^ flipLo)
+	case n <= 8:
+		seed ^= uint64(bits.ReverseBytes32(uint32(seed))) << 32
+		in := uint64(u32(b)) + uint64(u32(b[n-4:]))<<32
+		hi, lo = bits.Mul64(in^((u64(s[16:])^u64(s[24:]))+seed), prime1+uint64(n)<<2)
+		hi += lo << 1
+		lo ^= hi >> 3
+		lo ^= lo >> 35
+		lo *= 0x9FB21C651E98DF25
+		lo ^= lo >> 28
+		return xxh3Avalanche(hi), lo
+	case n <= 16:
+		flipLo := (u64(s[32:]) ^ u64(s[40:])) - seed
+		flipHi := (u64(s[48:]) ^ u64(s[56:])) + seed
+		inLo, inHi := u64(b), u64(b[n-8:])
+		mulHi, mulLo := bits.Mul64(inLo^inHi^flipLo, prime1)
+		mulLo += uint64(n-1) << 54
+		inHi ^= flipHi
+		mulHi += inHi + uint64(uint32(inHi))*(prime32_2-1)
+		mulLo ^= bits.ReverseBytes64(mulHi)
+		hi, lo = bits.Mul64(mulLo, prime2)
+		hi += mulHi * prime2
+		return xxh3Avalanche(hi), xxh3Avalanche(lo)
+	case n <= 128:
+		lo = uint64(n) * prime1
+		if n > 32 {
+			if n > 64 {
+				if n > 96 {
+					lo, hi = mix32(lo, hi, b[48:], b[n-64:], s[96:], seed)
+				}
+				lo, hi = mix32(lo, hi, b[32:], b[n-48:], s[64:], seed)
+			}
+			lo, hi = mix32(lo, hi, b[16:], b[n-32:], s[32:], seed)
+		}
+		lo, hi = mix32(lo, hi, b, b[n-16:], s, seed)
+		return xxh3Final128(lo, hi, n, seed)
+	case n <= 240:
+		lo = uint64(n) * prime1
+		for i := 0; i < 4; i++ {
+			lo, hi = mix32(lo, hi, b[32*i:], b[32*i+16:], s[32*i:], seed)
+		}
+		lo, hi = xxh3Avalanche(lo), xxh3Avalanche(hi)
+		for i := 4; i < n/32; i++ {
+			lo, hi = mix32(lo, hi, b[32*i:], b[32*i+16:], s[3+32*(i-4):], seed)
+		}
+		lo, hi = mix32(lo, hi, b[n-16:], b[n-32:], s[103:], -seed)
+		return xxh3Final128(lo, hi, n, seed)
+	}
+
+	secret := &xxh3Secret
+	if seed != 0 {
+		custom := xxh3CustomSecret(seed)
+		secret = &custom
+	}
+	acc := xxh3InitAcc
+	nblocks := (n - 1) / xxh3BlockLen
+	for i := 0; i < nblocks; i++ {
+		accumulate(&acc, b[i*xxh3BlockLen:], secret[:], xxh3BlockStripes)
+		scramble(&acc, secret[xxh3SecretSize-xxh3StripeLen:])
+	}
+	accumulate(&acc, b[nblocks*xxh3BlockLen:], secret[:], (n-1-nblocks*xxh3BlockLen)/xxh3StripeLen)
+	accumulate512(&acc, b[n-xxh3StripeLen:], secret[xxh3SecretSize-xxh3StripeLen-7:])
+	return xxh3Merge128(&acc, secret, uint64(n))
+}
+
+func xxh3Final128(lo, hi uint64, n int, seed uint64) (uint64, uint64) {
+	return -xxh3Avalanche(lo*prime1 + hi*prime4 + (uint64(n)-seed)*prime2), xxh3Avalanche(lo + hi)
+}
+
+func xxh3Merge128(acc *[8]uint64, secret *[xxh3SecretSize]byte, n uint64) (hi, lo uint64) {
+	lo = mergeAccs128(acc, secret[11:], n*prime1)
+	hi = mergeAccs128(acc, secret[xxh3SecretSize-64-11:], ^(n * prime2))
+	return
+}
+
+func mergeAccs128(acc *[8]uint64, secret []byte, h uint64) uint64 {
+	for i := 0; i < 4; i++ {
+		h += mul128Fold64(acc[2*i]^u64(secret[16*i:]), acc[2*i+1]^u64(secret[16*i+8:]))
+	}
+	return xxh3Avalanche(h)
+}
+
+func accumulate512(acc *[8]uint64, b, secret []byte) {
+	for i := 0; i < 8; i++ {
+		v := u64(b[8*i:])
+		k := v ^ u64(secret[8*i:])
+		acc[i^1] += v
+		acc[i] += uint64(uint32(k)) * (k >> 32)
+	}
+}
+
+// accumulate mixes n stripes of b into acc.
+func accumulate(acc *[8]uint64, b, secret []byte, n int) {
+	for i := 0; i < n; i++ {
+		accumulate512(acc, b[i*xxh3StripeLen:], secret[i*8:])
+	}
+}
+
+func scramble(acc *[8]uint64, secret []byte) {
+	for i := range acc {
+		a := acc[i]
+		a ^= a >> 47
+		a ^= u64(secret[8*i:])
+		acc[i] = a * prime32_1
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type xxh3Digest struct {
+	seed    uint64
+	secret  [xxh3SecretSize]byte
+	acc     [8]uint64
+	stripes int // stripes of the current block accumulated
+	total   uint64
+	buf     [xxh3BufSize]byte
+	n       int // bytes in buf
+}
+
+func (d *xxh3Digest) Reset() {
+	d.acc, d.stripes, d.total, d.n = xxh3InitAcc, 0, 0, 0
+}
+
+func (d *xxh3Digest) Size() int      { return 16 }
+func (d *xxh3Digest) BlockSize() int { return xxh3StripeLen }
+
+// consume accumulates n stripes of b, scrambling acc at the end of a block.
+func (d *xxh3Digest) consume(acc *[8]uint64, stripes int, b []byte, n int) int {
+	if toEnd := xxh3BlockStripes - stripes; toEnd <= n {
+		accumulate(acc, b, d.secret[stripes*8:], toEnd)
+		scramble(acc, d.secret[xxh3SecretSize-xxh3StripeLen:])
+		accumulate(acc, b[toEnd*xxh3StripeLen:], d.secret[:], n-toEnd)
+		return n - toEnd
+	}
+	accumulate(acc, b, d.secret[stripes*8:], n)
+	return stripes + n
+}
+
+// Write keeps the last bytes in buf, so that Sum128 can tell the short
+// inputs and hash the last stripe. When buf is consumed, the bytes before
+// the ones kept stay at its end.
+func (d *xxh3Digest) Write(b []byte) (n int, err error) {
+	n = len(b)
+	d.total += uint64(n)
+	if d.n+len(b) <= xxh3BufSize {
+		d.n += copy(d.buf[d.n:], b)
+		return
+	}
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], b)
+		d.stripes = d.consume(&d.acc, d.stripes, d.buf[:], xxh3BufSize/xxh3StripeLen)
+		b = b[c:]
+		d.n = 0
+	}
+	if len(b) > xxh3BufSize {
+		p := 0
+		for len(b)-p > xxh3BufSize {
+			d.stripes = d.consume(&d.acc, d.stripes, b[p:], xxh3BufSize/xxh3StripeLen)
+			p += xxh3BufSize
+		}
+		copy(d.buf[xxh3BufSize-xxh3StripeLen:], b[p-xxh3StripeLen:p])
+		b = b[p:]
+	}
+	d.n = copy(d.buf[:], b)
+	return
+}
+
+func (d *xxh3Digest) WriteString(s string) (int, error) {
+	return d.Write(bytesOf(s))
+}
+
+func (d *xxh3Digest) Sum128() (hi, lo uint64) {
+	if d.total <= 240 {
+		return xxh3Sum128(d.buf[:d.n], d.seed)
+	}
+	acc := d.acc
+	if d.n >= xxh3StripeLen {
+		d.consume(&acc, d.stripes, d.buf[:], (d.n-1)/xxh3StripeLen)
+		accumulate512(&acc, d.buf[d.n-xxh3StripeLen:], d.secret[xxh3SecretSize-xxh3StripeLen-7:])
+	} else {
+		var last [xxh3StripeLen]byte
+		c := copy(last[:], d.buf[xxh3BufSize-(xxh3StripeLen-d.n):])
+		copy(last[c:], d.buf[:d.n])
+		accumulate512(&acc, last[:], d.secret[xxh3SecretSize-xxh3StripeLen-7:])
+	}
+	return xxh3Merge128(&acc, &d.secret, d.total)
+}
+
+func (d *xxh3Digest) Sum64() uint64 {
+	_, lo := d.Sum128()
+	return lo
+}
+
+func (d *xxh3Digest) Sum(b []byte) []byte {
+	var s [16]byte
+	hi, lo := d.Sum128()
+	binary.BigEndian.PutUint64(s[:], hi)
+	binary.BigEndian.PutUint64(s[8:], lo)
+	return append(b, s[:]...)
+}
@@ -0,0 +1,182 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hashx
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	prime1 uint64 = 11400714785074694791
+	prime2 uint64 = 14029467366897019727
+	prime3 uint64 = 1609587929392839161
+	prime4 uint64 = 9650029242287828579
+	prime5 uint64 = 2870177450012600261
+)
+
+// XXH64 is XXH64 with the given seed.
+type XXH64 uint64
+
+// Sum64 implements the Hasher interface.
+func (seed XXH64) Sum64(b []byte) uint64 {
+	n := len(b)
+	var h uint64
+	if n >= 32 {
+		v1, v2, v3, v4 := uint64(seed)+prime1+prime2, uint64(seed)+prime2, uint64(seed), uint64(seed)-prime1
+		for len(b) >= 32 {
+			v1 = round(v1, u64(b[0:]))
+			v2 = round(v2, u64(b[8:]))
+			v3 = round(v3, u64(b[16:]))
+			v4 = round(v4, u64(b[24:]))
+			b = b[32:]
+		}
+		h = mergeAccs(v1, v2, v3, v4)
+	} else {
+		h = uint64(seed) + prime5
+	}
+	h += uint64(n)
+	return finalize(h, b)
+}
+
+// Sum64String implements the Hasher interface.
+func (seed XXH64) Sum64String(s string) uint64 {
+	return seed.Sum64(bytesOf(s))
+}
+
+// New implements the Hasher interface.
+func (seed XXH64) New() hash.Hash64 {
+	d := &xxh64Digest{seed: uint64(seed)}
+	d.Reset()
+	return d
+}
+
+func u64(b []byte) uint64 { return binary.LittleEndian.Uint64(b) }
+func u32(b []byte) uint32 { return binary.LittleEndian.Uint32(b) }
+
+func round(acc, input uint64) uint64 {
+	acc += input * prime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * prime1
+}
+
+func mergeRound(acc, val uint64) uint64 {
+	acc ^= round(0, val)
+	return acc*prime1 + prime4
+}
+
+func mergeAccs(v1, v2, v3, v4 uint64) uint64 {
+	h := bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) + bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+	h = mergeRound(h, v1)
+	h = mergeRound(h, v2)
+	h = mergeRound(h, v3)
+	return mergeRound(h, v4)
+}
+
+// finalize mixes the last (less than 32) bytes b into h.
+func finalize(h uint64, b []byte) uint64 {
+	for ; len(b) >= 8; b = b[8:] {
+		h ^= round(0, u64(b))
+		h = bits.RotateLeft64(h, 27)*prime1 + prime4
+	}
+	if len(b) >= 4 {
+		h ^= uint64(u32(b)) * prime1
+		h = bits.RotateLeft64(h, 23)*prime2 + prime3
+		b = b[4:]
+	}
+	for _, c := range b {
+		h ^= uint64(c) * prime5
+		h = bits.RotateLeft64(h, 11) * prime1
+	}
+	return avalanche(h)
+}
+
+func avalanche(h uint64) uint64 {
+	h ^= h >> 33
+	h *= prime2
+	h ^= h >> 29
+	h *= prime3
+	h ^= h >> 32
+	return h
+}
+
+// -----------------------------------------------------------------------------
+
+type xxh64Digest struct {
+	seed           uint64
+	v1, v2, v3, v4 uint64
+	total          uint64
+	mem            [32]byte
+	n              int // bytes in mem
+}
+
+func (d *xxh64Digest) Reset() {
+	d.v1, d.v2, d.v3, d.v4 = d.seed+prime1+prime2, d.seed+prime2, d.seed, d.seed-prime1
+	d.total, d.n = 0, 0
+}
+
+func (d *xxh64Digest) Size() int      { return 8 }
+func (d *xxh64Digest) BlockSize() int { return 32 }
+
+func (d *xxh64Digest) Write(b []byte) (n int, err error) {
+	n = len(b)
+	d.total += uint64(n)
+	if d.n+len(b) < 32 {
+		d.n += copy(d.mem[d.n:], b)
+		return
+	}
+	if d.n > 0 {
+		c := copy(d.mem[d.n:], b)
+		d.v1 = round(d.v1, u64(d.mem[0:]))
+		d.v2 = round(d.v2, u64(d.mem[8:]))
+		d.v3 = round(d.v3, u64(d.mem[16:]))
+		d.v4 = round(d.v4, u64(d.mem[24:]))
+		b = b[c:]
+		d.n = 0
+	}
+	for len(b) >= 32 {
+		d.v1 = round(d.v1, u64(b[0:]))
+		d.v2 = round(d.v2, u64(b[8:]))
+		d.v3 = round(d.v3, u64(b[16:]))
+		d.v4 = round(d.v4, u64(b[24:]))
+		b = b[32:]
+	}
+	d.n = copy(d.mem[:], b)
+	return
+}
+
+func (d *xxh64Digest) WriteString(s string) (int, error) {
+	return d.Write(bytesOf(s))
+}
+
+func (d *xxh64Digest) Sum64() uint64 {
+	var h uint64
+	if d.total >= 32 {
+		h = mergeAccs(d.v1, d.v2, d.v3, d.v4)
+	} else {
+		h = d.seed + prime5
+	}
+	h += d.total
+	return finalize(h, d.mem[:d.n])
+}
+
+func (d *xxh64Digest) Sum(b []byte) []byte {
+	var s [8]byte
+	binary.BigEndian.PutUint64(s[:], d.Sum64())
+	return append(b, s[:]...)
+}
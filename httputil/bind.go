@@ -0,0 +1,376 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package httputil
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------
+
+// PathValue returns the value of the path parameter name of r. It defaults
+// to r.PathValue with Go 1.22 and later; routers that keep path parameters
+// elsewhere replace it.
+var PathValue = func(r *http.Request, name string) string {
+	return pathValue(r, name)
+}
+
+// FieldError describes a request field that failed to bind or validate.
+type FieldError struct {
+	Field  string `json:"field"`
+	In     string `json:"in"` // path, query, header or body
+	Reason string `json:"reason"`
+}
+
+// BindError is the error returned by Bind. It replies as a 400 Bad Request
+// (see ReplyError), listing the failed fields.
+type BindError struct {
+	Fields []FieldError
+}
+
+func (e *BindError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid request: ")
+	for i, f := range e.Fields {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		if f.Field != "" {
+			b.WriteString(f.In + " " + f.Field + ": ")
+		}
+		b.WriteString(f.Reason)
+	}
+	return b.String()
+}
+
+func (e *BindError) has(in, name string) bool {
+	for _, f := range e.Fields {
+		if f.In == in && f.Field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HttpCode returns http.StatusBadRequest.
+func (e *BindError) HttpCode() int {
+	return http.StatusBadRequest
+}
+
+// Bind populates req, a pointer to struct, from the request r, and then
+// validates it. Fields are bound by their tags:
+//
+//	path:"name"     the path parameter name (see PathValue)
+//	query:"name"    the query parameter name
+//	header:"Name"   the header Name
+//
+// and the JSON body, if any, is decoded into req first, so that the other
+// sources override it. Slice fields take all the values of a query parameter
+// or a header. Nested structs without tags are walked recursively.
+//
+// The validate tag declares the constraints of a field, separated by commas:
+//
+//	required        the field isn't the zero value
+//	min=N, max=N    bounds of numbers, or of the length of strings and slices
+//	enum=a|b|c      the field is one of the values
+//
+// All failures are reported together in a *BindError.
+func Bind(r *http.Request, req interface{}) error {
+	v := reflect.ValueOf(req)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("httputil.Bind: req must be a pointer to struct")
+	}
+	e := new(BindError)
+	if err := bindBody(r, req); err != nil {
+		e.Fields = append(e.Fields, FieldError{In: "body", Reason: err.Error()})
+		return e
+	}
+	query := r.URL.Query()
+	bindFields(v.Elem(), e, func(sf reflect.StructField) (in, name string, vals []string, ok bool) {
+		if name, ok = sf.Tag.Lookup("path"); ok {
+			if val := PathValue(r, name); val != "" {
+				return "path", name, []string{val}, true
+			}
+		} else if name, ok = sf.Tag.Lookup("query"); ok {
+			if vals = query[name]; len(vals) > 0 {
+				return "query", name, vals, true
+			}
+		} else if name, ok = sf.Tag.Lookup("header"); ok {
+			if vals = r.Header[http.CanonicalHeaderKey(name)]; len(vals) > 0 {
+				return "header", name, vals, true
+			}
+		}
+		return
+	})
+	validateFields(v.Elem(), "", e)
+	if len(e.Fields) > 0 {
+		return e
+	}
+	return nil
+}
+
+func bindBody(r *http.Request, req interface{}) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct != "application/json" && !strings.HasSuffix(ct, "+json") {
+		return nil
+	}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err == nil || err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+type sourceFunc = func(sf reflect.StructField) (in, name string, vals []string, ok bool)
+
+func bindFields(v reflect.Value, e *BindError, source sourceFunc) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		f := v.Field(i)
+		in, name, vals, tagged := source(sf)
+		if tagged {
+			if vals != nil {
+				if err := setField(f, vals); err != nil {
+					e.Fields = append(e.Fields, FieldError{Field: name, In: in, Reason: err.Error()})
+				}
+			}
+			continue
+		}
+		if f.Kind() == reflect.Struct && !isTextUnmarshaler(f) {
+			bindFields(f, e, source)
+		}
+	}
+}
+
+var (
+	typDuration        = reflect.TypeOf(time.Duration(0))
+	typTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func isTextUnmarshaler(f reflect.Value) bool {
+	return reflect.PtrTo(f.Type()).Implements(typTextUnmarshaler)
+}
+
+// setField parses vals into f: all of them for a slice, the first one
+// otherwise.
+func setField(f reflect.Value, vals []string) error {
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 && !isTextUnmarshaler(f) {
+		sl := reflect.MakeSlice(f.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(sl.Index(i), val); err != nil {
+				return err
+			}
+		}
+		f.Set(sl)
+		return nil
+	}
+	return setValue(f, vals[0])
+}
+
+func setValue(f reflect.Value, s string) error {
+	if f.Kind() == reflect.Ptr {
+		p := reflect.New(f.Type().Elem())
+		if err := setValue(p.Elem(), s); err != nil {
+			return err
+		}
+		f.Set(p)
+		return nil
+	}
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if f.Type() == typDuration {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %v", f.Type())
+	}
+	return nil
+}
+
+// ----------------------------------------------------------
+
+// validateFields checks the validate tags of the fields of v.
+func validateFields(v reflect.Value, prefix string, e *BindError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		f := v.Field(i)
+		in, name := fieldName(sf)
+		if rules, ok := sf.Tag.Lookup("validate"); ok && rules != "" {
+			name = prefix + name
+			if e.has(in, name) { // failed to bind already
+				continue
+			}
+			for _, rule := range strings.Split(rules, ",") {
+				if reason := checkRule(f, strings.TrimSpace(rule)); reason != "" {
+					e.Fields = append(e.Fields, FieldError{Field: name, In: in, Reason: reason})
+					break
+				}
+			}
+			continue
+		}
+		if f.Kind() == reflect.Struct && !isTextUnmarshaler(f) && in == "body" {
+			validateFields(f, prefix+name+".", e)
+		}
+	}
+}
+
+// fieldName returns where a field is bound from, and its name there.
+func fieldName(sf reflect.StructField) (in, name string) {
+	for _, in := range [...]string{"path", "query", "header"} {
+		if name, ok := sf.Tag.Lookup(in); ok {
+			return in, name
+		}
+	}
+	if tag := sf.Tag.Get("json"); tag != "" && tag != "-" {
+		if name = strings.Split(tag, ",")[0]; name != "" {
+			return "body", name
+		}
+	}
+	return "body", sf.Name
+}
+
+// checkRule returns why f doesn't satisfy rule, or "" if it does.
+func checkRule(f reflect.Value, rule string) string {
+	key, arg := rule, ""
+	if pos := strings.IndexByte(rule, '='); pos >= 0 {
+		key, arg = rule[:pos], rule[pos+1:]
+	}
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			if key == "required" {
+				return "is required"
+			}
+			return "" // other constraints apply to present values only
+		}
+		f = f.Elem()
+	}
+	switch key {
+	case "required":
+		if isZero(f) {
+			return "is required"
+		}
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic("httputil: bad validate rule " + strconv.Quote(rule))
+		}
+		n, length := measure(f)
+		if key == "min" && n < bound {
+			if length {
+				return "length must be at least " + arg
+			}
+			return "must be at least " + arg
+		}
+		if key == "max" && n > bound {
+			if length {
+				return "length must be at most " + arg
+			}
+			return "must be at most " + arg
+		}
+	case "enum":
+		s := fmt.Sprint(f.Interface())
+		for _, allowed := range strings.Split(arg, "|") {
+			if s == allowed {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Replace(arg, "|", ", ", -1)
+	default:
+		panic("httputil: bad validate rule " + strconv.Quote(rule))
+	}
+	return ""
+}
+
+func isZero(f reflect.Value) bool {
+	switch f.Kind() {
+	case reflect.Slice, reflect.Map:
+		return f.Len() == 0
+	}
+	return reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface())
+}
+
+// measure returns the value of a number, or the length of anything else.
+func measure(f reflect.Value) (n float64, length bool) {
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(f.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(f.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return f.Float(), false
+	case reflect.String:
+		return float64(len([]rune(f.String()))), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(f.Len()), true
+	}
+	panic("httputil: min/max on " + f.Type().String())
+}
+
+// ----------------------------------------------------------
@@ -0,0 +1,103 @@
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type listReq struct {
+	Bucket  string        `path:"bucket" validate:"required"`
+	Prefix  string        `query:"prefix" validate:"max=8"`
+	Limit   int           `query:"limit" validate:"min=1,max=1000"`
+	Marks   []string      `query:"mark"`
+	Timeout time.Duration `header:"X-Timeout"`
+	Order   string        `json:"order" validate:"enum=asc|desc"`
+	Meta    struct {
+		Owner string `json:"owner" validate:"required"`
+	} `json:"meta"`
+}
+
+func newBindRequest(target, body string) *http.Request {
+	r := httptest.NewRequest("POST", target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return r
+}
+
+func TestBind(t *testing.T) {
+	old := PathValue
+	defer func() { PathValue = old }()
+	PathValue = func(r *http.Request, name string) string {
+		if name == "bucket" {
+			return "photos"
+		}
+		return ""
+	}
+
+	r := newBindRequest("/photos?prefix=2024/&limit=50&mark=a&mark=b", `{"order":"desc","limit":7,"meta":{"owner":"bob"}}`)
+	r.Header.Set("X-Timeout", "3s")
+	var req listReq
+	if err := Bind(r, &req); err != nil {
+		t.Fatal("Bind:", err)
+	}
+	if req.Bucket != "photos" || req.Prefix != "2024/" || req.Limit != 50 || len(req.Marks) != 2 ||
+		req.Timeout != 3*time.Second || req.Order != "desc" || req.Meta.Owner != "bob" {
+		t.Fatalf("Bind: %+v", req)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	r := newBindRequest("/?prefix=much-too-long&limit=x", `{"order":"random"}`)
+	var req listReq
+	err := Bind(r, &req)
+	e, ok := err.(*BindError)
+	if !ok {
+		t.Fatal("Bind:", err)
+	}
+	want := map[string]string{
+		"limit":      "query",
+		"bucket":     "path",
+		"prefix":     "query",
+		"order":      "body",
+		"meta.owner": "body",
+	}
+	if len(e.Fields) != len(want) {
+		t.Fatalf("Bind: %v", err)
+	}
+	for _, f := range e.Fields {
+		if want[f.Field] != f.In {
+			t.Fatalf("Bind: unexpected %+v", f)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	ReplyError(w, err)
+	var ret ErrorReply
+	if w.Code != 400 || json.Unmarshal(w.Body.Bytes(), &ret) != nil || ret.Err != err.Error() || len(ret.Fields) != 5 {
+		t.Fatalf("ReplyError: %d %s", w.Code, w.Body)
+	}
+
+	if err := Bind(newBindRequest("/", `{"order":`), &req); err == nil || err.(*BindError).Fields[0].In != "body" {
+		t.Fatal("Bind of a bad body:", err)
+	}
+}
+
+type ptrReq struct {
+	Size *int `query:"size" validate:"min=1"`
+}
+
+func TestBindPointer(t *testing.T) {
+	var req ptrReq
+	if err := Bind(httptest.NewRequest("GET", "/", nil), &req); err != nil || req.Size != nil {
+		t.Fatal("Bind of an absent optional field:", err)
+	}
+	if err := Bind(httptest.NewRequest("GET", "/?size=0", nil), &req); err == nil {
+		t.Fatal("Bind: min not checked")
+	}
+	if err := Bind(httptest.NewRequest("GET", "/?size=4", nil), &req); err != nil || *req.Size != 4 {
+		t.Fatal("Bind:", err)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ----------------------------------------------------------
+
+// CORSOptions configures the CORS middleware.
+type CORSOptions struct {
+	// AllowOrigins are the allowed origins. A pattern may contain one "*",
+	// which matches any string (e.g. "https://*.example.com"); the pattern
+	// "*" allows any origin.
+	AllowOrigins []string
+
+	// AllowOriginFunc, if not nil, is consulted for the origins that
+	// AllowOrigins doesn't allow.
+	AllowOriginFunc func(origin string) bool
+
+	// AllowMethods are the methods allowed by preflight requests. They
+	// default to GET, HEAD and POST.
+	AllowMethods []string
+
+	// AllowHeaders are the request headers allowed by preflight requests,
+	// besides the CORS-safelisted ones. "*" allows any header.
+	AllowHeaders []string
+
+	// ExposeHeaders are the response headers exposed to the client.
+	ExposeHeaders []string
+
+	// AllowCredentials allows requests with cookies or HTTP authentication.
+	// The allowed origin is then always echoed, never "*".
+	AllowCredentials bool
+
+	// MaxAge is how long the result of a preflight request may be cached.
+	// Zero means no Access-Control-Max-Age header.
+	MaxAge time.Duration
+}
+
+type cors struct {
+	h         http.Handler
+	origins   []string // lower-cased
+	anyOrigin bool
+	allowFunc func(origin string) bool
+	methods   map[string]bool
+	headers   map[string]bool // canonical
+	anyHeader bool
+	allowMeth string
+	expose    string
+	cred      bool
+	maxAge    string
+}
+
+// CORS returns a handler that serves cross-origin requests to h as
+// configured by opts. Preflight requests are answered directly and never
+// reach h.
+func CORS(h http.Handler, opts *CORSOptions) http.Handler {
+	p := &cors{
+		h:         h,
+		allowFunc: opts.AllowOriginFunc,
+		methods:   make(map[string]bool),
+		headers:   make(map[string]bool),
+		cred:      opts.AllowCredentials,
+		expose:    strings.Join(opts.ExposeHeaders, ", "),
+	}
+	for _, o := range opts.AllowOrigins {
+		if o == "*" {
+			p.anyOrigin = true
+		}
+		p.origins = append(p.origins, strings.ToLower(o))
+	}
+	methods := append([]string(nil), opts.AllowMethods...)
+	if len(methods) == 0 {
+		methods = []string{"GET", "HEAD", "POST"}
+	}
+	for i, m := range methods {
+		methods[i] = strings.ToUpper(m)
+		p.methods[methods[i]] = true
+	}
+	p.allowMeth = strings.Join(methods, ", ")
+	for _, hdr := range opts.AllowHeaders {
+		if hdr == "*" {
+			p.anyHeader = true
+		}
+		p.headers[http.CanonicalHeaderKey(hdr)] = true
+	}
+	if opts.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(opts.MaxAge / time.Second))
+	}
+	return p
+}
+
+func (p *cors) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != ""
+	h := w.Header()
+	if preflight {
+		h.Add("Vary", "Origin")
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	} else if !p.anyOrigin || p.cred {
+		// the reply depends on the origin unless it is always "*"
+		h.Add("Vary", "Origin")
+	}
+	if origin == "" || !p.allowOrigin(origin) {
+		if preflight {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		p.h.ServeHTTP(w, r)
+		return
+	}
+	if preflight {
+		if p.allowPreflight(r, h) {
+			p.setOrigin(h, origin)
+			if p.maxAge != "" {
+				h.Set("Access-Control-Max-Age", p.maxAge)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	p.setOrigin(h, origin)
+	if p.expose != "" {
+		h.Set("Access-Control-Expose-Headers", p.expose)
+	}
+	p.h.ServeHTTP(w, r)
+}
+
+func (p *cors) setOrigin(h http.Header, origin string) {
+	if p.anyOrigin && !p.cred {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.cred {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowPreflight checks the method and headers requested by a preflight
+// request, and sets the headers that allow them.
+func (p *cors) allowPreflight(r *http.Request, h http.Header) bool {
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if !p.methods[method] {
+		return false
+	}
+	reqHeaders := r.Header.Get("Access-Control-Request-Headers")
+	if !p.anyHeader {
+		for _, hdr := range strings.Split(reqHeaders, ",") {
+			if hdr = strings.TrimSpace(hdr); hdr != "" && !p.headers[http.CanonicalHeaderKey(hdr)] {
+				return false
+			}
+		}
+	}
+	h.Set("Access-Control-Allow-Methods", p.allowMeth)
+	if reqHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", reqHeaders)
+	}
+	return true
+}
+
+func (p *cors) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	for _, pat := range p.origins {
+		if matchOrigin(pat, lower) {
+			return true
+		}
+	}
+	return p.allowFunc != nil && p.allowFunc(origin)
+}
+
+func matchOrigin(pattern, origin string) bool {
+	pos := strings.IndexByte(pattern, '*')
+	if pos < 0 {
+		return pattern == origin
+	}
+	prefix, suffix := pattern[:pos], pattern[pos+1:]
+	return len(origin) >= len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+// ----------------------------------------------------------
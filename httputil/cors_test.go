@@ -0,0 +1,81 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveCORS(h http.Handler, method, origin string, hdrs ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/", nil)
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	for i := 0; i < len(hdrs); i += 2 {
+		r.Header.Set(hdrs[i], hdrs[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestCORS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	})
+	h := CORS(ok, &CORSOptions{
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowOriginFunc:  func(origin string) bool { return origin == "http://localhost:8080" },
+		AllowMethods:     []string{"GET", "PUT"},
+		AllowHeaders:     []string{"Content-Type", "x-reqid"},
+		ExposeHeaders:    []string{"X-Reqid"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	w := serveCORS(h, "GET", "https://api.example.com")
+	if w.Code != 200 || w.Header().Get("Access-Control-Allow-Origin") != "https://api.example.com" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "true" ||
+		w.Header().Get("Access-Control-Expose-Headers") != "X-Reqid" || w.Header().Get("Vary") != "Origin" {
+		t.Fatal("simple request:", w.Header())
+	}
+	if w = serveCORS(h, "GET", "http://localhost:8080"); w.Header().Get("Access-Control-Allow-Origin") == "" {
+		t.Fatal("AllowOriginFunc not consulted")
+	}
+	if w = serveCORS(h, "GET", "https://example.org"); w.Code != 200 || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("disallowed origin:", w.Header())
+	}
+
+	w = serveCORS(h, "OPTIONS", "https://a.example.com",
+		"Access-Control-Request-Method", "PUT", "Access-Control-Request-Headers", "content-type, X-Reqid")
+	if w.Code != 204 || w.Header().Get("Access-Control-Allow-Methods") != "GET, PUT" ||
+		w.Header().Get("Access-Control-Allow-Headers") != "content-type, X-Reqid" ||
+		w.Header().Get("Access-Control-Max-Age") != "600" || len(w.Header()["Vary"]) != 3 {
+		t.Fatal("preflight:", w.Code, w.Header())
+	}
+	w = serveCORS(h, "OPTIONS", "https://a.example.com",
+		"Access-Control-Request-Method", "DELETE")
+	if w.Code != 204 || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("preflight of a disallowed method:", w.Header())
+	}
+	w = serveCORS(h, "OPTIONS", "https://a.example.com",
+		"Access-Control-Request-Method", "GET", "Access-Control-Request-Headers", "Authorization")
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("preflight of a disallowed header:", w.Header())
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	h := CORS(http.NotFoundHandler(), &CORSOptions{AllowOrigins: []string{"*"}, AllowHeaders: []string{"*"}})
+	w := serveCORS(h, "GET", "https://x.test")
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Vary") != "" {
+		t.Fatal("any origin:", w.Header())
+	}
+	w = serveCORS(h, "OPTIONS", "https://x.test",
+		"Access-Control-Request-Method", "post", "Access-Control-Request-Headers", "X-Anything")
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || !strings.Contains(w.Header().Get("Access-Control-Allow-Methods"), "POST") {
+		t.Fatal("any origin preflight:", w.Header())
+	}
+}
@@ -62,3 +62,26 @@ func ReplyWithStream(w http.ResponseWriter, code int, bodyType string, body io.R
 }
 
 // ----------------------------------------------------------
+
+// ErrorReply is the body of an error reply.
+type ErrorReply struct {
+	Err    string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// ReplyError replies a http request with the error err, in the same format
+// as rpc.ErrorInfo: the code is err.HttpCode() if err has that method, 500
+// otherwise. A *BindError lists the failed fields too.
+func ReplyError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if e, ok := err.(interface{ HttpCode() int }); ok {
+		code = e.HttpCode()
+	}
+	ret := &ErrorReply{Err: err.Error()}
+	if e, ok := err.(*BindError); ok {
+		ret.Fields = e.Fields
+	}
+	Reply(w, code, ret)
+}
+
+// ----------------------------------------------------------
@@ -0,0 +1,141 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ----------------------------------------------------------
+
+// A MarshalFunc encodes a reply body.
+type MarshalFunc = func(v interface{}) ([]byte, error)
+
+var (
+	encodersMu     sync.RWMutex
+	encoders       = map[string]MarshalFunc{"application/json": json.Marshal}
+	encoderTypes   = []string{"application/json"} // in the order of registration
+	defaultEncoder = "application/json"
+)
+
+// RegisterEncoder registers the encoder of reply bodies of the media type
+// mediaType (e.g. "application/msgpack", "application/x-protobuf") for
+// ReplyFor. JSON is built in; other formats are registered by the
+// application, so this package doesn't depend on any third-party encoder.
+func RegisterEncoder(mediaType string, marshal MarshalFunc) {
+	mediaType = strings.ToLower(mediaType)
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	if _, ok := encoders[mediaType]; !ok {
+		encoderTypes = append(encoderTypes, mediaType)
+	}
+	encoders[mediaType] = marshal
+}
+
+// SetDefaultEncoder sets the media type that ReplyFor uses when the request
+// accepts any type, or none of the registered ones. It is JSON by default.
+func SetDefaultEncoder(mediaType string) {
+	mediaType = strings.ToLower(mediaType)
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	if _, ok := encoders[mediaType]; !ok {
+		panic("httputil.SetDefaultEncoder: unregistered media type " + mediaType)
+	}
+	defaultEncoder = mediaType
+}
+
+// ReplyFor replies the request r with v, encoded in the registered media
+// type that r accepts best according to its Accept header.
+func ReplyFor(w http.ResponseWriter, r *http.Request, code int, v interface{}) {
+	mediaType, marshal := negotiate(r.Header.Get("Accept"))
+	msg, err := marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Add("Vary", "Accept")
+	ReplyWith(w, code, mediaType, msg)
+}
+
+type acceptRange struct {
+	typ string
+	q   float64
+}
+
+// negotiate returns the encoder that the Accept header accept prefers.
+func negotiate(accept string) (string, MarshalFunc) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	ranges, rejected := parseAccept(accept)
+	for _, ar := range ranges {
+		if ar.typ == "*/*" {
+			break
+		}
+		if strings.HasSuffix(ar.typ, "/*") {
+			prefix := ar.typ[:len(ar.typ)-1]
+			if strings.HasPrefix(defaultEncoder, prefix) && !rejected[defaultEncoder] {
+				return defaultEncoder, encoders[defaultEncoder]
+			}
+			for _, typ := range encoderTypes {
+				if strings.HasPrefix(typ, prefix) && !rejected[typ] {
+					return typ, encoders[typ]
+				}
+			}
+		} else if marshal, ok := encoders[ar.typ]; ok {
+			return ar.typ, marshal
+		}
+	}
+	return defaultEncoder, encoders[defaultEncoder]
+}
+
+// parseAccept returns the media ranges of an Accept header with a non-zero
+// quality, the preferred first, and the media types of quality zero.
+func parseAccept(accept string) (ranges []acceptRange, rejected map[string]bool) {
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		ar := acceptRange{typ: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		if ar.typ == "" {
+			continue
+		}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					ar.q = q
+				}
+			}
+		}
+		if ar.q > 0 {
+			ranges = append(ranges, ar)
+		} else {
+			if rejected == nil {
+				rejected = make(map[string]bool)
+			}
+			rejected[ar.typ] = true
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return
+}
+
+// ----------------------------------------------------------
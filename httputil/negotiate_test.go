@@ -0,0 +1,37 @@
+package httputil
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReplyFor(t *testing.T) {
+	RegisterEncoder("application/x-test", func(v interface{}) ([]byte, error) {
+		return []byte("test"), nil
+	})
+	cases := []struct {
+		accept, typ, body string
+	}{
+		{"", "application/json", `{"a":1}`},
+		{"*/*", "application/json", `{"a":1}`},
+		{"application/x-test", "application/x-test", "test"},
+		{"text/html, application/x-test;q=0.5, application/json;q=0.9", "application/json", `{"a":1}`},
+		{"application/json;q=0, application/*", "application/x-test", "test"},
+		{"text/html", "application/json", `{"a":1}`},
+		{"Application/X-Test;q=0.8, */*;q=0.1", "application/x-test", "test"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		if c.accept != "" {
+			r.Header.Set("Accept", c.accept)
+		}
+		w := httptest.NewRecorder()
+		ReplyFor(w, r, 200, map[string]int{"a": 1})
+		if ct := w.Header().Get("Content-Type"); ct != c.typ || w.Body.String() != c.body {
+			t.Fatalf("Accept %q: %s %s", c.accept, ct, w.Body)
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Fatal("no Vary: Accept")
+		}
+	}
+}
@@ -0,0 +1,251 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package httputil
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ----------------------------------------------------------
+
+// ProxyRule routes the requests to the hosts matching Host through Proxy.
+//
+// Host has the syntax of a NO_PROXY entry: "example.com" matches the host
+// and its subdomains, ".example.com" (or "*.example.com") only the
+// subdomains, an IP address or a CIDR block (e.g. "10.0.0.0/8") matches the
+// IP hosts in it, and "*" matches any host. A ":port" suffix restricts the
+// entry to that port.
+type ProxyRule struct {
+	Host string
+
+	// Proxy is the proxy URL, with the scheme http, https or socks5 (http if
+	// it is omitted). "" or "direct" means no proxy.
+	Proxy string
+}
+
+// ProxyConfig configures a ProxySelector.
+type ProxyConfig struct {
+	// Rules are tried in order; the first one matching the host wins.
+	Rules []ProxyRule
+
+	// Default is the proxy of the hosts that no rule matches, with the
+	// syntax of ProxyRule.Proxy.
+	Default string
+
+	// NoProxy excludes hosts from any proxy, with the syntax of the
+	// NO_PROXY environment variable: a comma-separated list of ProxyRule.Host
+	// entries.
+	NoProxy string
+}
+
+type hostMatcher struct {
+	any     bool
+	ipNet   *net.IPNet
+	ip      net.IP
+	host    string // lower-cased, without leading dot
+	subOnly bool
+	port    string // "" means any port
+}
+
+type proxyRoute struct {
+	host  hostMatcher
+	proxy *url.URL // nil means direct
+}
+
+type proxyConfig struct {
+	routes  []proxyRoute
+	def     *url.URL
+	noProxy []hostMatcher
+}
+
+// ProxySelector selects the proxy of each request by its host. Its rules can
+// be updated at runtime. It is safe for concurrent use.
+type ProxySelector struct {
+	mu   sync.RWMutex
+	conf *proxyConfig
+}
+
+// NewProxySelector creates a proxy selector configured by conf.
+func NewProxySelector(conf *ProxyConfig) (*ProxySelector, error) {
+	p := new(ProxySelector)
+	if err := p.Update(conf); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Update replaces the configuration of p. On error the former configuration
+// is kept.
+func (p *ProxySelector) Update(conf *ProxyConfig) error {
+	c, err := parseProxyConfig(conf)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.conf = c
+	p.mu.Unlock()
+	return nil
+}
+
+// Proxy returns the proxy of req, nil for a direct connection. It is meant
+// for http.Transport.Proxy.
+func (p *ProxySelector) Proxy(req *http.Request) (*url.URL, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	port := req.URL.Port()
+	if port == "" {
+		switch req.URL.Scheme {
+		case "https", "wss":
+			port = "443"
+		default:
+			port = "80"
+		}
+	}
+	ip := net.ParseIP(host)
+
+	p.mu.RLock()
+	c := p.conf
+	p.mu.RUnlock()
+	for i := range c.noProxy {
+		if c.noProxy[i].match(host, ip, port) {
+			return nil, nil
+		}
+	}
+	for i := range c.routes {
+		if r := &c.routes[i]; r.host.match(host, ip, port) {
+			return r.proxy, nil
+		}
+	}
+	return c.def, nil
+}
+
+// Transport returns a clone of http.DefaultTransport that selects its
+// proxies by p.
+func (p *ProxySelector) Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = p.Proxy
+	return t
+}
+
+func parseProxyConfig(conf *ProxyConfig) (c *proxyConfig, err error) {
+	c = new(proxyConfig)
+	if conf == nil {
+		return
+	}
+	for _, r := range conf.Rules {
+		host, err := parseHostPattern(r.Host)
+		if err != nil {
+			return nil, err
+		}
+		proxy, err := parseProxyURL(r.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		c.routes = append(c.routes, proxyRoute{host: host, proxy: proxy})
+	}
+	if c.def, err = parseProxyURL(conf.Default); err != nil {
+		return nil, err
+	}
+	for _, s := range strings.Split(conf.NoProxy, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		m, err := parseHostPattern(s)
+		if err != nil {
+			return nil, err
+		}
+		c.noProxy = append(c.noProxy, m)
+	}
+	return c, nil
+}
+
+func parseProxyURL(s string) (*url.URL, error) {
+	if s == "" || s == "direct" {
+		return nil, nil
+	}
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("httputil: invalid proxy %q: %v", s, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("httputil: unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("httputil: invalid proxy %q: no host", s)
+	}
+	return u, nil
+}
+
+func parseHostPattern(s string) (m hostMatcher, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "*" {
+		m.any = true
+		return
+	}
+	if _, ipNet, e := net.ParseCIDR(s); e == nil {
+		m.ipNet = ipNet
+		return
+	}
+	if h, port, e := net.SplitHostPort(s); e == nil {
+		s, m.port = h, port
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if ip := net.ParseIP(s); ip != nil {
+		m.ip = ip
+		return
+	}
+	if strings.HasPrefix(s, "*.") {
+		s = s[1:]
+	}
+	if strings.HasPrefix(s, ".") {
+		s, m.subOnly = s[1:], true
+	}
+	if s == "" || strings.ContainsAny(s, "*/") {
+		return m, fmt.Errorf("httputil: invalid host pattern %q", s)
+	}
+	m.host = s
+	return
+}
+
+func (m *hostMatcher) match(host string, ip net.IP, port string) bool {
+	if m.port != "" && m.port != port {
+		return false
+	}
+	switch {
+	case m.any:
+		return true
+	case m.ipNet != nil:
+		return ip != nil && m.ipNet.Contains(ip)
+	case m.ip != nil:
+		return ip != nil && m.ip.Equal(ip)
+	}
+	if host == m.host {
+		return !m.subOnly
+	}
+	return strings.HasSuffix(host, "."+m.host)
+}
+
+// ----------------------------------------------------------
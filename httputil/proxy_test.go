@@ -0,0 +1,88 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func proxyOf(t *testing.T, p *ProxySelector, target string) string {
+	u, err := p.Proxy(httptest.NewRequest("GET", target, nil))
+	if err != nil {
+		t.Fatal("Proxy:", err)
+	}
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+func TestProxySelector(t *testing.T) {
+	p, err := NewProxySelector(&ProxyConfig{
+		Rules: []ProxyRule{
+			{Host: "*.corp.example.com", Proxy: "http://corp-proxy:3128"},
+			{Host: "storage.example.com:443", Proxy: "socks5://127.0.0.1:1080"},
+			{Host: "example.com", Proxy: "direct"},
+		},
+		Default: "egress:8080",
+		NoProxy: "localhost, 10.0.0.0/8, ::1, .internal",
+	})
+	if err != nil {
+		t.Fatal("NewProxySelector:", err)
+	}
+	cases := []struct{ target, proxy string }{
+		{"http://git.corp.example.com/", "http://corp-proxy:3128"},
+		{"http://corp.example.com/", ""},
+		{"https://storage.example.com/", "socks5://127.0.0.1:1080"},
+		{"http://storage.example.com/", ""},
+		{"http://www.example.com/", ""},
+		{"http://other.org/", "http://egress:8080"},
+		{"http://localhost:8080/", ""},
+		{"http://10.1.2.3/", ""},
+		{"http://11.1.2.3/", "http://egress:8080"},
+		{"http://[::1]:80/", ""},
+		{"http://db.internal/", ""},
+	}
+	for _, c := range cases {
+		if got := proxyOf(t, p, c.target); got != c.proxy {
+			t.Fatal("Proxy", c.target, "=", got, "want", c.proxy)
+		}
+	}
+
+	if err = p.Update(&ProxyConfig{Default: "ftp://x"}); err == nil {
+		t.Fatal("Update: no error for bad scheme")
+	}
+	if got := proxyOf(t, p, "http://other.org/"); got != "http://egress:8080" {
+		t.Fatal("config changed by a failed Update:", got)
+	}
+	if err = p.Update(&ProxyConfig{NoProxy: "*", Default: "egress:8080"}); err != nil {
+		t.Fatal("Update:", err)
+	}
+	if got := proxyOf(t, p, "http://other.org/"); got != "" {
+		t.Fatal("Proxy after Update:", got)
+	}
+}
+
+func TestProxySelectorTransport(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(204)
+	}))
+	defer proxy.Close()
+
+	p, err := NewProxySelector(&ProxyConfig{Rules: []ProxyRule{{Host: "*", Proxy: proxy.URL}}})
+	if err != nil {
+		t.Fatal("NewProxySelector:", err)
+	}
+	tr := p.Transport()
+	defer tr.CloseIdleConnections()
+	resp, err := (&http.Client{Transport: tr}).Get("http://upstream.test/a")
+	if err != nil {
+		t.Fatal("Get:", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 204 || proxied != "http://upstream.test/a" {
+		t.Fatal("proxied:", resp.StatusCode, proxied)
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package iox provides small io.Reader and io.Writer wrappers: byte
+// counters, a bounded writer, a section writer and a closer of many closers.
+package iox
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+
+	xerrors "github.com/qiniu/x/errors"
+)
+
+var (
+	// ErrLimitExceeded is returned by a LimitWriter when writing past its
+	// limit.
+	ErrLimitExceeded = errors.New("iox: write limit exceeded")
+
+	// ErrOffset is returned by a SectionWriter for writes outside of its
+	// section.
+	ErrOffset = errors.New("iox: invalid offset")
+)
+
+// -----------------------------------------------------------------------------
+
+// CountingReader counts the bytes read from R. Count is safe to call
+// concurrently with Read.
+type CountingReader struct {
+	R io.Reader
+	n int64
+}
+
+// NewCountingReader returns a CountingReader reading from r.
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{R: r}
+}
+
+func (p *CountingReader) Read(b []byte) (n int, err error) {
+	n, err = p.R.Read(b)
+	atomic.AddInt64(&p.n, int64(n))
+	return
+}
+
+// Count returns the number of bytes read so far.
+func (p *CountingReader) Count() int64 {
+	return atomic.LoadInt64(&p.n)
+}
+
+// CountingWriter counts the bytes written to W. Count is safe to call
+// concurrently with Write.
+type CountingWriter struct {
+	W io.Writer
+	n int64
+}
+
+// NewCountingWriter returns a CountingWriter writing to w.
+func NewCountingWriter(w io.Writer) *CountingWriter {
+	return &CountingWriter{W: w}
+}
+
+func (p *CountingWriter) Write(b []byte) (n int, err error) {
+	n, err = p.W.Write(b)
+	atomic.AddInt64(&p.n, int64(n))
+	return
+}
+
+// Count returns the number of bytes written so far.
+func (p *CountingWriter) Count() int64 {
+	return atomic.LoadInt64(&p.n)
+}
+
+// -----------------------------------------------------------------------------
+
+// LimitWriter writes at most N bytes to W. Unlike io.LimitReader, which
+// silently stops, a write that goes past the limit writes the bytes that fit
+// and then fails with ErrLimitExceeded.
+type LimitWriter struct {
+	W io.Writer
+	N int64 // max bytes remaining
+}
+
+// NewLimitWriter returns a LimitWriter writing at most n bytes to w.
+func NewLimitWriter(w io.Writer, n int64) *LimitWriter {
+	return &LimitWriter{W: w, N: n}
+}
+
+func (p *LimitWriter) Write(b []byte) (n int, err error) {
+	if int64(len(b)) <= p.N {
+		n, err = p.W.Write(b)
+		p.N -= int64(n)
+		return
+	}
+	if p.N > 0 {
+		n, err = p.W.Write(b[:p.N])
+		p.N -= int64(n)
+		if err != nil {
+			return
+		}
+	}
+	return n, ErrLimitExceeded
+}
+
+// -----------------------------------------------------------------------------
+
+// SectionWriter implements Write, WriteAt and Seek on a section of an
+// underlying io.WriterAt. It is the writing counterpart of
+// io.SectionReader.
+type SectionWriter struct {
+	w     io.WriterAt
+	base  int64
+	off   int64
+	limit int64
+}
+
+// NewSectionWriter returns a SectionWriter that writes to w starting at
+// offset off and stops with ErrLimitExceeded after n bytes.
+func NewSectionWriter(w io.WriterAt, off int64, n int64) *SectionWriter {
+	return &SectionWriter{w: w, base: off, off: off, limit: off + n}
+}
+
+func (p *SectionWriter) Write(b []byte) (n int, err error) {
+	n, err = p.writeAt(b, p.off)
+	p.off += int64(n)
+	return
+}
+
+// WriteAt writes b at offset off of the section.
+func (p *SectionWriter) WriteAt(b []byte, off int64) (n int, err error) {
+	if off < 0 || off > p.Size() {
+		return 0, ErrOffset
+	}
+	return p.writeAt(b, p.base+off)
+}
+
+func (p *SectionWriter) writeAt(b []byte, off int64) (n int, err error) {
+	if max := p.limit - off; int64(len(b)) > max {
+		if max < 0 {
+			max = 0
+		}
+		n, err = p.w.WriteAt(b[:max], off)
+		if err == nil {
+			err = ErrLimitExceeded
+		}
+		return
+	}
+	return p.w.WriteAt(b, off)
+}
+
+// Seek implements the io.Seeker interface.
+func (p *SectionWriter) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		offset += p.base
+	case io.SeekCurrent:
+		offset += p.off
+	case io.SeekEnd:
+		offset += p.limit
+	default:
+		return 0, errors.New("iox: invalid whence")
+	}
+	if offset < p.base {
+		return 0, ErrOffset
+	}
+	p.off = offset
+	return offset - p.base, nil
+}
+
+// Size returns the size of the section in bytes.
+func (p *SectionWriter) Size() int64 {
+	return p.limit - p.base
+}
+
+// -----------------------------------------------------------------------------
+
+// MultiCloser closes many io.Closers as one.
+type MultiCloser []io.Closer
+
+// Close closes all the closers in reverse order, so that resources are
+// released in the opposite order of their acquisition, and returns all of
+// their errors as an errors.List.
+func (p MultiCloser) Close() error {
+	var errs xerrors.List
+	for i := len(p) - 1; i >= 0; i-- {
+		if err := p[i].Close(); err != nil {
+			errs.Add(err)
+		}
+	}
+	return errs.ToError()
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,103 @@
+package iox
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	xerrors "github.com/qiniu/x/errors"
+)
+
+func TestCounting(t *testing.T) {
+	r := NewCountingReader(strings.NewReader("hello world"))
+	var buf bytes.Buffer
+	w := NewCountingWriter(&buf)
+	if _, err := io.Copy(w, r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Count() != 11 || w.Count() != 11 || buf.String() != "hello world" {
+		t.Fatal(r.Count(), w.Count(), buf.String())
+	}
+}
+
+func TestLimitWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewLimitWriter(&buf, 5)
+	if n, err := w.Write([]byte("abc")); n != 3 || err != nil {
+		t.Fatal(n, err)
+	}
+	if n, err := w.Write([]byte("defg")); n != 2 || err != ErrLimitExceeded {
+		t.Fatal(n, err)
+	}
+	if n, err := w.Write([]byte("h")); n != 0 || err != ErrLimitExceeded {
+		t.Fatal(n, err)
+	}
+	if buf.String() != "abcde" {
+		t.Fatal(buf.String())
+	}
+}
+
+type buffer []byte
+
+func (b buffer) WriteAt(p []byte, off int64) (int, error) {
+	return copy(b[off:], p), nil
+}
+
+func TestSectionWriter(t *testing.T) {
+	b := buffer([]byte("0123456789"))
+	w := NewSectionWriter(b, 2, 5)
+	if n, err := w.Write([]byte("ab")); n != 2 || err != nil {
+		t.Fatal(n, err)
+	}
+	if n, err := w.WriteAt([]byte("xyz"), 3); n != 2 || err != ErrLimitExceeded {
+		t.Fatal(n, err)
+	}
+	if off, err := w.Seek(-1, io.SeekEnd); off != 4 || err != nil {
+		t.Fatal(off, err)
+	}
+	if n, err := w.Write([]byte("Z")); n != 1 || err != nil {
+		t.Fatal(n, err)
+	}
+	if _, err := w.Seek(-1, io.SeekStart); err != ErrOffset {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteAt([]byte("!"), 6); err != ErrOffset {
+		t.Fatal(err)
+	}
+	if string(b) != "01ab4xZ789" {
+		t.Fatal(string(b))
+	}
+	if w.Size() != 5 {
+		t.Fatal(w.Size())
+	}
+}
+
+type closer struct {
+	order *[]int
+	id    int
+	err   error
+}
+
+func (c closer) Close() error {
+	*c.order = append(*c.order, c.id)
+	return c.err
+}
+
+func TestMultiCloser(t *testing.T) {
+	var order []int
+	e1, e3 := errors.New("e1"), errors.New("e3")
+	mc := MultiCloser{closer{&order, 1, e1}, ioutil.NopCloser(nil), closer{&order, 3, e3}}
+	err := mc.Close()
+	if list, ok := err.(xerrors.List); !ok || len(list) != 2 || list[0] != e3 || list[1] != e1 {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != 3 || order[1] != 1 {
+		t.Fatal(order)
+	}
+	if (MultiCloser{}).Close() != nil {
+		t.Fatal("empty MultiCloser")
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// Limiter is a byte budget shared by the streams it throttles: a token
+// bucket refilled at a rate of bytes per second, up to a burst of bytes. It
+// is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewLimiter returns a limiter of rate bytes per second, passing up to burst
+// bytes at once. The bucket starts full.
+func NewLimiter(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &Limiter{rate: rate, burst: burst, tokens: float64(burst), now: time.Now}
+	l.last = l.now()
+	return l
+}
+
+// SetRate changes the rate of the limiter. The waits in progress keep the
+// bytes they reserved.
+func (l *Limiter) SetRate(rate float64) {
+	l.mu.Lock()
+	l.advanceLocked(l.now())
+	l.rate = rate
+	l.mu.Unlock()
+}
+
+func (l *Limiter) advanceLocked(now time.Time) {
+	if d := now.Sub(l.last); d > 0 {
+		l.tokens = math.Min(l.tokens+d.Seconds()*l.rate, float64(l.burst))
+	}
+	l.last = now
+}
+
+// waitN blocks until n bytes, at most the burst, may pass, or ctx is done.
+// The bytes are reserved up front, so that the waiters are served in order,
+// and given back if ctx is done first.
+func (l *Limiter) waitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return errors.New("iox: zero rate limiter")
+	}
+	now := l.now()
+	l.advanceLocked(now)
+	l.tokens -= float64(n)
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(wait)) {
+		l.cancel(n)
+		return context.DeadlineExceeded
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(n)
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) cancel(n int) {
+	l.mu.Lock()
+	l.advanceLocked(l.now())
+	l.tokens = math.Min(l.tokens+float64(n), float64(l.burst))
+	l.mu.Unlock()
+}
+
+func (l *Limiter) burstOf() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.burst
+}
+
+// -----------------------------------------------------------------------------
+
+// throttle waits on all of its limiters for each chunk of bytes, so a stream
+// can be held to a budget of its own and to budgets shared with other
+// streams at the same time. Limiters count bytes: their burst caps the size
+// of the chunks passed to the underlying reader or writer.
+type throttle struct {
+	ctx  context.Context
+	lims []*Limiter
+}
+
+func (p *throttle) chunk(n int) int {
+	for _, l := range p.lims {
+		if b := l.burstOf(); b < n {
+			n = b
+		}
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (p *throttle) wait(n int) error {
+	for _, l := range p.lims {
+		if err := l.waitN(p.ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ThrottledReader reads from an io.Reader no faster than its limiters allow.
+type ThrottledReader struct {
+	r io.Reader
+	throttle
+}
+
+// NewThrottledReader returns a reader that reads from r at the rate of the
+// slowest of lims, in bytes per second. A wait is abandoned, failing the
+// Read with ctx.Err(), when ctx is done.
+func NewThrottledReader(ctx context.Context, r io.Reader, lims ...*Limiter) *ThrottledReader {
+	return &ThrottledReader{r: r, throttle: throttle{ctx: ctx, lims: lims}}
+}
+
+func (p *ThrottledReader) Read(b []byte) (n int, err error) {
+	if len(b) == 0 {
+		return p.r.Read(b)
+	}
+	b = b[:p.chunk(len(b))]
+	if err = p.wait(len(b)); err != nil {
+		return
+	}
+	return p.r.Read(b)
+}
+
+// ThrottledWriter writes to an io.Writer no faster than its limiters allow.
+type ThrottledWriter struct {
+	w io.Writer
+	throttle
+}
+
+// NewThrottledWriter returns a writer that writes to w at the rate of the
+// slowest of lims, in bytes per second. A wait is abandoned, failing the
+// Write with ctx.Err(), when ctx is done.
+func NewThrottledWriter(ctx context.Context, w io.Writer, lims ...*Limiter) *ThrottledWriter {
+	return &ThrottledWriter{w: w, throttle: throttle{ctx: ctx, lims: lims}}
+}
+
+func (p *ThrottledWriter) Write(b []byte) (n int, err error) {
+	for len(b) > 0 {
+		chunk := b[:p.chunk(len(b))]
+		if err = p.wait(len(chunk)); err != nil {
+			return
+		}
+		var nw int
+		nw, err = p.w.Write(chunk)
+		n += nw
+		if err != nil {
+			return
+		}
+		b = b[nw:]
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,67 @@
+package iox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	ctx := context.Background()
+	shared := NewLimiter(20000, 1000)
+	stream := NewLimiter(10000, 500)
+	data := bytes.Repeat([]byte("x"), 2500)
+
+	start := time.Now()
+	var buf bytes.Buffer
+	w := NewThrottledWriter(ctx, &buf, shared, stream)
+	if n, err := w.Write(data); n != len(data) || err != nil {
+		t.Fatal(n, err)
+	}
+	// the stream limiter allows 500 bytes at once and 10000 bytes/s then
+	if d := time.Since(start); d < 150*time.Millisecond {
+		t.Fatal("writes too fast:", d)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal("data mismatch")
+	}
+
+	r := NewThrottledReader(ctx, bytes.NewReader(data))
+	if b, err := ioutil.ReadAll(r); err != nil || len(b) != len(data) {
+		t.Fatal(len(b), err)
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	r = NewThrottledReader(cctx, bytes.NewReader(data), NewLimiter(1000, 100))
+	if _, err := io.Copy(ioutil.Discard, r); err != context.DeadlineExceeded {
+		t.Fatal("read with deadline:", err)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(10, 5)
+	l.now = func() time.Time { return now }
+	l.last = now
+	ctx := context.Background()
+	if err := l.waitN(ctx, 5); err != nil || l.tokens != 0 {
+		t.Fatal("burst:", err, l.tokens)
+	}
+	now = now.Add(time.Hour)
+	if l.waitN(ctx, 5); l.tokens != 0 {
+		t.Fatal("refill beyond burst:", l.tokens)
+	}
+	cctx, cancel := context.WithDeadline(ctx, now.Add(100*time.Millisecond))
+	defer cancel()
+	if err := l.waitN(cctx, 5); err != context.DeadlineExceeded || l.tokens != 0 {
+		t.Fatal("wait beyond the deadline:", err, l.tokens)
+	}
+	l.SetRate(0)
+	if err := l.waitN(ctx, 1); err == nil {
+		t.Fatal("wait at zero rate")
+	}
+}
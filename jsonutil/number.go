@@ -0,0 +1,304 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package jsonutil
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// ----------------------------------------------------------
+
+var (
+	typNumber          = reflect.TypeOf(json.Number(""))
+	typUnmarshaler     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	typTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// DecodeNumbers parses the JSON-encoded data into v as json.Unmarshal does,
+// but without turning numbers into float64: a number stored in an
+// interface{} value becomes a json.Number, which marshals back verbatim.
+//
+// The jsonnum field tag selects the target of the numbers of a field:
+//
+//	ID    interface{} `json:"id" jsonnum:"bigint"`    // *big.Int
+//	Price interface{} `json:"price" jsonnum:"bigfloat"` // *big.Float
+//	Raw   string      `json:"raw" jsonnum:"number"`    // the number's text
+//
+// A tagged field may also be declared with the target type itself
+// (json.Number, big.Int, *big.Int, big.Float or *big.Float). A big.Float
+// gets enough precision for all the digits of the number; note that it
+// marshals as a JSON string, so use "number" to re-marshal verbatim.
+func DecodeNumbers(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("jsonutil: DecodeNumbers of non-pointer")
+	}
+	return decodeValue(bytes.TrimSpace(data), rv.Elem(), "")
+}
+
+func decodeValue(raw []byte, v reflect.Value, numTag string) error {
+	if numTag != "" {
+		return decodeNumber(raw, v, numTag)
+	}
+	if isNull(raw) {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+	t := v.Type()
+	if reflect.PtrTo(t).Implements(typUnmarshaler) || reflect.PtrTo(t).Implements(typTextUnmarshaler) {
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			break
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var x interface{}
+		if err := dec.Decode(&x); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(&x).Elem())
+		return nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return decodeValue(raw, v.Elem(), "")
+	case reflect.Struct:
+		return decodeStruct(raw, v)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		sl := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(item, sl.Index(i), ""); err != nil {
+				return err
+			}
+		}
+		v.Set(sl)
+		return nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			break
+		}
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(t, len(items)))
+		}
+		for key, item := range items {
+			elem := reflect.New(t.Elem()).Elem()
+			if err := decodeValue(item, elem, ""); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+		return nil
+	}
+	return json.Unmarshal(raw, v.Addr().Interface())
+}
+
+func decodeStruct(raw []byte, v reflect.Value) error {
+	var items map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+	for _, f := range fieldsOf(v.Type()) {
+		item, ok := items[f.name]
+		if !ok {
+			for key, val := range items {
+				if strings.EqualFold(key, f.name) {
+					item, ok = val, true
+					break
+				}
+			}
+			if !ok {
+				continue
+			}
+		}
+		fv, err := fieldByIndex(v, f.index)
+		if err != nil {
+			return err
+		}
+		if f.quoted && !isNull(item) {
+			var s string
+			if err := json.Unmarshal(item, &s); err != nil {
+				return err
+			}
+			item = []byte(s)
+		}
+		if err := decodeValue(item, fv, f.numTag); err != nil {
+			return fmt.Errorf("jsonutil: field %s: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+type field struct {
+	name   string
+	index  []int
+	numTag string
+	quoted bool // the ",string" option
+}
+
+// fieldsOf returns the JSON fields of the struct type t, the fields of
+// embedded structs included.
+func fieldsOf(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && parts[0] == "" && ft.Kind() == reflect.Struct {
+			for _, f := range fieldsOf(ft) {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		f := field{name: parts[0], index: []int{i}, numTag: sf.Tag.Get("jsonnum")}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range parts[1:] {
+			f.quoted = f.quoted || opt == "string"
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// fieldByIndex is v.FieldByIndex, allocating nil embedded pointers.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, errors.New("jsonutil: nil pointer to unexported embedded struct")
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+func isNull(raw []byte) bool {
+	return string(raw) == "null"
+}
+
+// decodeNumber decodes the JSON number raw into v as numTag selects.
+func decodeNumber(raw []byte, v reflect.Value, numTag string) error {
+	if isNull(raw) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	var num json.Number
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&num); err != nil {
+		return err
+	}
+	s := string(num)
+	var x interface{}
+	switch numTag {
+	case "number":
+		x = num
+	case "bigint":
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return fmt.Errorf("%s is not an integer", s)
+		}
+		x = n
+	case "bigfloat":
+		f, _, err := big.ParseFloat(s, 10, precisionOf(s), big.ToNearestEven)
+		if err != nil {
+			return err
+		}
+		x = f
+	default:
+		return fmt.Errorf("unknown jsonnum tag %q", numTag)
+	}
+	return setNumber(v, reflect.ValueOf(x))
+}
+
+// precisionOf returns the precision in bits that represents all the digits
+// of the decimal number s.
+func precisionOf(s string) uint {
+	digits := 0
+	for i := 0; i < len(s) && s[i] != 'e' && s[i] != 'E'; i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits++
+		}
+	}
+	prec := uint(digits)*3322/1000 + 8 // log2(10) = 3.322
+	if prec < 64 {
+		prec = 64
+	}
+	return prec
+}
+
+// setNumber stores x (a json.Number, *big.Int or *big.Float) into v.
+func setNumber(v, x reflect.Value) error {
+	t := v.Type()
+	switch {
+	case t.Kind() == reflect.Interface && x.Type().Implements(t):
+		v.Set(x)
+	case x.Type().AssignableTo(t):
+		v.Set(x)
+	case x.Kind() == reflect.Ptr && x.Elem().Type().AssignableTo(t):
+		v.Set(x.Elem())
+	case x.Type() == typNumber && t.Kind() == reflect.String:
+		v.SetString(x.String())
+	default:
+		return fmt.Errorf("cannot store %v into %v", x.Type(), t)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------
@@ -0,0 +1,70 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+type Base struct {
+	Owner interface{} `json:"owner"`
+}
+
+type payload struct {
+	Base
+	ID      interface{}            `json:"id" jsonnum:"bigint"`
+	Serial  *big.Int               `json:"serial" jsonnum:"bigint"`
+	Price   *big.Float             `json:"price" jsonnum:"bigfloat"`
+	Raw     string                 `json:"raw" jsonnum:"number"`
+	Quoted  json.Number            `json:"quoted,string" jsonnum:"number"`
+	Extra   map[string]interface{} `json:"extra"`
+	Items   []struct{ N interface{} }
+	Count   int      `json:"count"`
+	Missing *big.Int `json:"missing" jsonnum:"bigint"`
+}
+
+func TestDecodeNumbers(t *testing.T) {
+	data := `{
+		"owner": 9007199254740993,
+		"id": 123456789012345678901234567890,
+		"serial": 18446744073709551617,
+		"price": 3.14159265358979323846264338327950288,
+		"raw": 1e400,
+		"quoted": "12345678901234567890",
+		"extra": {"n": 9007199254740993, "list": [1.10]},
+		"Items": [{"n": 1}, {"N": 12345678901234567}],
+		"count": 7,
+		"missing": null
+	}`
+	var ret payload
+	if err := DecodeNumbers([]byte(data), &ret); err != nil {
+		t.Fatal("DecodeNumbers:", err)
+	}
+	if ret.Owner != json.Number("9007199254740993") {
+		t.Fatalf("owner: %#v", ret.Owner)
+	}
+	if id, ok := ret.ID.(*big.Int); !ok || id.String() != "123456789012345678901234567890" {
+		t.Fatalf("id: %#v", ret.ID)
+	}
+	if ret.Serial.String() != "18446744073709551617" {
+		t.Fatal("serial:", ret.Serial)
+	}
+	if ret.Price.Text('f', 35) != "3.14159265358979323846264338327950288" {
+		t.Fatal("price:", ret.Price.Text('f', 35))
+	}
+	if ret.Raw != "1e400" || ret.Quoted != "12345678901234567890" || ret.Count != 7 || ret.Missing != nil {
+		t.Fatalf("unexpected: %+v", ret)
+	}
+	if ret.Items[1].N != json.Number("12345678901234567") || ret.Items[0].N != json.Number("1") {
+		t.Fatalf("items: %+v", ret.Items)
+	}
+	b, _ := json.Marshal(ret.Extra)
+	if string(b) != `{"list":[1.10],"n":9007199254740993}` {
+		t.Fatal("re-marshal:", string(b))
+	}
+
+	var bad payload
+	if err := DecodeNumbers([]byte(`{"id": 1.5}`), &bad); err == nil {
+		t.Fatal("DecodeNumbers: non-integer accepted as bigint")
+	}
+}
@@ -0,0 +1,210 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package lifecycle runs a service made of ordered components.
+//
+// Components register start/stop hooks with a Manager. Run starts them in
+// order, waits until the process receives SIGINT/SIGTERM, the context is
+// canceled, or a component reports a failure, and then stops the started
+// components in reverse order within a shutdown deadline.
+package lifecycle
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/qiniu/x/errors"
+)
+
+// DefaultShutdownTimeout is the default deadline for stopping all hooks.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Hook is a pair of start/stop callbacks of a component. Both are optional.
+type Hook struct {
+	Name string
+
+	// OnStart starts the component. It must not block: long running work is
+	// run in its own goroutine, see Manager.Go.
+	OnStart func(ctx context.Context) error
+
+	// OnStop stops the component, giving up when ctx is done.
+	OnStop func(ctx context.Context) error
+}
+
+// HookError reports the failure of a hook.
+type HookError struct {
+	Name string // name of the hook
+	Op   string // "start", "stop" or "run"
+	Err  error
+}
+
+func (e *HookError) Error() string {
+	return "lifecycle: " + e.Op + " " + e.Name + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// -----------------------------------------------------------------------------
+
+// Manager is a lifecycle manager.
+type Manager struct {
+	// ShutdownTimeout is the deadline for stopping all hooks. Zero means
+	// DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+
+	// Signals are the signals that trigger a shutdown. Nil means SIGINT
+	// and SIGTERM.
+	Signals []os.Signal
+
+	mu      sync.Mutex
+	hooks   []Hook
+	failure chan error
+	ctx     context.Context
+}
+
+// New creates a lifecycle manager.
+func New() *Manager {
+	return &Manager{failure: make(chan error, 1)}
+}
+
+// Append appends a hook. Hooks are started in the order they are appended
+// and stopped in the reverse order.
+func (m *Manager) Append(h Hook) {
+	m.mu.Lock()
+	m.hooks = append(m.hooks, h)
+	m.mu.Unlock()
+}
+
+// Go appends a hook whose OnStart runs fn in a new goroutine. If fn returns
+// before the shutdown begins, its error (or a nil error) is reported as a
+// failure of the hook and the manager shuts down. The ctx passed to fn is
+// canceled when the shutdown begins, and OnStop waits for fn to return.
+func (m *Manager) Go(name string, fn func(ctx context.Context) error) {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	m.Append(Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			ctx, cancel = context.WithCancel(m.runContext())
+			go func() {
+				defer close(done)
+				err := fn(ctx)
+				if ctx.Err() == nil {
+					if err == nil {
+						err = errors.New("exited unexpectedly")
+					}
+					m.Fail(&HookError{Name: name, Op: "run", Err: err})
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-ctx.Done():
+				select {
+				case <-done:
+				default:
+					return ctx.Err()
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// Fail reports a component failure, which triggers the shutdown. Only the
+// first failure is kept.
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failure <- err:
+	default:
+	}
+}
+
+func (m *Manager) runContext() context.Context {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ctx
+}
+
+// Run starts all hooks and blocks until a shutdown is triggered, then stops
+// them. It returns nil on a clean shutdown; otherwise the error is the
+// failure that triggered the shutdown and/or the errors of hooks that failed
+// to start or stop, each wrapped in a *HookError.
+func (m *Manager) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	m.mu.Lock()
+	m.ctx = ctx
+	hooks := append([]Hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	signals := m.Signals
+	if signals == nil {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, signals...)
+	defer signal.Stop(sigc)
+
+	var errs errors.List
+	started := 0
+	for _, h := range hooks {
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				errs.Add(&HookError{Name: h.Name, Op: "start", Err: err})
+				break
+			}
+		}
+		started++
+	}
+	if len(errs) == 0 {
+		select {
+		case <-sigc:
+		case <-ctx.Done():
+		case err := <-m.failure:
+			errs.Add(err)
+		}
+	}
+	cancel()
+
+	timeout := m.ShutdownTimeout
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), timeout)
+	defer stopCancel()
+	for i := started - 1; i >= 0; i-- {
+		h := hooks[i]
+		if h.OnStop != nil {
+			if err := h.OnStop(stopCtx); err != nil {
+				errs.Add(&HookError{Name: h.Name, Op: "stop", Err: err})
+			}
+		}
+	}
+	return errs.ToError()
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,104 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunUntilCanceled(t *testing.T) {
+	var events []string
+	m := New()
+	for _, name := range []string{"db", "cache", "http"} {
+		name := name
+		m.Append(Hook{
+			Name:    name,
+			OnStart: func(ctx context.Context) error { events = append(events, "start "+name); return nil },
+			OnStop:  func(ctx context.Context) error { events = append(events, "stop "+name); return nil },
+		})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := m.Run(ctx); err != nil {
+		t.Fatal("Run:", err)
+	}
+	want := "start db,start cache,start http,stop http,stop cache,stop db"
+	if got := strings.Join(events, ","); got != want {
+		t.Fatal("events:", got)
+	}
+}
+
+func TestStartFailure(t *testing.T) {
+	var stopped []string
+	m := New()
+	m.Append(Hook{Name: "a", OnStop: func(ctx context.Context) error { stopped = append(stopped, "a"); return nil }})
+	m.Append(Hook{Name: "b", OnStart: func(ctx context.Context) error { return errors.New("boom") }})
+	m.Append(Hook{Name: "c", OnStop: func(ctx context.Context) error { stopped = append(stopped, "c"); return nil }})
+	err := m.Run(context.Background())
+	var he *HookError
+	if !errors.As(err, &he) || he.Name != "b" || he.Op != "start" {
+		t.Fatal("Run:", err)
+	}
+	if len(stopped) != 1 || stopped[0] != "a" {
+		t.Fatal("stopped:", stopped)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	m := New()
+	m.Go("worker", func(ctx context.Context) error {
+		return errors.New("crashed")
+	})
+	m.Go("server", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	err := m.Run(context.Background())
+	var he *HookError
+	if !errors.As(err, &he) || he.Name != "worker" || he.Op != "run" {
+		t.Fatal("Run:", err)
+	}
+}
+
+func TestGoStopWaits(t *testing.T) {
+	m := New()
+	var mu sync.Mutex
+	exited := false
+	m.Append(Hook{Name: "db", OnStop: func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !exited {
+			return errors.New("stopped while the worker is running")
+		}
+		return nil
+	}})
+	release := make(chan struct{})
+	defer close(release)
+	m.Go("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	m.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		exited = true
+		mu.Unlock()
+		return nil
+	})
+	m.ShutdownTimeout = 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := m.Run(ctx)
+	var he *HookError
+	if !errors.As(err, &he) || he.Name != "stuck" || he.Op != "stop" || !errors.Is(err, context.DeadlineExceeded) ||
+		strings.Contains(err.Error(), "db") {
+		t.Fatal("Run:", err)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build unix
+// +build unix
+
+package lifecycle
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestSignal(t *testing.T) {
+	m := New()
+	m.Signals = []os.Signal{syscall.SIGUSR1}
+	m.Append(Hook{Name: "kill", OnStart: func(ctx context.Context) error {
+		return syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}})
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal("Run:", err)
+	}
+}
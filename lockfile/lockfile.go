@@ -0,0 +1,214 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package lockfile implements advisory file locks.
+//
+// A lock is held on an open file: it is released when Unlock is called or the
+// process exits, so a crashed holder never blocks others forever. Holders of
+// exclusive locks record their identity (pid, host and boot id) in the lock
+// file, which Owner reads back and Stale uses to tell whether the recorded
+// holder is gone; this is useful for diagnostics and for lock files
+// shared with tools that don't take advisory locks.
+package lockfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnsupported is returned on platforms without file locking.
+	ErrUnsupported = errors.New("lockfile: file locking is not supported")
+
+	// ErrNotLocked is returned by Unlock when the lock is not held.
+	ErrNotLocked = errors.New("lockfile: not locked")
+
+	// ErrLocked is returned by Lock and RLock when the lock is already held
+	// by this Lock object.
+	ErrLocked = errors.New("lockfile: already locked")
+)
+
+// Owner identifies the holder of an exclusive lock.
+type Owner struct {
+	Pid    int       `json:"pid"`
+	Host   string    `json:"host,omitempty"`
+	BootID string    `json:"bootid,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// -----------------------------------------------------------------------------
+
+// Lock is an advisory lock on a file. It is safe for concurrent use, but it is
+// not reentrant: a goroutine must not lock it twice.
+type Lock struct {
+	path string
+
+	mu        sync.Mutex
+	f         *os.File
+	exclusive bool
+}
+
+// New returns a lock on the named file, which is created on first use.
+func New(path string) *Lock {
+	return &Lock{path: path}
+}
+
+// Path returns the name of the lock file.
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// TryLock tries to acquire the exclusive lock without blocking.
+func (l *Lock) TryLock() (bool, error) {
+	return l.try(true)
+}
+
+// TryRLock tries to acquire a shared lock without blocking.
+func (l *Lock) TryRLock() (bool, error) {
+	return l.try(false)
+}
+
+// Lock acquires the exclusive lock, waiting until it is available or ctx is
+// done.
+func (l *Lock) Lock(ctx context.Context) error {
+	return l.wait(ctx, true)
+}
+
+// RLock acquires a shared lock, waiting until it is available or ctx is done.
+func (l *Lock) RLock(ctx context.Context) error {
+	return l.wait(ctx, false)
+}
+
+const (
+	minPollInterval = 5 * time.Millisecond
+	maxPollInterval = 200 * time.Millisecond
+)
+
+func (l *Lock) wait(ctx context.Context, exclusive bool) error {
+	d := minPollInterval
+	for {
+		ok, err := l.try(exclusive)
+		if ok || err != nil {
+			return err
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		if d *= 2; d > maxPollInterval {
+			d = maxPollInterval
+		}
+	}
+}
+
+func (l *Lock) try(exclusive bool) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f != nil {
+		return false, ErrLocked
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	ok, err := lockFile(f, exclusive)
+	if !ok || err != nil {
+		f.Close()
+		return false, err
+	}
+	if exclusive {
+		writeOwner(f)
+	}
+	l.f, l.exclusive = f, exclusive
+	return true, nil
+}
+
+// Unlock releases the lock. An exclusive holder clears its identity from the
+// lock file first; the file itself is kept, since removing it would race with
+// other processes that have it open.
+func (l *Lock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f := l.f
+	if f == nil {
+		return ErrNotLocked
+	}
+	l.f = nil
+	if l.exclusive {
+		f.Truncate(0)
+	}
+	err := unlockFile(f)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+func writeOwner(f *os.File) {
+	host, _ := os.Hostname()
+	b, _ := json.Marshal(&Owner{Pid: os.Getpid(), Host: host, BootID: bootID(), Time: time.Now()})
+	f.Truncate(0)
+	f.WriteAt(append(b, '\n'), 0)
+	f.Sync()
+}
+
+// -----------------------------------------------------------------------------
+
+// ReadOwner returns the identity recorded in the named lock file by its
+// last exclusive holder. It returns an error satisfying os.IsNotExist if no
+// identity is recorded.
+func ReadOwner(path string) (o *Owner, err error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if len(b) == 0 {
+		return nil, &os.PathError{Op: "readowner", Path: path, Err: os.ErrNotExist}
+	}
+	o = new(Owner)
+	if err = json.Unmarshal(b, o); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Owner returns the identity recorded in the lock file.
+func (l *Lock) Owner() (*Owner, error) {
+	return ReadOwner(l.path)
+}
+
+// Stale reports whether o no longer holds the lock: it ran on this host
+// before the last reboot, or its process has exited. Owners on other hosts
+// are never considered stale.
+func (o *Owner) Stale() bool {
+	if host, _ := os.Hostname(); host != o.Host {
+		return false
+	}
+	if boot := bootID(); boot != "" && o.BootID != "" && boot != o.BootID {
+		return true
+	}
+	return !processAlive(o.Pid)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,79 @@
+package lockfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+	a, b := New(path), New(path)
+	if ok, err := a.TryLock(); !ok || err != nil {
+		t.Fatal("TryLock:", ok, err)
+	}
+	if ok, err := b.TryRLock(); ok || err != nil {
+		t.Fatal("TryRLock while locked:", ok, err)
+	}
+	o, err := b.Owner()
+	if err != nil || o.Pid != os.Getpid() || o.Stale() {
+		t.Fatal("Owner:", o, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = b.Lock(ctx); err != context.DeadlineExceeded {
+		t.Fatal("Lock timeout:", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		a.Unlock()
+	}()
+	if err = b.Lock(context.Background()); err != nil {
+		t.Fatal("Lock:", err)
+	}
+	if err = b.Unlock(); err != nil {
+		t.Fatal("Unlock:", err)
+	}
+	if err = b.Unlock(); err != ErrNotLocked {
+		t.Fatal("Unlock twice:", err)
+	}
+	if _, err = b.Owner(); !os.IsNotExist(err) {
+		t.Fatal("Owner after Unlock:", err)
+	}
+}
+
+func TestShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+	a, b, c := New(path), New(path), New(path)
+	if ok, err := a.TryRLock(); !ok || err != nil {
+		t.Fatal("TryRLock a:", ok, err)
+	}
+	if ok, err := b.TryRLock(); !ok || err != nil {
+		t.Fatal("TryRLock b:", ok, err)
+	}
+	if ok, err := c.TryLock(); ok || err != nil {
+		t.Fatal("TryLock while shared:", ok, err)
+	}
+	a.Unlock()
+	b.Unlock()
+	if ok, err := c.TryLock(); !ok || err != nil {
+		t.Fatal("TryLock:", ok, err)
+	}
+	c.Unlock()
+}
+
+func TestStale(t *testing.T) {
+	host, _ := os.Hostname()
+	o := &Owner{Pid: 1 << 30, Host: host}
+	if !o.Stale() {
+		t.Fatal("dead owner is not stale")
+	}
+	o.Host = "another-host." + host
+	if o.Stale() {
+		t.Fatal("owner on another host is stale")
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"expvar"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// WritePrometheus writes all metrics of r in the Prometheus text exposition
+// format.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	for _, f := range r.sorted() {
+		children := f.sorted()
+		if len(children) == 0 {
+			continue
+		}
+		if f.help != "" {
+			b.WriteString("# HELP " + f.name + " " + escapeHelp(f.help) + "\n")
+		}
+		b.WriteString("# TYPE " + f.name + " " + f.kind.String() + "\n")
+		for _, c := range children {
+			labels := labelPairs(f.labels, c.values)
+			switch m := c.metric.(type) {
+			case *Counter:
+				writeSample(b, f.name, labels, "", strconv.FormatUint(m.Value(), 10))
+			case *Gauge:
+				writeSample(b, f.name, labels, "", formatFloat(m.Value()))
+			case *Histogram:
+				upper, cumulative := m.Buckets()
+				for i, le := range upper {
+					writeSample(b, f.name+"_bucket", labels, `le="`+formatFloat(le)+`"`, strconv.FormatUint(cumulative[i], 10))
+				}
+				count := strconv.FormatUint(m.Count(), 10)
+				writeSample(b, f.name+"_bucket", labels, `le="+Inf"`, count)
+				writeSample(b, f.name+"_sum", labels, "", formatFloat(m.Sum()))
+				writeSample(b, f.name+"_count", labels, "", count)
+			}
+		}
+	}
+	return b.Flush()
+}
+
+// Handler returns an http.Handler serving r in the Prometheus text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WritePrometheus(w)
+	})
+}
+
+func writeSample(b *bufio.Writer, name, labels, extra, value string) {
+	b.WriteString(name)
+	if labels != "" || extra != "" {
+		b.WriteByte('{')
+		b.WriteString(labels)
+		if labels != "" && extra != "" {
+			b.WriteByte(',')
+		}
+		b.WriteString(extra)
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+func labelPairs(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// -----------------------------------------------------------------------------
+
+// PublishExpvar publishes r as the expvar variable name. Each family is an
+// object member; unlabeled families map to their value, labeled ones map
+// "label=value,..." keys to values.
+func (r *Registry) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(r.expvarValue))
+}
+
+type histogramVar struct {
+	Count   uint64            `json:"count"`
+	Sum     interface{}       `json:"sum"`
+	Buckets map[string]uint64 `json:"buckets"`
+}
+
+func (r *Registry) expvarValue() interface{} {
+	ret := make(map[string]interface{})
+	for _, f := range r.sorted() {
+		children := f.sorted()
+		values := make(map[string]interface{}, len(children))
+		for _, c := range children {
+			var v interface{}
+			switch m := c.metric.(type) {
+			case *Counter:
+				v = m.Value()
+			case *Gauge:
+				v = jsonFloat(m.Value())
+			case *Histogram:
+				upper, cumulative := m.Buckets()
+				h := &histogramVar{Count: m.Count(), Sum: jsonFloat(m.Sum()), Buckets: make(map[string]uint64, len(upper))}
+				for i, le := range upper {
+					h.Buckets[formatFloat(le)] = cumulative[i]
+				}
+				v = h
+			}
+			if len(f.labels) == 0 {
+				ret[f.name] = v
+				break
+			}
+			pairs := make([]string, len(f.labels))
+			for i, label := range f.labels {
+				pairs[i] = label + "=" + c.values[i]
+			}
+			values[strings.Join(pairs, ",")] = v
+		}
+		if len(f.labels) != 0 {
+			ret[f.name] = values
+		}
+	}
+	return ret
+}
+
+// jsonFloat returns v as a JSON number; NaN and infinities, which JSON can't
+// represent, are reported as null.
+func jsonFloat(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,361 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package metrics is a lightweight in-process metrics registry.
+//
+// It provides counters, gauges and histograms, optionally partitioned by
+// labels. Looking up a labeled child (With) takes a lock and may allocate, so
+// hot paths should look children up once and keep them: updating a metric is
+// a single atomic operation and never allocates.
+//
+// A Registry can be exported in the Prometheus text format (WritePrometheus,
+// Handler) and through expvar (PublishExpvar).
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// -----------------------------------------------------------------------------
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	v uint64
+}
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() {
+	atomic.AddUint64(&c.v, 1)
+}
+
+// Add increments the counter by n.
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.v, n)
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.v)
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	bits uint64
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) {
+	atomic.StoreUint64(&g.bits, math.Float64bits(v))
+}
+
+// Add adds delta (which may be negative) to the gauge.
+func (g *Gauge) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&g.bits)
+		v := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&g.bits, old, v) {
+			return
+		}
+	}
+}
+
+// Value returns the current value of the gauge.
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+// DefBuckets are the default histogram buckets, suitable for latencies in
+// seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	count  uint64 // first, to be 64-bit aligned for atomic operations
+	sum    Gauge
+	upper  []float64 // sorted upper bounds, without +Inf
+	counts []uint64  // len(upper)+1, the last one is +Inf
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{upper: buckets, counts: make([]uint64, len(buckets)+1)}
+}
+
+// Observe records v.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v)
+	atomic.AddUint64(&h.counts[i], 1)
+	atomic.AddUint64(&h.count, 1)
+	h.sum.Add(v)
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	return atomic.LoadUint64(&h.count)
+}
+
+// Sum returns the sum of all observations.
+func (h *Histogram) Sum() float64 {
+	return h.sum.Value()
+}
+
+// Buckets returns the upper bounds of the buckets (the implicit +Inf one not
+// included) with their cumulative counts.
+func (h *Histogram) Buckets() (upper []float64, cumulative []uint64) {
+	cumulative = make([]uint64, len(h.upper))
+	var n uint64
+	for i := range h.upper {
+		n += atomic.LoadUint64(&h.counts[i])
+		cumulative[i] = n
+	}
+	return h.upper, cumulative
+}
+
+// -----------------------------------------------------------------------------
+
+// Kind is the kind of a metric family.
+type Kind int
+
+const (
+	KindCounter Kind = iota
+	KindGauge
+	KindHistogram
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindCounter:
+		return "counter"
+	case KindGauge:
+		return "gauge"
+	}
+	return "histogram"
+}
+
+type child struct {
+	values []string
+	metric interface{} // *Counter, *Gauge or *Histogram
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    Kind
+	labels  []string
+	buckets []float64
+
+	mu       sync.RWMutex
+	children map[string]*child
+}
+
+func (f *family) with(values []string) interface{} {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	f.mu.RLock()
+	c, ok := f.children[key]
+	f.mu.RUnlock()
+	if ok {
+		return c.metric
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c, ok = f.children[key]; ok {
+		return c.metric
+	}
+	c = &child{values: append([]string(nil), values...)}
+	switch f.kind {
+	case KindCounter:
+		c.metric = new(Counter)
+	case KindGauge:
+		c.metric = new(Gauge)
+	default:
+		c.metric = newHistogram(f.buckets)
+	}
+	f.children[key] = c
+	return c.metric
+}
+
+// sorted returns the children sorted by their label values.
+func (f *family) sorted() []*child {
+	f.mu.RLock()
+	keys := make([]string, 0, len(f.children))
+	for key := range f.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	ret := make([]*child, len(keys))
+	for i, key := range keys {
+		ret[i] = f.children[key]
+	}
+	f.mu.RUnlock()
+	return ret
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct{ f *family }
+
+// With returns the counter of the given label values, creating it if needed.
+func (v CounterVec) With(labelValues ...string) *Counter {
+	return v.f.with(labelValues).(*Counter)
+}
+
+// GaugeVec is a gauge partitioned by labels.
+type GaugeVec struct{ f *family }
+
+// With returns the gauge of the given label values, creating it if needed.
+func (v GaugeVec) With(labelValues ...string) *Gauge {
+	return v.f.with(labelValues).(*Gauge)
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct{ f *family }
+
+// With returns the histogram of the given label values, creating it if needed.
+func (v HistogramVec) With(labelValues ...string) *Histogram {
+	return v.f.with(labelValues).(*Histogram)
+}
+
+// -----------------------------------------------------------------------------
+
+// Registry is a set of metric families.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Default is the default registry.
+var Default = NewRegistry()
+
+// register returns the family of name, creating it if needed. Registering
+// the same name twice with a different kind or labels panics.
+func (r *Registry) register(name, help string, kind Kind, buckets []float64, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.families[name]; ok {
+		if f.kind != kind || strings.Join(f.labels, ",") != strings.Join(labels, ",") {
+			panic("metrics: conflicting registration of " + name)
+		}
+		return f
+	}
+	if kind == KindHistogram {
+		if buckets == nil {
+			buckets = DefBuckets
+		}
+		buckets = append([]float64(nil), buckets...)
+		sort.Float64s(buckets)
+	}
+	f := &family{
+		name: name, help: help, kind: kind, buckets: buckets,
+		labels: append([]string(nil), labels...), children: make(map[string]*child),
+	}
+	r.families[name] = f
+	return f
+}
+
+// Counter returns the unlabeled counter name.
+func (r *Registry) Counter(name, help string) *Counter {
+	return r.CounterVec(name, help).With()
+}
+
+// CounterVec returns the counter family name partitioned by labels.
+func (r *Registry) CounterVec(name, help string, labels ...string) CounterVec {
+	return CounterVec{r.register(name, help, KindCounter, nil, labels)}
+}
+
+// Gauge returns the unlabeled gauge name.
+func (r *Registry) Gauge(name, help string) *Gauge {
+	return r.GaugeVec(name, help).With()
+}
+
+// GaugeVec returns the gauge family name partitioned by labels.
+func (r *Registry) GaugeVec(name, help string, labels ...string) GaugeVec {
+	return GaugeVec{r.register(name, help, KindGauge, nil, labels)}
+}
+
+// Histogram returns the unlabeled histogram name. Nil buckets means
+// DefBuckets.
+func (r *Registry) Histogram(name, help string, buckets []float64) *Histogram {
+	return r.HistogramVec(name, help, buckets).With()
+}
+
+// HistogramVec returns the histogram family name partitioned by labels. Nil
+// buckets means DefBuckets.
+func (r *Registry) HistogramVec(name, help string, buckets []float64, labels ...string) HistogramVec {
+	return HistogramVec{r.register(name, help, KindHistogram, buckets, labels)}
+}
+
+// Unregister removes the family name from the registry.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.families, name)
+	r.mu.Unlock()
+}
+
+func (r *Registry) sorted() []*family {
+	r.mu.RLock()
+	ret := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		ret = append(ret, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(ret, func(i, j int) bool { return ret[i].name < ret[j].name })
+	return ret
+}
+
+// -----------------------------------------------------------------------------
+
+// NewCounter returns the unlabeled counter name of the Default registry.
+func NewCounter(name, help string) *Counter {
+	return Default.Counter(name, help)
+}
+
+// NewCounterVec returns the counter family name of the Default registry.
+func NewCounterVec(name, help string, labels ...string) CounterVec {
+	return Default.CounterVec(name, help, labels...)
+}
+
+// NewGauge returns the unlabeled gauge name of the Default registry.
+func NewGauge(name, help string) *Gauge {
+	return Default.Gauge(name, help)
+}
+
+// NewGaugeVec returns the gauge family name of the Default registry.
+func NewGaugeVec(name, help string, labels ...string) GaugeVec {
+	return Default.GaugeVec(name, help, labels...)
+}
+
+// NewHistogram returns the unlabeled histogram name of the Default registry.
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return Default.Histogram(name, help, buckets)
+}
+
+// NewHistogramVec returns the histogram family name of the Default registry.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) HistogramVec {
+	return Default.HistogramVec(name, help, buckets, labels...)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestPrometheus(t *testing.T) {
+	r := NewRegistry()
+	reqs := r.CounterVec("http_requests_total", "Total requests.", "method", "code")
+	reqs.With("GET", "200").Add(3)
+	reqs.With("POST", "500").Inc()
+	r.Gauge("inflight", "In-flight requests.").Set(2.5)
+	h := r.Histogram("latency_seconds", "", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+
+	var buf bytes.Buffer
+	r.WritePrometheus(&buf)
+	want := `# HELP http_requests_total Total requests.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",code="200"} 3
+http_requests_total{method="POST",code="500"} 1
+# HELP inflight In-flight requests.
+# TYPE inflight gauge
+inflight 2.5
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 5.55
+latency_seconds_count 3
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("WritePrometheus:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExpvar(t *testing.T) {
+	r := NewRegistry()
+	r.CounterVec("hits", "", "group").With("a").Add(2)
+	r.Gauge("load", "").Add(-1)
+	b, err := json.Marshal(r.expvarValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"hits":{"group=a":2},"load":-1}`; string(b) != want {
+		t.Fatalf("expvar: %s, want %s", b, want)
+	}
+
+	r.Gauge("ratio", "").Set(math.NaN())
+	h := r.Histogram("latency", "", []float64{1})
+	h.Observe(math.NaN())
+	h.Observe(0.5)
+	if b, err = json.Marshal(r.expvarValue()); err != nil {
+		t.Fatal("NaN samples:", err)
+	}
+	want := `{"hits":{"group=a":2},"latency":{"count":2,"sum":null,"buckets":{"1":1}},"load":-1,"ratio":null}`
+	if string(b) != want {
+		t.Fatalf("expvar: %s, want %s", b, want)
+	}
+}
+
+func TestConflict(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("conflicting registration doesn't panic")
+		}
+	}()
+	r := NewRegistry()
+	r.Counter("x", "")
+	r.Gauge("x", "")
+}
+
+func BenchmarkCounter(b *testing.B) {
+	c := NewRegistry().CounterVec("c", "", "l").With("v")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Inc()
+	}
+}
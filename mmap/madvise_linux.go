@@ -0,0 +1,36 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package mmap
+
+import (
+	"syscall"
+)
+
+var advices = [...]int{
+	Normal:     syscall.MADV_NORMAL,
+	Sequential: syscall.MADV_SEQUENTIAL,
+	Random:     syscall.MADV_RANDOM,
+	WillNeed:   syscall.MADV_WILLNEED,
+	DontNeed:   syscall.MADV_DONTNEED,
+}
+
+func madvise(data []byte, advice Advice) error {
+	if advice < 0 || int(advice) >= len(advices) {
+		return syscall.EINVAL
+	}
+	return syscall.Madvise(data, advices[advice])
+}
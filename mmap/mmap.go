@@ -0,0 +1,181 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package mmap provides read-only memory-mapped files.
+//
+// A mapped file is exposed both as a []byte (zero-copy, see Bytes) and as an
+// io.ReaderAt. If the underlying file is truncated while it is mapped, touching
+// the vanished pages raises a fault: ReadAt and At recover from it and return
+// ErrTruncated, while direct accesses to the slice returned by Bytes crash the
+// program as any invalid memory access does.
+package mmap
+
+import (
+	"errors"
+	"io"
+	"os"
+	"runtime/debug"
+)
+
+var (
+	// ErrClosed is returned when a ReaderAt is used after Close.
+	ErrClosed = errors.New("mmap: closed")
+
+	// ErrTruncated is returned when the mapped file was truncated after it
+	// had been mapped.
+	ErrTruncated = errors.New("mmap: file truncated while mapped")
+)
+
+// Advice is a hint about how the mapped memory will be accessed.
+type Advice int
+
+const (
+	// Normal means no special treatment.
+	Normal Advice = iota
+
+	// Sequential means pages will be accessed in sequential order.
+	Sequential
+
+	// Random means pages will be accessed in random order.
+	Random
+
+	// WillNeed means pages will be accessed in the near future.
+	WillNeed
+
+	// DontNeed means pages will not be accessed in the near future.
+	DontNeed
+)
+
+// -----------------------------------------------------------------------------
+
+// ReaderAt reads a memory-mapped file.
+type ReaderAt struct {
+	data   []byte
+	unmap  func([]byte) error
+	closed bool
+}
+
+// Open memory-maps the named file for reading.
+func Open(filename string) (*ReaderAt, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewReaderAt(f)
+}
+
+// NewReaderAt memory-maps f for reading. The mapping stays valid after f is
+// closed.
+func NewReaderAt(f *os.File) (*ReaderAt, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if size == 0 {
+		return &ReaderAt{}, nil
+	}
+	if int64(int(size)) != size || size < 0 {
+		return nil, errors.New("mmap: file is too large")
+	}
+	data, unmap, err := mmap(f, int(size))
+	if err != nil {
+		return nil, &os.PathError{Op: "mmap", Path: f.Name(), Err: err}
+	}
+	return &ReaderAt{data: data, unmap: unmap}, nil
+}
+
+// Len returns the length of the mapped file.
+func (r *ReaderAt) Len() int {
+	return len(r.data)
+}
+
+// Bytes returns the mapped memory. The returned slice must not be modified
+// and must not be used after Close.
+func (r *ReaderAt) Bytes() []byte {
+	return r.data
+}
+
+// At returns the byte at index i.
+func (r *ReaderAt) At(i int) (b byte, err error) {
+	if r.closed {
+		return 0, ErrClosed
+	}
+	if i < 0 || i >= len(r.data) {
+		return 0, io.EOF
+	}
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer recoverFault(&err)
+	return r.data[i], nil
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (r *ReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if r.closed {
+		return 0, ErrClosed
+	}
+	if off < 0 || int64(len(r.data)) < off {
+		return 0, errors.New("mmap: invalid ReadAt offset")
+	}
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer recoverFault(&err)
+	n = copy(p, r.data[off:])
+	if n < len(p) {
+		err = io.EOF
+	}
+	return
+}
+
+// Advise gives the kernel a hint about how the mapped memory will be
+// accessed. It is a no-op on platforms that don't support it.
+func (r *ReaderAt) Advise(advice Advice) error {
+	if r.closed {
+		return ErrClosed
+	}
+	if len(r.data) == 0 {
+		return nil
+	}
+	return madvise(r.data, advice)
+}
+
+// Close unmaps the file.
+func (r *ReaderAt) Close() error {
+	if r.closed {
+		return ErrClosed
+	}
+	r.closed = true
+	data := r.data
+	r.data = nil
+	if data == nil || r.unmap == nil {
+		return nil
+	}
+	return r.unmap(data)
+}
+
+// recoverFault turns the fault of accessing a page beyond the end of a
+// truncated file into ErrTruncated.
+func recoverFault(err *error) {
+	if e := recover(); e != nil {
+		if _, ok := e.(interface{ Addr() uintptr }); ok {
+			*err = ErrTruncated
+			return
+		}
+		panic(e)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,69 @@
+package mmap
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReaderAt(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data")
+	ioutil.WriteFile(name, []byte("hello, world"), 0644)
+	r, err := Open(name)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if r.Len() != 12 || string(r.Bytes()) != "hello, world" {
+		t.Fatal("Bytes:", string(r.Bytes()))
+	}
+	if err = r.Advise(Sequential); err != nil {
+		t.Fatal("Advise:", err)
+	}
+	b := make([]byte, 5)
+	if n, err := r.ReadAt(b, 7); n != 5 || err != nil || string(b) != "world" {
+		t.Fatal("ReadAt:", n, err, string(b))
+	}
+	if n, err := r.ReadAt(b, 10); n != 2 || err != io.EOF {
+		t.Fatal("ReadAt EOF:", n, err)
+	}
+	if c, err := r.At(4); c != 'o' || err != nil {
+		t.Fatal("At:", c, err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal("Close:", err)
+	}
+	if _, err = r.ReadAt(b, 0); err != ErrClosed {
+		t.Fatal("ReadAt after Close:", err)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "empty")
+	ioutil.WriteFile(name, nil, 0644)
+	r, err := Open(name)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	defer r.Close()
+	if n, err := r.ReadAt(make([]byte, 1), 0); n != 0 || err != io.EOF {
+		t.Fatal("ReadAt:", n, err)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data")
+	ioutil.WriteFile(name, make([]byte, 3*os.Getpagesize()), 0644)
+	r, err := Open(name)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	defer r.Close()
+	if err = os.Truncate(name, 0); err != nil {
+		t.Skip("Truncate:", err)
+	}
+	if _, err = r.ReadAt(make([]byte, 1), int64(2*os.Getpagesize())); err != ErrTruncated {
+		t.Fatal("ReadAt truncated:", err)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package netx provides networking helpers, such as a caching DNS resolver.
+package netx
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/qiniu/x/objcache"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	// DefaultTTL is the default time a successful lookup is cached.
+	DefaultTTL = 30 * time.Second
+
+	// DefaultNegativeTTL is the default time a lookup of a host that
+	// doesn't exist is cached.
+	DefaultNegativeTTL = 5 * time.Second
+)
+
+// ResolverOptions configures a Resolver.
+type ResolverOptions struct {
+	// Resolver does the lookups. Nil means net.DefaultResolver.
+	Resolver *net.Resolver
+
+	// Dialer dials the connections of DialContext. Nil means a zero
+	// net.Dialer.
+	Dialer *net.Dialer
+
+	// MaxEntries is the max number of cached hosts. Zero means no limit.
+	MaxEntries int
+
+	// TTL is how long successful lookups are cached. net.Resolver doesn't
+	// report the TTLs of the DNS records, so they are configured here.
+	// Zero or negative means DefaultTTL.
+	TTL time.Duration
+
+	// NegativeTTL is how long lookups of hosts that don't exist (NXDOMAIN)
+	// are cached. Zero means DefaultNegativeTTL, negative means they aren't
+	// cached. Other errors, such as timeouts, are never cached.
+	NegativeTTL time.Duration
+}
+
+type dnsEntry struct {
+	addrs []string
+	err   error // a not found error
+	ttl   time.Duration
+}
+
+// TTL implements objcache.TTLer, so that the group expires the entry.
+func (e *dnsEntry) TTL() time.Duration {
+	return e.ttl
+}
+
+// Resolver is a caching DNS resolver. Its lookups are cached in an objcache
+// group, so concurrent lookups of the same host share a single query. It
+// is safe for concurrent use.
+type Resolver struct {
+	group  *objcache.Group
+	dialer *net.Dialer
+	ttl    time.Duration
+	negTTL time.Duration
+
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+// NewResolver creates a caching resolver whose cache is the objcache group
+// name, which must be unique.
+func NewResolver(name string, opts *ResolverOptions) *Resolver {
+	if opts == nil {
+		opts = new(ResolverOptions)
+	}
+	r := &Resolver{
+		dialer: opts.Dialer,
+		ttl:    opts.TTL,
+		negTTL: opts.NegativeTTL,
+	}
+	if r.dialer == nil {
+		r.dialer = new(net.Dialer)
+	}
+	if r.ttl <= 0 {
+		r.ttl = DefaultTTL
+	}
+	if r.negTTL == 0 {
+		r.negTTL = DefaultNegativeTTL
+	}
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	r.lookupHost = resolver.LookupHost
+	r.group = objcache.NewGroup(name, opts.MaxEntries, r.load)
+	return r
+}
+
+// Group returns the objcache group of the cached lookups.
+func (r *Resolver) Group() *objcache.Group {
+	return r.group
+}
+
+// LookupHost looks host up, and returns its addresses. An IP address is
+// returned as is.
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	v, err := r.group.Get(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	e := v.(*dnsEntry)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.addrs, nil
+}
+
+// load is the getter of the group.
+func (r *Resolver) load(ctx objcache.Context, key objcache.Key) (objcache.Value, error) {
+	return r.lookup(contextOf(ctx), key.(string))
+}
+
+func (r *Resolver) lookup(ctx context.Context, host string) (*dnsEntry, error) {
+	addrs, err := r.lookupHost(ctx, host)
+	if err != nil {
+		if de, ok := err.(*net.DNSError); ok && de.IsNotFound && r.negTTL > 0 {
+			return &dnsEntry{err: err, ttl: r.negTTL}, nil
+		}
+		return nil, err
+	}
+	return &dnsEntry{addrs: addrs, ttl: r.ttl}, nil
+}
+
+func contextOf(ctx objcache.Context) context.Context {
+	if c, ok := ctx.(context.Context); ok {
+		return c
+	}
+	return context.Background()
+}
+
+// DialContext connects to address on the named network, as
+// net.Dialer.DialContext does, resolving the host of address by r. The
+// addresses of the host are tried in order until one connects.
+func (r *Resolver) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := r.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var firstErr error
+	for _, addr := range addrs {
+		conn, err := r.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// -----------------------------------------------------------------------------
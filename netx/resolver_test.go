@@ -0,0 +1,114 @@
+package netx
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qiniu/x/objcache"
+	"github.com/qiniu/x/objcache/cachetest"
+)
+
+func newTestResolver(t *testing.T, name string, opts *ResolverOptions) (*Resolver, *int32, *cachetest.FakeClock) {
+	r := NewResolver(name, opts)
+	t.Cleanup(func() { objcache.RemoveGroup(name) })
+	var lookups int32
+	clock := cachetest.NewFakeClock(time.Now())
+	r.Group().SetClock(clock)
+	r.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		time.Sleep(10 * time.Millisecond)
+		switch host {
+		case "localhost":
+			return []string{"127.0.0.1"}, nil
+		case "timeout.test":
+			return nil, &net.DNSError{Err: "i/o timeout", Name: host, IsTimeout: true}
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return r, &lookups, clock
+}
+
+func TestResolver(t *testing.T) {
+	r, lookups, clock := newTestResolver(t, "netx-test-resolver", nil)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if addrs, err := r.LookupHost(ctx, "localhost"); err != nil || addrs[0] != "127.0.0.1" {
+				t.Error("LookupHost:", addrs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if *lookups != 1 {
+		t.Fatal("concurrent lookups not shared:", *lookups)
+	}
+	clock.Advance(DefaultTTL + time.Second)
+	r.LookupHost(ctx, "localhost")
+	if *lookups != 2 {
+		t.Fatal("expired entry not looked up again")
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.LookupHost(ctx, "nx.test"); err == nil || !err.(*net.DNSError).IsNotFound {
+			t.Fatal("LookupHost(nx.test):", err)
+		}
+	}
+	if *lookups != 3 {
+		t.Fatal("NXDOMAIN not cached:", *lookups)
+	}
+	r.LookupHost(ctx, "timeout.test")
+	r.LookupHost(ctx, "timeout.test")
+	if *lookups != 5 {
+		t.Fatal("timeout cached:", *lookups)
+	}
+	if addrs, _ := r.LookupHost(ctx, "10.0.0.1"); len(addrs) != 1 || *lookups != 5 {
+		t.Fatal("IP address looked up")
+	}
+}
+
+func TestResolverNegativeTTL(t *testing.T) {
+	r, lookups, clock := newTestResolver(t, "netx-test-negative-ttl", &ResolverOptions{TTL: -time.Second})
+	for i := 0; i < 3; i++ {
+		if addrs, err := r.LookupHost(context.Background(), "localhost"); err != nil || addrs[0] != "127.0.0.1" {
+			t.Fatal("LookupHost:", addrs, err)
+		}
+	}
+	if *lookups != 1 {
+		t.Fatal("lookups with a negative TTL:", *lookups)
+	}
+	clock.Advance(DefaultTTL + time.Second)
+	r.LookupHost(context.Background(), "localhost")
+	if *lookups != 2 {
+		t.Fatal("a negative TTL isn't DefaultTTL:", *lookups)
+	}
+}
+
+func TestResolverDial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	defer ln.Close()
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+	r, _, _ := newTestResolver(t, "netx-test-dial", nil)
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	c, err := r.DialContext(context.Background(), "tcp", net.JoinHostPort("localhost", port))
+	if err != nil {
+		t.Fatal("DialContext:", err)
+	}
+	c.Close()
+	if _, err = r.DialContext(context.Background(), "tcp", "nx.test:80"); err == nil {
+		t.Fatal("DialContext of an unknown host")
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+// A Future is the pending result of a Group.GetAsync.
+type Future struct {
+	done chan struct{}
+	val  Value
+	err  error
+}
+
+// Wait waits for the result of the Get. It may be called several times, by
+// several goroutines.
+func (f *Future) Wait() (Value, error) {
+	<-f.done
+	return f.val, f.err
+}
+
+// Done returns a channel closed once the result is ready, e.g. to select on
+// several futures.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// GetAsync is Get that doesn't wait for a load: it returns a Future of its
+// result, e.g. to start the loads of several keys and then join them. A
+// cached key returns a ready Future; a missing one shares the load of the
+// concurrent Gets of the key, as Get does.
+func (g *Group) GetAsync(ctx Context, key Key) *Future {
+	f := &Future{done: make(chan struct{})}
+	if val, hit, err := g.getCached(ctx, key); hit {
+		f.val, f.err = val, err
+		close(f.done)
+		return f
+	}
+	go func() {
+		f.val, f.err = g.fill(ctx, key, nil)
+		close(f.done)
+	}()
+	return f
+}
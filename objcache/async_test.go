@@ -0,0 +1,46 @@
+package objcache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetAsync(t *testing.T) {
+	errBad := errors.New("bad")
+	release := make(chan struct{})
+	var loads int64
+	g := NewGroup("async-group", 0, func(ctx Context, key Key) (Value, error) {
+		atomic.AddInt64(&loads, 1)
+		<-release
+		if key == "bad" {
+			return nil, errBad
+		}
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("async-group") })
+	a1, a2, bad := g.GetAsync(nil, "a"), g.GetAsync(nil, "a"), g.GetAsync(nil, "bad")
+	select {
+	case <-a1.Done():
+		t.Fatal("future ready before the load")
+	default:
+	}
+	close(release)
+	for _, f := range []*Future{a1, a2} {
+		if v, err := f.Wait(); err != nil || v != "a" {
+			t.Fatal("Wait:", v, err)
+		}
+	}
+	if _, err := bad.Wait(); err != errBad {
+		t.Fatal("Wait of a failed load:", err)
+	}
+	if v, _ := g.Get(nil, "a"); v != "a" || atomic.LoadInt64(&loads) != 2 {
+		t.Fatal("loads:", loads)
+	}
+	f := g.GetAsync(nil, "a")
+	select {
+	case <-f.Done():
+	default:
+		t.Fatal("future of a cached key not ready")
+	}
+}
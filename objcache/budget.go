@@ -0,0 +1,169 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// -----------------------------------------------------------------------------
+
+// Budget is a byte budget shared by several groups, so that the memory used
+// by all their values (see Sizer) is bounded as a whole rather than group by
+// group, see Group.SetBudget. When the budget is exceeded, entries are
+// evicted from the group holding the most bytes, from the least recently
+// used one of its shards in turn. The groups may also keep budgets of their
+// own, see Group.SetMaxBytes.
+type Budget struct {
+	nbytes int64 // atomic
+	max    int64 // atomic, 0 if none
+
+	mu     sync.Mutex
+	caches []*cache
+}
+
+// NewBudget creates a budget of maxBytes, 0 for no limit.
+func NewBudget(maxBytes int64) *Budget {
+	return &Budget{max: maxBytes}
+}
+
+// Bytes returns the total size of the values of the groups sharing b.
+func (b *Budget) Bytes() int64 {
+	return atomic.LoadInt64(&b.nbytes)
+}
+
+// MaxBytes returns the size of b, 0 if there is no limit.
+func (b *Budget) MaxBytes() int64 {
+	return atomic.LoadInt64(&b.max)
+}
+
+// SetMaxBytes changes the size of b, evicting entries if it is exceeded.
+func (b *Budget) SetMaxBytes(n int64) {
+	atomic.StoreInt64(&b.max, n)
+	b.prune(nil, 0)
+}
+
+func (b *Budget) add(n int64) {
+	atomic.AddInt64(&b.nbytes, n)
+}
+
+func (b *Budget) over() bool {
+	max := atomic.LoadInt64(&b.max)
+	return max > 0 && atomic.LoadInt64(&b.nbytes) > max
+}
+
+func (b *Budget) attach(c *cache) {
+	b.mu.Lock()
+	b.caches = append(b.caches, c)
+	b.mu.Unlock()
+	b.add(atomic.LoadInt64(&c.budget.nbytes))
+}
+
+func (b *Budget) detach(c *cache) {
+	b.mu.Lock()
+	for i, p := range b.caches {
+		if p == c {
+			b.caches = append(b.caches[:i], b.caches[i+1:]...)
+			break
+		}
+	}
+	b.mu.Unlock()
+	b.add(-atomic.LoadInt64(&c.budget.nbytes))
+}
+
+// prune evicts entries until b is met, from the cache holding the most bytes
+// each time. from is the cache an entry was just added to, in its i-th
+// shard, if any: that shard is evicted from last, to keep the new entry.
+func (b *Budget) prune(from *cache, i int) {
+	b.mu.Lock()
+	caches := append([]*cache(nil), b.caches...)
+	b.mu.Unlock()
+	for b.over() && len(caches) > 0 {
+		k := 0
+		for j, c := range caches {
+			if atomic.LoadInt64(&c.budget.nbytes) > atomic.LoadInt64(&caches[k].budget.nbytes) {
+				k = j
+			}
+		}
+		c := caches[k]
+		start := int(atomic.AddUint32(&c.evictNext, 1))
+		if c == from {
+			start = i + 1
+		}
+		if !c.evictOldest(start) {
+			// nothing left to evict there
+			caches = append(caches[:k], caches[k+1:]...)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// SetBudget makes the group share b with other groups, nil for none: the
+// values of both tiers of its cache count toward b, see Budget. It must be
+// called before the group is used.
+func (g *Group) SetBudget(b *Budget) {
+	g.mainCache.setShared(b)
+	g.hotCache.setShared(b)
+}
+
+// Budget returns the budget the group shares, nil if none.
+func (g *Group) Budget() *Budget {
+	return g.mainCache.budget.shared
+}
+
+func (c *cache) setShared(b *Budget) {
+	old := c.budget.shared
+	if old == b {
+		return
+	}
+	if old != nil {
+		old.detach(c)
+	}
+	c.budget.shared = b
+	if b != nil {
+		b.attach(c)
+		if b.over() {
+			b.prune(nil, 0)
+		}
+	}
+}
+
+// evictOldest evicts the least recently used entry of a shard, trying the
+// shards in turn from the start-th one, and reports whether there was one.
+func (c *cache) evictOldest(start int) bool {
+	n := len(c.shards)
+	for j := 0; j < n; j++ {
+		if c.shards[(start+j)%n].evictOldest() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *shard) evictOldest() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru.Len() == 0 {
+		return false
+	}
+	c.lru.RemoveOldest()
+	return true
+}
+
+// -----------------------------------------------------------------------------
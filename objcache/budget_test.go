@@ -0,0 +1,50 @@
+package objcache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(100)
+	getter := func(ctx Context, key Key) (Value, error) {
+		return strings.Repeat("x", 10), nil
+	}
+	g1 := NewGroupWith("budget-group-1", getter, WithMaxItems(100), WithBudget(b))
+	t.Cleanup(func() { RemoveGroup("budget-group-1") })
+	g2 := NewGroupWith("budget-group-2", getter, WithMaxItems(100), WithBudget(b))
+	t.Cleanup(func() { RemoveGroup("budget-group-2") })
+	if g1.Budget() != b {
+		t.Fatal("Budget")
+	}
+	for i := 0; i < 6; i++ {
+		g1.Get(nil, i)
+	}
+	for i := 0; i < 4; i++ {
+		g2.Get(nil, i)
+	}
+	if b.Bytes() != 100 {
+		t.Fatal("Bytes:", b.Bytes())
+	}
+	// g1 holds the most bytes: its oldest entry goes
+	g2.Get(nil, 4)
+	if b.Bytes() != 100 || g1.CacheStats().Items != 5 || g2.CacheStats().Items != 5 {
+		t.Fatal("cross-group eviction:", b.Bytes(), g1.CacheStats(), g2.CacheStats())
+	}
+	if _, ok := g1.Peek(0); ok {
+		t.Fatal("oldest entry of g1 kept")
+	}
+	g2.Remove(1)
+	if b.Bytes() != 90 {
+		t.Fatal("Bytes after Remove:", b.Bytes())
+	}
+
+	b.SetMaxBytes(50)
+	if b.Bytes() > 50 || g1.CacheStats().Bytes+g2.CacheStats().Bytes != b.Bytes() {
+		t.Fatal("SetMaxBytes:", b.Bytes(), g1.CacheStats(), g2.CacheStats())
+	}
+	g1.SetBudget(nil)
+	if b.Bytes() != g2.CacheStats().Bytes {
+		t.Fatal("SetBudget(nil):", b.Bytes())
+	}
+}
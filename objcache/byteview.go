@@ -0,0 +1,86 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"bytes"
+	"io"
+)
+
+// -----------------------------------------------------------------------------
+
+// A ByteView is an immutable view of bytes, the value cached by a
+// CodecGroup. Its size counts toward the byte budget of the cache (it is a
+// Sizer), and it is encoded as is for peers, snapshots and secondary stores
+// (it is an encoding.BinaryMarshaler).
+type ByteView struct {
+	b []byte
+}
+
+// NewByteView returns a view of a copy of b.
+func NewByteView(b []byte) ByteView {
+	return ByteView{append([]byte(nil), b...)}
+}
+
+// Len returns the length of the view.
+func (v ByteView) Len() int {
+	return len(v.b)
+}
+
+// Size implements Sizer.
+func (v ByteView) Size() int64 {
+	return int64(len(v.b))
+}
+
+// ByteSlice returns a copy of the bytes.
+func (v ByteView) ByteSlice() []byte {
+	return append([]byte(nil), v.b...)
+}
+
+// String returns the bytes as a string.
+func (v ByteView) String() string {
+	return string(v.b)
+}
+
+// At returns the i-th byte.
+func (v ByteView) At(i int) byte {
+	return v.b[i]
+}
+
+// Equal reports whether v and w hold the same bytes.
+func (v ByteView) Equal(w ByteView) bool {
+	return bytes.Equal(v.b, w.b)
+}
+
+// Reader returns a reader of the bytes.
+func (v ByteView) Reader() io.ReadSeeker {
+	return bytes.NewReader(v.b)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (v ByteView) MarshalBinary() ([]byte, error) {
+	return v.ByteSlice(), nil
+}
+
+// DecodeByteView is a DecodeFunc of ByteViews, e.g. to load the snapshot of
+// a CodecGroup (see Group.LoadFrom) or to read its secondary store (see
+// Group.SetSecondary).
+func DecodeByteView(key string, data []byte) (Value, error) {
+	return NewByteView(data), nil
+}
+
+// -----------------------------------------------------------------------------
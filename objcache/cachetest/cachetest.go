@@ -0,0 +1,435 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package cachetest provides conformance test suites for implementations of
+// the extension points of objcache: getters, secondary stores, eviction
+// policies and peer pickers. A suite is run from a test of the implementation:
+//
+//	func TestConformance(t *testing.T) {
+//		cachetest.TestPolicy(t, mypolicy.New)
+//	}
+//
+// FakeClock is a clock for the tests of groups that depend on time.
+package cachetest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/qiniu/x/objcache"
+)
+
+// -----------------------------------------------------------------------------
+
+// TestPolicy checks that the policies created by newPolicy obey the Policy
+// contract: they hold at most maxEntries entries, never return a removed or
+// evicted entry, call onEvicted exactly once for each entry that leaves
+// them (including by Remove and Clear) and never for a replaced value,
+// enumerate exactly their entries if they implement objcache.Ranger, and
+// look them up if they implement objcache.Peeker.
+func TestPolicy(t *testing.T, newPolicy objcache.NewPolicyFunc) {
+	t.Run("Capacity", func(t *testing.T) {
+		ev := newEvictions()
+		p := newPolicy(10, ev.onEvicted)
+		for i := 0; i < 100; i++ {
+			p.Add(i, i)
+			if p.Len() > 10 {
+				t.Fatalf("Len = %d after %d adds, want at most 10", p.Len(), i+1)
+			}
+		}
+		present := 0
+		for i := 0; i < 100; i++ {
+			v, ok := p.Get(i)
+			if ok {
+				present++
+				if v != i {
+					t.Fatalf("Get(%d) = %v", i, v)
+				}
+				if ev.count(i) != 0 {
+					t.Fatalf("Get(%d) returned an evicted entry", i)
+				}
+			} else if ev.count(i) != 1 {
+				t.Fatalf("entry %d is gone, evicted %d times", i, ev.count(i))
+			}
+		}
+		if present != p.Len() {
+			t.Fatalf("Len = %d, but %d entries are present", p.Len(), present)
+		}
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		ev := newEvictions()
+		p := newPolicy(0, ev.onEvicted)
+		p.Add("k", 1)
+		p.Add("k", 2)
+		if v, ok := p.Get("k"); !ok || v != 2 {
+			t.Fatalf("Get after replace = %v, %v", v, ok)
+		}
+		if p.Len() != 1 || ev.total() != 0 {
+			t.Fatalf("replace: Len = %d, %d evictions", p.Len(), ev.total())
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		ev := newEvictions()
+		p := newPolicy(0, ev.onEvicted)
+		for i := 0; i < 10; i++ {
+			p.Add(i, i)
+		}
+		p.Remove(3)
+		p.Remove(3)
+		p.Remove("absent")
+		if _, ok := p.Get(3); ok {
+			t.Fatal("Get returned a removed entry")
+		}
+		if ev.count(3) != 1 || ev.total() != 1 || p.Len() != 9 {
+			t.Fatalf("Remove: %d evictions of the key, %d in total, Len = %d", ev.count(3), ev.total(), p.Len())
+		}
+		for p.Len() > 0 {
+			n := p.Len()
+			p.RemoveOldest()
+			if p.Len() != n-1 {
+				t.Fatal("RemoveOldest didn't remove one entry")
+			}
+		}
+		p.RemoveOldest()
+		if ev.total() != 10 {
+			t.Fatalf("%d evictions, want 10", ev.total())
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		ev := newEvictions()
+		p := newPolicy(0, ev.onEvicted)
+		for i := 0; i < 10; i++ {
+			p.Add(i, i)
+		}
+		p.Clear()
+		if p.Len() != 0 || ev.total() != 10 {
+			t.Fatalf("Clear: Len = %d, %d evictions", p.Len(), ev.total())
+		}
+		for i := 0; i < 10; i++ {
+			if _, ok := p.Get(i); ok {
+				t.Fatal("Get returned a cleared entry")
+			}
+		}
+		p.Add("again", 1)
+		if v, ok := p.Get("again"); !ok || v != 1 {
+			t.Fatal("Add after Clear failed")
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		p := newPolicy(0, nil)
+		r, ok := p.(objcache.Ranger)
+		if !ok {
+			t.Skip("policy doesn't implement Ranger")
+		}
+		for i := 0; i < 10; i++ {
+			p.Add(i, i*10)
+		}
+		p.Remove(5)
+		seen := make(map[objcache.Key]bool)
+		r.Range(func(key objcache.Key, value objcache.Value) bool {
+			if seen[key] {
+				t.Fatalf("Range visits %v twice", key)
+			}
+			seen[key] = true
+			if value != key.(int)*10 {
+				t.Fatalf("Range: %v => %v", key, value)
+			}
+			return true
+		})
+		if len(seen) != 9 || seen[5] {
+			t.Fatalf("Range visited %v", seen)
+		}
+		n := 0
+		r.Range(func(key objcache.Key, value objcache.Value) bool {
+			n++
+			return false
+		})
+		if n != 1 {
+			t.Fatal("Range doesn't stop when fn returns false")
+		}
+	})
+
+	t.Run("Peek", func(t *testing.T) {
+		p := newPolicy(0, nil)
+		pk, ok := p.(objcache.Peeker)
+		if !ok {
+			t.Skip("policy doesn't implement Peeker")
+		}
+		for i := 0; i < 10; i++ {
+			p.Add(i, i*10)
+		}
+		p.Remove(5)
+		for i := 0; i < 10; i++ {
+			v, ok := pk.Peek(i)
+			if ok != (i != 5) || (ok && v != i*10) {
+				t.Fatalf("Peek(%d) = %v, %v", i, v, ok)
+			}
+		}
+		if _, ok := pk.Peek("missing"); ok {
+			t.Fatal("Peek of a missing key: ok")
+		}
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		ev := newEvictions()
+		p := newPolicy(10, ev.onEvicted)
+		r, ok := p.(objcache.Resizer)
+		if !ok {
+			t.Skip("policy doesn't implement Resizer")
+		}
+		for i := 0; i < 10; i++ {
+			p.Add(i, i)
+		}
+		r.SetMaxEntries(20)
+		for i := 10; i < 20; i++ {
+			p.Add(i, i)
+		}
+		if p.Len() != 20 || ev.total() != 0 {
+			t.Fatalf("after growing: Len = %d, %d evictions", p.Len(), ev.total())
+		}
+		r.SetMaxEntries(5)
+		p.Add(20, 20)
+		for p.Len() > 5 {
+			p.RemoveOldest()
+		}
+		p.Add(21, 21)
+		if p.Len() > 5 {
+			t.Fatalf("after shrinking: Len = %d", p.Len())
+		}
+	})
+}
+
+type evictions struct {
+	mu sync.Mutex
+	n  map[objcache.Key]int
+}
+
+func newEvictions() *evictions {
+	return &evictions{n: make(map[objcache.Key]int)}
+}
+
+func (e *evictions) onEvicted(key objcache.Key, value objcache.Value) {
+	e.mu.Lock()
+	e.n[key]++
+	e.mu.Unlock()
+}
+
+func (e *evictions) count(key objcache.Key) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.n[key]
+}
+
+func (e *evictions) total() (n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, c := range e.n {
+		n += c
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
+
+// TestSecondaryStore checks that the stores created by newStore obey the
+// SecondaryStore contract: Get returns what was last Set, or ErrNotFound
+// (possibly wrapped) for absent and deleted keys, deleting an absent key is
+// not an error, and the store is safe for concurrent use. Each call of
+// newStore must return an empty store.
+func TestSecondaryStore(t *testing.T, newStore func(t *testing.T) objcache.SecondaryStore) {
+	t.Run("SetGetDelete", func(t *testing.T) {
+		s := newStore(t)
+		if _, err := s.Get("absent"); !isNotFound(err) {
+			t.Fatalf("Get(absent): err = %v, want ErrNotFound", err)
+		}
+		for _, data := range [][]byte{[]byte("v1"), []byte("version 2"), {}} {
+			if err := s.Set("key", data); err != nil {
+				t.Fatal("Set:", err)
+			}
+			got, err := s.Get("key")
+			if err != nil || string(got) != string(data) {
+				t.Fatalf("Get = %q, %v; want %q", got, err, data)
+			}
+		}
+		if err := s.Delete("key"); err != nil {
+			t.Fatal("Delete:", err)
+		}
+		if _, err := s.Get("key"); !isNotFound(err) {
+			t.Fatalf("Get after Delete: err = %v, want ErrNotFound", err)
+		}
+		if err := s.Delete("key"); err != nil {
+			t.Fatal("Delete of an absent key:", err)
+		}
+	})
+
+	t.Run("Keys", func(t *testing.T) {
+		s := newStore(t)
+		keys := []string{"", "a", "a/b", "with space", "unicode-键", string(make([]byte, 300))}
+		for i, k := range keys {
+			if err := s.Set(k, []byte(fmt.Sprint(i))); err != nil {
+				t.Fatalf("Set(%q): %v", k, err)
+			}
+		}
+		for i, k := range keys {
+			if got, err := s.Get(k); err != nil || string(got) != fmt.Sprint(i) {
+				t.Fatalf("Get(%q) = %q, %v", k, got, err)
+			}
+		}
+	})
+
+	t.Run("NoAliasing", func(t *testing.T) {
+		s := newStore(t)
+		data := []byte("original")
+		s.Set("k", data)
+		copy(data, "modified")
+		if got, _ := s.Get("k"); string(got) != "original" {
+			t.Fatalf("the store keeps the caller's buffer: Get = %q", got)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		s := newStore(t)
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					key := fmt.Sprintf("k%d", i%10)
+					val := []byte(fmt.Sprintf("%s-%d-%d", key, w, i))
+					if err := s.Set(key, val); err != nil {
+						t.Error("Set:", err)
+						return
+					}
+					got, err := s.Get(key)
+					if err != nil && !isNotFound(err) {
+						t.Error("Get:", err)
+						return
+					}
+					if err == nil && (len(got) < len(key) || string(got[:len(key)]) != key) {
+						t.Errorf("Get(%s) = %q: torn or misplaced value", key, got)
+						return
+					}
+					if i%7 == 0 {
+						s.Delete(key)
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+	})
+}
+
+func isNotFound(err error) bool {
+	for err != nil {
+		if err == objcache.ErrNotFound {
+			return true
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
+
+// TestGetter checks that getter obeys the contract of a group getter for
+// keys: it is safe for concurrent use, it returns the same value for the
+// same key (as compared by reflect.DeepEqual), and a group built on it
+// returns what it returns.
+func TestGetter(t *testing.T, getter objcache.GetterFunc, ctx objcache.Context, keys []objcache.Key) {
+	want := make([]objcache.Value, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		want[i], errs[i] = getter(ctx, key)
+	}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i, key := range keys {
+					v, err := getter(ctx, key)
+					if (err == nil) != (errs[i] == nil) || (err == nil && !reflect.DeepEqual(v, want[i])) {
+						t.Errorf("getter(%v) = %v, %v; first returned %v, %v", key, v, err, want[i], errs[i])
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		g := objcache.NewGroup(fmt.Sprintf("cachetest-getter-%p-%s", t, t.Name()), 0, getter)
+		for round := 0; round < 2; round++ { // load, then hit
+			for i, key := range keys {
+				v, err := g.Get(ctx, key)
+				if (err == nil) != (errs[i] == nil) || (err == nil && !reflect.DeepEqual(v, want[i])) {
+					t.Fatalf("Group.Get(%v) = %v, %v; getter returned %v, %v", key, v, err, want[i], errs[i])
+				}
+			}
+		}
+	})
+}
+
+// -----------------------------------------------------------------------------
+
+// TestPeerPicker checks that picker obeys the PeerPicker contract for keys:
+// it is safe for concurrent use, and it picks the same owner for the same
+// key, as long as the peers don't change.
+func TestPeerPicker(t *testing.T, picker objcache.PeerPicker, keys []string) {
+	type owner struct {
+		peer objcache.ProtoGetter
+		ok   bool
+	}
+	want := make([]owner, len(keys))
+	for i, key := range keys {
+		want[i].peer, want[i].ok = picker.PickPeer(key)
+		if want[i].ok && want[i].peer == nil {
+			t.Fatalf("PickPeer(%q) = nil, true", key)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, key := range keys {
+				peer, ok := picker.PickPeer(key)
+				if ok != want[i].ok || (ok && peer != want[i].peer) {
+					t.Errorf("PickPeer(%q) = %v, %v; first returned %v, %v", key, peer, ok, want[i].peer, want[i].ok)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,126 @@
+package cachetest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qiniu/x/objcache"
+)
+
+func TestLRU(t *testing.T) {
+	TestPolicy(t, objcache.NewLRU)
+}
+
+type memStore struct {
+	mu sync.Mutex
+	m  map[string][]byte
+}
+
+func (s *memStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.m[key]
+	if !ok {
+		return nil, fmt.Errorf("memstore: %w", objcache.ErrNotFound)
+	}
+	return b, nil
+}
+
+func (s *memStore) Set(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *memStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+	return nil
+}
+
+func TestMemStore(t *testing.T) {
+	TestSecondaryStore(t, func(t *testing.T) objcache.SecondaryStore {
+		return &memStore{m: make(map[string][]byte)}
+	})
+}
+
+func TestEchoGetter(t *testing.T) {
+	errOdd := errors.New("odd")
+	TestGetter(t, func(ctx objcache.Context, key objcache.Key) (objcache.Value, error) {
+		if key.(int)%2 == 1 {
+			return nil, errOdd
+		}
+		return []int{key.(int)}, nil
+	}, nil, []objcache.Key{0, 1, 2, 3})
+}
+
+func TestHTTPPool(t *testing.T) {
+	pool := objcache.NewHTTPPool("http://a", &objcache.HTTPPoolOptions{Replicas: 10})
+	pool.Set("http://a", "http://b", "http://c")
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprint("key", i)
+	}
+	TestPeerPicker(t, pool, keys)
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := NewFakeClock(start)
+	t1 := clock.NewTimer(2 * time.Second)
+	t2 := clock.NewTimer(time.Second)
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-t2.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	clock.Advance(time.Second)
+	if at := <-t2.C(); !at.Equal(start.Add(time.Second)) {
+		t.Fatal("t2 fired at", at)
+	}
+	if !t1.Stop() || t1.Stop() || clock.Timers() != 0 {
+		t.Fatal("Stop")
+	}
+	clock.Advance(time.Hour)
+	select {
+	case <-t1.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	if t2.Reset(time.Second) {
+		t.Fatal("Reset of a fired timer")
+	}
+	clock.Advance(time.Second)
+	<-t2.C()
+
+	loads := 0
+	g := objcache.NewGroupWith("fake-clock-group", func(ctx objcache.Context, key objcache.Key) (objcache.Value, error) {
+		loads++
+		return loads, nil
+	}, objcache.WithClock(clock), objcache.WithTTL(time.Minute))
+	t.Cleanup(func() { objcache.RemoveGroup("fake-clock-group") })
+	get := func() int {
+		v, err := g.Get(nil, "k")
+		if err != nil {
+			t.Fatal("Get:", err)
+		}
+		return v.(int)
+	}
+	if get() != 1 {
+		t.Fatal("first load")
+	}
+	clock.Advance(59 * time.Second)
+	if get() != 1 {
+		t.Fatal("expired early")
+	}
+	clock.Advance(time.Second)
+	if get() != 2 {
+		t.Fatal("not expired")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// A Clock tells the time to a group and runs its timers, so that tests can
+// advance time deterministically, see Group.SetClock.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// A Timer of a Clock sends the time on its channel once it expires, as a
+// *time.Timer does.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// SystemClock is the clock of the system, the default one.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// SetClock replaces the clock of the group, which tells the time of the
+// TTLs, the refreshes, the stale values and the reload intervals, and runs
+// the timers of the janitor and of GetWithTimeout. It must be called before
+// the group is used.
+func (g *Group) SetClock(clock Clock) {
+	g.clock = clock
+}
+
+// -----------------------------------------------------------------------------
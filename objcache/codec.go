@@ -0,0 +1,128 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// -----------------------------------------------------------------------------
+
+// A Codec encodes the values of a CodecGroup to bytes, and back. Unmarshal
+// must neither modify nor keep data, which is cached.
+type Codec interface {
+	Marshal(value Value) ([]byte, error)
+	Unmarshal(data []byte) (Value, error)
+}
+
+// JSONCodec is a Codec of JSON. New returns the pointer a value is decoded
+// into, e.g. func() interface{} { return new(User) }.
+type JSONCodec struct {
+	New func() interface{}
+}
+
+// Marshal implements Codec.
+func (c JSONCodec) Marshal(value Value) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal implements Codec.
+func (c JSONCodec) Unmarshal(data []byte) (Value, error) {
+	v := c.New()
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// CodecGroup is a group that caches the values of its getter encoded by a
+// codec, as ByteViews, and hands decoded values to its callers: each Get
+// decodes a fresh value, that the caller may modify. As the cached values
+// are bytes, they can be fetched from peers and saved to snapshots or to a
+// secondary store (use DecodeByteView to read them back). The untyped Group
+// (see CodecGroup.Group) gives access to the rest of its API.
+type CodecGroup struct {
+	g     *Group
+	codec Codec
+}
+
+// NewCodecGroup creates a group whose getter values are encoded by codec,
+// see NewGroup.
+func NewCodecGroup(name string, cacheNum int, getter GetterFunc, codec Codec) *CodecGroup {
+	g := NewGroup(name, cacheNum, func(ctx Context, key Key) (Value, error) {
+		val, err := getter(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		data, err := codec.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		return ByteView{data}, nil
+	})
+	return &CodecGroup{g, codec}
+}
+
+// Group returns the untyped group, whose values are ByteViews (or []byte,
+// if fetched from a peer).
+func (p *CodecGroup) Group() *Group {
+	return p.g
+}
+
+// Name returns the name of the group.
+func (p *CodecGroup) Name() string {
+	return p.g.name
+}
+
+// Get returns the decoded value of key, see Group.Get.
+func (p *CodecGroup) Get(ctx Context, key Key) (Value, error) {
+	val, err := p.g.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return p.decode(val)
+}
+
+// Set encodes value and caches it under key, see Group.Set.
+func (p *CodecGroup) Set(key Key, value Value) error {
+	data, err := p.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	p.g.Set(key, ByteView{data})
+	return nil
+}
+
+// Remove removes key from the cache, see Group.Remove.
+func (p *CodecGroup) Remove(key Key) bool {
+	return p.g.Remove(key)
+}
+
+func (p *CodecGroup) decode(val Value) (Value, error) {
+	switch v := val.(type) {
+	case ByteView:
+		return p.codec.Unmarshal(v.b)
+	case []byte:
+		return p.codec.Unmarshal(v)
+	}
+	return nil, fmt.Errorf("objcache: group %s: cached value of type %T, want ByteView", p.g.name, val)
+}
+
+// -----------------------------------------------------------------------------
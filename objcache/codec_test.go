@@ -0,0 +1,52 @@
+package objcache
+
+import (
+	"bytes"
+	"testing"
+)
+
+type codecUser struct {
+	Name string
+	Age  int
+}
+
+func TestCodecGroup(t *testing.T) {
+	loads := 0
+	codec := JSONCodec{New: func() interface{} { return new(codecUser) }}
+	p := NewCodecGroup("codec-group", 0, func(ctx Context, key Key) (Value, error) {
+		loads++
+		return &codecUser{Name: key.(string), Age: 42}, nil
+	}, codec)
+	v, err := p.Get(nil, "alice")
+	if err != nil || *v.(*codecUser) != (codecUser{"alice", 42}) {
+		t.Fatal("Get:", v, err)
+	}
+	v.(*codecUser).Age = 0 // callers get their own copy
+	if v, _ = p.Get(nil, "alice"); v.(*codecUser).Age != 42 || loads != 1 {
+		t.Fatal("Get of a cached key:", v, loads)
+	}
+	if bv, ok := p.Group().TryGet("alice"); !ok || bv.(ByteView).String() != `{"Name":"alice","Age":42}` {
+		t.Fatal("cached value:", bv)
+	}
+	if s := p.Group().CacheStats(); s.Bytes != int64(len(`{"Name":"alice","Age":42}`)) {
+		t.Fatal("bytes:", s.Bytes)
+	}
+	if err = p.Set("bob", &codecUser{Name: "bob"}); err != nil {
+		t.Fatal("Set:", err)
+	}
+
+	var buf bytes.Buffer
+	if n, err := p.Group().SaveTo(&buf); err != nil || n != 2 {
+		t.Fatal("SaveTo:", n, err)
+	}
+	q := NewCodecGroup("codec-restored-group", 0, nil, codec)
+	if _, err := q.Group().LoadFrom(&buf, DecodeByteView); err != nil {
+		t.Fatal("LoadFrom:", err)
+	}
+	if v, err = q.Get(nil, "bob"); err != nil || v.(*codecUser).Name != "bob" {
+		t.Fatal("Get of a restored key:", v, err)
+	}
+	if v, err = p.decode([]byte(`{"Name":"carol"}`)); err != nil || v.(*codecUser).Name != "carol" {
+		t.Fatal("decode of a peer value:", v, err)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package consistenthash implements a ring hash: each node owns some points
+// of a hash circle, and a key belongs to the node owning the first point
+// after the hash of the key. Adding or removing one of n nodes only moves
+// about 1/n of the keys, so key ownership stays stable as peers join and
+// leave.
+package consistenthash
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// -----------------------------------------------------------------------------
+
+// Hash is a 32-bit hash function.
+type Hash func(data []byte) uint32
+
+// DefaultReplicas is a number of points per node that spreads the keys
+// evenly enough for a few dozen nodes.
+const DefaultReplicas = 50
+
+// Map is a ring hash of nodes. It is not safe for concurrent use.
+type Map struct {
+	hash     Hash
+	replicas int
+	hashes   []uint32 // sorted
+	owners   map[uint32]string
+}
+
+// New creates a ring hash giving replicas points to each node (at least 1),
+// placed by fn (crc32.ChecksumIEEE if nil).
+func New(replicas int, fn Hash) *Map {
+	if replicas <= 0 {
+		replicas = 1
+	}
+	if fn == nil {
+		fn = crc32.ChecksumIEEE
+	}
+	return &Map{hash: fn, replicas: replicas, owners: make(map[uint32]string)}
+}
+
+// IsEmpty reports whether there are no nodes.
+func (m *Map) IsEmpty() bool {
+	return len(m.hashes) == 0
+}
+
+// Add adds nodes.
+func (m *Map) Add(nodes ...string) {
+	for _, node := range nodes {
+		for i := 0; i < m.replicas; i++ {
+			h := m.hash([]byte(strconv.Itoa(i) + node))
+			if _, dup := m.owners[h]; !dup {
+				m.hashes = append(m.hashes, h)
+			}
+			m.owners[h] = node
+		}
+	}
+	sort.Slice(m.hashes, func(i, j int) bool { return m.hashes[i] < m.hashes[j] })
+}
+
+// Remove removes nodes.
+func (m *Map) Remove(nodes ...string) {
+	gone := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		gone[node] = true
+	}
+	hashes := m.hashes[:0]
+	for _, h := range m.hashes {
+		if gone[m.owners[h]] {
+			delete(m.owners, h)
+		} else {
+			hashes = append(hashes, h)
+		}
+	}
+	m.hashes = hashes
+}
+
+// Get returns the node owning key, "" if there are no nodes.
+func (m *Map) Get(key string) string {
+	if len(m.hashes) == 0 {
+		return ""
+	}
+	h := m.hash([]byte(key))
+	i := sort.Search(len(m.hashes), func(i int) bool { return m.hashes[i] >= h })
+	if i == len(m.hashes) {
+		i = 0
+	}
+	return m.owners[m.hashes[i]]
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,69 @@
+package consistenthash
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHashing(t *testing.T) {
+	// the hash of "<replica><node>" or of a key is its value as a number
+	m := New(3, func(data []byte) uint32 {
+		n, err := strconv.Atoi(string(data))
+		if err != nil {
+			panic(err)
+		}
+		return uint32(n)
+	})
+	if !m.IsEmpty() || m.Get("1") != "" {
+		t.Fatal("empty map")
+	}
+	// points 2, 12, 22, 4, 14, 24, 6, 16, 26
+	m.Add("6", "4", "2")
+	cases := map[string]string{"2": "2", "11": "2", "23": "4", "27": "2"}
+	for key, node := range cases {
+		if got := m.Get(key); got != node {
+			t.Fatalf("Get(%s) = %s, want %s", key, got, node)
+		}
+	}
+	// points 8, 18, 28 added
+	m.Add("8")
+	if got := m.Get("27"); got != "8" {
+		t.Fatal("Get(27) after Add:", got)
+	}
+	m.Remove("8", "2")
+	if got := m.Get("27"); got != "4" {
+		t.Fatal("Get(27) after Remove:", got)
+	}
+	if got := m.Get("11"); got != "4" {
+		t.Fatal("Get(11) after Remove:", got)
+	}
+}
+
+func TestStability(t *testing.T) {
+	m := New(DefaultReplicas, nil)
+	m.Add("a", "b", "c")
+	owners := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		owners[key] = m.Get(key)
+	}
+	m.Add("d")
+	moved := 0
+	for key, owner := range owners {
+		if now := m.Get(key); now != owner {
+			if now != "d" {
+				t.Fatal("key moved between old nodes:", key, owner, now)
+			}
+			moved++
+		}
+	}
+	if moved < 100 || moved > 400 {
+		t.Fatal("keys moved to the new node:", moved)
+	}
+	m.Remove("d")
+	for key, owner := range owners {
+		if now := m.Get(key); now != owner {
+			t.Fatal("key not back to its owner:", key, owner, now)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// -----------------------------------------------------------------------------
+
+// DebugGroup is the introspection data of a group served by DebugHandler.
+type DebugGroup struct {
+	*GroupVar
+
+	Capacity int     // max entries of the main cache, 0 if unlimited
+	MaxBytes int64   // max total size of the values, 0 if unlimited
+	HitRatio float64 // CacheHits / Gets
+
+	// HotKeys are the hottest keys of the group, if asked for: those of the
+	// hot cache, and then the most recently used ones of the main cache.
+	HotKeys []string `json:",omitempty"`
+}
+
+// Debug returns the introspection data of the group, with up to keys of its
+// hottest keys.
+func (g *Group) Debug(keys int) *DebugGroup {
+	v := g.Var()
+	d := &DebugGroup{
+		GroupVar: v,
+		Capacity: g.Capacity(),
+		MaxBytes: g.MaxBytes(),
+	}
+	if v.Gets > 0 {
+		d.HitRatio = float64(v.CacheHits) / float64(v.Gets)
+	}
+	if keys > 0 {
+		hot := g.hotCache.recentKeys(keys)
+		main := g.mainCache.recentKeys(keys - len(hot))
+		for _, key := range append(hot, main...) {
+			d.HotKeys = append(d.HotKeys, fmt.Sprint(key))
+		}
+	}
+	return d
+}
+
+// DebugHandler returns an http.Handler serving the introspection data of all
+// the groups as a JSON object mapping the name of each group to its
+// DebugGroup, e.g. to be mounted under /debug/objcache:
+//
+//	http.Handle("/debug/objcache", objcache.DebugHandler())
+//
+// The query parameter group selects a single group, served as a DebugGroup,
+// and keys=n asks for n of the hottest keys of each group.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(serveDebug)
+}
+
+func serveDebug(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	keys := 0
+	if s := q.Get("keys"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, "bad keys: "+s, http.StatusBadRequest)
+			return
+		}
+		keys = n
+	}
+	var ret interface{}
+	if name := q.Get("group"); name != "" {
+		g := GetGroup(name)
+		if g == nil {
+			http.Error(w, "no such group: "+name, http.StatusNotFound)
+			return
+		}
+		ret = g.Debug(keys)
+	} else {
+		all := make(map[string]*DebugGroup)
+		for _, g := range Groups() {
+			all[g.name] = g.Debug(keys)
+		}
+		ret = all
+	}
+	data, err := json.MarshalIndent(ret, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// -----------------------------------------------------------------------------
+
+// recentKeys returns up to n of the most recently used keys of the cache,
+// taking the most recently used ones of each shard in turn.
+func (c *cache) recentKeys(n int) (keys []Key) {
+	if n <= 0 {
+		return
+	}
+	per := make([][]Key, len(c.shards))
+	for i := range c.shards {
+		per[i] = c.shards[i].recentKeys(n)
+	}
+	for i := 0; len(keys) < n; i++ {
+		added := false
+		for _, ks := range per {
+			if i < len(ks) && len(keys) < n {
+				keys = append(keys, ks[i])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return
+}
+
+// recentKeys returns up to n of the most useful live keys of the policy of
+// the shard. It returns none if the policy isn't a Ranger.
+func (c *shard) recentKeys(n int) (keys []Key) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.lru.(Ranger)
+	if !ok {
+		return
+	}
+	now := c.now()
+	r.Range(func(key Key, value Value) bool {
+		if _, neg := value.(*negEntry); neg {
+			return true
+		}
+		if exp, ok := c.expires[key]; (!ok || now.Before(exp)) && !c.oldLocked(key) {
+			keys = append(keys, key)
+		}
+		return len(keys) < n
+	})
+	return
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,63 @@
+package objcache
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	g := NewGroup("debug-group", 100, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("debug-group") })
+	g.Get(nil, "a")
+	g.Get(nil, "b")
+	g.Get(nil, "b")
+	g.Get(nil, "c")
+	g.Get(nil, "b")
+
+	h := DebugHandler()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/objcache?group=debug-group&keys=2", nil))
+	if w.Code != 200 {
+		t.Fatal("ServeHTTP:", w.Code, w.Body.String())
+	}
+	var d struct {
+		Gets, CacheHits, Items int64
+		Capacity               int
+		HitRatio               float64
+		HotKeys                []string
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &d); err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if d.Gets != 5 || d.CacheHits != 2 || d.Items != 3 || d.Capacity != 100 || d.HitRatio != 0.4 {
+		t.Fatal("DebugGroup:", w.Body.String())
+	}
+	if !reflect.DeepEqual(d.HotKeys, []string{"b", "c"}) {
+		t.Fatal("HotKeys:", d.HotKeys)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/objcache", nil))
+	var all map[string]map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &all); err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if _, ok := all["debug-group"]; !ok {
+		t.Fatal("groups:", w.Body.String())
+	}
+	if _, ok := all["debug-group"]["HotKeys"]; ok {
+		t.Fatal("HotKeys not asked for:", all["debug-group"])
+	}
+
+	for _, url := range []string{"/debug/objcache?group=nope", "/debug/objcache?keys=x"} {
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		if w.Code == 200 {
+			t.Fatal("ServeHTTP", url, ":", w.Code)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+
+// depNode is a node of the dependency graph: either a key or a tag.
+type depNode struct {
+	key   Key
+	tag   string
+	isTag bool
+}
+
+// deps is the dependency graph of a group. An edge from a parent node to a
+// key means that invalidating the parent invalidates the key too.
+type deps struct {
+	mu         sync.Mutex
+	dependents map[depNode]map[Key]struct{} // parent => dependent keys
+	parents    map[Key][]depNode            // key => its parents
+}
+
+func (d *deps) add(key Key, parent depNode) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dependents == nil {
+		d.dependents = make(map[depNode]map[Key]struct{})
+		d.parents = make(map[Key][]depNode)
+	}
+	children, ok := d.dependents[parent]
+	if !ok {
+		children = make(map[Key]struct{})
+		d.dependents[parent] = children
+	}
+	if _, ok = children[key]; !ok {
+		children[key] = struct{}{}
+		d.parents[key] = append(d.parents[key], parent)
+	}
+}
+
+// forget removes the edges from the parents of key to key, once key has
+// left the cache. Edges from key to its dependents are kept: a key that is
+// not cached can still be invalidated, e.g. when the source it stands for
+// changes.
+func (d *deps) forget(key Key) {
+	d.mu.Lock()
+	d.forgetLocked(key)
+	d.mu.Unlock()
+}
+
+func (d *deps) forgetLocked(key Key) {
+	for _, parent := range d.parents[key] {
+		children := d.dependents[parent]
+		delete(children, key)
+		if len(children) == 0 {
+			delete(d.dependents, parent)
+		}
+	}
+	delete(d.parents, key)
+}
+
+// has reports whether key depends on any node.
+func (d *deps) has(key Key) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.parents[key]) > 0
+}
+
+// closure returns the keys depending on root, directly or not, and forgets
+// their edges.
+func (d *deps) closure(root depNode) (keys []Key) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen := make(map[Key]bool)
+	todo := []depNode{root}
+	for len(todo) > 0 {
+		n := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		for key := range d.dependents[n] {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+				todo = append(todo, depNode{key: key})
+			}
+		}
+	}
+	for _, key := range keys {
+		d.forgetLocked(key)
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
+
+// DependOn records that the value of key is derived from the values of
+// parents: invalidating any of them invalidates key as well. Dependencies
+// are transitive. Getters of derived objects usually call DependOn for the
+// sources they read.
+//
+// The dependencies of key are dropped when key leaves the cache, so they
+// are to be registered each time its value is loaded.
+func (g *Group) DependOn(key Key, parents ...Key) {
+	for _, parent := range parents {
+		g.deps.add(key, depNode{key: parent})
+	}
+}
+
+// DependOnTag records that key depends on tags: invalidating any of them
+// (see InvalidateTag) invalidates key.
+func (g *Group) DependOnTag(key Key, tags ...string) {
+	for _, tag := range tags {
+		g.deps.add(key, depNode{tag: tag, isTag: true})
+	}
+}
+
+// SetWithTags adds value to the cache under key, tagged with tags, so that
+// whole categories of entries (e.g. the listings of one bucket) can be
+// removed by InvalidateTag without knowing their keys. It replaces the
+// previous value of key and its previous tags and dependencies. The tags of
+// value, if it is a Tagger, are added to tags.
+func (g *Group) SetWithTags(key Key, value Value, tags ...string) {
+	g.set(key, value, tags)
+}
+
+// set is Set, tagging key with tags too. The tags are registered before
+// value is added: an InvalidateTag in between drops them, and value is
+// removed then rather than cached untagged.
+func (g *Group) set(key Key, value Value, tags []string) {
+	g.flights.forget(key)
+	g.deps.forget(key)
+	g.hotCache.remove(key)
+	g.DependOnTag(key, tags...)
+	g.tag(key, value)
+	tagged := g.deps.has(key)
+	g.mainCache.add(key, value)
+	if tagged && !g.deps.has(key) {
+		g.Remove(key)
+		return
+	}
+	g.setSecondary(key, value)
+}
+
+// A Tagger is a value that knows the tags of its entry, e.g. the user it
+// was derived from: when the getter returns it, or it is passed to Set, its
+// key is tagged with them as by SetWithTags.
+type Tagger interface {
+	Tags() []string
+}
+
+// tag tags key with the tags of value, if it is a Tagger.
+func (g *Group) tag(key Key, value Value) {
+	if t, ok := value.(Tagger); ok {
+		g.DependOnTag(key, t.Tags()...)
+	}
+}
+
+// Invalidate removes key and all the keys depending on it from the cache.
+// It returns the number of dependent keys that were invalidated.
+func (g *Group) Invalidate(key Key) int {
+	keys := g.deps.closure(depNode{key: key})
+	g.deps.forget(key)
+	g.Remove(key)
+	for _, k := range keys {
+		g.Remove(k)
+	}
+	return len(keys)
+}
+
+// InvalidateTag removes all the keys depending on tag from the cache. It
+// returns the number of invalidated keys.
+func (g *Group) InvalidateTag(tag string) int {
+	keys := g.deps.closure(depNode{tag: tag, isTag: true})
+	for _, k := range keys {
+		g.Remove(k)
+	}
+	return len(keys)
+}
+
+// -----------------------------------------------------------------------------
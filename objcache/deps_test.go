@@ -0,0 +1,220 @@
+package objcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDependencies(t *testing.T) {
+	var evicted []Key
+	var g *Group
+	g = NewGroup("deps-group", 0, func(ctx Context, key Key) (Value, error) {
+		switch k := key.(string); k {
+		case "page":
+			g.DependOn(k, "thumb", "text")
+		case "thumb":
+			g.DependOn(k, "image")
+			g.DependOnTag(k, "bucket:photos")
+		case "other":
+			g.DependOnTag(k, "bucket:photos")
+		}
+		return "V:" + key.(string), nil
+	}, func(key Key, value Value) { evicted = append(evicted, key) })
+	t.Cleanup(func() { RemoveGroup("deps-group") })
+
+	load := func() {
+		for _, k := range []string{"image", "thumb", "text", "page", "other"} {
+			if _, err := g.Get(nil, k); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	cached := func(k string) bool {
+		_, ok := g.TryGet(k)
+		return ok
+	}
+
+	load()
+	if n := g.Invalidate("image"); n != 2 {
+		t.Fatal("Invalidate(image):", n)
+	}
+	if cached("image") || cached("thumb") || cached("page") || !cached("text") || !cached("other") {
+		t.Fatal("unexpected cache content after Invalidate")
+	}
+	if len(evicted) != 3 {
+		t.Fatal("evicted:", evicted)
+	}
+
+	load()
+	if n := g.InvalidateTag("bucket:photos"); n != 3 {
+		t.Fatal("InvalidateTag:", n)
+	}
+	if cached("thumb") || cached("other") || cached("page") || !cached("image") {
+		t.Fatal("unexpected cache content after InvalidateTag")
+	}
+	if n := g.InvalidateTag("bucket:photos"); n != 0 {
+		t.Fatal("edges not forgotten:", n)
+	}
+
+	// cycles don't loop forever
+	g.DependOn("a", "b")
+	g.DependOn("b", "a")
+	if n := g.Invalidate("a"); n != 2 {
+		t.Fatal("Invalidate with cycle:", n)
+	}
+	// all derived objects are gone, so are their edges
+	g.deps.mu.Lock()
+	defer g.deps.mu.Unlock()
+	if len(g.deps.parents) != 0 || len(g.deps.dependents) != 0 {
+		t.Fatal("edges left:", g.deps.parents, g.deps.dependents)
+	}
+}
+
+func TestSetWithTags(t *testing.T) {
+	g := NewGroup("tags-group", 0, func(ctx Context, key Key) (Value, error) {
+		return nil, ErrNotFound
+	})
+	t.Cleanup(func() { RemoveGroup("tags-group") })
+	g.SetWithTags("b1/a", 1, "bucket:b1", "listing")
+	g.SetWithTags("b1/b", 2, "bucket:b1")
+	g.SetWithTags("b2/a", 3, "bucket:b2", "listing")
+	g.SetWithTags("b2/b", 4, "bucket:b2")
+	g.SetWithTags("b2/b", 5) // retagged: no longer in bucket:b2
+
+	if n := g.InvalidateTag("listing"); n != 2 {
+		t.Fatal("InvalidateTag(listing):", n)
+	}
+	if n := g.InvalidateTag("bucket:b2"); n != 0 {
+		t.Fatal("InvalidateTag(bucket:b2):", n)
+	}
+	if v, ok := g.TryGet("b2/b"); !ok || v != 5 {
+		t.Fatal("b2/b:", v, ok)
+	}
+	if _, ok := g.TryGet("b1/a"); ok {
+		t.Fatal("b1/a not invalidated")
+	}
+	if n := g.InvalidateTag("bucket:b1"); n != 1 {
+		t.Fatal("InvalidateTag(bucket:b1):", n)
+	}
+	if g.CacheStats().Items != 1 {
+		t.Fatal("items:", g.CacheStats().Items)
+	}
+}
+
+func TestSetWithTagsSecondary(t *testing.T) {
+	store := &mapStore{m: map[string][]byte{}}
+	g := NewGroup("tags-secondary-group", 0, func(ctx Context, key Key) (Value, error) {
+		return nil, ErrNotFound
+	})
+	t.Cleanup(func() { RemoveGroup("tags-secondary-group") })
+	g.SetSecondary(store, nil)
+	g.SetWithTags("k", []byte("v"), "tag")
+	if string(store.m["k"]) != "v" {
+		t.Fatal("SetWithTags not written to the secondary store:", store.m)
+	}
+	if n := g.InvalidateTag("tag"); n != 1 || len(store.m) != 0 {
+		t.Fatal("InvalidateTag:", n, store.m)
+	}
+}
+
+func TestDependOnFailedLoad(t *testing.T) {
+	var g *Group
+	g = NewGroup("deps-failed-group", 0, func(ctx Context, key Key) (Value, error) {
+		g.DependOn(key, "src")
+		g.DependOnTag(key, "tag")
+		return nil, ErrNotFound
+	})
+	t.Cleanup(func() { RemoveGroup("deps-failed-group") })
+	if _, err := g.Get(nil, "k"); err != ErrNotFound {
+		t.Fatal("Get:", err)
+	}
+	if g.deps.has("k") {
+		t.Fatal("edges of a failed load kept")
+	}
+}
+
+type taggedVal struct {
+	v    int
+	tags []string
+}
+
+func (p *taggedVal) Tags() []string {
+	return p.tags
+}
+
+func TestTagger(t *testing.T) {
+	g := NewGroup("tagger-group", 0, func(ctx Context, key Key) (Value, error) {
+		return &taggedVal{1, []string{"user:42"}}, nil
+	})
+	t.Cleanup(func() { RemoveGroup("tagger-group") })
+	g.Get(nil, "profile")
+	g.Set("feed", &taggedVal{2, []string{"user:42", "feeds"}})
+	g.SetWithTags("friends", &taggedVal{3, []string{"user:42"}}, "social")
+	if n := g.InvalidateTag("user:42"); n != 3 {
+		t.Fatal("InvalidateTag(user:42):", n)
+	}
+	if g.CacheStats().Items != 0 {
+		t.Fatal("items:", g.CacheStats().Items)
+	}
+}
+
+func TestTaggerSecondary(t *testing.T) {
+	store := &mapStore{m: map[string][]byte{"a": []byte("42"), "b": []byte("42"), "c": []byte("7")}}
+	g := NewGroup("tagger-secondary-group", 0, func(ctx Context, key Key) (Value, error) {
+		return nil, ErrNotFound
+	})
+	t.Cleanup(func() { RemoveGroup("tagger-secondary-group") })
+	g.SetBatchGetter(func(ctx Context, keys []Key) ([]Value, []error) {
+		return make([]Value, len(keys)), make([]error, len(keys))
+	})
+	g.SetSecondary(store, func(key string, data []byte) (Value, error) {
+		return &taggedVal{tags: []string{"user:" + string(data)}}, nil
+	})
+	g.Get(nil, "a")
+	g.GetMulti(nil, []Key{"b", "c"})
+	if n := g.InvalidateTag("user:42"); n != 2 {
+		t.Fatal("InvalidateTag of values from the secondary store:", n)
+	}
+	if _, ok := g.TryGet("c"); !ok || g.CacheStats().Items != 1 {
+		t.Fatal("items:", g.CacheStats().Items)
+	}
+}
+
+func TestDependOnAfterRefresh(t *testing.T) {
+	var g *Group
+	var mu sync.Mutex
+	loads := 0
+	g = NewGroup("deps-refresh-group", 0, func(ctx Context, key Key) (Value, error) {
+		g.DependOn(key, "src")
+		mu.Lock()
+		defer mu.Unlock()
+		loads++
+		return loads, nil
+	})
+	t.Cleanup(func() { RemoveGroup("deps-refresh-group") })
+	clock := &lockedClock{now: time.Unix(1000, 0)}
+	g.SetClock(clock)
+	g.SetRefreshAfter(time.Minute)
+
+	if v, _ := g.Get(nil, "k"); v != 1 {
+		t.Fatal("Get:", v)
+	}
+	clock.advance(2 * time.Minute)
+	g.Get(nil, "k") // triggers a background reload
+	for deadline := time.Now().Add(time.Second); ; {
+		if v, _ := g.TryGet("k"); v == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the stale entry was not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := g.Invalidate("src"); n != 1 {
+		t.Fatal("Invalidate(src) after a refresh:", n)
+	}
+	if _, ok := g.TryGet("k"); ok {
+		t.Fatal("k is still cached")
+	}
+}
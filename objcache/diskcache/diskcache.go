@@ -0,0 +1,414 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package diskcache implements a size-bounded cache of byte blobs stored as
+// files in a directory.
+//
+// Every key is stored in its own file whose path is derived from the SHA-1
+// hash of the key. A file carries the key, the data and a CRC32 checksum, so
+// the in-memory index can always be rebuilt by scanning the directory: there
+// is no separate index file that could be torn by a crash. Files are written
+// to a temporary name and renamed into place, so readers never observe a
+// partially written entry.
+//
+// A *Cache implements objcache.SecondaryStore.
+package diskcache
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qiniu/x/objcache"
+)
+
+var (
+	// ErrNotFound is returned by Get when the key is absent.
+	ErrNotFound = objcache.ErrNotFound
+
+	// ErrCorrupted is returned by Get when the file of an entry fails its
+	// integrity check. The corrupted file is removed.
+	ErrCorrupted = errors.New("diskcache: corrupted entry")
+
+	// ErrClosed is returned when the cache is used after Close.
+	ErrClosed = errors.New("diskcache: cache closed")
+)
+
+var _ objcache.SecondaryStore = (*Cache)(nil)
+
+// -----------------------------------------------------------------------------
+
+// SyncMode decides how hard Set tries to make an entry durable.
+type SyncMode int
+
+const (
+	// SyncNone leaves flushing to the operating system.
+	SyncNone SyncMode = iota
+
+	// SyncFile fsyncs each entry file before renaming it into place.
+	SyncFile
+
+	// SyncAll fsyncs each entry file and then its parent directory, so the
+	// rename itself survives a power loss.
+	SyncAll
+)
+
+// Options configures a Cache.
+type Options struct {
+	// MaxBytes is the size budget of all entry files. When it is exceeded the
+	// least recently used entries are removed. Zero means no limit.
+	MaxBytes int64
+
+	// Sync is the fsync policy of Set.
+	Sync SyncMode
+}
+
+const (
+	fileMagic = "XDC1"
+	fileExt   = ".dc"
+	tempExt   = ".tmp"
+
+	// magic | keylen(uint32) | key | data | crc32(uint32)
+	hdrSize = len(fileMagic) + 4
+	crcSize = 4
+)
+
+type entry struct {
+	name  string // file name relative to the cache dir
+	key   string
+	size  int64
+	atime time.Time
+}
+
+// Cache is a disk cache. It is safe for concurrent use.
+type Cache struct {
+	dir  string
+	opts Options
+
+	mu     sync.Mutex
+	ll     *list.List // front is the most recently used
+	items  map[string]*list.Element
+	bytes  int64
+	closed bool
+}
+
+// Open opens (or creates) a disk cache in dir. The index is rebuilt from the
+// entry files found in dir; stale temporary files left by a crash are removed.
+func Open(dir string, opts *Options) (c *Cache, err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	c = &Cache{dir: dir, ll: list.New(), items: make(map[string]*list.Element)}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if err = c.load(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.pruneLocked()
+	c.mu.Unlock()
+	return
+}
+
+func (c *Cache) load() error {
+	var ents []*entry
+	err := filepath.Walk(c.dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		name := fi.Name()
+		if strings.HasSuffix(name, tempExt) {
+			os.Remove(path)
+			return nil
+		}
+		if !strings.HasSuffix(name, fileExt) {
+			return nil
+		}
+		key, err := readKey(path)
+		if err != nil {
+			os.Remove(path)
+			return nil
+		}
+		rel, _ := filepath.Rel(c.dir, path)
+		ents = append(ents, &entry{name: rel, key: key, size: fi.Size(), atime: fi.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// oldest first, so that the newest ends up in the front of the list
+	sort.Slice(ents, func(i, j int) bool { return ents[i].atime.Before(ents[j].atime) })
+	for _, e := range ents {
+		c.items[e.key] = c.ll.PushFront(e)
+		c.bytes += e.size
+	}
+	return nil
+}
+
+func readKey(path string) (key string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	var hdr [hdrSize]byte
+	if _, err = f.ReadAt(hdr[:], 0); err != nil {
+		return
+	}
+	if string(hdr[:len(fileMagic)]) != fileMagic {
+		return "", ErrCorrupted
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return
+	}
+	klen := int64(binary.LittleEndian.Uint32(hdr[len(fileMagic):]))
+	if int64(hdrSize)+klen+crcSize > fi.Size() {
+		return "", ErrCorrupted
+	}
+	b := make([]byte, klen)
+	if _, err = f.ReadAt(b, int64(hdrSize)); err != nil {
+		return
+	}
+	return string(b), nil
+}
+
+// fileName returns the path of key relative to the cache dir.
+func fileName(key string) string {
+	h := sha1.Sum([]byte(key))
+	s := hex.EncodeToString(h[:])
+	return filepath.Join(s[:2], s[2:]+fileExt)
+}
+
+// -----------------------------------------------------------------------------
+
+// Get returns the data stored under key.
+func (c *Cache) Get(key string) (data []byte, err error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	ele, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	c.ll.MoveToFront(ele)
+	e := ele.Value.(*entry)
+	atime := time.Now()
+	e.atime = atime
+	name := e.name
+	c.mu.Unlock()
+
+	path := filepath.Join(c.dir, name)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.forget(key, e)
+			err = ErrNotFound
+		}
+		return
+	}
+	data, ok = decode(b, key)
+	if !ok {
+		c.mu.Lock()
+		c.removeLocked(key, e)
+		c.mu.Unlock()
+		return nil, ErrCorrupted
+	}
+	// persist the access time, so the LRU order survives a restart.
+	os.Chtimes(path, atime, atime)
+	return
+}
+
+// Set stores data under key.
+func (c *Cache) Set(key string, data []byte) (err error) {
+	name := fileName(key)
+	path := filepath.Join(c.dir, name)
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	f, err := ioutil.TempFile(dir, "*"+tempExt)
+	if err != nil {
+		return
+	}
+	tmp := f.Name()
+	b := encode(key, data)
+	_, err = f.Write(b)
+	if err == nil && c.opts.Sync != SyncNone {
+		err = f.Sync()
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		os.Remove(tmp)
+		return ErrClosed
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return
+	}
+	if c.opts.Sync == SyncAll {
+		syncDir(dir)
+	}
+	size := int64(len(b))
+	if ele, ok := c.items[key]; ok {
+		e := ele.Value.(*entry)
+		c.bytes += size - e.size
+		e.size, e.atime = size, time.Now()
+		c.ll.MoveToFront(ele)
+	} else {
+		e := &entry{name: name, key: key, size: size, atime: time.Now()}
+		c.items[key] = c.ll.PushFront(e)
+		c.bytes += size
+	}
+	c.pruneLocked()
+	return nil
+}
+
+// Delete removes key from the cache.
+func (c *Cache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	if ele, ok := c.items[key]; ok {
+		return c.removeLocked(key, ele.Value.(*entry))
+	}
+	return nil
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Bytes returns the total size of all entry files.
+func (c *Cache) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
+
+// Close closes the cache. The entry files are kept on disk.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Cache) forget(key string, e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ele, ok := c.items[key]; ok && ele.Value.(*entry) == e {
+		c.ll.Remove(ele)
+		delete(c.items, key)
+		c.bytes -= e.size
+	}
+}
+
+func (c *Cache) removeLocked(key string, e *entry) error {
+	ele, ok := c.items[key]
+	if !ok || ele.Value.(*entry) != e {
+		return nil
+	}
+	c.ll.Remove(ele)
+	delete(c.items, key)
+	c.bytes -= e.size
+	err := os.Remove(filepath.Join(c.dir, e.name))
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	return err
+}
+
+func (c *Cache) pruneLocked() {
+	if c.opts.MaxBytes <= 0 {
+		return
+	}
+	for c.bytes > c.opts.MaxBytes {
+		ele := c.ll.Back()
+		if ele == nil {
+			return
+		}
+		e := ele.Value.(*entry)
+		c.removeLocked(e.key, e)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func encode(key string, data []byte) []byte {
+	b := make([]byte, hdrSize+len(key)+len(data)+crcSize)
+	copy(b, fileMagic)
+	binary.LittleEndian.PutUint32(b[len(fileMagic):], uint32(len(key)))
+	n := hdrSize + copy(b[hdrSize:], key)
+	n += copy(b[n:], data)
+	binary.LittleEndian.PutUint32(b[n:], crc32.ChecksumIEEE(b[:n]))
+	return b
+}
+
+func decode(b []byte, key string) (data []byte, ok bool) {
+	if len(b) < hdrSize+crcSize || string(b[:len(fileMagic)]) != fileMagic {
+		return
+	}
+	n := len(b) - crcSize
+	if crc32.ChecksumIEEE(b[:n]) != binary.LittleEndian.Uint32(b[n:]) {
+		return
+	}
+	klen := int(binary.LittleEndian.Uint32(b[len(fileMagic):]))
+	if hdrSize+klen > n || string(b[hdrSize:hdrSize+klen]) != key {
+		return
+	}
+	return b[hdrSize+klen : n], true
+}
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	f.Close()
+	return err
+}
+
+// -----------------------------------------------------------------------------
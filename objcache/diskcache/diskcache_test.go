@@ -0,0 +1,102 @@
+package diskcache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qiniu/x/objcache"
+	"github.com/qiniu/x/objcache/cachetest"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, &Options{Sync: SyncAll})
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if _, err = c.Get("foo"); err != ErrNotFound {
+		t.Fatal("Get absent:", err)
+	}
+	if err = c.Set("foo", []byte("hello")); err != nil {
+		t.Fatal("Set:", err)
+	}
+	data, err := c.Get("foo")
+	if err != nil || string(data) != "hello" {
+		t.Fatal("Get:", string(data), err)
+	}
+	if err = c.Delete("foo"); err != nil {
+		t.Fatal("Delete:", err)
+	}
+	if _, err = c.Get("foo"); err != ErrNotFound {
+		t.Fatal("Get deleted:", err)
+	}
+	if c.Len() != 0 || c.Bytes() != 0 {
+		t.Fatal("Len/Bytes after delete:", c.Len(), c.Bytes())
+	}
+}
+
+func TestPruneAndReopen(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte{'a'}, 100)
+	size := int64(len(encode("k0", data)))
+	c, err := Open(dir, &Options{MaxBytes: 3 * size})
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	for _, key := range []string{"k0", "k1", "k2"} {
+		c.Set(key, data)
+	}
+	c.Get("k0") // k1 becomes the oldest
+	c.Set("k3", data)
+	if _, err = c.Get("k1"); err != ErrNotFound {
+		t.Fatal("k1 is not pruned:", err)
+	}
+	if c.Len() != 3 || c.Bytes() != 3*size {
+		t.Fatal("Len/Bytes:", c.Len(), c.Bytes())
+	}
+	c.Close()
+
+	c, err = Open(dir, &Options{MaxBytes: 3 * size})
+	if err != nil {
+		t.Fatal("reopen:", err)
+	}
+	defer c.Close()
+	for _, key := range []string{"k0", "k2", "k3"} {
+		if v, err := c.Get(key); err != nil || !bytes.Equal(v, data) {
+			t.Fatal("Get after reopen:", key, err)
+		}
+	}
+}
+
+func TestCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, nil)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	c.Set("foo", []byte("hello"))
+	path := filepath.Join(dir, fileName("foo"))
+	b, _ := ioutil.ReadFile(path)
+	b[len(b)-crcSize-1] ^= 0xff
+	ioutil.WriteFile(path, b, 0644)
+	if _, err = c.Get("foo"); err != ErrCorrupted {
+		t.Fatal("Get corrupted:", err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("corrupted file is not removed:", err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	cachetest.TestSecondaryStore(t, func(t *testing.T) objcache.SecondaryStore {
+		c, err := Open(t.TempDir(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		return c
+	})
+}
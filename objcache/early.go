@@ -0,0 +1,98 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// SetEarlyExpiration turns on probabilistic early expiration ("XFetch"), so
+// that the expiry of a hot key doesn't send all its Gets to the getter at
+// once: as an entry with a TTL (see SetTTL) approaches its expiry, each Get
+// reloads it in the background with a probability growing with the time the
+// getter took to load it, and with beta. A Get at time now triggers the
+// reload if
+//
+//	now - delta * beta * ln(rand()) >= expiry
+//
+// where delta is the duration of the last load of the entry and rand() is
+// uniform in (0, 1]. A beta of 1 is the usual choice, larger values reload
+// earlier; zero, the default, turns it off. The reload is the one of
+// SetRefreshAfter, and an entry triggers at most one of them.
+func (g *Group) SetEarlyExpiration(beta float64) {
+	g.mainCache.setBeta(beta)
+}
+
+// commitLoad caches val, loaded by a getter that took d, unless key was
+// removed meanwhile.
+func (g *Group) commitLoad(key Key, val Value, d time.Duration) {
+	g.flights.commit(key, func() {
+		g.mainCache.add(key, val)
+		g.mainCache.shardOf(key).setDelta(key, d)
+		g.tag(key, val)
+	})
+}
+
+// -----------------------------------------------------------------------------
+
+func (c *cache) setBeta(beta float64) {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		s.beta = beta
+		if beta <= 0 {
+			s.deltas = nil
+		}
+		s.mu.Unlock()
+	}
+}
+
+// setDelta records the load duration of the entry of key, if it has a TTL.
+func (c *shard) setDelta(key Key, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.beta <= 0 {
+		return
+	}
+	if _, ok := c.expires[key]; !ok {
+		return
+	}
+	if c.deltas == nil {
+		c.deltas = make(map[Key]time.Duration)
+	}
+	c.deltas[key] = d
+}
+
+// dueEarlyLocked reports whether key is due for an early reload, and if so,
+// forgets its load duration so that it triggers a single one.
+func (c *shard) dueEarlyLocked(key Key) bool {
+	delta, ok := c.deltas[key]
+	if !ok {
+		return false
+	}
+	exp := c.expires[key]
+	gap := -float64(delta) * c.beta * math.Log(1-rand.Float64())
+	if c.now().Add(time.Duration(gap)).Before(exp) {
+		return false
+	}
+	delete(c.deltas, key)
+	return true
+}
+
+// -----------------------------------------------------------------------------
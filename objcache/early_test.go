@@ -0,0 +1,47 @@
+package objcache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEarlyExpiration(t *testing.T) {
+	var loads int64
+	newGroup := func(name string, beta float64) *Group {
+		g := NewGroupWith(name, func(ctx Context, key Key) (Value, error) {
+			time.Sleep(5 * time.Millisecond)
+			return atomic.AddInt64(&loads, 1), nil
+		}, WithTTL(time.Minute))
+		g.SetEarlyExpiration(beta)
+		return g
+	}
+
+	g := newGroup("early-expiration-group", 1e-12)
+	g.Get(nil, "k")
+	for i := 0; i < 10; i++ {
+		g.Get(nil, "k")
+	}
+	if s := g.LoadStats(); s.Refreshes != 0 {
+		t.Fatal("early reload far from the expiry:", s.Refreshes)
+	}
+
+	// a huge beta makes the entry always due
+	g = newGroup("early-expiration-hot-group", 1e12)
+	atomic.StoreInt64(&loads, 0)
+	g.Get(nil, "k")
+	for i := 0; i < 10; i++ {
+		if v, _ := g.Get(nil, "k"); v == nil {
+			t.Fatal("Get returned no value")
+		}
+	}
+	if s := g.LoadStats(); s.Refreshes != 1 {
+		t.Fatal("early reloads:", s.Refreshes)
+	}
+	for deadline := time.Now().Add(time.Second); atomic.LoadInt64(&loads) != 2; {
+		if time.Now().After(deadline) {
+			t.Fatal("no early reload")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
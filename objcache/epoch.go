@@ -0,0 +1,76 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"sync/atomic"
+)
+
+// InvalidateAll invalidates all the entries of both tiers of the cache of
+// the group in O(1), unlike Purge: it starts a new epoch of the cache, and
+// the entries cached before it are treated as misses. They are removed, and
+// disposed of, lazily: when they are looked up, evicted, or reaped by the
+// janitor (see StartJanitor).
+//
+// As with Purge, the loads in progress are not cached when they complete.
+// Pinned entries are kept until unpinned, and so are the values of the
+// secondary store. The invalidated entries count in CacheStats until they
+// are removed.
+func (g *Group) InvalidateAll() {
+	g.flights.forgetAll()
+	g.mainCache.newEpoch()
+	g.hotCache.newEpoch()
+}
+
+func (c *cache) newEpoch() {
+	atomic.AddUint64(&c.epoch, 1)
+}
+
+// oldLocked reports whether the entry of key was cached before the current
+// epoch of the cache. The entries of the first epoch have no record in
+// epochs, so that a group never invalidated doesn't pay for them.
+func (c *shard) oldLocked(key Key) bool {
+	return c.epochs[key] != atomic.LoadUint64(c.epoch)
+}
+
+// removeOldLocked removes the entries cached before the current epoch. Those
+// of the first epoch are only found if the policy is a Ranger.
+func (c *shard) removeOldLocked() {
+	if atomic.LoadUint64(c.epoch) == 0 {
+		return
+	}
+	var old []Key
+	if r, ok := c.lru.(Ranger); ok {
+		r.Range(func(key Key, value Value) bool {
+			if c.oldLocked(key) {
+				old = append(old, key)
+			}
+			return true
+		})
+	} else {
+		for key := range c.epochs {
+			if _, pinned := c.pinned[key]; !pinned && c.oldLocked(key) {
+				old = append(old, key)
+			}
+		}
+	}
+	c.reason = EvictRemoved
+	for _, key := range old {
+		c.lru.Remove(key)
+	}
+	c.reason = EvictCapacity
+}
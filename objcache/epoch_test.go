@@ -0,0 +1,65 @@
+package objcache
+
+import (
+	"testing"
+)
+
+func TestInvalidateAll(t *testing.T) {
+	loads := 0
+	var evicted []Key
+	g := NewGroup("invalidate-all-group", 0, func(ctx Context, key Key) (Value, error) {
+		loads++
+		return loads, nil
+	}, func(key Key, value Value) {
+		evicted = append(evicted, key)
+	})
+	t.Cleanup(func() { RemoveGroup("invalidate-all-group") })
+	g.Get(nil, "a")
+	g.Get(nil, "b")
+	g.Get(nil, "c")
+	g.Pin("c")
+	g.InvalidateAll()
+	if len(evicted) != 0 {
+		t.Fatal("InvalidateAll evicted eagerly:", evicted)
+	}
+	if g.Contains("a") || !g.Contains("c") {
+		t.Fatal("Contains after InvalidateAll")
+	}
+	g.Range(func(key Key, value Value) bool {
+		if key != "c" {
+			t.Fatal("Range after InvalidateAll:", key)
+		}
+		return true
+	})
+	if v, _ := g.Get(nil, "a"); v != 4 || len(evicted) != 1 || evicted[0] != "a" {
+		t.Fatal("Get after InvalidateAll:", v, evicted)
+	}
+	if v, _ := g.Get(nil, "a"); v != 4 {
+		t.Fatal("reloaded value not cached:", v)
+	}
+	g.ReapExpired()
+	if len(evicted) != 2 || evicted[1] != "b" {
+		t.Fatal("ReapExpired after InvalidateAll:", evicted)
+	}
+	if s := g.CacheStats(); s.Items != 2 {
+		t.Fatal("items:", s.Items)
+	}
+}
+
+func TestInvalidateAllAgain(t *testing.T) {
+	g := NewGroup("invalidate-again-group", 0, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("invalidate-again-group") })
+	g.InvalidateAll()
+	g.Get(nil, "a") // cached in the second epoch
+	g.InvalidateAll()
+	g.Get(nil, "b")
+	if g.Contains("a") || !g.Contains("b") {
+		t.Fatal("Contains after a second InvalidateAll")
+	}
+	g.ReapExpired()
+	if s := g.CacheStats(); s.Items != 1 {
+		t.Fatal("items:", s.Items)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"strconv"
+)
+
+// EvictReason tells why an entry left the cache of a group, see
+// Group.OnEvict.
+type EvictReason int
+
+const (
+	// EvictCapacity means the entry was evicted to make room, by the
+	// capacity or the byte budget of the cache.
+	EvictCapacity EvictReason = iota
+
+	// EvictExpired means the TTL of the entry elapsed.
+	EvictExpired
+
+	// EvictRemoved means the entry was removed by Remove, Purge, Invalidate
+	// or InvalidateTag, or dropped by SetPolicy.
+	EvictRemoved
+
+	// EvictReplaced means the entry was replaced by a different value.
+	EvictReplaced
+
+	// EvictRejected means the policy didn't admit the new entry, see
+	// package tinylfu. Its value, handed out by the load that got it, is
+	// neither passed to onEvicted nor disposed of.
+	EvictRejected
+)
+
+var evictReasons = [...]string{"capacity", "expired", "removed", "replaced", "rejected"}
+
+func (r EvictReason) String() string {
+	if r >= 0 && int(r) < len(evictReasons) {
+		return evictReasons[r]
+	}
+	return "EvictReason(" + strconv.Itoa(int(r)) + ")"
+}
+
+// An EvictFunc is called when an entry leaves the cache of a group.
+type EvictFunc = func(key Key, value Value, reason EvictReason)
+
+// OnEvict registers fn to be called, after the onEvicted func of the group,
+// each time an entry leaves either tier of its cache, e.g. to log evictions,
+// update metrics or release external resources tied to the entry. Like
+// onEvicted, fn is called with the cache locked, so it must not call the
+// methods of the group.
+func (g *Group) OnEvict(fn EvictFunc) {
+	g.evictMu.Lock()
+	g.evictFns = append(g.evictFns, fn)
+	g.evictMu.Unlock()
+}
+
+func (g *Group) notifyEvicted(key Key, value Value, reason EvictReason) {
+	g.evictMu.RLock()
+	fns := g.evictFns
+	g.evictMu.RUnlock()
+	for _, fn := range fns {
+		fn(key, value, reason)
+	}
+}
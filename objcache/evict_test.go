@@ -0,0 +1,42 @@
+package objcache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOnEvict(t *testing.T) {
+	g := NewGroup("on-evict-group", 2, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("on-evict-group") })
+	var got []string
+	g.OnEvict(func(key Key, value Value, reason EvictReason) {
+		got = append(got, key.(string)+":"+reason.String())
+	})
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g.SetClock(clock)
+
+	g.Get(nil, "a")
+	g.Get(nil, "b")
+	g.Get(nil, "c") // evicts a
+	g.Set("b", "new b")
+	g.Remove("c")
+	g.SetWithTTL("d", "d", time.Second)
+	clock.now = clock.now.Add(time.Second)
+	g.Get(nil, "d")
+	g.Purge()
+
+	want := []string{"a:capacity", "b:replaced", "c:removed", "d:expired", "d:removed", "b:removed"}
+	if len(got) != len(want) {
+		t.Fatal("evictions:", got)
+	}
+	// the order of Purge is unspecified
+	if !reflect.DeepEqual(got[:4], want[:4]) || !(got[4] == want[4] && got[5] == want[5] || got[4] == want[5] && got[5] == want[4]) {
+		t.Fatal("evictions:", got)
+	}
+	if s := EvictReason(9).String(); s != "EvictReason(9)" {
+		t.Fatal("String:", s)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"expvar"
+)
+
+// -----------------------------------------------------------------------------
+
+// GroupVar is the expvar value of a group, see PublishExpvar. Gets counts
+// the lookups of the group, CacheHits those served by either tier of its
+// cache, and Items and Evictions sum both tiers.
+type GroupVar struct {
+	Gets      int64
+	CacheHits int64
+	Items     int64
+	Evictions int64
+
+	MainCache CacheStats
+	HotCache  CacheStats
+	Loads     LoadStats
+
+	resets int // of the stats of the group before the snapshot
+}
+
+// Var returns the stats of the group as published by PublishExpvar.
+func (g *Group) Var() *GroupVar {
+	g.loads.mu.Lock()
+	resets := g.loads.resets
+	g.loads.mu.Unlock()
+	main, hot := g.mainCache.stats(), g.hotCache.stats()
+	return &GroupVar{
+		Gets:      main.Gets,
+		CacheHits: main.Hits + hot.Hits,
+		Items:     main.Items + hot.Items,
+		Evictions: main.Evictions + hot.Evictions,
+		MainCache: main,
+		HotCache:  hot,
+		Loads:     g.LoadStats(),
+		resets:    resets,
+	}
+}
+
+// PublishExpvar publishes the stats of all the groups as the expvar variable
+// name (e.g. "objcache"), an object mapping the name of each group to its
+// GroupVar, served under /debug/vars. The groups created later are included.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(groupsVar))
+}
+
+func groupsVar() interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+	ret := make(map[string]*GroupVar, len(groups))
+	for name, g := range groups {
+		ret[name] = g.Var()
+	}
+	return ret
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,29 @@
+package objcache
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	g := NewGroup("expvar-group", 0, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("expvar-group") })
+	g.Get(nil, "a")
+	g.Get(nil, "a")
+	g.Get(nil, "b")
+
+	if expvar.Get("objcache-test") == nil {
+		PublishExpvar("objcache-test")
+	}
+	var ret map[string]*GroupVar
+	if err := json.Unmarshal([]byte(expvar.Get("objcache-test").String()), &ret); err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	v := ret["expvar-group"]
+	if v == nil || v.Gets != 3 || v.CacheHits != 1 || v.Items != 2 || v.MainCache.Items != 2 {
+		t.Fatalf("expvar of the group: %+v", v)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var errLoadPanicked = errors.New("objcache: load panicked")
+
+// flight is an in-flight or completed load.
+type flight struct {
+	done      chan struct{}
+	val       Value
+	err       error
+	forgotten bool // the key was removed while loading
+}
+
+// flightGroup runs at most one load at once for a given key: concurrent
+// callers wait for it and share its result.
+type flightGroup struct {
+	mu      sync.Mutex
+	m       map[Key]*flight
+	deduped int64
+}
+
+// do runs fn, unless a load of key is in flight already, in which case it
+// waits for its result. A waiter gives up when ctx is a context.Context
+// that is done first; the load itself goes on.
+func (fg *flightGroup) do(ctx Context, key Key, fn func() (Value, error)) (Value, error) {
+	fg.mu.Lock()
+	if f, ok := fg.m[key]; ok {
+		fg.deduped++
+		fg.mu.Unlock()
+		var done <-chan struct{}
+		if c, ok := ctx.(context.Context); ok {
+			done = c.Done()
+		}
+		select {
+		case <-f.done:
+			return f.val, f.err
+		case <-done:
+			return nil, ctx.(context.Context).Err()
+		}
+	}
+	if fg.m == nil {
+		fg.m = make(map[Key]*flight)
+	}
+	f := &flight{done: make(chan struct{}), err: errLoadPanicked}
+	fg.m[key] = f
+	fg.mu.Unlock()
+
+	defer func() {
+		fg.mu.Lock()
+		delete(fg.m, key)
+		fg.mu.Unlock()
+		close(f.done)
+	}()
+	f.val, f.err = fn()
+	return f.val, f.err
+}
+
+// doMulti is do for distinct keys at once: fn loads the keys that are not
+// in flight already, all together, and the others wait for their loads.
+// fn returns a value and an error (errs may be nil) for each of its keys.
+func (fg *flightGroup) doMulti(ctx Context, keys []Key, fn func(keys []Key) (vals []Value, errs []error)) ([]Value, []error) {
+	fs := make([]*flight, len(keys))
+	var own []Key
+	var owned []*flight
+	fg.mu.Lock()
+	if fg.m == nil {
+		fg.m = make(map[Key]*flight)
+	}
+	for i, key := range keys {
+		if f, ok := fg.m[key]; ok {
+			fg.deduped++
+			fs[i] = f
+			continue
+		}
+		f := &flight{done: make(chan struct{}), err: errLoadPanicked}
+		fg.m[key] = f
+		fs[i] = f
+		own, owned = append(own, key), append(owned, f)
+	}
+	fg.mu.Unlock()
+
+	if len(own) > 0 {
+		func() {
+			defer func() {
+				fg.mu.Lock()
+				for _, key := range own {
+					delete(fg.m, key)
+				}
+				fg.mu.Unlock()
+				for _, f := range owned {
+					close(f.done)
+				}
+			}()
+			vals, errs := fn(own)
+			for i, f := range owned {
+				f.val, f.err = vals[i], nil
+				if errs != nil {
+					f.err = errs[i]
+				}
+			}
+		}()
+	}
+
+	var done <-chan struct{}
+	if c, ok := ctx.(context.Context); ok {
+		done = c.Done()
+	}
+	vals, errs := make([]Value, len(keys)), make([]error, len(keys))
+	for i, f := range fs {
+		select {
+		case <-f.done:
+			vals[i], errs[i] = f.val, f.err
+		case <-done:
+			errs[i] = ctx.(context.Context).Err()
+		}
+	}
+	return vals, errs
+}
+
+// forget marks the load of key in flight, if any, as stale.
+func (fg *flightGroup) forget(key Key) {
+	fg.mu.Lock()
+	if f, ok := fg.m[key]; ok {
+		f.forgotten = true
+	}
+	fg.mu.Unlock()
+}
+
+// forgetAll marks all the loads in flight as stale.
+func (fg *flightGroup) forgetAll() {
+	fg.mu.Lock()
+	for _, f := range fg.m {
+		f.forgotten = true
+	}
+	fg.mu.Unlock()
+}
+
+// commit calls add with the result of the load of key in flight, unless it
+// has been forgotten meanwhile.
+func (fg *flightGroup) commit(key Key, add func()) {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	if f, ok := fg.m[key]; ok && !f.forgotten {
+		add()
+	}
+}
+
+func (fg *flightGroup) dedupedLoads() int64 {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	return fg.deduped
+}
@@ -0,0 +1,188 @@
+package objcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetDeduplicates(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	g := NewGroup("flight-group", 0, func(ctx Context, key Key) (Value, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return key.(string) + "!", nil
+	})
+	t.Cleanup(func() { RemoveGroup("flight-group") })
+
+	const n = 10
+	var wg sync.WaitGroup
+	vals := make([]Value, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			vals[i], _ = g.Get(nil, "k")
+		}(i)
+	}
+	for deadline := time.Now().Add(time.Second); g.LoadStats().Deduped != n-1; {
+		if time.Now().After(deadline) {
+			t.Fatal("Deduped:", g.LoadStats().Deduped)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatal("getter calls:", n)
+	}
+	for _, v := range vals {
+		if v != "k!" {
+			t.Fatal("Get:", v)
+		}
+	}
+}
+
+func TestGetDeduplicatesErrors(t *testing.T) {
+	errLoad := errors.New("load failed")
+	release := make(chan struct{})
+	var calls int32
+	g := NewGroup("flight-error-group", 0, func(ctx Context, key Key) (Value, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		if key == "panic" {
+			panic("boom")
+		}
+		return nil, errLoad
+	})
+	t.Cleanup(func() { RemoveGroup("flight-error-group") })
+	waitDeduped := func(n int64) {
+		for deadline := time.Now().Add(time.Second); g.LoadStats().Deduped != n; {
+			if time.Now().After(deadline) {
+				t.Fatal("Deduped:", g.LoadStats().Deduped)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	leader := make(chan error, 1)
+	go func() {
+		_, err := g.Get(nil, "k")
+		leader <- err
+	}()
+	for g.LoadStats().Active != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.Get(ctx, "k"); err != context.Canceled {
+		t.Fatal("Get with canceled context:", err)
+	}
+	waitDeduped(1)
+	release <- struct{}{}
+	if err := <-leader; err != errLoad {
+		t.Fatal("Get:", err)
+	}
+
+	// the waiters of a load that panics get an error
+	go func() {
+		defer func() { recover() }()
+		g.Get(nil, "panic")
+	}()
+	for g.LoadStats().Active != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := g.Get(nil, "panic")
+		waiter <- err
+	}()
+	waitDeduped(2)
+	close(release)
+	if err := <-waiter; err != errLoadPanicked {
+		t.Fatal("Get of a panicking load:", err)
+	}
+
+	// errors aren't cached
+	if _, err := g.Get(nil, "k"); err != errLoad || atomic.LoadInt32(&calls) != 3 {
+		t.Fatal("Get after error:", err, atomic.LoadInt32(&calls))
+	}
+}
+
+func TestRemove(t *testing.T) {
+	var evicted []Key
+	release := make(chan struct{})
+	g := NewGroup("remove-group", 0, func(ctx Context, key Key) (Value, error) {
+		if key == "slow" {
+			<-release
+		}
+		return key, nil
+	}, func(key Key, value Value) {
+		evicted = append(evicted, key)
+	})
+	t.Cleanup(func() { RemoveGroup("remove-group") })
+	g.Get(nil, "k")
+	if !g.Remove("k") || g.Remove("k") {
+		t.Fatal("Remove: wrong result")
+	}
+	if len(evicted) != 1 || evicted[0] != "k" {
+		t.Fatal("evicted:", evicted)
+	}
+
+	// a load in progress when Remove is called isn't cached
+	done := make(chan Value)
+	go func() {
+		v, _ := g.Get(nil, "slow")
+		done <- v
+	}()
+	for g.LoadStats().Active != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if g.Remove("slow") {
+		t.Fatal("Remove of a key being loaded: true")
+	}
+	close(release)
+	if v := <-done; v != "slow" {
+		t.Fatal("Get:", v)
+	}
+	if _, ok := g.TryGet("slow"); ok {
+		t.Fatal("value loaded before Remove cached")
+	}
+	if _, err := g.Get(nil, "slow"); err != nil {
+		t.Fatal("Get:", err)
+	}
+	if _, ok := g.TryGet("slow"); !ok {
+		t.Fatal("value loaded after Remove not cached")
+	}
+}
+
+func TestRemoveConcurrent(t *testing.T) {
+	g := NewGroup("remove-concurrent-group", 0, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("remove-concurrent-group") })
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if v, err := g.Get(nil, j%10); err != nil || v != j%10 {
+					t.Error("Get:", v, err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				g.Remove(j % 10)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,185 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"encoding"
+	"fmt"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// GroupOf is a Group of keys of type K and values of type V, with typed
+// methods. The untyped Group (see GroupOf.Group) gives access to the rest
+// of its API, such as stats, TTLs and peers.
+//
+// As in any group, a value that is a Disposer is disposed when it leaves the
+// cache, see Acquire.
+type GroupOf[K comparable, V any] struct {
+	g *Group
+}
+
+// NewGroupOf creates a group of keys of type K and values of type V, see
+// NewGroup.
+func NewGroupOf[K comparable, V any](name string, cacheNum int, getter func(ctx Context, key K) (V, error), onEvicted ...func(key K, value V)) *GroupOf[K, V] {
+	var evicted []OnEvictedFunc
+	if len(onEvicted) > 0 && onEvicted[0] != nil {
+		fn := onEvicted[0]
+		evicted = append(evicted, func(key Key, value Value) {
+			if v, ok := value.(V); ok {
+				fn(key.(K), v)
+			}
+		})
+	}
+	g := NewGroup(name, cacheNum, func(ctx Context, key Key) (Value, error) {
+		k, ok := key.(K)
+		if !ok {
+			var zero K
+			return nil, fmt.Errorf("objcache: group %s: key of type %T, want %T", name, key, zero)
+		}
+		return getter(ctx, k)
+	}, evicted...)
+	return &GroupOf[K, V]{g}
+}
+
+// Group returns the untyped group.
+func (p *GroupOf[K, V]) Group() *Group {
+	return p.g
+}
+
+// Name returns the name of the group.
+func (p *GroupOf[K, V]) Name() string {
+	return p.g.name
+}
+
+// Get returns the value of key, see Group.Get.
+func (p *GroupOf[K, V]) Get(ctx Context, key K) (V, error) {
+	val, err := p.g.Get(ctx, key)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return valueOf[V](val)
+}
+
+// GetOrLoad returns the value of key, loaded by loader on a miss, see
+// Group.GetOrLoad.
+func (p *GroupOf[K, V]) GetOrLoad(ctx Context, key K, loader func() (V, error)) (V, error) {
+	val, err := p.g.GetOrLoad(ctx, key, func() (Value, error) {
+		return loader()
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return valueOf[V](val)
+}
+
+// GetMulti returns the values of keys, see Group.GetMulti.
+func (p *GroupOf[K, V]) GetMulti(ctx Context, keys []K) (vals []V, errs []error) {
+	ks := make([]Key, len(keys))
+	for i, key := range keys {
+		ks[i] = key
+	}
+	uvals, errs := p.g.GetMulti(ctx, ks)
+	vals = make([]V, len(keys))
+	for i, val := range uvals {
+		if errs == nil || errs[i] == nil {
+			var err error
+			if vals[i], err = valueOf[V](val); err != nil {
+				if errs == nil {
+					errs = make([]error, len(keys))
+				}
+				errs[i] = err
+			}
+		}
+	}
+	return
+}
+
+// Acquire returns the value of key, which isn't disposed until release is
+// called, see Group.Acquire.
+func (p *GroupOf[K, V]) Acquire(ctx Context, key K) (val V, release func(), err error) {
+	h, err := p.g.Acquire(ctx, key)
+	if err != nil {
+		return
+	}
+	if val, err = valueOf[V](h.Value()); err != nil {
+		h.Release()
+		return
+	}
+	return val, h.Release, nil
+}
+
+// TryGet returns the cached value of key, if any.
+func (p *GroupOf[K, V]) TryGet(key K) (val V, ok bool) {
+	v, ok := p.g.TryGet(key)
+	if ok {
+		val, ok = v.(V)
+	}
+	return
+}
+
+// Set adds value to the cache under key, see Group.Set.
+func (p *GroupOf[K, V]) Set(key K, value V) {
+	p.g.Set(key, value)
+}
+
+// SetWithTTL adds value to the cache under key, expiring after ttl, see
+// Group.SetWithTTL.
+func (p *GroupOf[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	p.g.SetWithTTL(key, value, ttl)
+}
+
+// Update replaces the cached value of key by the value returned by fn, see
+// Group.Update. A cached value that isn't a V is left alone.
+func (p *GroupOf[K, V]) Update(key K, fn func(old V) (V, bool)) bool {
+	return p.g.Update(key, func(old Value) (Value, bool) {
+		v, err := valueOf[V](old)
+		if err != nil {
+			return nil, false
+		}
+		return fn(v)
+	})
+}
+
+// Remove removes key from the cache, see Group.Remove.
+func (p *GroupOf[K, V]) Remove(key K) bool {
+	return p.g.Remove(key)
+}
+
+// valueOf returns val as a V. The values fetched from peers are decoded:
+// see EncodeValue.
+func valueOf[V any](val Value) (ret V, err error) {
+	if v, ok := val.(V); ok {
+		return v, nil
+	}
+	if data, ok := val.([]byte); ok {
+		switch p := any(&ret).(type) {
+		case *string:
+			*p = string(data)
+			return
+		case encoding.BinaryUnmarshaler:
+			err = p.UnmarshalBinary(data)
+			return
+		}
+	}
+	return ret, fmt.Errorf("objcache: value of type %T, want %T", val, ret)
+}
+
+// -----------------------------------------------------------------------------
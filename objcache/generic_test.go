@@ -0,0 +1,69 @@
+package objcache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type disposable struct {
+	n        int
+	disposed *[]int
+}
+
+func (d disposable) Dispose() error {
+	*d.disposed = append(*d.disposed, d.n)
+	return nil
+}
+
+func TestGroupOf(t *testing.T) {
+	var disposed, evicted []int
+	g := NewGroupOf("group-of", 1, func(ctx Context, key int) (disposable, error) {
+		if key < 0 {
+			return disposable{}, errors.New("negative key")
+		}
+		return disposable{key * 10, &disposed}, nil
+	}, func(key int, value disposable) {
+		evicted = append(evicted, key)
+	})
+	if v, err := g.Get(nil, 1); err != nil || v.n != 10 {
+		t.Fatal("Get:", v, err)
+	}
+	if _, err := g.Get(nil, -1); err == nil {
+		t.Fatal("Get of a failing key: no error")
+	}
+	if v, ok := g.TryGet(1); !ok || v.n != 10 {
+		t.Fatal("TryGet:", v, ok)
+	}
+	g.Set(2, disposable{20, &disposed}) // evicts 1
+	if len(disposed) != 1 || disposed[0] != 10 || len(evicted) != 1 || evicted[0] != 1 {
+		t.Fatal("eviction:", disposed, evicted)
+	}
+	vals, errs := g.GetMulti(nil, []int{2, -2})
+	if vals[0].n != 20 || errs == nil || errs[0] != nil || errs[1] == nil {
+		t.Fatal("GetMulti:", vals, errs)
+	}
+	if _, err := g.Group().Get(nil, "k"); err == nil {
+		t.Fatal("Get of a key of the wrong type: no error")
+	}
+}
+
+type intVal int
+
+func (v *intVal) UnmarshalBinary(data []byte) error {
+	n, err := strconv.Atoi(string(data))
+	*v = intVal(n)
+	return err
+}
+
+func TestValueOf(t *testing.T) {
+	if s, err := valueOf[string]([]byte("abc")); err != nil || s != "abc" {
+		t.Fatal("valueOf string:", s, err)
+	}
+	if v, err := valueOf[intVal]([]byte("42")); err != nil || v != 42 {
+		t.Fatal("valueOf BinaryUnmarshaler:", v, err)
+	}
+	if _, err := valueOf[int]("42"); err == nil {
+		t.Fatal("valueOf of a wrong type: no error")
+	}
+}
@@ -0,0 +1,329 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qiniu/x/objcache/consistenthash"
+)
+
+// GRPCMethod is the gRPC method served by a GRPCPool: the service is
+//
+//	service Peer {
+//		rpc Get(GetRequest) returns (GetResponse);
+//	}
+//	message GetRequest {
+//		string group = 1;
+//		string key = 2;
+//	}
+//	message GetResponse {
+//		bytes value = 1; // encoded by EncodeValue
+//	}
+const GRPCMethod = "/objcache.Peer/Get"
+
+// Status codes of gRPC, see https://grpc.github.io/grpc/core/md_doc_statuscodes.html.
+const (
+	grpcOK              = 0
+	grpcInvalidArgument = 3
+	grpcNotFound        = 5
+	grpcInternal        = 13
+	grpcUnimplemented   = 12
+)
+
+// maxGRPCMessage bounds the size of the messages between peers.
+const maxGRPCMessage = 64 << 20
+
+// GRPCPoolOptions configures a GRPCPool.
+type GRPCPoolOptions struct {
+	// Transport is used to fetch values from peers. gRPC runs over
+	// HTTP/2, so it must speak HTTP/2 to them, as http.DefaultTransport
+	// (the default) does over TLS.
+	Transport http.RoundTripper
+
+	// NewRing, Replicas and HashFn set the ring assigning keys to peers,
+	// as those of HTTPPoolOptions.
+	NewRing  NewRingFunc
+	Replicas int
+	HashFn   consistenthash.Hash
+}
+
+// GRPCPool is a pool of peers talking gRPC, without depending on a gRPC
+// library: it speaks the gRPC protocol over the HTTP/2 of net/http, so its
+// peers may as well be served by the gRPC implementation of another
+// language. It is a PeerPicker, and an http.Handler serving the values of
+// the groups of the current process to the other peers by GRPCMethod, to be
+// mounted by an HTTP/2 server (e.g. an http.Server with TLS):
+//
+//	pool := objcache.NewGRPCPool("https://10.0.0.1:8443", nil)
+//	pool.Set("https://10.0.0.1:8443", "https://10.0.0.2:8443")
+//	http.Handle(objcache.GRPCMethod, pool)
+//	objcache.RegisterNewGroupHook(func(g *objcache.Group) {
+//		g.SetPeerPicker(pool)
+//	})
+type GRPCPool struct {
+	self   string
+	client *http.Client
+
+	newRing NewRingFunc
+
+	mu      sync.RWMutex
+	ring    PeerRing
+	getters map[string]*grpcGetter
+}
+
+// NewGRPCPool creates a pool of peers for the current process, whose base
+// URL is self (e.g. "https://10.0.0.1:8443").
+func NewGRPCPool(self string, opts *GRPCPoolOptions) *GRPCPool {
+	p := &GRPCPool{self: self, client: http.DefaultClient, newRing: ringOf(nil, 0, nil)}
+	if opts != nil {
+		p.newRing = ringOf(opts.NewRing, opts.Replicas, opts.HashFn)
+		if opts.Transport != nil {
+			p.client = &http.Client{Transport: opts.Transport}
+		}
+	}
+	return p
+}
+
+// Set replaces the peers of the pool with peers, the base URLs of all the
+// processes of the pool (the current one included).
+func (p *GRPCPool) Set(peers ...string) {
+	ring := p.newRing(peers)
+	getters := make(map[string]*grpcGetter, len(peers))
+	for _, peer := range peers {
+		getters[peer] = &grpcGetter{client: p.client, url: strings.TrimSuffix(peer, "/") + GRPCMethod}
+	}
+	p.mu.Lock()
+	p.getters = getters
+	p.ring = ring
+	p.mu.Unlock()
+}
+
+// PickPeer implements PeerPicker.
+func (p *GRPCPool) PickPeer(key string) (ProtoGetter, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.getters) == 0 {
+		return nil, false
+	}
+	peer := p.ring.Get(key)
+	if peer == p.self {
+		return nil, false
+	}
+	return p.getters[peer], true
+}
+
+// ServeHTTP serves the gRPC calls of GRPCMethod.
+func (p *GRPCPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "not a gRPC call", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	if r.URL.Path != GRPCMethod {
+		writeGRPCStatus(w, grpcUnimplemented, "unknown method "+r.URL.Path)
+		return
+	}
+	msg, err := readGRPCMessage(r.Body)
+	if err != nil {
+		writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+		return
+	}
+	var name, key string
+	err = decodeProto(msg, func(field int, b []byte) {
+		switch field {
+		case 1:
+			name = string(b)
+		case 2:
+			key = string(b)
+		}
+	})
+	if err != nil {
+		writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+		return
+	}
+	g := GetGroup(name)
+	if g == nil {
+		writeGRPCStatus(w, grpcNotFound, "no such group: "+name)
+		return
+	}
+	val, err := g.Get(r.Context(), key)
+	if err == nil {
+		var data []byte
+		if data, err = EncodeValue(val); err == nil {
+			w.Write(grpcFrame(appendProtoBytes(nil, 1, data)))
+			writeGRPCStatus(w, grpcOK, "")
+			return
+		}
+	}
+	code := grpcInternal
+	if err == ErrNotFound {
+		code = grpcNotFound
+	}
+	writeGRPCStatus(w, code, err.Error())
+}
+
+func writeGRPCStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set("Grpc-Message", url.PathEscape(msg))
+	}
+}
+
+type grpcGetter struct {
+	client *http.Client
+	url    string
+}
+
+func (h *grpcGetter) Get(ctx Context, group string, key string) ([]byte, error) {
+	c, ok := ctx.(context.Context)
+	if !ok {
+		c = context.Background()
+	}
+	msg := appendProtoBytes(appendProtoBytes(nil, 1, []byte(group)), 2, []byte(key))
+	req, err := http.NewRequest("POST", h.url, bytes.NewReader(grpcFrame(msg)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Te", "trailers")
+	resp, err := h.client.Do(req.WithContext(c))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxGRPCMessage+5))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("objcache: peer %s: %s", h.url, resp.Status)
+	}
+	status, smsg := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" { // a Trailers-Only response
+		status, smsg = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != strconv.Itoa(grpcOK) {
+		if m, err := url.PathUnescape(smsg); err == nil {
+			smsg = m
+		}
+		return nil, fmt.Errorf("objcache: peer %s: grpc status %s: %s", h.url, status, smsg)
+	}
+	msg, err = readGRPCMessage(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("objcache: peer %s: %v", h.url, err)
+	}
+	var val []byte
+	err = decodeProto(msg, func(field int, b []byte) {
+		if field == 1 {
+			val = b
+		}
+	})
+	if val == nil && err == nil {
+		val = []byte{}
+	}
+	return val, err
+}
+
+// -----------------------------------------------------------------------------
+
+// grpcFrame returns msg in a gRPC frame: a compressed flag, the length of
+// msg as 4 bytes in big endian and msg.
+func grpcFrame(msg []byte) []byte {
+	b := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	return append(b, msg...)
+}
+
+func readGRPCMessage(r io.Reader) ([]byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("objcache: bad gRPC frame: %v", err)
+	}
+	if hdr[0] != 0 {
+		return nil, errors.New("objcache: compressed gRPC messages are not supported")
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > maxGRPCMessage {
+		return nil, errors.New("objcache: gRPC message too large")
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, fmt.Errorf("objcache: bad gRPC frame: %v", err)
+	}
+	return msg, nil
+}
+
+// appendProtoBytes appends the length-delimited protobuf field to b.
+func appendProtoBytes(b []byte, field int, data []byte) []byte {
+	var n [binary.MaxVarintLen64]byte
+	b = append(b, n[:binary.PutUvarint(n[:], uint64(field)<<3|2)]...)
+	b = append(b, n[:binary.PutUvarint(n[:], uint64(len(data)))]...)
+	return append(b, data...)
+}
+
+// decodeProto calls fn with the length-delimited fields of the protobuf
+// message msg, and skips the others.
+func decodeProto(msg []byte, fn func(field int, b []byte)) error {
+	bad := errors.New("objcache: bad protobuf message")
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return bad
+		}
+		msg = msg[n:]
+		switch tag & 7 {
+		case 0: // varint
+			if _, n = binary.Uvarint(msg); n <= 0 {
+				return bad
+			}
+			msg = msg[n:]
+		case 1: // 64-bit
+			if len(msg) < 8 {
+				return bad
+			}
+			msg = msg[8:]
+		case 2:
+			l, n := binary.Uvarint(msg)
+			if n <= 0 || l > uint64(len(msg)-n) {
+				return bad
+			}
+			fn(int(tag>>3), msg[n:n+int(l)])
+			msg = msg[n+int(l):]
+		case 5: // 32-bit
+			if len(msg) < 4 {
+				return bad
+			}
+			msg = msg[4:]
+		default:
+			return bad
+		}
+	}
+	return nil
+}
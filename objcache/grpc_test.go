@@ -0,0 +1,62 @@
+package objcache
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGRPCPool(t *testing.T) {
+	NewGroup("grpc-pool-group", 0, func(ctx Context, key Key) (Value, error) {
+		if key == "fail" {
+			return nil, errors.New("load failed")
+		}
+		return "value of " + key.(string), nil
+	})
+	t.Cleanup(func() { RemoveGroup("grpc-pool-group") })
+
+	srv := httptest.NewUnstartedServer(nil)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+	pool := NewGRPCPool("self", &GRPCPoolOptions{Transport: srv.Client().Transport})
+	srv.Config.Handler = pool
+	pool.Set(srv.URL)
+
+	peer, ok := pool.PickPeer("a/b c")
+	if !ok {
+		t.Fatal("PickPeer: no peer")
+	}
+	data, err := peer.Get(context.Background(), "grpc-pool-group", "a/b c")
+	if err != nil || string(data) != "value of a/b c" {
+		t.Fatal("Get:", string(data), err)
+	}
+	if _, err = peer.Get(nil, "grpc-pool-group", "fail"); err == nil || !strings.Contains(err.Error(), "status 13: load failed") {
+		t.Fatal("Get of a failing key:", err)
+	}
+	if _, err = peer.Get(nil, "no-such-group", "k"); err == nil || !strings.Contains(err.Error(), "status 5") {
+		t.Fatal("Get of an unknown group:", err)
+	}
+
+	pool.Set("self")
+	if _, ok := pool.PickPeer("k"); ok {
+		t.Fatal("PickPeer of a key owned by self")
+	}
+}
+
+func TestProto(t *testing.T) {
+	msg := appendProtoBytes(appendProtoBytes(nil, 1, []byte("g")), 2, []byte("key"))
+	msg = append(msg, 3<<3|0, 0x96, 0x01) // an unknown varint field
+	var got []string
+	if err := decodeProto(msg, func(field int, b []byte) { got = append(got, string(b)) }); err != nil {
+		t.Fatal("decodeProto:", err)
+	}
+	if len(got) != 2 || got[0] != "g" || got[1] != "key" {
+		t.Fatal("decodeProto:", got)
+	}
+	if err := decodeProto([]byte{1<<3 | 2, 10, 'x'}, func(int, []byte) {}); err == nil {
+		t.Fatal("decodeProto of a truncated message")
+	}
+}
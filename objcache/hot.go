@@ -0,0 +1,88 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"math/rand"
+)
+
+// CacheType selects a tier of the cache of a group, see Group.StatsOf.
+type CacheType int
+
+const (
+	// MainCache is the tier of the keys the process is the owner of, which
+	// are loaded by the getter of the group.
+	MainCache CacheType = iota + 1
+
+	// HotCache is the tier of the keys owned by peers that are popular
+	// enough to be kept locally, to avoid network hotspotting. It has its
+	// own capacity, an eighth of the main one by default.
+	HotCache
+)
+
+// hotRatio is the inverse of the probability that a value fetched from a
+// peer is promoted to the hot cache: keys requested often are likely to be
+// promoted soon, while sporadic ones mostly aren't.
+const hotRatio = 10
+
+func hotCapacity(cacheNum int) int {
+	if cacheNum <= 0 {
+		return 0
+	}
+	return (cacheNum + 7) / 8
+}
+
+// StatsOf returns the stats of a tier of the cache of the group.
+func (g *Group) StatsOf(which CacheType) CacheStats {
+	switch which {
+	case MainCache:
+		return g.mainCache.stats()
+	case HotCache:
+		return g.hotCache.stats()
+	}
+	return CacheStats{}
+}
+
+// HotCapacity returns the max number of entries of the hot cache, 0 if
+// there is no limit.
+func (g *Group) HotCapacity() int {
+	return g.hotCache.capacity()
+}
+
+// SetHotCapacity changes the max number of entries of the hot cache. Zero
+// means no limit.
+func (g *Group) SetHotCapacity(n int) {
+	g.hotCache.setCapacity(n)
+}
+
+// SetHotMaxBytes sets the byte budget of the hot cache, see SetMaxBytes.
+func (g *Group) SetHotMaxBytes(n int64) {
+	g.hotCache.setMaxBytes(n)
+}
+
+// fetchedFromPeer is called by a load with the value of key it fetched from
+// the peer that owns it. It promotes some of these values to the hot cache.
+func (g *Group) fetchedFromPeer(key Key, value Value) {
+	if rand.Intn(hotRatio) == 0 {
+		g.promote(key, value)
+	}
+}
+
+// promote adds the value of key being loaded to the hot cache.
+func (g *Group) promote(key Key, value Value) {
+	g.flights.commit(key, func() { g.hotCache.add(key, value) })
+}
@@ -0,0 +1,53 @@
+package objcache
+
+import (
+	"testing"
+)
+
+func TestHotCache(t *testing.T) {
+	g := NewGroup("hot-group", 16, func(ctx Context, key Key) (Value, error) {
+		return "main:" + key.(string), nil
+	})
+	t.Cleanup(func() { RemoveGroup("hot-group") })
+	if n := g.HotCapacity(); n != 2 {
+		t.Fatal("HotCapacity:", n)
+	}
+	fetch := func(key string) {
+		g.flights.do(nil, key, func() (Value, error) {
+			g.promote(key, "peer:"+key)
+			return nil, nil
+		})
+	}
+	fetch("a")
+	if v, err := g.Get(nil, "a"); err != nil || v != "peer:a" {
+		t.Fatal("Get of a hot key:", v, err)
+	}
+	if s := g.StatsOf(HotCache); s.Items != 1 || s.Hits != 1 {
+		t.Fatalf("hot stats: %+v", s)
+	}
+	if s := g.StatsOf(MainCache); s.Items != 0 || s.Gets != 1 {
+		t.Fatalf("main stats: %+v", s)
+	}
+
+	fetch("b")
+	fetch("c")
+	if s := g.StatsOf(HotCache); s.Items != 2 || s.Evictions != 1 {
+		t.Fatalf("hot stats after eviction: %+v", s)
+	}
+
+	g.Set("b", "set:b")
+	if v, _ := g.TryGet("b"); v != "set:b" || g.StatsOf(HotCache).Items != 1 {
+		t.Fatal("Set of a hot key:", v)
+	}
+	if !g.Remove("c") || g.StatsOf(HotCache).Items != 0 {
+		t.Fatal("Remove of a hot key")
+	}
+	fetch("d")
+	if n := g.Purge(); n != 2 {
+		t.Fatal("Purge:", n)
+	}
+	g.promote("e", "peer:e") // not within a fetch: ignored
+	if _, ok := g.TryGet("e"); ok {
+		t.Fatal("value promoted outside of a fetch")
+	}
+}
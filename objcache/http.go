@@ -0,0 +1,516 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qiniu/x/objcache/consistenthash"
+)
+
+// DefaultBasePath is the default URL path prefix of the requests between
+// peers of an HTTPPool.
+const DefaultBasePath = "/_objcache/"
+
+const (
+	// DefaultMaxBatch is the default maximum number of keys fetched from a
+	// peer by a batch request.
+	DefaultMaxBatch = 64
+
+	// DefaultCompressAbove is the default size in bytes above which a peer
+	// compresses its responses.
+	DefaultCompressAbove = 4 << 10
+
+	// DefaultPeerTimeout is the default timeout of the requests to peers.
+	DefaultPeerTimeout = 10 * time.Second
+)
+
+// The bounds of the batch requests a peer serves, so that a request can't
+// take the memory nor the goroutines of the process.
+const (
+	maxBatchKeys     = 1024    // keys of a batch, and max MaxBatch
+	maxBatchBody     = 1 << 20 // bytes of the body of a batch request
+	batchParallelism = 16      // keys of a batch loaded at once
+)
+
+// HTTPPoolOptions configures an HTTPPool.
+type HTTPPoolOptions struct {
+	// BasePath is the URL path prefix of the requests between peers.
+	// Default DefaultBasePath.
+	BasePath string
+
+	// Transport is used to fetch values from peers. Nil means
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Timeout bounds each request to a peer, the reading of its response
+	// included. Zero means DefaultPeerTimeout, and a negative value no
+	// timeout.
+	Timeout time.Duration
+
+	// NewRing creates the ring assigning keys to peers, e.g.
+	// RendezvousRing. Nil means ConsistentRing(Replicas, HashFn).
+	NewRing NewRingFunc
+
+	// Replicas is the number of points of each peer on the default hash
+	// ring. Default consistenthash.DefaultReplicas.
+	Replicas int
+
+	// HashFn hashes the keys and the points of the peers on the default
+	// hash ring. Default XXH64, see ConsistentRing. All the peers must use
+	// the same function.
+	HashFn consistenthash.Hash
+
+	// BatchWindow is how long a fetch from a peer waits for other fetches
+	// of the same group from the same peer, to send them all by one
+	// request. Zero means every key is fetched by its own request.
+	BatchWindow time.Duration
+
+	// MaxBatch is the maximum number of keys of a batch request: a full
+	// batch is sent without waiting for the end of BatchWindow. Default
+	// DefaultMaxBatch, and at most 1024, the most a peer serves.
+	MaxBatch int
+
+	// CompressAbove is the size in bytes above which the responses served
+	// to the other peers are gzip compressed. Zero means
+	// DefaultCompressAbove, and a negative value disables compression.
+	CompressAbove int
+}
+
+// HTTPPool is a pool of peers talking HTTP. It is a PeerPicker, and an
+// http.Handler serving the values of the groups of the current process to
+// the other peers, to be mounted at its BasePath:
+//
+//	pool := objcache.NewHTTPPool("http://10.0.0.1:8080", nil)
+//	pool.Set("http://10.0.0.1:8080", "http://10.0.0.2:8080")
+//	http.Handle(objcache.DefaultBasePath, pool)
+//	objcache.RegisterNewGroupHook(func(g *objcache.Group) {
+//		g.SetPeerPicker(pool)
+//	})
+//
+// A peer serves GET requests to BasePath + group + "/" + key (both path
+// escaped) with the value encoded by EncodeValue, and POST requests to
+// BasePath + group + "/" with the values of a batch of keys. The body of a
+// batch request is a sequence of keys, each one preceded by its length as
+// a uvarint. The body of its response holds the result of each key in the
+// same order: a byte that is 0 for a value and 1 for an error, followed by
+// the length as a uvarint and the bytes of the value or of the error
+// message. A peer serves batches of at most 1024 keys and 1 MiB, loading 16
+// keys at a time. Responses larger than CompressAbove are gzip compressed if
+// the request accepts it.
+type HTTPPool struct {
+	self     string
+	basePath string
+	client   *http.Client
+
+	batchWindow   time.Duration
+	maxBatch      int
+	compressAbove int
+
+	newRing NewRingFunc
+
+	mu      sync.RWMutex
+	ring    PeerRing
+	getters map[string]*httpGetter
+}
+
+// NewHTTPPool creates a pool of peers for the current process, whose base
+// URL is self (e.g. "http://10.0.0.1:8080").
+func NewHTTPPool(self string, opts *HTTPPoolOptions) *HTTPPool {
+	p := &HTTPPool{
+		self: self, basePath: DefaultBasePath, maxBatch: DefaultMaxBatch, compressAbove: DefaultCompressAbove,
+	}
+	client := &http.Client{Timeout: DefaultPeerTimeout}
+	p.newRing = ringOf(nil, 0, nil)
+	if opts != nil {
+		p.newRing = ringOf(opts.NewRing, opts.Replicas, opts.HashFn)
+		p.batchWindow = opts.BatchWindow
+		if opts.MaxBatch > 0 {
+			p.maxBatch = opts.MaxBatch
+			if p.maxBatch > maxBatchKeys {
+				p.maxBatch = maxBatchKeys
+			}
+		}
+		if opts.CompressAbove != 0 {
+			p.compressAbove = opts.CompressAbove
+		}
+		if opts.BasePath != "" {
+			p.basePath = opts.BasePath
+		}
+		client.Transport = opts.Transport
+		if opts.Timeout != 0 {
+			client.Timeout = opts.Timeout
+		}
+		if client.Timeout < 0 {
+			client.Timeout = 0
+		}
+	}
+	p.client = client
+	return p
+}
+
+// BasePath returns the URL path prefix the pool is to be mounted at.
+func (p *HTTPPool) BasePath() string {
+	return p.basePath
+}
+
+// Set replaces the peers of the pool with peers, the base URLs of all the
+// processes of the pool (the current one included).
+func (p *HTTPPool) Set(peers ...string) {
+	ring := p.newRing(peers)
+	getters := make(map[string]*httpGetter, len(peers))
+	for _, peer := range peers {
+		getters[peer] = &httpGetter{
+			client: p.client, baseURL: strings.TrimSuffix(peer, "/") + p.basePath,
+			window: p.batchWindow, maxBatch: p.maxBatch,
+		}
+	}
+	p.mu.Lock()
+	p.getters = getters
+	p.ring = ring
+	p.mu.Unlock()
+}
+
+// PickPeer implements PeerPicker.
+func (p *HTTPPool) PickPeer(key string) (ProtoGetter, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.getters) == 0 {
+		return nil, false
+	}
+	peer := p.ring.Get(key)
+	if peer == p.self {
+		return nil, false
+	}
+	return p.getters[peer], true
+}
+
+// ServeHTTP serves the values of the groups of the current process.
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, p.basePath) {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.SplitN(r.URL.EscapedPath()[len(p.basePath):], "/", 2)
+	if len(parts) != 2 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	name, err1 := url.PathUnescape(parts[0])
+	key, err2 := url.PathUnescape(parts[1])
+	if err1 != nil || err2 != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	g := GetGroup(name)
+	if g == nil {
+		http.Error(w, "no such group: "+name, http.StatusNotFound)
+		return
+	}
+	if r.Method == "POST" && key == "" {
+		p.serveBatch(w, r, g)
+		return
+	}
+	val, err := g.Get(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := EncodeValue(val)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.write(w, r, data)
+}
+
+func (p *HTTPPool) serveBatch(w http.ResponseWriter, r *http.Request, g *Group) {
+	keys, err := readBatchKeys(http.MaxBytesReader(w, r.Body, maxBatchBody))
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	data := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, batchParallelism)
+	for i, key := range keys {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			val, err := g.Get(r.Context(), key)
+			if err == nil {
+				data[i], err = EncodeValue(val)
+			}
+			errs[i] = err
+			<-sem
+		}(i, key)
+	}
+	wg.Wait()
+
+	var buf bytes.Buffer
+	for i := range keys {
+		if errs[i] != nil {
+			buf.WriteByte(1)
+			writeBatchItem(&buf, []byte(errs[i].Error()))
+		} else {
+			buf.WriteByte(0)
+			writeBatchItem(&buf, data[i])
+		}
+	}
+	p.write(w, r, buf.Bytes())
+}
+
+// write sends data, compressed if it is large enough and r accepts it.
+func (p *HTTPPool) write(w http.ResponseWriter, r *http.Request, data []byte) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if p.compressAbove < 0 || len(data) <= p.compressAbove || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	zw := gzip.NewWriter(w)
+	zw.Write(data)
+	zw.Close()
+}
+
+func writeBatchItem(buf *bytes.Buffer, b []byte) {
+	var n [binary.MaxVarintLen64]byte
+	buf.Write(n[:binary.PutUvarint(n[:], uint64(len(b)))])
+	buf.Write(b)
+}
+
+func readBatchItem(r *bufio.Reader, max uint64) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > max {
+		return nil, errors.New("objcache: batch item too large")
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func readBatchKeys(body io.Reader) (keys []string, err error) {
+	r := bufio.NewReader(body)
+	for {
+		if _, err = r.Peek(1); err == io.EOF {
+			return keys, nil
+		}
+		if len(keys) == maxBatchKeys {
+			return nil, errors.New("objcache: too many keys in batch")
+		}
+		key, err := readBatchItem(r, maxBatchBody)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, string(key))
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type httpGetter struct {
+	client   *http.Client
+	baseURL  string
+	window   time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	batches map[string]*peerBatch // pending batch of each group
+}
+
+// A peerBatch is a batch of keys of a group to be fetched by one request.
+// The request is canceled once all its callers are gone.
+type peerBatch struct {
+	group   string
+	keys    []string
+	calls   []*batchCall
+	timer   *time.Timer
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiting int // callers, guarded by the mutex of the getter
+}
+
+type batchCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+func (h *httpGetter) Get(ctx Context, group string, key string) ([]byte, error) {
+	c, ok := ctx.(context.Context)
+	if !ok {
+		c = context.Background()
+	}
+	if h.window <= 0 {
+		return h.get(c, group, key)
+	}
+
+	call := &batchCall{done: make(chan struct{})}
+	h.mu.Lock()
+	b := h.batches[group]
+	if b == nil {
+		if h.batches == nil {
+			h.batches = make(map[string]*peerBatch)
+		}
+		b = &peerBatch{group: group}
+		b.ctx, b.cancel = context.WithCancel(context.Background())
+		b.timer = time.AfterFunc(h.window, func() { h.flush(b) })
+		h.batches[group] = b
+	}
+	b.keys = append(b.keys, key)
+	b.calls = append(b.calls, call)
+	b.waiting++
+	full := len(b.keys) >= h.maxBatch
+	if full {
+		delete(h.batches, group)
+	}
+	h.mu.Unlock()
+	if full && b.timer.Stop() {
+		go h.send(b)
+	}
+
+	select {
+	case <-call.done:
+		return call.data, call.err
+	case <-c.Done():
+		h.mu.Lock()
+		if b.waiting--; b.waiting == 0 {
+			b.cancel()
+		}
+		h.mu.Unlock()
+		return nil, c.Err()
+	}
+}
+
+// flush sends b at the end of the batch window.
+func (h *httpGetter) flush(b *peerBatch) {
+	h.mu.Lock()
+	if h.batches[b.group] == b {
+		delete(h.batches, b.group)
+	}
+	h.mu.Unlock()
+	h.send(b)
+}
+
+// send fetches the keys of b, which is no longer pending. The request isn't
+// canceled by a caller giving up, since the other keys of the batch may
+// still be waited for, but by the last one.
+func (h *httpGetter) send(b *peerBatch) {
+	defer b.cancel()
+	if len(b.keys) == 1 {
+		call := b.calls[0]
+		call.data, call.err = h.get(b.ctx, b.group, b.keys[0])
+		close(call.done)
+		return
+	}
+	results, err := h.getBatch(b.ctx, b.group, b.keys)
+	for i, call := range b.calls {
+		if err != nil {
+			call.err = err
+		} else {
+			call.data, call.err = results[i].data, results[i].err
+		}
+		close(call.done)
+	}
+}
+
+func (h *httpGetter) get(ctx context.Context, group string, key string) ([]byte, error) {
+	req, err := http.NewRequest("GET", h.baseURL+url.PathEscape(group)+"/"+url.PathEscape(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	return h.do(req.WithContext(ctx))
+}
+
+func (h *httpGetter) getBatch(ctx context.Context, group string, keys []string) ([]batchCall, error) {
+	var body bytes.Buffer
+	for _, key := range keys {
+		writeBatchItem(&body, []byte(key))
+	}
+	req, err := http.NewRequest("POST", h.baseURL+url.PathEscape(group)+"/", &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	data, err := h.do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(bytes.NewReader(data))
+	results := make([]batchCall, len(keys))
+	for i := range results {
+		status, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("objcache: peer %s: bad batch response: %v", h.baseURL, err)
+		}
+		item, err := readBatchItem(r, uint64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("objcache: peer %s: bad batch response: %v", h.baseURL, err)
+		}
+		if status == 0 {
+			results[i].data = item
+		} else {
+			results[i].err = fmt.Errorf("objcache: peer %s: %s", h.baseURL, item)
+		}
+	}
+	return results, nil
+}
+
+// do sends req and returns the body of a successful response.
+func (h *httpGetter) do(req *http.Request) ([]byte, error) {
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var body io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		body = zr
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("objcache: peer %s: %s: %s", h.baseURL, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -0,0 +1,313 @@
+package objcache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakePeer struct {
+	mu   sync.Mutex
+	keys []string
+	err  error
+}
+
+func (p *fakePeer) Get(ctx Context, group string, key string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, group+"/"+key)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []byte("peer:" + key), nil
+}
+
+type fakePicker struct {
+	peer *fakePeer
+}
+
+func (p fakePicker) PickPeer(key string) (ProtoGetter, bool) {
+	if strings.HasPrefix(key, "remote") {
+		return p.peer, true
+	}
+	return nil, false
+}
+
+func TestPeerFetch(t *testing.T) {
+	g := NewGroup("peer-fetch-group", 0, func(ctx Context, key Key) (Value, error) {
+		return "local", nil
+	})
+	t.Cleanup(func() { RemoveGroup("peer-fetch-group") })
+	peer := new(fakePeer)
+	g.SetPeerPicker(fakePicker{peer})
+
+	if v, err := g.Get(nil, "mine"); err != nil || v != "local" {
+		t.Fatal("Get of an owned key:", v, err)
+	}
+	v, err := g.Get(nil, "remote1")
+	if err != nil || string(v.([]byte)) != "peer:remote1" {
+		t.Fatal("Get of a key owned by a peer:", v, err)
+	}
+	if len(peer.keys) != 1 || peer.keys[0] != "peer-fetch-group/remote1" {
+		t.Fatal("peer requests:", peer.keys)
+	}
+	if g.CacheStats().Items != 1 {
+		t.Fatal("value of a peer cached in the main cache")
+	}
+	if v, err := g.Get(nil, 42); err != nil || v != "local" {
+		t.Fatal("Get of a non-string key:", v, err)
+	}
+
+	peer.err = errors.New("peer down")
+	if v, err := g.Get(nil, "remote2"); err != nil || v != "local" {
+		t.Fatal("Get when the peer fails:", v, err)
+	}
+	s := g.LoadStats()
+	if s.Loads != 4 || s.LocalLoads != 3 || s.LoadErrors != 0 || s.PeerLoads != 1 || s.PeerErrors != 1 {
+		t.Fatalf("load stats: %+v", s)
+	}
+}
+
+func TestHTTPPool(t *testing.T) {
+	NewGroup("http-pool-group", 0, func(ctx Context, key Key) (Value, error) {
+		if key == "fail" {
+			return nil, errors.New("load failed")
+		}
+		return "value of " + key.(string), nil
+	})
+	t.Cleanup(func() { RemoveGroup("http-pool-group") })
+
+	pool := NewHTTPPool("self", nil)
+	srv := httptest.NewServer(pool)
+	defer srv.Close()
+	pool.Set(srv.URL)
+
+	peer, ok := pool.PickPeer("a/b c")
+	if !ok {
+		t.Fatal("PickPeer: no peer")
+	}
+	data, err := peer.Get(context.Background(), "http-pool-group", "a/b c")
+	if err != nil || string(data) != "value of a/b c" {
+		t.Fatal("Get:", string(data), err)
+	}
+	if _, err = peer.Get(nil, "http-pool-group", "fail"); err == nil || !strings.Contains(err.Error(), "load failed") {
+		t.Fatal("Get of a failing key:", err)
+	}
+	if _, err = peer.Get(nil, "no-such-group", "k"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatal("Get of an unknown group:", err)
+	}
+
+	pool.Set(srv.URL, "self")
+	owned := 0
+	for i := 0; i < 1000; i++ {
+		if _, ok := pool.PickPeer(strconv.Itoa(i)); !ok {
+			owned++
+		}
+	}
+	if owned < 200 || owned > 800 {
+		t.Fatal("keys owned by self:", owned)
+	}
+}
+
+func TestPeerRings(t *testing.T) {
+	var picked []string
+	ring := func(peers []string) PeerRing {
+		picked = peers
+		return RendezvousRing(peers)
+	}
+	pool := NewHTTPPool("self", &HTTPPoolOptions{NewRing: ring})
+	if _, ok := pool.PickPeer("k"); ok {
+		t.Fatal("PickPeer without peers: ok")
+	}
+	pool.Set("http://a", "self")
+	if len(picked) != 2 {
+		t.Fatal("NewRing not called:", picked)
+	}
+	owned := 0
+	for i := 0; i < 1000; i++ {
+		if _, ok := pool.PickPeer(strconv.Itoa(i)); !ok {
+			owned++
+		}
+	}
+	if owned < 400 || owned > 600 {
+		t.Fatal("keys owned by self:", owned)
+	}
+	for _, newRing := range []NewRingFunc{ConsistentRing(10, nil), RendezvousRing} {
+		r := newRing([]string{"a", "b", "c"})
+		if p := r.Get("k"); p == "" || r.Get("k") != p {
+			t.Fatal("Get:", p)
+		}
+		if p := newRing(nil).Get("k"); p != "" {
+			t.Fatal("Get without peers:", p)
+		}
+	}
+}
+
+type recordingTransport struct {
+	mu        sync.Mutex
+	requests  []string
+	encodings []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil {
+		t.mu.Lock()
+		t.requests = append(t.requests, req.Method+" "+req.URL.Path)
+		t.encodings = append(t.encodings, resp.Header.Get("Content-Encoding"))
+		t.mu.Unlock()
+	}
+	return resp, err
+}
+
+func TestHTTPPoolBatch(t *testing.T) {
+	NewGroup("http-batch-group", 0, func(ctx Context, key Key) (Value, error) {
+		if key == "fail" {
+			return nil, errors.New("load failed")
+		}
+		if key == "big" {
+			return strings.Repeat("x", 1000), nil
+		}
+		return "value of " + key.(string), nil
+	})
+	t.Cleanup(func() { RemoveGroup("http-batch-group") })
+
+	tr := new(recordingTransport)
+	pool := NewHTTPPool("self", &HTTPPoolOptions{
+		Transport: tr, BatchWindow: 20 * time.Millisecond, MaxBatch: 4, CompressAbove: 100,
+	})
+	srv := httptest.NewServer(pool)
+	defer srv.Close()
+	pool.Set(srv.URL)
+	peer, _ := pool.PickPeer("k")
+
+	keys := []string{"a", "b c", "fail", "big"}
+	data := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			data[i], errs[i] = peer.Get(nil, "http-batch-group", key)
+		}(i, key)
+	}
+	wg.Wait()
+	if errs[0] != nil || string(data[0]) != "value of a" || errs[1] != nil || string(data[1]) != "value of b c" {
+		t.Fatal("batched Get:", string(data[0]), errs[0], string(data[1]), errs[1])
+	}
+	if errs[2] == nil || !strings.Contains(errs[2].Error(), "load failed") {
+		t.Fatal("batched Get of a failing key:", errs[2])
+	}
+	if errs[3] != nil || len(data[3]) != 1000 {
+		t.Fatal("batched Get of a large value:", len(data[3]), errs[3])
+	}
+	if len(tr.requests) != 1 || tr.requests[0] != "POST /_objcache/http-batch-group/" || tr.encodings[0] != "gzip" {
+		t.Fatal("requests:", tr.requests, tr.encodings)
+	}
+
+	// a key alone in its window is fetched by a GET, compressed only if large
+	for _, key := range []string{"a", "big"} {
+		if _, err := peer.Get(context.Background(), "http-batch-group", key); err != nil {
+			t.Fatal("Get:", err)
+		}
+	}
+	if len(tr.requests) != 3 || tr.requests[1] != "GET /_objcache/http-batch-group/a" || tr.encodings[1] != "" || tr.encodings[2] != "gzip" {
+		t.Fatal("requests:", tr.requests, tr.encodings)
+	}
+}
+
+func TestHTTPPoolBatchBounds(t *testing.T) {
+	var mu sync.Mutex
+	var running, most int
+	NewGroup("http-bounds-group", 0, func(ctx Context, key Key) (Value, error) {
+		mu.Lock()
+		if running++; running > most {
+			most = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("http-bounds-group") })
+	pool := NewHTTPPool("self", nil)
+	srv := httptest.NewServer(pool)
+	defer srv.Close()
+	post := func(body []byte) int {
+		resp, err := http.Post(srv.URL+"/_objcache/http-bounds-group/", "application/octet-stream", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal("Post:", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	batch := func(n, size int) []byte {
+		var buf bytes.Buffer
+		for i := 0; i < n; i++ {
+			writeBatchItem(&buf, []byte(fmt.Sprintf("%0*d", size, i)))
+		}
+		return buf.Bytes()
+	}
+	if code := post(batch(100, 4)); code != http.StatusOK || most > batchParallelism {
+		t.Fatal("batch:", code, most)
+	}
+	if code := post(batch(maxBatchKeys+1, 4)); code != http.StatusBadRequest {
+		t.Fatal("batch of too many keys:", code)
+	}
+	if code := post(batch(2, maxBatchBody/2)); code != http.StatusBadRequest {
+		t.Fatal("batch too large:", code)
+	}
+	if code := post([]byte{0xff, 0xff, 0xff, 0xff, 0x0f}); code != http.StatusBadRequest {
+		t.Fatal("batch of a corrupt length:", code)
+	}
+	if p := NewHTTPPool("self", &HTTPPoolOptions{MaxBatch: 1 << 20}); p.maxBatch != maxBatchKeys || p.client.Timeout != DefaultPeerTimeout {
+		t.Fatal("NewHTTPPool:", p.maxBatch, p.client.Timeout)
+	}
+}
+
+func TestHTTPPoolBatchCancel(t *testing.T) {
+	canceled := make(chan struct{}, 2)
+	NewGroup("http-cancel-group", 0, func(ctx Context, key Key) (Value, error) {
+		<-ctx.(context.Context).Done()
+		canceled <- struct{}{}
+		return nil, errors.New("canceled")
+	})
+	t.Cleanup(func() { RemoveGroup("http-cancel-group") })
+	pool := NewHTTPPool("self", &HTTPPoolOptions{BatchWindow: 10 * time.Millisecond})
+	srv := httptest.NewServer(pool)
+	defer srv.Close()
+	pool.Set(srv.URL)
+	peer, _ := pool.PickPeer("k")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			if _, err := peer.Get(ctx, "http-cancel-group", key); err != context.DeadlineExceeded {
+				t.Error("Get:", err)
+			}
+		}(key)
+	}
+	wg.Wait()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("batch request not canceled with its callers")
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// EntryInfo describes a cached entry, see Group.EntryInfo.
+type EntryInfo struct {
+	Age        time.Duration // since the value was cached, see Group.SetEntryInfo
+	LastAccess time.Time     // of the last lookup hitting the entry, or when it was cached
+	Expires    time.Time     // zero if the entry has no TTL
+	Size       int64         // of the value, 0 if unknown: see Sizer
+	Pinned     bool          // see Group.Pin
+}
+
+// Contains reports whether key is cached, without changing the order of
+// eviction nor the stats of the group: see Peek.
+func (g *Group) Contains(key Key) bool {
+	_, ok := g.Peek(key)
+	return ok
+}
+
+// EntryInfo returns the metadata of the cached entry of key, if any, without
+// changing the order of eviction nor the stats of the group, e.g. for health
+// checks and admin tools. Cached errors and expired entries are not reported.
+// As with Peek, if the policy of the group isn't a Peeker, the lookup counts
+// as an access. Age and LastAccess are zero unless SetEntryInfo is on.
+func (g *Group) EntryInfo(key Key) (info EntryInfo, ok bool) {
+	if info, ok = g.mainCache.shardOf(key).info(key); !ok {
+		info, ok = g.hotCache.shardOf(key).info(key)
+	}
+	return
+}
+
+// SetEntryInfo turns on the tracking of the age and last access of the
+// entries for EntryInfo. It is off by default, as it costs a reading of the
+// clock on every hit and a record per entry. Only the entries cached from now
+// on are tracked, so it is best called right after NewGroup.
+func (g *Group) SetEntryInfo(on bool) {
+	g.mainCache.setInfo(on)
+	g.hotCache.setInfo(on)
+}
+
+// -----------------------------------------------------------------------------
+
+type entryStamp struct {
+	added    time.Time
+	accessed time.Time
+}
+
+// touchLocked records an access of key, if the shard tracks them.
+func (c *shard) touchLocked(key Key) {
+	if !c.stamped {
+		return
+	}
+	if s, ok := c.stamps[key]; ok {
+		s.accessed = c.now()
+		c.stamps[key] = s
+	}
+}
+
+func (c *cache) setInfo(on bool) {
+	for i := range c.shards {
+		c.shards[i].setInfo(on)
+	}
+}
+
+func (c *shard) setInfo(on bool) {
+	c.mu.Lock()
+	c.stamped = on
+	if !on {
+		c.stamps = nil
+	}
+	c.mu.Unlock()
+}
+
+func (c *shard) info(key Key) (info EntryInfo, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var value Value
+	if p, pinned := c.pinned[key]; pinned {
+		value, ok = p.value, true
+		info.Pinned = true
+	} else {
+		value, ok = c.lookupLocked(key)
+	}
+	if !ok {
+		return
+	}
+	if _, neg := value.(*negEntry); neg || (!info.Pinned && c.oldLocked(key)) {
+		return info, false
+	}
+	now := c.now()
+	if exp, has := c.expires[key]; has {
+		if !now.Before(exp) && !info.Pinned {
+			return info, false
+		}
+		info.Expires = exp
+	}
+	if s, has := c.stamps[key]; has {
+		info.Age = now.Sub(s.added)
+		info.LastAccess = s.accessed
+	}
+	info.Size = sizeOf(value)
+	return info, true
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,67 @@
+package objcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEntryInfo(t *testing.T) {
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g := NewGroupWith("entry-info-group", func(ctx Context, key Key) (Value, error) {
+		if key == "bad" {
+			return nil, errors.New("bad")
+		}
+		return "value", nil
+	}, WithMaxItems(10), WithClock(clock), WithEntryInfo())
+	t.Cleanup(func() { RemoveGroup("entry-info-group") })
+	g.SetNegativeTTL(time.Minute)
+	start := clock.now
+	g.Get(nil, "a")
+	g.SetWithTTL("b", []byte("bb"), time.Minute)
+	g.Get(nil, "bad")
+	stats := g.CacheStats()
+	if !g.Contains("a") || g.Contains("bad") || g.Contains("c") {
+		t.Fatal("Contains")
+	}
+	clock.now = clock.now.Add(time.Second)
+	g.Get(nil, "a")
+	clock.now = clock.now.Add(time.Second)
+	info, ok := g.EntryInfo("a")
+	if !ok || info.Age != 2*time.Second || !info.LastAccess.Equal(start.Add(time.Second)) || info.Size != 5 || !info.Expires.IsZero() {
+		t.Fatal("EntryInfo of a:", info, ok)
+	}
+	info, ok = g.EntryInfo("b")
+	if !ok || !info.Expires.Equal(start.Add(time.Minute)) || info.Size != 2 || !info.LastAccess.Equal(start) {
+		t.Fatal("EntryInfo of b:", info, ok)
+	}
+	if _, ok = g.EntryInfo("bad"); ok {
+		t.Fatal("EntryInfo of a cached error")
+	}
+	stats.Gets++ // by the Get of a
+	stats.Hits++
+	if g.CacheStats() != stats {
+		t.Fatal("probes changed the stats:", g.CacheStats(), stats)
+	}
+	clock.now = start.Add(time.Minute)
+	if _, ok = g.EntryInfo("b"); ok || g.Contains("b") {
+		t.Fatal("expired entry reported")
+	}
+}
+
+func TestEntryInfoOff(t *testing.T) {
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g := NewGroupWith("entry-info-off-group", func(ctx Context, key Key) (Value, error) {
+		return "value", nil
+	}, WithClock(clock))
+	t.Cleanup(func() { RemoveGroup("entry-info-off-group") })
+	g.Get(nil, "a")
+	clock.now = clock.now.Add(time.Second)
+	g.Get(nil, "a")
+	if info, ok := g.EntryInfo("a"); !ok || info.Age != 0 || !info.LastAccess.IsZero() || info.Size != 5 {
+		t.Fatal("EntryInfo without tracking:", info, ok)
+	}
+	if g.mainCache.shardOf("a").stamps != nil {
+		t.Fatal("entries stamped without tracking")
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// StartJanitor starts a goroutine that reaps the expired entries of the group
+// every interval, see ReapExpired, until StopJanitor. Otherwise an expired
+// entry stays in the cache, holding its memory and resources, until it is
+// looked up or evicted. Starting the janitor again changes its interval.
+func (g *Group) StartJanitor(interval time.Duration) {
+	if interval <= 0 {
+		panic("objcache: non-positive janitor interval")
+	}
+	g.janitorMu.Lock()
+	defer g.janitorMu.Unlock()
+	if g.janitor != nil {
+		close(g.janitor)
+	}
+	g.janitor = make(chan struct{})
+	go g.runJanitor(interval, g.janitor)
+}
+
+func (g *Group) runJanitor(interval time.Duration, done chan struct{}) {
+	timer := g.clock.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C():
+			g.ReapExpired()
+			timer.Reset(interval)
+		}
+	}
+}
+
+// StopJanitor stops the goroutine of StartJanitor, if any.
+func (g *Group) StopJanitor() {
+	g.janitorMu.Lock()
+	defer g.janitorMu.Unlock()
+	if g.janitor != nil {
+		close(g.janitor)
+		g.janitor = nil
+	}
+}
+
+// ReapExpired removes the expired entries of the group, passing their values
+// to onEvicted and disposing of them, and returns their number. It locks one
+// shard of the cache at a time. Pinned entries are kept, and so are those
+// that may still be served stale (see SetMaxStale). The entries invalidated
+// by InvalidateAll are removed too, but not counted.
+func (g *Group) ReapExpired() int {
+	return g.mainCache.reapExpired() + g.hotCache.reapExpired()
+}
+
+func (c *cache) reapExpired() (n int) {
+	for i := range c.shards {
+		n += c.shards[i].reapExpired()
+	}
+	return
+}
+
+func (c *shard) reapExpired() (n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, exp := range c.expires {
+		if now.Before(exp.Add(c.maxStale)) {
+			continue
+		}
+		if _, pinned := c.pinned[key]; pinned {
+			continue
+		}
+		size := c.lru.Len()
+		c.reason = EvictExpired
+		c.lru.Remove(key)
+		c.reason = EvictCapacity
+		if c.lru.Len() < size {
+			c.nexpire++
+			n++
+		} else {
+			delete(c.expires, key)
+		}
+	}
+	c.removeOldLocked()
+	return
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,49 @@
+package objcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReapExpired(t *testing.T) {
+	g := NewGroup("janitor-group", 0, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("janitor-group") })
+	var reasons []EvictReason
+	g.OnEvict(func(key Key, value Value, reason EvictReason) {
+		reasons = append(reasons, reason)
+	})
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g.SetClock(clock)
+	g.SetWithTTL("a", "a", time.Second)
+	g.SetWithTTL("b", "b", time.Second)
+	g.SetWithTTL("c", "c", time.Hour)
+	g.Set("d", "d")
+	g.Pin("b")
+	if n := g.ReapExpired(); n != 0 {
+		t.Fatal("ReapExpired before the TTL:", n)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if n := g.ReapExpired(); n != 1 || len(reasons) != 1 || reasons[0] != EvictExpired {
+		t.Fatal("ReapExpired:", n, reasons)
+	}
+	if s := g.CacheStats(); s.Items != 3 || s.Expirations != 1 {
+		t.Fatal("CacheStats:", s)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	g := NewGroupWith("janitor-running-group", nil, WithTTL(time.Millisecond), WithJanitor(time.Millisecond))
+	t.Cleanup(func() { RemoveGroup("janitor-running-group") })
+	defer g.StopJanitor()
+	g.Set("a", "a")
+	for i := 0; g.CacheStats().Items != 0; i++ {
+		if i == 1000 {
+			t.Fatal("the janitor didn't reap the entry")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	g.StopJanitor()
+	g.StopJanitor()
+}
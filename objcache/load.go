@@ -0,0 +1,248 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// Priorities of loads, see WithPriority. Any int is a valid priority: loads
+// of a higher priority are started first.
+const (
+	PriorityBatch       = -10
+	PriorityNormal      = 0
+	PriorityInteractive = 10
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose cache loads are queued with priority
+// p when the group's load limit is reached (see Group.SetMaxLoads), so that
+// e.g. background refreshes don't starve user requests.
+func WithPriority(ctx context.Context, p int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityOf(ctx Context) int {
+	if c, ok := ctx.(context.Context); ok {
+		if p, ok := c.Value(priorityKey{}).(int); ok {
+			return p
+		}
+	}
+	return PriorityNormal
+}
+
+// LoadStats are the statistics of the loads of a group.
+type LoadStats struct {
+	Active     int           // loads in progress
+	QueueDepth int           // loads waiting for a slot
+	Queued     int64         // loads that had to wait, in total
+	Abandoned  int64         // queued loads whose context was done first
+	QueueWait  time.Duration // time spent waiting by all of them
+	MaxWait    time.Duration // the longest wait
+	Loads      int64         // Gets that missed the cache
+	Deduped    int64         // Loads that shared the load of a concurrent one
+	LocalLoads int64         // keys loaded by the getters
+	LoadErrors int64         // local loads that failed
+	PeerLoads  int64         // values fetched from peers
+	PeerErrors int64         // failed fetches from peers, then loaded locally
+	Refreshes  int64         // background reloads, see Group.SetRefreshAfter
+	Timeouts   int64         // Gets that gave up waiting, see Group.GetWithTimeout
+	StaleHits  int64         // stale values served after a failed load, see Group.SetMaxStale
+	Throttled  int64         // loads denied by Group.SetMinReloadInterval
+
+	SecondaryHits   int64 // values found in the secondary store, see Group.SetSecondary
+	SecondaryErrors int64 // failed operations of the secondary store
+}
+
+// -----------------------------------------------------------------------------
+
+// loadLimiter bounds the number of concurrent loads, queueing the others by
+// priority, and then in FIFO order.
+type loadLimiter struct {
+	mu      sync.Mutex
+	max     int // 0 means no limit
+	active  int
+	waiters waitQueue
+	seq     uint64
+	stats   LoadStats
+	resets  int // see Group.ResetStats
+}
+
+type waiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{}
+	index    int // in the heap, -1 once granted
+}
+
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index, q[j].index = i, j
+}
+func (q *waitQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+func (q *waitQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	w.index = -1
+	return w
+}
+
+func (l *loadLimiter) setMax(n int) {
+	l.mu.Lock()
+	l.max = n
+	l.grantLocked()
+	l.mu.Unlock()
+}
+
+// acquire waits for a load slot. It fails only if ctx is a context.Context
+// that is done before a slot is free.
+func (l *loadLimiter) acquire(ctx Context) error {
+	l.mu.Lock()
+	if l.max <= 0 || (l.active < l.max && len(l.waiters) == 0) {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	l.seq++
+	w := &waiter{priority: priorityOf(ctx), seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.waiters, w)
+	l.stats.Queued++
+	l.mu.Unlock()
+
+	start := time.Now()
+	var done <-chan struct{}
+	if c, ok := ctx.(context.Context); ok {
+		done = c.Done()
+	}
+	select {
+	case <-w.ready:
+		l.recordWait(time.Since(start))
+		return nil
+	case <-done:
+		l.mu.Lock()
+		if w.index < 0 { // granted meanwhile
+			l.mu.Unlock()
+			l.release()
+		} else {
+			heap.Remove(&l.waiters, w.index)
+			l.stats.Abandoned++
+			l.mu.Unlock()
+		}
+		l.recordWait(time.Since(start))
+		return ctx.(context.Context).Err()
+	}
+}
+
+func (l *loadLimiter) recordWait(d time.Duration) {
+	l.mu.Lock()
+	l.stats.QueueWait += d
+	if d > l.stats.MaxWait {
+		l.stats.MaxWait = d
+	}
+	l.mu.Unlock()
+}
+
+func (l *loadLimiter) release() {
+	l.mu.Lock()
+	l.active--
+	l.grantLocked()
+	l.mu.Unlock()
+}
+
+func (l *loadLimiter) grantLocked() {
+	for len(l.waiters) > 0 && (l.max <= 0 || l.active < l.max) {
+		w := heap.Pop(&l.waiters).(*waiter)
+		l.active++
+		close(w.ready)
+	}
+}
+
+// inc increments counter, a field of l.stats.
+func (l *loadLimiter) inc(counter *int64) {
+	l.mu.Lock()
+	*counter++
+	l.mu.Unlock()
+}
+
+func (l *loadLimiter) loadStats() LoadStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.stats
+	s.Active, s.QueueDepth = l.active, len(l.waiters)
+	return s
+}
+
+// -----------------------------------------------------------------------------
+
+// SetMaxLoads bounds the number of loads of the group running at once to n.
+// Zero means no limit. Loads beyond the limit wait in a queue ordered by the
+// priority of their context (see WithPriority), and give up if it is a
+// context.Context that is done first.
+func (g *Group) SetMaxLoads(n int) {
+	g.loads.setMax(n)
+}
+
+// LoadStats returns the statistics of the loads of the group.
+func (g *Group) LoadStats() LoadStats {
+	s := g.loads.loadStats()
+	s.Deduped = g.flights.dedupedLoads()
+	return s
+}
+
+// load calls get, the getter of the group or the loader of a GetOrLoad,
+// within the load limit, unless the key is throttled.
+func (g *Group) load(ctx Context, key Key, get GetterFunc) (val Value, err error) {
+	if err = g.loads.acquire(ctx); err != nil {
+		return // counted in Abandoned
+	}
+	defer g.loads.release()
+	now := g.clock.Now()
+	if err = g.throttle.check(key, now); err != nil {
+		g.loads.inc(&g.loads.stats.Throttled)
+		return
+	}
+	defer func() {
+		if err != nil {
+			g.loads.inc(&g.loads.stats.LoadErrors)
+		}
+		g.throttle.done(key, now, err)
+	}()
+	g.loads.inc(&g.loads.stats.LocalLoads)
+	start := g.clock.Now()
+	val, err = get(ctx, key)
+	g.observeLoad(key, start, err)
+	return
+}
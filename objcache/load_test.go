@@ -0,0 +1,77 @@
+package objcache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadPriority(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	g := NewGroup("priority-group", 0, func(ctx Context, key Key) (Value, error) {
+		if key == "blocker" {
+			<-release
+		}
+		mu.Lock()
+		order = append(order, key.(string))
+		mu.Unlock()
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("priority-group") })
+	g.SetMaxLoads(1)
+
+	var wg sync.WaitGroup
+	get := func(ctx context.Context, key string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.Get(ctx, key)
+		}()
+	}
+	waitDepth := func(n int) {
+		for deadline := time.Now().Add(time.Second); g.LoadStats().QueueDepth != n; {
+			if time.Now().After(deadline) {
+				t.Fatal("queue depth:", g.LoadStats().QueueDepth)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	bg := context.Background()
+	get(bg, "blocker")
+	for g.LoadStats().Active != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	get(WithPriority(bg, PriorityBatch), "batch")
+	waitDepth(1)
+	get(bg, "normal")
+	waitDepth(2)
+	get(WithPriority(bg, PriorityInteractive), "interactive")
+	waitDepth(3)
+
+	ctx, cancel := context.WithCancel(bg)
+	errc := make(chan error, 1)
+	go func() {
+		_, err := g.Get(ctx, "canceled")
+		errc <- err
+	}()
+	waitDepth(4)
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Fatal("canceled load:", err)
+	}
+
+	close(release)
+	wg.Wait()
+	want := []string{"blocker", "interactive", "normal", "batch"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatal("order:", order)
+		}
+	}
+	if s := g.LoadStats(); s.Active != 0 || s.QueueDepth != 0 || s.Queued != 4 || s.Abandoned != 1 || s.LoadErrors != 0 || s.MaxWait <= 0 {
+		t.Fatalf("stats: %+v", s)
+	}
+}
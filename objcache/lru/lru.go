@@ -82,6 +82,18 @@ func (c *Cache) Get(key Key) (value interface{}, ok bool) {
 	return
 }
 
+// Peek looks up a key's value from the cache, without updating its
+// recency.
+func (c *Cache) Peek(key Key) (value interface{}, ok bool) {
+	if c.cache == nil {
+		return
+	}
+	if ele, hit := c.cache[key]; hit {
+		return ele.Value.(*entry).value, true
+	}
+	return
+}
+
 // Remove removes the provided key from the cache.
 func (c *Cache) Remove(key Key) {
 	if c.cache == nil {
@@ -103,6 +115,19 @@ func (c *Cache) RemoveOldest() {
 	}
 }
 
+// Oldest returns the least recently used item of the cache, without updating
+// its recency.
+func (c *Cache) Oldest() (key Key, value interface{}, ok bool) {
+	if c.cache == nil {
+		return
+	}
+	if ele := c.ll.Back(); ele != nil {
+		kv := ele.Value.(*entry)
+		return kv.key, kv.value, true
+	}
+	return
+}
+
 func (c *Cache) removeElement(e *list.Element) {
 	c.ll.Remove(e)
 	kv := e.Value.(*entry)
@@ -112,6 +137,21 @@ func (c *Cache) removeElement(e *list.Element) {
 	}
 }
 
+// Range calls fn for each item of the cache, from the most recently used to
+// the least recently used, until fn returns false. fn must not modify the
+// cache.
+func (c *Cache) Range(fn func(key Key, value interface{}) bool) {
+	if c.cache == nil {
+		return
+	}
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		kv := e.Value.(*entry)
+		if !fn(kv.key, kv.value) {
+			return
+		}
+	}
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache) Len() int {
 	if c.cache == nil {
@@ -95,3 +95,32 @@ func TestEvict(t *testing.T) {
 		t.Fatalf("got %v in second evicted key; want %s", evictedKeys[1], "myKey1")
 	}
 }
+
+func TestPeek(t *testing.T) {
+	lru := New(2)
+	lru.Add("a", 1)
+	lru.Add("b", 2)
+	if v, ok := lru.Peek("a"); !ok || v != 1 {
+		t.Fatalf("Peek(a) = %v, %v", v, ok)
+	}
+	lru.Add("c", 3) // a is still the oldest
+	if _, ok := lru.Peek("a"); ok {
+		t.Fatal("Peek promoted a")
+	}
+}
+
+func TestOldest(t *testing.T) {
+	lru := New(0)
+	if _, _, ok := lru.Oldest(); ok {
+		t.Fatal("Oldest of an empty cache")
+	}
+	lru.Add("a", 1)
+	lru.Add("b", 2)
+	if k, v, ok := lru.Oldest(); !ok || k != "a" || v != 1 {
+		t.Fatalf("Oldest = %v, %v, %v", k, v, ok)
+	}
+	lru.Get("a")
+	if k, _, _ := lru.Oldest(); k != "b" {
+		t.Fatalf("Oldest after Get(a) = %v", k)
+	}
+}
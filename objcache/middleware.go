@@ -0,0 +1,38 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+// A Middleware wraps the getter of a group, e.g. to add metrics, logging,
+// retries or tracing to the loads, see Group.Use.
+type Middleware = func(next GetterFunc) GetterFunc
+
+// Use wraps the getter of the group with mws, in order: the middlewares of
+// a call are inside those of the previous calls, and the first middleware of
+// a call is the outermost of them. It must be called before the group is
+// used.
+func (g *Group) Use(mws ...Middleware) {
+	g.mws = append(g.mws, mws...)
+	g.get = g.chain(g.base)
+}
+
+// chain wraps get with the middlewares of the group.
+func (g *Group) chain(get GetterFunc) GetterFunc {
+	for i := len(g.mws) - 1; i >= 0; i-- {
+		get = g.mws[i](get)
+	}
+	return get
+}
@@ -0,0 +1,47 @@
+package objcache
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next GetterFunc) GetterFunc {
+			return func(ctx Context, key Key) (Value, error) {
+				calls = append(calls, name)
+				return next(ctx, key)
+			}
+		}
+	}
+	retry := func(next GetterFunc) GetterFunc {
+		return func(ctx Context, key Key) (val Value, err error) {
+			for i := 0; i < 3; i++ {
+				if val, err = next(ctx, key); err == nil {
+					return
+				}
+			}
+			return
+		}
+	}
+	fails := 2
+	g := NewGroupWith("middleware-group", func(ctx Context, key Key) (Value, error) {
+		calls = append(calls, "getter")
+		if fails > 0 {
+			fails--
+			return nil, errors.New("transient")
+		}
+		return key, nil
+	}, WithMiddleware(trace("a"), retry))
+	t.Cleanup(func() { RemoveGroup("middleware-group") })
+	g.Use(trace("b"))
+	if v, err := g.Get(nil, "k"); err != nil || v != "k" {
+		t.Fatal("Get:", v, err)
+	}
+	want := []string{"a", "b", "getter", "b", "getter", "b", "getter"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatal("calls:", calls)
+	}
+}
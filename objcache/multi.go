@@ -0,0 +1,188 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A BatchGetterFunc loads several keys at once. It returns a value for
+// each key, and errs is either nil or an error for each key.
+type BatchGetterFunc = func(ctx Context, keys []Key) (vals []Value, errs []error)
+
+// SetBatchGetter makes GetMulti load the missing keys owned by the current
+// process by a single call of getter, rather than by concurrent calls of
+// the getter of the group, which still loads the keys missed by Get. It
+// must be called before the group is used.
+func (g *Group) SetBatchGetter(getter BatchGetterFunc) {
+	g.getMulti = getter
+}
+
+// GetMulti returns the values of keys, and the error of each key in errs,
+// which is nil if all the keys succeed. The cached values are served
+// immediately; the missing keys are fetched from their owner or loaded by
+// the getter, concurrently, or in one batch if there is a batch getter
+// (see SetBatchGetter). As with Get, a missing key shares the load of a
+// concurrent Get of the same key.
+func (g *Group) GetMulti(ctx Context, keys []Key) (vals []Value, errs []error) {
+	vals, errs = make([]Value, len(keys)), make([]error, len(keys))
+	var missing []Key
+	indexes := make(map[Key][]int) // of the missing keys
+	for i, key := range keys {
+		if val, ok := g.lookupCache(key); ok {
+			g.refreshIfStale(ctx, key)
+			vals[i], errs[i] = cachedResult(val)
+			continue
+		}
+		if _, dup := indexes[key]; !dup {
+			missing = append(missing, key)
+		}
+		indexes[key] = append(indexes[key], i)
+	}
+
+	var batch []Key // the keys of the batch getter
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	set := func(key Key, val Value, err error) {
+		mu.Lock()
+		for _, i := range indexes[key] {
+			vals[i], errs[i] = val, err
+		}
+		mu.Unlock()
+	}
+	for _, key := range missing {
+		if _, remote := g.peerOf(key); g.getMulti != nil && !remote {
+			batch = append(batch, key)
+			continue
+		}
+		wg.Add(1)
+		go func(key Key) {
+			defer wg.Done()
+			val, err := g.fill(ctx, key, nil)
+			set(key, val, err)
+		}(key)
+	}
+	if len(batch) > 0 {
+		bvals, berrs := g.fillMulti(ctx, batch)
+		for i, key := range batch {
+			set(key, bvals[i], berrs[i])
+		}
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return
+		}
+	}
+	return vals, nil
+}
+
+// fillMulti loads distinct keys after a cache miss by the batch getter.
+func (g *Group) fillMulti(ctx Context, keys []Key) ([]Value, []error) {
+	for range keys {
+		g.loads.inc(&g.loads.stats.Loads)
+	}
+	return g.flights.doMulti(ctx, keys, func(keys []Key) ([]Value, []error) {
+		vals, errs := make([]Value, len(keys)), make([]error, len(keys))
+		var rest []Key
+		var at []int // of the keys of rest
+		for i, key := range keys {
+			// a load that completed after our lookup may have added the key
+			if val, ok := g.mainCache.peek(key); ok {
+				vals[i], errs[i] = cachedResult(val)
+			} else if val, ok := g.hotCache.peek(key); ok {
+				vals[i] = val
+			} else if val, ok := g.getSecondary(key); ok {
+				g.flights.commit(key, func() {
+					g.mainCache.add(key, val)
+					g.tag(key, val)
+				})
+				vals[i] = val
+			} else {
+				rest, at = append(rest, key), append(at, i)
+			}
+		}
+		if len(rest) == 0 {
+			return vals, errs
+		}
+		ctx, span := g.startBatchSpan(ctx, len(rest))
+		defer span.End()
+		start := g.clock.Now()
+		rvals, rerrs := g.loadMulti(ctx, rest)
+		d := g.clock.Now().Sub(start)
+		for j, key := range rest {
+			val, err := rvals[j], rerrs[j]
+			if err == nil {
+				g.commitLoad(key, val, d)
+				g.setSecondary(key, val)
+			} else if stale, ok := g.serveStale(key); ok {
+				val, err = stale, nil
+			} else {
+				g.cacheError(key, err)
+				span.SetError(err)
+			}
+			vals[at[j]], errs[at[j]] = val, err
+		}
+		return vals, errs
+	})
+}
+
+// loadMulti calls the batch getter of the group within the load limit,
+// where a batch counts as one load.
+func (g *Group) loadMulti(ctx Context, keys []Key) (vals []Value, errs []error) {
+	if err := g.loads.acquire(ctx); err != nil {
+		vals, errs = make([]Value, len(keys)), make([]error, len(keys))
+		for i := range errs {
+			errs[i] = err
+		}
+		return // abandoned in the queue: not a failed load
+	}
+	defer g.loads.release()
+	for range keys {
+		g.loads.inc(&g.loads.stats.LocalLoads)
+	}
+	start := g.clock.Now()
+	vals, errs = g.getMulti(ctx, keys)
+	if len(vals) != len(keys) || (errs != nil && len(errs) != len(keys)) {
+		err := fmt.Errorf("objcache: batch getter returned %d values and %d errors for %d keys", len(vals), len(errs), len(keys))
+		vals, errs = nil, make([]error, len(keys))
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+	for i, key := range keys {
+		var err error
+		if errs != nil {
+			err = errs[i]
+		}
+		g.observeLoad(key, start, err)
+	}
+	if vals == nil {
+		vals = make([]Value, len(keys))
+	}
+	if errs == nil {
+		errs = make([]error, len(keys))
+	}
+	for _, err := range errs {
+		if err != nil {
+			g.loads.inc(&g.loads.stats.LoadErrors)
+		}
+	}
+	return
+}
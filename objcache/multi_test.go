@@ -0,0 +1,83 @@
+package objcache
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestGetMulti(t *testing.T) {
+	errFail := errors.New("load failed")
+	var wg sync.WaitGroup
+	wg.Add(3) // the loads of b, c and fail must run concurrently
+	g := NewGroup("get-multi-group", 0, func(ctx Context, key Key) (Value, error) {
+		if key != "a" {
+			wg.Done()
+			wg.Wait()
+		}
+		if key == "fail" {
+			return nil, errFail
+		}
+		return "v" + key.(string), nil
+	})
+	t.Cleanup(func() { RemoveGroup("get-multi-group") })
+	g.Get(nil, "a")
+
+	vals, errs := g.GetMulti(nil, []Key{"a", "b", "c", "b", "fail"})
+	if !reflect.DeepEqual(vals, []Value{"va", "vb", "vc", "vb", nil}) {
+		t.Fatal("GetMulti:", vals)
+	}
+	if !reflect.DeepEqual(errs, []error{nil, nil, nil, nil, errFail}) {
+		t.Fatal("GetMulti errors:", errs)
+	}
+	if vals, errs = g.GetMulti(nil, []Key{"c", "b"}); errs != nil || !reflect.DeepEqual(vals, []Value{"vc", "vb"}) {
+		t.Fatal("GetMulti of cached keys:", vals, errs)
+	}
+}
+
+func TestBatchGetter(t *testing.T) {
+	errFail := errors.New("load failed")
+	g := NewGroup("batch-getter-group", 0, func(ctx Context, key Key) (Value, error) {
+		return "single " + key.(string), nil
+	})
+	t.Cleanup(func() { RemoveGroup("batch-getter-group") })
+	var batches [][]Key
+	g.SetBatchGetter(func(ctx Context, keys []Key) ([]Value, []error) {
+		batches = append(batches, keys)
+		vals, errs := make([]Value, len(keys)), make([]error, len(keys))
+		for i, key := range keys {
+			if key == "fail" {
+				errs[i] = errFail
+			} else {
+				vals[i] = "batch " + key.(string)
+			}
+		}
+		return vals, errs
+	})
+	g.Get(nil, "a")
+
+	vals, errs := g.GetMulti(nil, []Key{"a", "b", "fail", "c"})
+	if !reflect.DeepEqual(vals, []Value{"single a", "batch b", nil, "batch c"}) {
+		t.Fatal("GetMulti:", vals)
+	}
+	if !reflect.DeepEqual(errs, []error{nil, nil, errFail, nil}) {
+		t.Fatal("GetMulti errors:", errs)
+	}
+	if !reflect.DeepEqual(batches, [][]Key{{"b", "fail", "c"}}) {
+		t.Fatal("batches:", batches)
+	}
+	if v, err := g.Get(nil, "b"); err != nil || v != "batch b" {
+		t.Fatal("batch load not cached:", v, err)
+	}
+	if s := g.LoadStats(); s.Loads != 4 || s.LocalLoads != 4 || s.LoadErrors != 1 {
+		t.Fatalf("load stats: %+v", s)
+	}
+
+	g.SetBatchGetter(func(ctx Context, keys []Key) ([]Value, []error) {
+		return nil, nil
+	})
+	if _, errs = g.GetMulti(nil, []Key{"d"}); errs == nil || errs[0] == nil {
+		t.Fatal("GetMulti with a bad batch getter: no error")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// negEntry is a cached loader error, see Group.SetNegativeTTL.
+type negEntry struct {
+	err error
+}
+
+// SetNegativeTTL makes the group cache the errors of its getter for ttl, so
+// that the Gets of a key that doesn't exist upstream are served from the
+// cache rather than hammering the backend (a getter may return ErrNotFound
+// for such keys). Zero, the default, disables negative caching. The errors
+// of a context that is canceled or past its deadline are never cached.
+//
+// A cached error counts as an entry of the cache, but it isn't passed to
+// onEvicted, nor returned by TryGet. Remove, Set and Purge drop it as any
+// entry.
+func (g *Group) SetNegativeTTL(ttl time.Duration) {
+	g.mainCache.setNegativeTTL(ttl)
+}
+
+// cacheError caches err as the result of the load of key, and drops the
+// dependencies its getter registered.
+func (g *Group) cacheError(key Key, err error) {
+	g.flights.commit(key, func() { g.deps.forget(key) })
+	ttl := g.mainCache.negativeTTL()
+	if ttl <= 0 || err == errLoadPanicked || err == ErrThrottled ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	g.flights.commit(key, func() { g.mainCache.addTTL(key, &negEntry{err}, ttl) })
+}
+
+// cachedResult turns a cached value back into the result of a Get.
+func cachedResult(val Value) (Value, error) {
+	if n, ok := val.(*negEntry); ok {
+		return nil, n.err
+	}
+	return val, nil
+}
@@ -0,0 +1,71 @@
+package objcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNegativeTTL(t *testing.T) {
+	var loads int
+	var evicted []Key
+	g := NewGroup("negative-group", 0, func(ctx Context, key Key) (Value, error) {
+		loads++
+		if c, ok := ctx.(context.Context); ok && c.Err() != nil {
+			return nil, c.Err()
+		}
+		if key == "missing" {
+			return nil, ErrNotFound
+		}
+		return key, nil
+	}, func(key Key, value Value) {
+		evicted = append(evicted, key)
+	})
+	t.Cleanup(func() { RemoveGroup("negative-group") })
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g.SetClock(clock)
+
+	if _, err := g.Get(nil, "missing"); err != ErrNotFound {
+		t.Fatal("Get:", err)
+	}
+	if _, err := g.Get(nil, "missing"); err != ErrNotFound || loads != 2 {
+		t.Fatal("Get without negative caching:", err, loads)
+	}
+
+	g.SetNegativeTTL(time.Second)
+	for i := 0; i < 3; i++ {
+		if _, err := g.Get(nil, "missing"); err != ErrNotFound {
+			t.Fatal("Get:", err)
+		}
+	}
+	if loads != 3 {
+		t.Fatal("cached error not served from the cache:", loads)
+	}
+	if _, ok := g.TryGet("missing"); ok {
+		t.Fatal("TryGet of a cached error: ok")
+	}
+	clock.now = clock.now.Add(time.Second)
+	if _, err := g.Get(nil, "missing"); err != ErrNotFound || loads != 4 {
+		t.Fatal("cached error not expired:", err, loads)
+	}
+
+	g.Set("missing", "found")
+	if v, err := g.Get(nil, "missing"); err != nil || v != "found" {
+		t.Fatal("Get after Set:", v, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.Get(ctx, "canceled"); err != context.Canceled {
+		t.Fatal("Get with a canceled context:", err)
+	}
+	if v, err := g.Get(nil, "canceled"); err != nil || v != "canceled" {
+		t.Fatal("context error cached:", v, err)
+	}
+	if s := g.LoadStats(); s.LocalLoads != 6 || s.LoadErrors != 5 {
+		t.Fatalf("load stats: %+v", s)
+	}
+	if len(evicted) != 0 {
+		t.Fatal("cached errors passed to onEvicted:", evicted)
+	}
+}
@@ -1,7 +1,12 @@
 package objcache
 
 import (
+	"errors"
+	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/qiniu/x/objcache/lru"
 )
@@ -21,6 +26,42 @@ type OnEvictedFunc = func(key Key, value Value)
 // A GetterFunc implements Getter with a function.
 type GetterFunc = func(ctx Context, key Key) (val Value, err error)
 
+// A Sizer is a value that knows its size in bytes, which counts toward the
+// byte budget of the cache (see Group.SetMaxBytes). The size of a value must
+// not change while it is cached. Values other than Sizers, []byte and string
+// count as zero bytes.
+type Sizer interface {
+	Size() int64
+}
+
+func sizeOf(value Value) int64 {
+	switch v := value.(type) {
+	case Sizer:
+		return v.Size()
+	case []byte:
+		return int64(len(v))
+	case string:
+		return int64(len(v))
+	}
+	return 0
+}
+
+// ErrNotFound is returned by a SecondaryStore when the requested key is absent.
+var ErrNotFound = errors.New("objcache: not found")
+
+// A SecondaryStore is a byte-oriented store (disk, redis, memcached, etc.)
+// that can back the in-memory cache of a group.
+type SecondaryStore interface {
+	// Get returns the data stored under key, or ErrNotFound if there is none.
+	Get(key string) (data []byte, err error)
+
+	// Set stores data under key, replacing any previous data.
+	Set(key string, data []byte) error
+
+	// Delete removes key from the store. Deleting an absent key is not an error.
+	Delete(key string) error
+}
+
 // newGroupHook, if non-nil, is called right after a new group is created.
 var newGroupHook func(*Group)
 
@@ -36,10 +77,39 @@ func RegisterNewGroupHook(fn func(*Group)) {
 // A Group is a cache namespace and associated data loaded spread over
 // a group of 1 or more machines.
 type Group struct {
-	name string
-	get  GetterFunc
+	name     string
+	get      GetterFunc // base wrapped by mws
+	base     GetterFunc
+	mws      []Middleware
+	getMulti BatchGetterFunc
+
+	secondary       SecondaryStore
+	decodeSecondary DecodeFunc
+
+	setter Setter // see Put
+	puts   putLocks
 
 	mainCache cache
+	hotCache  cache // values owned by peers
+	deps      deps
+	loads     loadLimiter
+	throttle  throttle
+	flights   flightGroup
+
+	peersMu sync.RWMutex
+	peers   PeerPicker
+
+	evictMu  sync.RWMutex
+	evictFns []EvictFunc
+	observer EventObserver
+
+	refsMu sync.Mutex
+	refs   map[Key]*ref // of the cached Disposers that were acquired
+
+	clock Clock
+
+	janitorMu sync.Mutex
+	janitor   chan struct{} // closed to stop the janitor, see StartJanitor
 }
 
 var (
@@ -56,31 +126,136 @@ func GetGroup(name string) *Group {
 	return g
 }
 
+// Groups returns the registered groups, sorted by name, e.g. for metrics
+// exporters and debug pages.
+func Groups() []*Group {
+	mu.RLock()
+	ret := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		ret = append(ret, g)
+	}
+	mu.RUnlock()
+	sort.Slice(ret, func(i, j int) bool { return ret[i].name < ret[j].name })
+	return ret
+}
+
+// RemoveGroup unregisters the named group, so that a group of the same name
+// can be created again, stops its janitor and purges its cache, passing the
+// remaining values to onEvicted. It reports whether there was such a group.
+// A removed group keeps working, but it isn't served to peers anymore.
+func RemoveGroup(name string) bool {
+	mu.Lock()
+	g, ok := groups[name]
+	delete(groups, name)
+	mu.Unlock()
+	if ok {
+		g.StopJanitor()
+		g.Purge()
+	}
+	return ok
+}
+
+// ResetGroups removes all the groups as RemoveGroup does, e.g. between the
+// tests of a suite that creates groups. The hook registered by
+// RegisterNewGroupHook is kept.
+func ResetGroups() {
+	mu.Lock()
+	old := groups
+	groups = make(map[string]*Group)
+	mu.Unlock()
+	for _, g := range old {
+		g.StopJanitor()
+		g.Purge()
+	}
+}
+
 // NewGroup creates a coordinated group-aware Getter from a Getter.
 //
 // The returned Getter tries (but does not guarantee) to run only one
 // Get call at once for a given key across an entire set of peer
 // processes. Concurrent callers both in the local process and in
 // other processes receive copies of the answer once the original Get
-// completes.
+// completes. The peers are set by SetPeerPicker.
 //
-// The group name must be unique for each getter.
+// The cache is split into shards by the hash of the keys, each with its own
+// lock and LRU order, so that concurrent Gets of different keys seldom
+// contend: an unbounded cache has 16 shards, and a bounded one up to 16 of
+// at least 64 entries each, so that a cache of less than 128 entries keeps
+// an exact LRU order. The capacity is spread evenly over the shards, while the
+// byte budget is shared by all of them.
+//
+// The group name must be unique for each getter: NewGroup panics if a
+// group of that name exists, see RemoveGroup and NewGroupOrGet.
 func NewGroup(name string, cacheNum int, getter GetterFunc, onEvicted ...OnEvictedFunc) *Group {
+	o := groupOptions{maxItems: cacheNum}
+	if onEvicted != nil {
+		o.onEvicted = onEvicted[0]
+	}
+	return newGroup(name, getter, &o)
+}
+
+// NewGroupOrGet is NewGroup that returns the group of that name if it
+// exists, rather than panicking, and reports whether it created the group,
+// e.g. for components that may race to create a shared group. The arguments
+// are ignored when the group exists.
+func NewGroupOrGet(name string, cacheNum int, getter GetterFunc, onEvicted ...OnEvictedFunc) (g *Group, created bool) {
+	o := groupOptions{maxItems: cacheNum}
+	if onEvicted != nil {
+		o.onEvicted = onEvicted[0]
+	}
+	return registerGroup(name, getter, &o, true)
+}
+
+func newGroup(name string, getter GetterFunc, o *groupOptions) *Group {
+	g, _ := registerGroup(name, getter, o, false)
+	return g
+}
+
+// registerGroup creates and registers a group. If a group of that name
+// exists, it is returned if orGet is set, and registerGroup panics otherwise.
+func registerGroup(name string, getter GetterFunc, o *groupOptions, orGet bool) (*Group, bool) {
 	mu.Lock()
 	defer mu.Unlock()
-	if _, dup := groups[name]; dup {
+	if g, dup := groups[name]; dup {
+		if orGet {
+			return g, false
+		}
 		panic("duplicate registration of group " + name)
 	}
 	g := &Group{
-		name: name,
-		get:  getter,
+		name:  name,
+		get:   getter,
+		base:  getter,
+		clock: SystemClock,
+	}
+	evicted := func(key Key, value Value, reason EvictReason) {
+		if reason != EvictReplaced { // a reload keeps the edges its getter registered
+			g.deps.forget(key)
+		}
+		if _, neg := value.(*negEntry); neg {
+			return
+		}
+		if reason == EvictRejected { // the value is still used by the load
+			g.notifyEvicted(key, value, reason)
+			g.observeEvict(key, reason)
+			return
+		}
+		if o.onEvicted != nil {
+			o.onEvicted(key, value)
+		}
+		g.notifyEvicted(key, value, reason)
+		g.observeEvict(key, reason)
+		g.dispose(key, value)
 	}
-	g.mainCache.init(cacheNum, onEvicted...)
+	now := func() time.Time { return g.clock.Now() }
+	g.mainCache.init(o.maxItems, o.shards, now, evicted)
+	g.hotCache.init(hotCapacity(o.maxItems), 0, now, evicted)
+	o.apply(g)
 	if newGroupHook != nil {
 		newGroupHook(g)
 	}
 	groups[name] = g
-	return g
+	return g, true
 }
 
 // Name returns the name of the group.
@@ -88,86 +263,523 @@ func (g *Group) Name() string {
 	return g.name
 }
 
-// Get func.
+// Get returns the value of key, loaded by the getter of the group on a
+// miss. Concurrent Gets of a missing key share a single load. If ctx is a
+// context.Context, the load is traced as a span of tracex.
 func (g *Group) Get(ctx Context, key Key) (val Value, err error) {
-	val, ok := g.mainCache.get(key)
-	if ok {
-		return
+	if val, hit, err := g.getCached(ctx, key); hit {
+		return val, err
 	}
-	val, err = g.get(ctx, key)
-	if err == nil {
-		g.mainCache.add(key, val)
+	return g.fill(ctx, key, nil)
+}
+
+// GetOrLoad is like Get, but a miss is loaded by loader instead of the getter
+// of the group, e.g. when the load needs arguments of the request that are
+// not part of the key. The load is still shared by concurrent calls (of Get
+// or GetOrLoad) and its result cached; it is wrapped by the middlewares of
+// the group, and is always done locally rather than by the owner peer of key.
+func (g *Group) GetOrLoad(ctx Context, key Key, loader func() (Value, error)) (val Value, err error) {
+	if val, hit, err := g.getCached(ctx, key); hit {
+		return val, err
 	}
-	return
+	return g.fill(ctx, key, g.chain(func(ctx Context, key Key) (Value, error) {
+		return loader()
+	}))
+}
+
+// getCached is the lookup of Get, which reports whether key is cached.
+func (g *Group) getCached(ctx Context, key Key) (val Value, hit bool, err error) {
+	if val, ok := g.mainCache.get(key); ok {
+		g.observeLookup(key, true)
+		g.refreshIfStale(ctx, key)
+		val, err = cachedResult(val)
+		return val, true, err
+	}
+	if val, ok := g.hotCache.get(key); ok {
+		g.observeLookup(key, true)
+		return val, true, nil
+	}
+	g.observeLookup(key, false)
+	return nil, false, nil
+}
+
+// fill loads key after a cache miss, from its owner or by the getter. A
+// non-nil get replaces the getter, and the owner isn't asked then.
+func (g *Group) fill(ctx Context, key Key, get GetterFunc) (Value, error) {
+	g.loads.inc(&g.loads.stats.Loads)
+	return g.flights.do(ctx, key, func() (Value, error) {
+		ctx, span := g.startLoadSpan(ctx, key)
+		defer span.End()
+		// a load that completed after our lookup may have added the key
+		if val, ok := g.mainCache.peek(key); ok {
+			span.SetAttr("hit", true)
+			val, err := cachedResult(val)
+			span.SetError(err)
+			return val, err
+		}
+		if val, ok := g.hotCache.peek(key); ok {
+			span.SetAttr("hit", true)
+			return val, nil
+		}
+		if get == nil {
+			if val, ok := g.getFromPeer(ctx, key); ok {
+				span.SetAttr("source", "peer")
+				return val, nil
+			}
+			get = g.get
+		}
+		if val, ok := g.getSecondary(key); ok {
+			span.SetAttr("source", "secondary")
+			g.flights.commit(key, func() {
+				g.mainCache.add(key, val)
+				g.tag(key, val)
+			})
+			return val, nil
+		}
+		span.SetAttr("source", "getter")
+		start := g.clock.Now()
+		val, err := g.load(ctx, key, get)
+		if err == nil {
+			g.commitLoad(key, val, g.clock.Now().Sub(start))
+			g.setSecondary(key, val)
+			return val, nil
+		}
+		span.SetError(err)
+		if val, ok := g.serveStale(key); ok {
+			span.SetAttr("stale", true)
+			return val, nil
+		}
+		g.cacheError(key, err)
+		return val, err
+	})
+}
+
+// Remove removes key from the cache, passing its value to onEvicted, and
+// reports whether it was cached. A load of key in progress is not cached
+// when it completes, so that a value that was stale when Remove was called
+// isn't cached either. The keys depending on key are kept: see Invalidate.
+func (g *Group) Remove(key Key) bool {
+	g.flights.forget(key)
+	g.deleteSecondary(key)
+	hot := g.hotCache.remove(key)
+	return g.mainCache.remove(key) || hot
+}
+
+// Set adds value to the cache under key without calling the getter, e.g. for
+// values computed out of band. It replaces the previous value of key, whose
+// onEvicted func is called, and drops its dependencies.
+func (g *Group) Set(key Key, value Value) {
+	g.set(key, value, nil)
+}
+
+// Purge removes all the entries of the cache, passing their values to
+// onEvicted, and returns their number. As with Remove, the loads in progress
+// are not cached.
+func (g *Group) Purge() int {
+	g.flights.forgetAll()
+	return g.mainCache.clear() + g.hotCache.clear()
 }
 
 // TryGet func.
 func (g *Group) TryGet(key Key) (val Value, ok bool) {
-	return g.mainCache.get(key)
+	if val, ok = g.lookupCache(key); ok {
+		if _, neg := val.(*negEntry); neg {
+			return nil, false
+		}
+	}
+	return
+}
+
+// Peek returns the cached value of key, if any, without changing the order of
+// eviction, the stats of the group, nor removing an expired entry: e.g. for
+// monitoring or speculative checks. If the policy of the group isn't a
+// Peeker, the lookup counts as an access.
+func (g *Group) Peek(key Key) (val Value, ok bool) {
+	if val, ok = g.mainCache.quietGet(key); !ok {
+		val, ok = g.hotCache.quietGet(key)
+	}
+	if _, neg := val.(*negEntry); neg {
+		return nil, false
+	}
+	return
+}
+
+func (g *Group) lookupCache(key Key) (val Value, ok bool) {
+	if val, ok = g.mainCache.get(key); !ok {
+		val, ok = g.hotCache.get(key)
+	}
+	g.observeLookup(key, ok)
+	return
+}
+
+// Capacity returns the max number of entries of the group, 0 if there is
+// no limit.
+func (g *Group) Capacity() int {
+	return g.mainCache.capacity()
 }
 
-// CacheStats returns stats about the provided cache within the group.
+// SetCapacity changes the max number of entries of the group, evicting the
+// entries beyond it. Zero means no limit. The number of shards of the cache
+// is set by NewGroup and doesn't change.
+func (g *Group) SetCapacity(n int) {
+	g.mainCache.setCapacity(n)
+}
+
+// MaxBytes returns the byte budget of the group, 0 if there is none.
+func (g *Group) MaxBytes() int64 {
+	return g.mainCache.getMaxBytes()
+}
+
+// SetMaxBytes sets the byte budget of the group: entries are evicted when
+// the total size of the values (see Sizer) exceeds n, even if the max number
+// of entries isn't reached. A value larger than n is evicted right away.
+// Zero means no budget.
+func (g *Group) SetMaxBytes(n int64) {
+	g.mainCache.setMaxBytes(n)
+}
+
+// SetPolicy replaces the eviction policy of the group, which is LRU by
+// default (see NewLRU). The entries already cached are moved to the new
+// policy if the old one implements Ranger, and dropped otherwise, so it is
+// best called right after NewGroup.
+func (g *Group) SetPolicy(newPolicy NewPolicyFunc) {
+	g.mainCache.setPolicy(newPolicy)
+}
+
+// CacheStats returns stats about the main cache of the group.
 func (g *Group) CacheStats() CacheStats {
 	return g.mainCache.stats()
 }
 
-// cache is a wrapper around an *lru.Cache that adds synchronization,
-// makes values always be ByteView, and counts the size of all keys and
-// values.
-type cache struct {
+// shard is a wrapper around a Policy that adds synchronization and
+// counts gets and hits. A cache is made of shards.
+type shard struct {
 	mu         sync.RWMutex
-	lru        *lru.Cache
+	lru        Policy
+	maxEntries int
+	budget     *budget // shared by the shards of the cache
+	onEvicted  OnEvictedFunc
 	nhit, nget int64
+	nevict     int64
+	nbevict    int64 // bytes of the evicted entries
+	nexpire    int64
+	reason     EvictReason // of the entries leaving the cache
+	ttl        time.Duration
+	maxStale   time.Duration         // see Group.SetMaxStale
+	expires    map[Key]time.Time     // of the entries with a TTL
+	refresh    time.Duration         // see Group.SetRefreshAfter
+	born       map[Key]time.Time     // of the entries, if refresh is set
+	stamped    bool                  // see Group.SetEntryInfo
+	stamps     map[Key]entryStamp    // of the entries, if stamped is set
+	beta       float64               // see Group.SetEarlyExpiration
+	deltas     map[Key]time.Duration // load durations of the entries, if beta is set
+	epoch      *uint64               // of the cache, see Group.InvalidateAll
+	epochs     map[Key]uint64        // of the entries cached after the first epoch
+	pinned     map[Key]*pinnedEntry  // out of the policy, see Group.Pin
+	moving     bool                  // an entry leaves the policy to get pinned
+	admitting  bool                  // admitted is being added to the policy
+	admitted   Key
+	now        func() time.Time
 }
 
-func (c *cache) stats() CacheStats {
+// stats returns the stats of the shard, but Bytes: see cache.stats.
+func (c *shard) stats() CacheStats {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return CacheStats{
-		Items: c.itemsLocked(),
-		Gets:  c.nget,
-		Hits:  c.nhit,
+		Items:        c.itemsLocked(),
+		Gets:         c.nget,
+		Hits:         c.nhit,
+		Evictions:    c.nevict,
+		Expirations:  c.nexpire,
+		BytesEvicted: c.nbevict,
 	}
 }
 
-func (c *cache) init(cacheNum int, onEvicted ...OnEvictedFunc) {
-	c.lru = lru.New(cacheNum)
-	if onEvicted != nil {
-		c.lru.OnEvicted = onEvicted[0]
+func (c *shard) init(maxEntries int, b *budget, now func() time.Time, onEvicted func(key Key, value Value, reason EvictReason)) {
+	c.maxEntries = maxEntries
+	c.budget = b
+	c.now = now
+	c.onEvicted = func(key Key, value Value) {
+		if c.moving {
+			return
+		}
+		delete(c.expires, key)
+		delete(c.born, key)
+		delete(c.stamps, key)
+		delete(c.epochs, key)
+		delete(c.deltas, key)
+		size := sizeOf(value)
+		b.add(-size)
+		reason := c.reason
+		if c.admitting && reason == EvictCapacity && key == c.admitted {
+			reason = EvictRejected
+		}
+		if reason == EvictCapacity {
+			c.nevict++
+			c.nbevict += size
+		}
+		onEvicted(key, value, reason)
+	}
+	c.lru = NewLRU(maxEntries, c.onEvicted)
+}
+
+// setCapacity changes the max number of entries, evicting the entries
+// beyond it.
+func (c *shard) setCapacity(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxEntries = n
+	if r, ok := c.lru.(Resizer); ok {
+		r.SetMaxEntries(n)
+	}
+	for n > 0 && c.lru.Len() > n {
+		c.lru.RemoveOldest()
+	}
+}
+
+// prune evicts entries of the shard until the byte budget of the cache is
+// met, or the shard is empty.
+func (c *shard) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneToLocked(0)
+}
+
+// pruneLocked is prune that keeps the most recently used entry, which may
+// have just been added: the other shards are pruned first, see cache.prune.
+func (c *shard) pruneLocked() {
+	c.pruneToLocked(1)
+}
+
+func (c *shard) pruneToLocked(min int) {
+	for c.budget.over() && c.lru.Len() > min {
+		c.lru.RemoveOldest()
+	}
+}
+
+// setPolicy replaces the policy of the cache, moving the cached entries to
+// the new one.
+func (c *shard) setPolicy(newPolicy NewPolicyFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old := c.lru
+	c.lru = newPolicy(c.maxEntries, c.onEvicted)
+	if r, ok := old.(Ranger); ok {
+		// Range goes from the most useful entry: add them the other way
+		// around, so that they keep their order.
+		var kvs []lru.Key
+		r.Range(func(key Key, value Value) bool {
+			kvs = append(kvs, key, value)
+			return true
+		})
+		for i := len(kvs) - 2; i >= 0; i -= 2 {
+			c.lru.Add(kvs[i], kvs[i+1])
+		}
+	} else {
+		c.reason = EvictRemoved
+		old.Clear()
+		c.reason = EvictCapacity
 	}
 }
 
-func (c *cache) add(key Key, value Value) {
+func (c *shard) add(key Key, value Value) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.addLocked(key, value, c.ttlOfLocked(value))
+}
+
+// addLocked adds value under key. A different value it replaces leaves the
+// cache as if it were removed, so that its onEvicted func is called.
+func (c *shard) addLocked(key Key, value Value, ttl time.Duration) {
+	if c.replacePinnedLocked(key, value, ttl) {
+		return
+	}
+	old, ok := c.lookupLocked(key)
+	if ok && !sameValue(old, value) {
+		c.reason = EvictReplaced
+		c.lru.Remove(key)
+		c.reason = EvictCapacity
+		ok = false
+	}
+	// account for the entry first: a policy with an admission filter may
+	// reject it right away, as if it were evicted.
+	if !ok {
+		c.budget.add(sizeOf(value))
+	}
+	c.stampLocked(key, ttl)
+	c.admitting, c.admitted = true, key
 	c.lru.Add(key, value)
+	c.admitting, c.admitted = false, nil
+	c.pruneLocked()
+}
+
+// stampLocked records the time key was added at, and when it expires. The
+// clock is only read if needed, as this is on the path of every miss.
+func (c *shard) stampLocked(key Key, ttl time.Duration) {
+	var now time.Time
+	if ttl > 0 || c.refresh > 0 || c.stamped {
+		now = c.now()
+	}
+	if ttl > 0 {
+		if c.expires == nil {
+			c.expires = make(map[Key]time.Time)
+		}
+		c.expires[key] = now.Add(ttl)
+	} else {
+		delete(c.expires, key)
+	}
+	if c.refresh > 0 {
+		if c.born == nil {
+			c.born = make(map[Key]time.Time)
+		}
+		c.born[key] = now
+	}
+	if c.stamped {
+		if c.stamps == nil {
+			c.stamps = make(map[Key]entryStamp)
+		}
+		c.stamps[key] = entryStamp{added: now, accessed: now}
+	}
+	if epoch := atomic.LoadUint64(c.epoch); epoch != 0 {
+		if c.epochs == nil {
+			c.epochs = make(map[Key]uint64)
+		}
+		c.epochs[key] = epoch
+	}
+}
+
+// sameValue reports whether a and b are the same value. Only pointers and
+// basic kinds are compared: == on other comparable types may still panic,
+// e.g. on a struct with an interface field holding a slice.
+func sameValue(a, b Value) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return va.Pointer() == vb.Pointer()
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return a == b
+	}
+	return false
+}
+
+func (c *shard) remove(key Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.pinned[key]; ok {
+		delete(c.pinned, key)
+		c.leaveLocked(key, p.value, EvictRemoved)
+		return true
+	}
+	if _, ok := c.lru.Get(key); !ok {
+		return false
+	}
+	c.reason = EvictRemoved
+	c.lru.Remove(key)
+	c.reason = EvictCapacity
+	return true
+}
+
+func (c *shard) clear() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.itemsLocked()
+	c.reason = EvictRemoved
+	c.lru.Clear()
+	c.reason = EvictCapacity
+	for key, p := range c.pinned {
+		delete(c.pinned, key)
+		c.leaveLocked(key, p.value, EvictRemoved)
+	}
+	return int(n)
 }
 
-func (c *cache) get(key Key) (value Value, ok bool) {
+func (c *shard) get(key Key) (value Value, ok bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.nget++
-	v, ok := c.lru.Get(key)
+	if p, pinned := c.pinned[key]; pinned {
+		c.nhit++
+		c.touchLocked(key)
+		return p.value, true
+	}
+	value, ok = c.lru.Get(key)
+	if ok && c.expiredLocked(key) {
+		value, ok = nil, false
+	}
 	if ok {
-		value = v.(Value)
 		c.nhit++
+		c.touchLocked(key)
 	}
 	return
 }
 
-func (c *cache) items() int64 {
+// peek is get without counting the lookup in the stats, nor recording it in
+// the policy if it is a Peeker, e.g. to check again for a key that was just
+// missed.
+func (c *shard) peek(key Key) (value Value, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, pinned := c.pinned[key]; pinned {
+		return p.value, true
+	}
+	value, ok = c.lookupLocked(key)
+	if ok && c.expiredLocked(key) {
+		value, ok = nil, false
+	}
+	return
+}
+
+// lookupLocked looks up key in the policy, without recording the access if
+// the policy is a Peeker.
+func (c *shard) lookupLocked(key Key) (Value, bool) {
+	if pk, ok := c.lru.(Peeker); ok {
+		return pk.Peek(key)
+	}
+	return c.lru.Get(key)
+}
+
+// quietGet is peek that leaves the cache as is, if the policy is a Peeker.
+func (c *shard) quietGet(key Key) (value Value, ok bool) {
 	c.mu.RLock()
+	pk, isPeeker := c.lru.(Peeker)
+	if !isPeeker {
+		c.mu.RUnlock()
+		return c.peek(key)
+	}
 	defer c.mu.RUnlock()
-	return c.itemsLocked()
+	if p, pinned := c.pinned[key]; pinned {
+		return p.value, true
+	}
+	if value, ok = pk.Peek(key); ok {
+		if exp, has := c.expires[key]; (has && !c.now().Before(exp)) || c.oldLocked(key) {
+			return nil, false
+		}
+	}
+	return
 }
 
-func (c *cache) itemsLocked() int64 {
-	return int64(c.lru.Len())
+func (c *shard) itemsLocked() int64 {
+	return int64(c.lru.Len() + len(c.pinned))
 }
 
 // CacheStats are returned by stats accessors on Group.
 type CacheStats struct {
-	Items int64
-	Gets  int64
-	Hits  int64
+	Items       int64
+	Bytes       int64 // total size of the values, see Sizer
+	Gets        int64
+	Hits        int64
+	Evictions   int64 // entries evicted to make room, not removals
+	Expirations int64 // entries removed because their TTL elapsed
+
+	BytesEvicted int64 // total size of the entries evicted to make room
 }
@@ -4,6 +4,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var (
@@ -59,3 +60,298 @@ func TestGetVal(t *testing.T) {
 		t.Errorf("key got %q; want %q", val, want)
 	}
 }
+
+func TestSet(t *testing.T) {
+	var evicted []Value
+	g := NewGroup("set-group", 0, func(ctx Context, key Key) (Value, error) {
+		t.Fatal("getter called for", key)
+		return nil, nil
+	}, func(key Key, value Value) {
+		evicted = append(evicted, value)
+	})
+	t.Cleanup(func() { RemoveGroup("set-group") })
+	g.Set("k", "v1")
+	g.Set("k", "v1")
+	if len(evicted) != 0 {
+		t.Fatal("same value disposed:", evicted)
+	}
+	g.Set("k", "v2")
+	if v, err := g.Get(nil, "k"); err != nil || v != "v2" {
+		t.Fatal("Get:", v, err)
+	}
+	if len(evicted) != 1 || evicted[0] != "v1" {
+		t.Fatal("replaced value not disposed:", evicted)
+	}
+	g.Set("m", map[string]int{}) // not comparable
+	g.Set("m", map[string]int{})
+	if len(evicted) != 2 {
+		t.Fatal("replaced map value not disposed:", evicted)
+	}
+	type meta struct{ Meta interface{} } // comparable, but == panics
+	g.Set("s", meta{[]int{1}})
+	g.Set("s", meta{[]int{1}})
+	if len(evicted) != 3 {
+		t.Fatal("replaced struct value not disposed:", evicted)
+	}
+
+	g.SetWithTags("t", "t1", "tag")
+	g.SetWithTags("t", "t2", "tag")
+	if n := g.InvalidateTag("tag"); n != 1 {
+		t.Fatal("InvalidateTag after replacing a tagged value:", n)
+	}
+	if s := g.CacheStats(); s.Evictions != 0 {
+		t.Fatal("replacements counted as evictions:", s.Evictions)
+	}
+}
+
+func TestSetDuringLoad(t *testing.T) {
+	release := make(chan struct{})
+	g := NewGroup("set-during-load-group", 0, func(ctx Context, key Key) (Value, error) {
+		<-release
+		return "loaded", nil
+	})
+	t.Cleanup(func() { RemoveGroup("set-during-load-group") })
+	done := make(chan Value)
+	go func() {
+		v, _ := g.Get(nil, "k")
+		done <- v
+	}()
+	for g.LoadStats().Active != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	g.Set("k", "set")
+	close(release)
+	if v := <-done; v != "loaded" {
+		t.Fatal("Get:", v)
+	}
+	if v, ok := g.TryGet("k"); !ok || v != "set" {
+		t.Fatal("value loaded before Set replaced it:", v, ok)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	evicted := make(map[Key]Value)
+	g := NewGroup("purge-group", 0, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	}, func(key Key, value Value) {
+		evicted[key] = value
+	})
+	t.Cleanup(func() { RemoveGroup("purge-group") })
+	g.SetTTL(time.Hour)
+	for _, key := range []string{"a", "b", "c"} {
+		g.Get(nil, key)
+	}
+	g.SetWithTags("d", "d", "tag")
+	if n := g.Purge(); n != 4 {
+		t.Fatal("Purge:", n)
+	}
+	if len(evicted) != 4 || evicted["d"] != "d" {
+		t.Fatal("evicted:", evicted)
+	}
+	if s := g.CacheStats(); s.Items != 0 || s.Evictions != 0 {
+		t.Fatalf("CacheStats: %+v", s)
+	}
+	if n := g.InvalidateTag("tag"); n != 0 {
+		t.Fatal("InvalidateTag after Purge:", n)
+	}
+	if v, err := g.Get(nil, "a"); err != nil || v != "a" {
+		t.Fatal("Get after Purge:", v, err)
+	}
+	if n := g.Purge(); n != 1 {
+		t.Fatal("Purge again:", n)
+	}
+}
+
+type blob int64
+
+func (b blob) Size() int64 { return int64(b) }
+
+func TestMaxBytes(t *testing.T) {
+	var evicted []Key
+	g := NewGroup("bytes-group", 100, func(ctx Context, key Key) (Value, error) {
+		return nil, nil
+	}, func(key Key, value Value) {
+		evicted = append(evicted, key)
+	})
+	t.Cleanup(func() { RemoveGroup("bytes-group") })
+	g.SetMaxBytes(100)
+	g.Set("a", blob(40))
+	g.Set("b", []byte("0123456789"))
+	g.Set("c", blob(40))
+	if s := g.CacheStats(); s.Bytes != 90 || len(evicted) != 0 {
+		t.Fatal("CacheStats:", s, evicted)
+	}
+	g.Set("d", "0123456789abcdefghij") // evicts a
+	if s := g.CacheStats(); s.Bytes != 70 || len(evicted) != 1 || evicted[0] != "a" {
+		t.Fatal("after eviction:", s, evicted)
+	}
+	g.Set("c", blob(80)) // replaces c, evicts b
+	if s := g.CacheStats(); s.Bytes != 100 || s.Items != 2 || s.Evictions != 2 || s.BytesEvicted != 50 {
+		t.Fatal("after replacement:", s, evicted)
+	}
+	g.Set("huge", blob(101))
+	if s := g.CacheStats(); s.Items != 0 || s.BytesEvicted != 50+100+101 {
+		t.Fatal("value beyond the budget cached:", s)
+	}
+	g.SetMaxBytes(0)
+	g.Set("huge", blob(101))
+	g.Set("e", blob(60))
+	if s := g.CacheStats(); s.Bytes != 161 || g.MaxBytes() != 0 {
+		t.Fatal("without budget:", s)
+	}
+	g.SetMaxBytes(150)
+	if s := g.CacheStats(); s.Bytes != 60 {
+		t.Fatal("SetMaxBytes:", s)
+	}
+	g.Remove("e")
+	if s := g.CacheStats(); s.Bytes != 0 {
+		t.Fatal("after Remove:", s)
+	}
+}
+
+func TestRemoveGroup(t *testing.T) {
+	var evicted []Key
+	newGroup := func() *Group {
+		return NewGroup("unregister-group", 0, func(ctx Context, key Key) (Value, error) {
+			return key, nil
+		}, func(key Key, value Value) {
+			evicted = append(evicted, key)
+		})
+	}
+	g := newGroup()
+	g.Get(nil, "a")
+	g.StartJanitor(time.Hour)
+	hasGroup := func() bool {
+		all := Groups()
+		for i, p := range all {
+			if i > 0 && all[i-1].Name() >= p.Name() {
+				t.Fatal("Groups not sorted")
+			}
+		}
+		for _, p := range all {
+			if p == g {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasGroup() {
+		t.Fatal("Groups: group missing")
+	}
+	if !RemoveGroup("unregister-group") || hasGroup() || GetGroup("unregister-group") != nil {
+		t.Fatal("RemoveGroup: not removed")
+	}
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Fatal("RemoveGroup: remaining entries not evicted:", evicted)
+	}
+	if g.janitor != nil {
+		t.Fatal("RemoveGroup: janitor not stopped")
+	}
+	if RemoveGroup("unregister-group") {
+		t.Fatal("RemoveGroup of a removed group: true")
+	}
+
+	g = newGroup() // no duplicate registration
+	g.Get(nil, "b")
+	g.StartJanitor(time.Hour)
+	ResetGroups()
+	if GetGroup("unregister-group") != nil || len(evicted) != 2 || g.janitor != nil {
+		t.Fatal("ResetGroups:", evicted)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	g := NewGroup("peek-group", 2, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("peek-group") })
+	g.Get(nil, "a")
+	g.Get(nil, "b")
+	stats := g.CacheStats()
+	if v, ok := g.Peek("a"); !ok || v != "a" {
+		t.Fatal("Peek:", v, ok)
+	}
+	if _, ok := g.Peek("c"); ok {
+		t.Fatal("Peek of a missing key: ok")
+	}
+	if g.CacheStats() != stats {
+		t.Fatal("Peek changed the stats")
+	}
+	g.Get(nil, "c") // a is still the oldest
+	if _, ok := g.Peek("a"); ok {
+		t.Fatal("Peek promoted a")
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	var getterLoads, mwCalls int64
+	g := NewGroupWith("get-or-load-group", func(ctx Context, key Key) (Value, error) {
+		atomic.AddInt64(&getterLoads, 1)
+		return "getter", nil
+	}, WithMiddleware(func(next GetterFunc) GetterFunc {
+		return func(ctx Context, key Key) (Value, error) {
+			atomic.AddInt64(&mwCalls, 1)
+			return next(ctx, key)
+		}
+	}))
+	t.Cleanup(func() { RemoveGroup("get-or-load-group") })
+	var loads int64
+	start := make(chan struct{})
+	loader := func() (Value, error) {
+		<-start
+		atomic.AddInt64(&loads, 1)
+		return "loader", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := g.GetOrLoad(nil, "k", loader); err != nil || v != "loader" {
+				t.Error("GetOrLoad:", v, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(start)
+	wg.Wait()
+	if loads != 1 || mwCalls != 1 {
+		t.Fatal("loads:", loads, mwCalls)
+	}
+	if v, err := g.Get(nil, "k"); err != nil || v != "loader" || getterLoads != 0 {
+		t.Fatal("Get:", v, err, getterLoads)
+	}
+	if v, _ := g.GetOrLoad(nil, "k", loader); v != "loader" || loads != 1 {
+		t.Fatal("GetOrLoad of a cached key:", v, loads)
+	}
+}
+
+func TestNewGroupOrGet(t *testing.T) {
+	getter := func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	}
+	var wg sync.WaitGroup
+	var created int64
+	all := make([]*Group, 8)
+	for i := range all {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g, ok := NewGroupOrGet("or-get-group", 10, getter)
+			if ok {
+				atomic.AddInt64(&created, 1)
+			}
+			all[i] = g
+		}(i)
+	}
+	wg.Wait()
+	t.Cleanup(func() { RemoveGroup("or-get-group") })
+	if created != 1 || GetGroup("or-get-group") != all[0] {
+		t.Fatal("NewGroupOrGet: created", created)
+	}
+	for _, g := range all {
+		if g != all[0] {
+			t.Fatal("NewGroupOrGet: different groups")
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// An EventObserver receives the events of the groups it is set on, see
+// Group.SetObserver, e.g. to feed them into a telemetry pipeline. Its methods
+// are called synchronously by the goroutine causing the event, so they must
+// be fast and safe for concurrent use. Embed NopObserver to implement only
+// some of them.
+type EventObserver interface {
+	// OnHit is called when a lookup of key is served by the cache.
+	OnHit(group string, key Key)
+
+	// OnMiss is called when a lookup of key isn't served by the cache.
+	OnMiss(group string, key Key)
+
+	// OnLoad is called after the getter (or the batch getter) of the group
+	// loaded key, which took d; err is the error of the load, if any.
+	OnLoad(group string, key Key, d time.Duration, err error)
+
+	// OnEvict is called when an entry leaves the cache, see Group.OnEvict.
+	// It is called with the cache locked, so it must not call the methods
+	// of the group.
+	OnEvict(group string, key Key, reason EvictReason)
+
+	// OnTune is called when a Tuner changes the capacity of the group.
+	OnTune(d TunerDecision)
+}
+
+// NopObserver is an EventObserver ignoring all the events.
+type NopObserver struct{}
+
+// OnHit implements EventObserver.
+func (NopObserver) OnHit(group string, key Key) {}
+
+// OnMiss implements EventObserver.
+func (NopObserver) OnMiss(group string, key Key) {}
+
+// OnLoad implements EventObserver.
+func (NopObserver) OnLoad(group string, key Key, d time.Duration, err error) {}
+
+// OnEvict implements EventObserver.
+func (NopObserver) OnEvict(group string, key Key, reason EvictReason) {}
+
+// OnTune implements EventObserver.
+func (NopObserver) OnTune(d TunerDecision) {}
+
+// SetObserver sets the observer of the events of the group, nil for none. It
+// must be called before the group is used.
+func (g *Group) SetObserver(o EventObserver) {
+	g.observer = o
+}
+
+func (g *Group) observeLookup(key Key, hit bool) {
+	if o := g.observer; o != nil {
+		if hit {
+			o.OnHit(g.name, key)
+		} else {
+			o.OnMiss(g.name, key)
+		}
+	}
+}
+
+func (g *Group) observeLoad(key Key, start time.Time, err error) {
+	if o := g.observer; o != nil {
+		o.OnLoad(g.name, key, g.clock.Now().Sub(start), err)
+	}
+}
+
+func (g *Group) observeEvict(key Key, reason EvictReason) {
+	if o := g.observer; o != nil {
+		o.OnEvict(g.name, key, reason)
+	}
+}
+
+func (g *Group) observeTune(d TunerDecision) {
+	if o := g.observer; o != nil {
+		o.OnTune(d)
+	}
+}
+
+// -----------------------------------------------------------------------------
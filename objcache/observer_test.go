@@ -0,0 +1,69 @@
+package objcache
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	NopObserver
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(format string, args ...interface{}) {
+	o.mu.Lock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+	o.mu.Unlock()
+}
+
+func (o *recordingObserver) OnHit(group string, key Key) {
+	o.record("hit %s %v", group, key)
+}
+
+func (o *recordingObserver) OnMiss(group string, key Key) {
+	o.record("miss %s %v", group, key)
+}
+
+func (o *recordingObserver) OnLoad(group string, key Key, d time.Duration, err error) {
+	o.record("load %s %v %v %v", group, key, d, err)
+}
+
+func (o *recordingObserver) OnEvict(group string, key Key, reason EvictReason) {
+	o.record("evict %s %v %v", group, key, reason)
+}
+
+func TestObserver(t *testing.T) {
+	o := new(recordingObserver)
+	errBad := errors.New("bad")
+	g := NewGroupWith("observer-group", func(ctx Context, key Key) (Value, error) {
+		if key == "bad" {
+			return nil, errBad
+		}
+		return key, nil
+	}, WithMaxItems(1), WithClock(&fixedClock{time.Unix(1000, 0)}), WithObserver(o))
+	t.Cleanup(func() { RemoveGroup("observer-group") })
+	g.Get(nil, "a")
+	g.Get(nil, "a")
+	g.Get(nil, "bad")
+	g.Get(nil, "b")
+	want := []string{
+		"miss observer-group a",
+		"load observer-group a 0s <nil>",
+		"hit observer-group a",
+		"miss observer-group bad",
+		"load observer-group bad 0s bad",
+		"miss observer-group b",
+		"load observer-group b 0s <nil>",
+		"evict observer-group a capacity",
+	}
+	if !reflect.DeepEqual(o.events, want) {
+		t.Fatal("events:", o.events)
+	}
+
+	var _ EventObserver = NopObserver{}
+}
@@ -0,0 +1,199 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// An Option configures a group created by NewGroupWith.
+type Option func(o *groupOptions)
+
+type groupOptions struct {
+	maxItems  int
+	maxBytes  int64
+	budget    *Budget
+	ttl       time.Duration
+	shards    int
+	policy    NewPolicyFunc
+	clock     Clock
+	janitor   time.Duration
+	maxLoads  int
+	mws       []Middleware
+	observer  EventObserver
+	setter    Setter
+	onEvicted OnEvictedFunc
+	info      bool
+}
+
+// apply applies the options that are not needed to create the caches of g.
+func (o *groupOptions) apply(g *Group) {
+	if o.mws != nil {
+		g.Use(o.mws...)
+	}
+	if o.observer != nil {
+		g.SetObserver(o.observer)
+	}
+	if o.setter != nil {
+		g.SetSetter(o.setter)
+	}
+	if o.policy != nil {
+		g.SetPolicy(o.policy)
+	}
+	if o.maxBytes > 0 {
+		g.SetMaxBytes(o.maxBytes)
+	}
+	if o.budget != nil {
+		g.SetBudget(o.budget)
+	}
+	if o.ttl > 0 {
+		g.SetTTL(o.ttl)
+	}
+	if o.clock != nil {
+		g.SetClock(o.clock)
+	}
+	if o.maxLoads > 0 {
+		g.SetMaxLoads(o.maxLoads)
+	}
+	if o.janitor > 0 {
+		g.StartJanitor(o.janitor)
+	}
+	if o.info {
+		g.SetEntryInfo(true)
+	}
+}
+
+// NewGroupWith creates a group as NewGroup does, configured by opts. With no
+// options, the cache of the group has no limit.
+func NewGroupWith(name string, getter GetterFunc, opts ...Option) *Group {
+	var o groupOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newGroup(name, getter, &o)
+}
+
+// WithMaxItems sets the max number of entries of the cache, see NewGroup.
+func WithMaxItems(n int) Option {
+	return func(o *groupOptions) {
+		o.maxItems = n
+	}
+}
+
+// WithMaxBytes sets the byte budget of the cache, see Group.SetMaxBytes.
+func WithMaxBytes(n int64) Option {
+	return func(o *groupOptions) {
+		o.maxBytes = n
+	}
+}
+
+// WithBudget makes the group share b with other groups, see
+// Group.SetBudget.
+func WithBudget(b *Budget) Option {
+	return func(o *groupOptions) {
+		o.budget = b
+	}
+}
+
+// WithTTL sets the default TTL of the entries, see Group.SetTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *groupOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithShards sets the number of shards of the main cache, rounded up to a
+// power of two, instead of deriving it from the max number of entries (see
+// NewGroup). A single shard keeps an exact eviction order.
+func WithShards(n int) Option {
+	return func(o *groupOptions) {
+		o.shards = n
+	}
+}
+
+// WithEvictionPolicy sets the eviction policy of the cache, see
+// Group.SetPolicy.
+func WithEvictionPolicy(newPolicy NewPolicyFunc) Option {
+	return func(o *groupOptions) {
+		o.policy = newPolicy
+	}
+}
+
+// WithClock sets the clock of the group, see Group.SetClock.
+func WithClock(clock Clock) Option {
+	return func(o *groupOptions) {
+		o.clock = clock
+	}
+}
+
+// WithMaxLoads bounds the number of loads running at once, see
+// Group.SetMaxLoads.
+func WithMaxLoads(n int) Option {
+	return func(o *groupOptions) {
+		o.maxLoads = n
+	}
+}
+
+// WithJanitor starts a janitor reaping the expired entries every interval,
+// see Group.StartJanitor.
+func WithJanitor(interval time.Duration) Option {
+	return func(o *groupOptions) {
+		o.janitor = interval
+	}
+}
+
+// WithMiddleware wraps the getter with mws, see Group.Use.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(o *groupOptions) {
+		o.mws = append(o.mws, mws...)
+	}
+}
+
+// WithObserver sets the observer of the events of the group, see
+// Group.SetObserver.
+func WithObserver(observer EventObserver) Option {
+	return func(o *groupOptions) {
+		o.observer = observer
+	}
+}
+
+// WithSetter sets the backend store written by Put, see Group.SetSetter.
+func WithSetter(s Setter) Option {
+	return func(o *groupOptions) {
+		o.setter = s
+	}
+}
+
+// WithEntryInfo turns on the tracking of the entries for EntryInfo, see
+// Group.SetEntryInfo.
+func WithEntryInfo() Option {
+	return func(o *groupOptions) {
+		o.info = true
+	}
+}
+
+// WithOnEvicted sets the func called with the values leaving the cache, as
+// the onEvicted argument of NewGroup.
+func WithOnEvicted(onEvicted OnEvictedFunc) Option {
+	return func(o *groupOptions) {
+		o.onEvicted = onEvicted
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,81 @@
+package objcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time { return c.now }
+
+func (c *fixedClock) NewTimer(d time.Duration) Timer { return SystemClock.NewTimer(d) }
+
+// lockedClock is a fixedClock safe for concurrent use, e.g. by refreshes.
+type lockedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *lockedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *lockedClock) NewTimer(d time.Duration) Timer { return SystemClock.NewTimer(d) }
+
+func (c *lockedClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestNewGroupWith(t *testing.T) {
+	var evicted []Key
+	clock := &fixedClock{time.Unix(1000, 0)}
+	var policy int
+	g := NewGroupWith("group-with", func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	},
+		WithMaxItems(1000),
+		WithMaxBytes(10),
+		WithTTL(time.Minute),
+		WithShards(3),
+		WithMaxLoads(2),
+		WithClock(clock),
+		WithEvictionPolicy(func(maxEntries int, onEvicted OnEvictedFunc) Policy {
+			policy++
+			return NewLRU(maxEntries, onEvicted)
+		}),
+		WithOnEvicted(func(key Key, value Value) {
+			evicted = append(evicted, key)
+		}),
+	)
+	t.Cleanup(func() { RemoveGroup("group-with") })
+	if len(g.mainCache.shards) != 4 || policy != 4 {
+		t.Fatal("shards:", len(g.mainCache.shards), policy)
+	}
+	if g.Capacity() != 1000 || g.MaxBytes() != 10 || g.loads.max != 2 {
+		t.Fatal("limits:", g.Capacity(), g.MaxBytes(), g.loads.max)
+	}
+	g.Get(nil, "abcd")
+	g.Get(nil, "efgh")
+	g.Get(nil, "ijkl") // beyond the byte budget
+	if len(evicted) != 1 || evicted[0] == "ijkl" {
+		t.Fatal("evicted:", evicted)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if _, ok := g.TryGet("ijkl"); ok {
+		t.Fatal("TryGet after the TTL")
+	}
+
+	g = NewGroupWith("group-with-defaults", nil)
+	t.Cleanup(func() { RemoveGroup("group-with-defaults") })
+	if len(g.mainCache.shards) != maxShards || g.Capacity() != 0 || g.MaxBytes() != 0 {
+		t.Fatal("defaults:", len(g.mainCache.shards), g.Capacity(), g.MaxBytes())
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/qiniu/x/hashring"
+	"github.com/qiniu/x/hashx"
+	"github.com/qiniu/x/objcache/consistenthash"
+)
+
+// A ProtoGetter fetches values from a peer.
+type ProtoGetter interface {
+	// Get returns the encoded value of key in the named group of the peer.
+	Get(ctx Context, group string, key string) ([]byte, error)
+}
+
+// A PeerPicker picks the peer that owns a key. Implementations usually
+// assign keys by a consistent hash (see package consistenthash), so that
+// key ownership stays stable as peers join and leave.
+type PeerPicker interface {
+	// PickPeer returns the peer that owns key, and false if the current
+	// process is the owner.
+	PickPeer(key string) (peer ProtoGetter, ok bool)
+}
+
+// A PeerRing assigns keys to the peers of a pool, see HTTPPoolOptions.NewRing.
+type PeerRing interface {
+	// Get returns the peer owning key, "" if there are no peers.
+	Get(key string) string
+}
+
+// A NewRingFunc creates the ring of peers of a pool. All the processes of
+// the pool must use the same.
+type NewRingFunc func(peers []string) PeerRing
+
+// ConsistentRing returns a NewRingFunc of ring hashes giving replicas points
+// to each peer (see package consistenthash), placed by fn. Nil fn means the
+// low 32 bits of XXH64 (see package hashx). It is the default ring of the
+// pools, with consistenthash.DefaultReplicas replicas.
+func ConsistentRing(replicas int, fn consistenthash.Hash) NewRingFunc {
+	if fn == nil {
+		fn = xxh32
+	}
+	return func(peers []string) PeerRing {
+		ring := consistenthash.New(replicas, fn)
+		ring.Add(peers...)
+		return ring
+	}
+}
+
+// ringOf returns newRing, or the ConsistentRing of replicas and fn if nil.
+func ringOf(newRing NewRingFunc, replicas int, fn consistenthash.Hash) NewRingFunc {
+	if newRing != nil {
+		return newRing
+	}
+	if replicas <= 0 {
+		replicas = consistenthash.DefaultReplicas
+	}
+	return ConsistentRing(replicas, fn)
+}
+
+func xxh32(data []byte) uint32 {
+	return uint32(hashx.XXH64(0).Sum64(data))
+}
+
+// RendezvousRing is a NewRingFunc assigning keys to peers by rendezvous
+// hashing (see package hashring): adding or removing a peer only moves the
+// keys it owns, without the memory of the points of a ring hash.
+func RendezvousRing(peers []string) PeerRing {
+	r := hashring.NewStrings()
+	for _, peer := range peers {
+		r.Add(peer, 1)
+	}
+	return rendezvousRing{r}
+}
+
+type rendezvousRing struct {
+	r *hashring.Rendezvous[string]
+}
+
+func (p rendezvousRing) Get(key string) string {
+	peer, _ := p.r.Get(key)
+	return peer
+}
+
+// SetPeerPicker makes the group fetch the values of the keys owned by other
+// processes from them, rather than loading them by its getter. Only string
+// keys are fetched from peers. It is usually called from a hook registered
+// by RegisterNewGroupHook, so that all groups share the peers.
+//
+// Peers send values encoded as bytes (see EncodeValue), so the values
+// fetched from a peer are []byte. If a fetch fails, the value is loaded
+// locally.
+func (g *Group) SetPeerPicker(peers PeerPicker) {
+	g.peersMu.Lock()
+	g.peers = peers
+	g.peersMu.Unlock()
+}
+
+func (g *Group) peerPicker() PeerPicker {
+	g.peersMu.RLock()
+	defer g.peersMu.RUnlock()
+	return g.peers
+}
+
+// peerOf returns the owner of key, and false if the current process is
+// the owner.
+func (g *Group) peerOf(key Key) (peer ProtoGetter, ok bool) {
+	peers := g.peerPicker()
+	skey, isString := key.(string)
+	if peers == nil || !isString {
+		return
+	}
+	return peers.PickPeer(skey)
+}
+
+// getFromPeer fetches the value of key from its owner. ok is false if the
+// current process is the owner, or if the fetch failed.
+func (g *Group) getFromPeer(ctx Context, key Key) (val Value, ok bool) {
+	peer, ok := g.peerOf(key)
+	if !ok {
+		return
+	}
+	data, err := peer.Get(ctx, g.name, key.(string))
+	if err != nil {
+		g.loads.inc(&g.loads.stats.PeerErrors)
+		return nil, false
+	}
+	g.loads.inc(&g.loads.stats.PeerLoads)
+	g.fetchedFromPeer(key, data)
+	return data, true
+}
+
+// EncodeValue encodes a value to send it to a peer: []byte and string values
+// are sent as is, and encoding.BinaryMarshalers by their MarshalBinary
+// method. Other values can't be sent.
+func EncodeValue(val Value) ([]byte, error) {
+	switch v := val.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	}
+	return nil, fmt.Errorf("objcache: can't encode a value of type %T", val)
+}
+
+// -----------------------------------------------------------------------------
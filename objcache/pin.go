@@ -0,0 +1,110 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// pinnedEntry is an entry taken out of the policy of a cache, so that it
+// can't be evicted.
+type pinnedEntry struct {
+	value Value
+	n     int // pins
+}
+
+// Pin exempts the cached entry of key from eviction until it is unpinned,
+// e.g. for a huge value that is expensive to build and must not be evicted
+// while a request uses it. It reports whether key is cached: Get it first.
+// Pins nest, so each Pin must be followed by an Unpin.
+//
+// A pinned entry doesn't expire, and doesn't count toward the capacity of
+// the cache, though it does toward its byte budget. Set replaces it, and it
+// is dropped by Remove and Purge as any entry.
+func (g *Group) Pin(key Key) bool {
+	return g.mainCache.pin(key)
+}
+
+// Unpin undoes a Pin of key. When the last pin is undone, the entry can be
+// evicted again, and expires if its TTL has elapsed. It reports whether key
+// was pinned.
+func (g *Group) Unpin(key Key) bool {
+	return g.mainCache.unpin(key)
+}
+
+// -----------------------------------------------------------------------------
+
+func (c *shard) pin(key Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.pinned[key]; ok {
+		p.n++
+		return true
+	}
+	value, ok := c.lru.Get(key)
+	if !ok || c.expiredLocked(key) {
+		return false
+	}
+	c.moving = true
+	c.lru.Remove(key)
+	c.moving = false
+	if c.pinned == nil {
+		c.pinned = make(map[Key]*pinnedEntry)
+	}
+	c.pinned[key] = &pinnedEntry{value: value, n: 1}
+	return true
+}
+
+func (c *shard) unpin(key Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pinned[key]
+	if !ok {
+		return false
+	}
+	if p.n--; p.n == 0 {
+		delete(c.pinned, key)
+		c.lru.Add(key, p.value)
+		c.pruneLocked()
+	}
+	return true
+}
+
+// replacePinnedLocked replaces the value of key if it is pinned, and reports
+// whether it is.
+func (c *shard) replacePinnedLocked(key Key, value Value, ttl time.Duration) bool {
+	p, ok := c.pinned[key]
+	if !ok {
+		return false
+	}
+	if old := p.value; !sameValue(old, value) {
+		p.value = value
+		c.leaveLocked(key, old, EvictReplaced)
+		c.budget.add(sizeOf(value))
+	}
+	c.stampLocked(key, ttl)
+	c.pruneLocked()
+	return true
+}
+
+// leaveLocked passes the value of key, which has left the cache for reason,
+// to onEvicted.
+func (c *shard) leaveLocked(key Key, value Value, reason EvictReason) {
+	c.reason = reason
+	c.onEvicted(key, value)
+	c.reason = EvictCapacity
+}
@@ -0,0 +1,59 @@
+package objcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPin(t *testing.T) {
+	var evicted []Key
+	g := NewGroup("pin-group", 2, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	}, func(key Key, value Value) {
+		evicted = append(evicted, key)
+	})
+	t.Cleanup(func() { RemoveGroup("pin-group") })
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g.SetClock(clock)
+	g.SetTTL(time.Minute)
+
+	if g.Pin("a") {
+		t.Fatal("Pin of a missing key: true")
+	}
+	g.Get(nil, "a")
+	if !g.Pin("a") || !g.Pin("a") {
+		t.Fatal("Pin: false")
+	}
+	for _, key := range []string{"b", "c", "d"} {
+		g.Get(nil, key)
+	}
+	clock.now = clock.now.Add(time.Hour)
+	if v, ok := g.TryGet("a"); !ok || v != "a" {
+		t.Fatal("pinned entry evicted or expired:", evicted)
+	}
+	if n := g.CacheStats().Items; n != 3 {
+		t.Fatal("items:", n)
+	}
+
+	if !g.Unpin("a") {
+		t.Fatal("Unpin: false")
+	}
+	if _, ok := g.TryGet("a"); !ok {
+		t.Fatal("entry unpinned before its last Unpin")
+	}
+	g.Unpin("a")
+	if _, ok := g.TryGet("a"); ok || g.Unpin("a") {
+		t.Fatal("unpinned entry did not expire")
+	}
+
+	g.Get(nil, "e")
+	g.Pin("e")
+	g.Set("e", "new e")
+	if v, _ := g.TryGet("e"); v != "new e" {
+		t.Fatal("Set of a pinned entry:", v)
+	}
+	n := len(evicted)
+	if !g.Remove("e") || len(evicted) != n+1 || g.Unpin("e") {
+		t.Fatal("Remove of a pinned entry:", evicted)
+	}
+}
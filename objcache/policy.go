@@ -0,0 +1,95 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"github.com/qiniu/x/objcache/lru"
+)
+
+// A Policy stores the entries of the cache of a group and decides which one
+// to evict when it is full. Its methods are called with the cache locked, so
+// a Policy need not be safe for concurrent use.
+type Policy interface {
+	// Add adds or replaces the value of key, evicting entries if the
+	// policy is full. A policy with an admission filter may evict the new
+	// entry itself instead: the group then reports it as EvictRejected.
+	Add(key Key, value Value)
+
+	// Get looks up the value of key, recording the access.
+	Get(key Key) (value Value, ok bool)
+
+	// Remove removes key.
+	Remove(key Key)
+
+	// RemoveOldest evicts the entry the policy considers the least useful.
+	RemoveOldest()
+
+	// Len returns the number of entries.
+	Len() int
+
+	// Clear removes all the entries.
+	Clear()
+}
+
+// A Ranger is a Policy that can enumerate its entries.
+type Ranger interface {
+	// Range calls fn for each entry until fn returns false, preferably
+	// from the most useful entry to the least useful one. fn must not
+	// modify the policy.
+	Range(fn func(key Key, value Value) bool)
+}
+
+// A Peeker is a Policy that can look up an entry without recording the
+// access, see Group.Peek.
+type Peeker interface {
+	// Peek looks up the value of key, leaving the order of eviction as is.
+	Peek(key Key) (value Value, ok bool)
+}
+
+// A Resizer is a Policy whose capacity can be changed. The capacity of
+// other policies only changes when they are replaced.
+type Resizer interface {
+	// SetMaxEntries changes the max number of entries. The caller evicts the
+	// entries beyond it with RemoveOldest.
+	SetMaxEntries(n int)
+}
+
+// A NewPolicyFunc creates a Policy holding up to maxEntries entries (zero
+// means no limit), which calls onEvicted (if not nil) for each entry that
+// is evicted or removed.
+type NewPolicyFunc = func(maxEntries int, onEvicted OnEvictedFunc) Policy
+
+// NewLRU creates the default, least recently used, policy.
+func NewLRU(maxEntries int, onEvicted OnEvictedFunc) Policy {
+	c := lru.New(maxEntries)
+	c.OnEvicted = onEvicted
+	return lruPolicy{c}
+}
+
+type lruPolicy struct {
+	*lru.Cache
+}
+
+func (p lruPolicy) SetMaxEntries(n int) {
+	p.MaxEntries = n
+}
+
+var (
+	_ Ranger  = lruPolicy{}
+	_ Peeker  = lruPolicy{}
+	_ Resizer = lruPolicy{}
+)
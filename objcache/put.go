@@ -0,0 +1,75 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNoSetter is returned by Group.Put when the group has no Setter.
+var ErrNoSetter = errors.New("objcache: no setter")
+
+// A Setter writes values to the backend store a group caches, see
+// Group.Put.
+type Setter interface {
+	Set(key Key, value Value) error
+}
+
+// A SetterFunc implements Setter with a function.
+type SetterFunc func(key Key, value Value) error
+
+// Set implements Setter.
+func (f SetterFunc) Set(key Key, value Value) error {
+	return f(key, value)
+}
+
+// SetSetter sets the backend store written by Put. It must be called before
+// the group is used.
+func (g *Group) SetSetter(s Setter) {
+	g.setter = s
+}
+
+// putLocks serialize the Puts of the keys of the same stripe.
+type putLocks [16]sync.Mutex
+
+func (l *putLocks) of(key Key) *sync.Mutex {
+	return &l[hashKey(key)%uint32(len(l))]
+}
+
+// Put writes value to the backend store of the group (see SetSetter) and
+// then caches it under key, as Set does, so that the cache and the store
+// stay consistent: concurrent Puts of a key are serialized, and the loads of
+// key in progress, which may have read the old value, are not cached when
+// they complete. If the store fails, key is removed from the cache, as the
+// store may hold either value, and Put returns the error.
+func (g *Group) Put(key Key, value Value) error {
+	if g.setter == nil {
+		return ErrNoSetter
+	}
+	mu := g.puts.of(key)
+	mu.Lock()
+	defer mu.Unlock()
+	err := g.setter.Set(key, value)
+	g.flights.forget(key)
+	if err != nil {
+		g.Remove(key)
+		return err
+	}
+	g.Set(key, value)
+	return nil
+}
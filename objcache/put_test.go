@@ -0,0 +1,67 @@
+package objcache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestPut(t *testing.T) {
+	var mu sync.Mutex
+	store := map[Key]Value{"k": "old"}
+	block := make(chan struct{})
+	loading := make(chan struct{})
+	errDown := errors.New("down")
+	var setErr error
+	g := NewGroupWith("put-group", func(ctx Context, key Key) (Value, error) {
+		mu.Lock()
+		v := store[key]
+		mu.Unlock()
+		if key == "k" {
+			close(loading)
+			<-block
+		}
+		return v, nil
+	}, WithSetter(SetterFunc(func(key Key, value Value) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if setErr != nil {
+			return setErr
+		}
+		store[key] = value
+		return nil
+	})))
+	t.Cleanup(func() { RemoveGroup("put-group") })
+
+	// a load of the old value in progress isn't cached
+	done := make(chan Value)
+	go func() {
+		v, _ := g.Get(nil, "k")
+		done <- v
+	}()
+	<-loading
+	if err := g.Put("k", "new"); err != nil {
+		t.Fatal("Put:", err)
+	}
+	close(block)
+	if v := <-done; v != "old" {
+		t.Fatal("Get in progress:", v)
+	}
+	if v, ok := g.TryGet("k"); !ok || v != "new" {
+		t.Fatal("cached value:", v, ok)
+	}
+
+	setErr = errDown
+	if err := g.Put("k", "newer"); err != errDown {
+		t.Fatal("failed Put:", err)
+	}
+	if _, ok := g.TryGet("k"); ok {
+		t.Fatal("key cached after a failed Put")
+	}
+
+	g = NewGroup("put-no-setter-group", 0, nil)
+	t.Cleanup(func() { RemoveGroup("put-no-setter-group") })
+	if err := g.Put("k", "v"); err != ErrNoSetter {
+		t.Fatal("Put without a setter:", err)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// Range calls fn for each entry of both tiers of the cache of the group,
+// until fn returns false, e.g. for debugging dashboards or to remove some
+// entries. The entries are a snapshot of each shard of the cache (see
+// NewGroup) taken before the first call, from the most recently used one of
+// the shard, and taking it doesn't change their order. fn may call the
+// methods of the group.
+//
+// Expired entries and cached errors are skipped. If the policy of the group
+// isn't a Ranger, only its pinned entries are enumerated.
+func (g *Group) Range(fn func(key Key, value Value) bool) {
+	main, _ := g.mainCache.entries()
+	hot, _ := g.hotCache.entries()
+	for _, ents := range [][]cacheEntry{main, hot} {
+		for _, e := range ents {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns a snapshot of the keys of both tiers of the cache of the
+// group, e.g. for admin tools, in the order of Range: from the most recently
+// used one of each shard. Expired entries and cached errors are skipped.
+func (g *Group) Keys() []Key {
+	main, _ := g.mainCache.entries()
+	hot, _ := g.hotCache.entries()
+	keys := make([]Key, 0, len(main)+len(hot))
+	for _, ents := range [][]cacheEntry{main, hot} {
+		for _, e := range ents {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// -----------------------------------------------------------------------------
+
+type cacheEntry struct {
+	key    Key
+	value  Value
+	expire time.Time // zero if none
+}
+
+// entries returns a snapshot of the live entries of the cache: the pinned
+// ones, and then the ones of the policy from the most useful one. ok is
+// false if the policy isn't a Ranger.
+func (c *shard) entries() (ents []cacheEntry, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := c.now()
+	for key, p := range c.pinned {
+		if _, neg := p.value.(*negEntry); !neg {
+			ents = append(ents, cacheEntry{key, p.value, c.expires[key]})
+		}
+	}
+	r, ok := c.lru.(Ranger)
+	if !ok {
+		return
+	}
+	r.Range(func(key Key, value Value) bool {
+		if _, neg := value.(*negEntry); neg {
+			return true
+		}
+		exp, ok := c.expires[key]
+		if (!ok || now.Before(exp)) && !c.oldLocked(key) {
+			ents = append(ents, cacheEntry{key, value, exp})
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,42 @@
+package objcache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	g := NewGroup("range-group", 100, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("range-group") })
+	for _, key := range []string{"a", "b", "c", "d"} {
+		g.Get(nil, key)
+	}
+	g.Get(nil, "a")
+
+	var keys []Key
+	g.Range(func(key Key, value Value) bool {
+		keys = append(keys, key)
+		if key == "c" {
+			g.Remove(key)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(keys, []Key{"a", "d", "c", "b"}) {
+		t.Fatal("Range:", keys)
+	}
+	g.Get(nil, "d")
+
+	keys = nil
+	g.Range(func(key Key, value Value) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	if !reflect.DeepEqual(keys, []Key{"d", "a"}) {
+		t.Fatal("Range after a Remove:", keys)
+	}
+	if keys = g.Keys(); !reflect.DeepEqual(keys, []Key{"d", "a", "b"}) {
+		t.Fatal("Keys:", keys)
+	}
+}
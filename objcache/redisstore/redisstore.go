@@ -0,0 +1,309 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package redisstore implements objcache.SecondaryStore on a Redis server,
+// so that the instances of a service share a second tier of cache (see
+// objcache.Group.SetSecondary).
+//
+// The store speaks the Redis protocol (RESP) itself, with GET, SET and DEL
+// only, over a small pool of connections; it doesn't depend on any client
+// library.
+package redisstore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qiniu/x/objcache"
+)
+
+var (
+	// ErrNotFound is returned by Get when the key is absent.
+	ErrNotFound = objcache.ErrNotFound
+
+	// ErrClosed is returned when the store is used after Close.
+	ErrClosed = errors.New("redisstore: store closed")
+)
+
+var _ objcache.SecondaryStore = (*Store)(nil)
+
+// An Error is an error reply of the Redis server.
+type Error string
+
+func (e Error) Error() string {
+	return "redisstore: " + string(e)
+}
+
+// -----------------------------------------------------------------------------
+
+// Options configures a Store. Zero fields take their defaults.
+type Options struct {
+	// Password, if not empty, authenticates the connections.
+	Password string
+
+	// DB is the database selected by the connections.
+	DB int
+
+	// Prefix is prepended to the keys, e.g. to share a database between
+	// several groups.
+	Prefix string
+
+	// TTL is the expiration of the stored values. Zero means none.
+	TTL time.Duration
+
+	// DialTimeout bounds the time to connect. Default 5 seconds.
+	DialTimeout time.Duration
+
+	// IOTimeout bounds the time of a command. Default 3 seconds.
+	IOTimeout time.Duration
+
+	// MaxIdle is the number of idle connections kept for reuse. Default 4.
+	MaxIdle int
+}
+
+// Store is a Redis store. It is safe for concurrent use.
+type Store struct {
+	addr string
+	opts Options
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// New creates a store on the Redis server at addr ("host:port"). It
+// connects lazily.
+func New(addr string, opts *Options) *Store {
+	s := &Store{addr: addr}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.DialTimeout <= 0 {
+		s.opts.DialTimeout = 5 * time.Second
+	}
+	if s.opts.IOTimeout <= 0 {
+		s.opts.IOTimeout = 3 * time.Second
+	}
+	if s.opts.MaxIdle <= 0 {
+		s.opts.MaxIdle = 4
+	}
+	return s
+}
+
+// Get returns the data stored under key.
+func (s *Store) Get(key string) ([]byte, error) {
+	reply, err := s.do("GET", s.opts.Prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNotFound
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redisstore: unexpected reply %v to GET", reply)
+	}
+	return data, nil
+}
+
+// Set stores data under key, with the TTL of the options.
+func (s *Store) Set(key string, data []byte) (err error) {
+	if ttl := s.opts.TTL; ttl > 0 {
+		ms := int64((ttl + time.Millisecond - 1) / time.Millisecond)
+		_, err = s.do("SET", s.opts.Prefix+key, data, "PX", strconv.FormatInt(ms, 10))
+	} else {
+		_, err = s.do("SET", s.opts.Prefix+key, data)
+	}
+	return
+}
+
+// Delete removes key from the store.
+func (s *Store) Delete(key string) error {
+	_, err := s.do("DEL", s.opts.Prefix+key)
+	return err
+}
+
+// Close closes the idle connections of the store. The commands in progress
+// complete, and then their connections are closed too.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	idle := s.idle
+	s.idle, s.closed = nil, true
+	s.mu.Unlock()
+	for _, c := range idle {
+		c.Close()
+	}
+	return nil
+}
+
+// do runs a command, whose args are strings or []byte, and returns its reply.
+func (s *Store) do(args ...interface{}) (reply interface{}, err error) {
+	c, err := s.get()
+	if err != nil {
+		return
+	}
+	reply, err = c.do(s.opts.IOTimeout, args...)
+	if _, ok := err.(Error); err == nil || ok {
+		s.put(c)
+	} else {
+		c.Close()
+	}
+	return
+}
+
+func (s *Store) get() (*conn, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(s.idle); n > 0 {
+		c := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return c, nil
+	}
+	s.mu.Unlock()
+	return s.dial()
+}
+
+func (s *Store) put(c *conn) {
+	s.mu.Lock()
+	if !s.closed && len(s.idle) < s.opts.MaxIdle {
+		s.idle = append(s.idle, c)
+		c = nil
+	}
+	s.mu.Unlock()
+	if c != nil {
+		c.Close()
+	}
+}
+
+func (s *Store) dial() (*conn, error) {
+	nc, err := net.DialTimeout("tcp", s.addr, s.opts.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if s.opts.Password != "" {
+		if _, err = c.do(s.opts.IOTimeout, "AUTH", s.opts.Password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if s.opts.DB != 0 {
+		if _, err = c.do(s.opts.IOTimeout, "SELECT", strconv.Itoa(s.opts.DB)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func (c *conn) do(timeout time.Duration, args ...interface{}) (interface{}, error) {
+	c.SetDeadline(time.Now().Add(timeout))
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		default:
+			panic("redisstore: bad argument type")
+		}
+		fmt.Fprintf(c.w, "$%d\r\n", len(b))
+		c.w.Write(b)
+		c.w.WriteString("\r\n")
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+// readReply reads a reply: a string, an Error, an int64, a []byte, an
+// []interface{} or nil.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redisstore: empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		b := make([]byte, n+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				if _, ok := err.(Error); !ok {
+					return nil, err
+				}
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redisstore: bad reply %q", line)
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redisstore: bad reply line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// -----------------------------------------------------------------------------
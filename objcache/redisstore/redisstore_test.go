@@ -0,0 +1,191 @@
+package redisstore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qiniu/x/objcache"
+	"github.com/qiniu/x/objcache/cachetest"
+)
+
+// fakeServer is a Redis server with AUTH, SELECT, GET, SET [PX] and DEL.
+type fakeServer struct {
+	ln       net.Listener
+	password string
+
+	mu   sync.Mutex
+	data map[string]string
+	ttls map[string]int64
+	cmds []string
+}
+
+func newFakeServer(t *testing.T, password string) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can't listen:", err)
+	}
+	s := &fakeServer{ln: ln, password: password, data: make(map[string]string), ttls: make(map[string]int64)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeServer) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	authed := s.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.cmds = append(s.cmds, strings.Join(args, " "))
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			if authed = args[1] == s.password; authed {
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "SELECT":
+			reply = "+OK\r\n"
+		case cmd == "GET":
+			if v, ok := s.data[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case cmd == "SET":
+			s.data[args[1]] = args[2]
+			if len(args) == 5 && args[3] == "PX" {
+				s.ttls[args[1]], _ = strconv.ParseInt(args[4], 10, 64)
+			}
+			reply = "+OK\r\n"
+		case cmd == "DEL":
+			n := 0
+			if _, ok := s.data[args[1]]; ok {
+				delete(s.data, args[1])
+				n = 1
+			}
+			reply = fmt.Sprintf(":%d\r\n", n)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+		if _, err = io.WriteString(c, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || line[0] != '*' {
+		return nil, fmt.Errorf("bad command %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		v, err := readReply(r)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = string(v.([]byte))
+	}
+	return args, nil
+}
+
+func TestStore(t *testing.T) {
+	srv := newFakeServer(t, "secret")
+	s := New(srv.ln.Addr().String(), &Options{Password: "secret", DB: 2, Prefix: "app:", TTL: 1500 * time.Microsecond})
+	defer s.Close()
+	if err := s.Set("k", []byte("v")); err != nil {
+		t.Fatal("Set:", err)
+	}
+	if data, err := s.Get("k"); err != nil || string(data) != "v" {
+		t.Fatal("Get:", data, err)
+	}
+	if _, err := s.Get("absent"); err != ErrNotFound {
+		t.Fatal("Get of an absent key:", err)
+	}
+	srv.mu.Lock()
+	cmds, ttl := srv.cmds, srv.ttls["app:k"]
+	srv.mu.Unlock()
+	want := []string{"AUTH secret", "SELECT 2", "SET app:k v PX 2", "GET app:k", "GET app:absent"}
+	if strings.Join(cmds, "|") != strings.Join(want, "|") || ttl != 2 {
+		t.Fatal("commands:", cmds, ttl)
+	}
+
+	bad := New(srv.ln.Addr().String(), &Options{Password: "wrong"})
+	if _, err := bad.Get("k"); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Fatal("Get with a wrong password:", err)
+	}
+	s.Close()
+	if _, err := s.Get("k"); err != ErrClosed {
+		t.Fatal("Get after Close:", err)
+	}
+}
+
+func TestGroupSecondary(t *testing.T) {
+	srv := newFakeServer(t, "")
+	s := New(srv.ln.Addr().String(), nil)
+	defer s.Close()
+	loads := 0
+	newGroup := func(name string) *objcache.Group {
+		g := objcache.NewGroup(name, 0, func(ctx objcache.Context, key objcache.Key) (objcache.Value, error) {
+			loads++
+			return "v" + key.(string), nil
+		})
+		g.SetSecondary(s, func(key string, data []byte) (objcache.Value, error) {
+			return string(data), nil
+		})
+		t.Cleanup(func() { objcache.RemoveGroup(name) })
+		return g
+	}
+	g1, g2 := newGroup("redis-group-1"), newGroup("redis-group-2")
+	if v, err := g1.Get(nil, "a"); err != nil || v != "va" {
+		t.Fatal("Get:", v, err)
+	}
+	if v, err := g2.Get(nil, "a"); err != nil || v != "va" || loads != 1 {
+		t.Fatal("Get from the secondary store:", v, err, loads)
+	}
+	if s := g2.LoadStats(); s.SecondaryHits != 1 || s.SecondaryErrors != 0 {
+		t.Fatal("LoadStats:", s)
+	}
+	g2.Remove("a")
+	g1.Remove("a")
+	if v, err := g1.Get(nil, "a"); err != nil || v != "va" || loads != 2 {
+		t.Fatal("Get after Remove:", v, err, loads)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	srv := newFakeServer(t, "")
+	cachetest.TestSecondaryStore(t, func(t *testing.T) objcache.SecondaryStore {
+		s := New(srv.ln.Addr().String(), &Options{Prefix: t.Name() + ":"})
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
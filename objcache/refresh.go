@@ -0,0 +1,98 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"context"
+	"time"
+)
+
+// SetRefreshAfter turns on refresh-ahead: a Get of an entry cached more than
+// d ago (and not expired yet, see SetTTL) returns the cached value at once,
+// and reloads it in the background, so that hot keys stay warm without the
+// latency of a miss. A reload that fails keeps the cached value, which is
+// reloaded again after d. Zero, the default, turns it off. Only the entries
+// cached from now on are refreshed, so it is best called right after
+// NewGroup.
+//
+// The background reload gets the values of the context of the Get, but not
+// its deadline nor its cancellation.
+func (g *Group) SetRefreshAfter(d time.Duration) {
+	g.mainCache.setRefresh(d)
+}
+
+// refreshIfStale reloads key in the background if it is due.
+func (g *Group) refreshIfStale(ctx Context, key Key) {
+	if !g.mainCache.dueForRefresh(key) {
+		return
+	}
+	if c, ok := ctx.(context.Context); ok {
+		ctx = detachedContext{c}
+	}
+	g.loads.inc(&g.loads.stats.Refreshes)
+	go g.flights.do(ctx, key, func() (Value, error) {
+		start := g.clock.Now()
+		val, err := g.load(ctx, key, g.get)
+		if err == nil {
+			g.commitLoad(key, val, g.clock.Now().Sub(start))
+			g.setSecondary(key, val)
+		}
+		return val, err
+	})
+}
+
+// detachedContext has the values of a context, but never gets done.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return }
+func (detachedContext) Done() <-chan struct{}                   { return nil }
+func (detachedContext) Err() error                              { return nil }
+
+// -----------------------------------------------------------------------------
+
+func (c *shard) setRefresh(d time.Duration) {
+	c.mu.Lock()
+	c.refresh = d
+	if d <= 0 {
+		c.born = nil
+	}
+	c.mu.Unlock()
+}
+
+// dueForRefresh reports whether key was loaded long enough ago to be
+// refreshed, or is due for an early reload (see Group.SetEarlyExpiration),
+// and if so, restarts its refresh period so that a single Get triggers the
+// reload.
+func (c *shard) dueForRefresh(key Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dueEarlyLocked(key) {
+		return true
+	}
+	born, ok := c.born[key]
+	if !ok {
+		return false
+	}
+	now := c.now()
+	if now.Sub(born) < c.refresh {
+		return false
+	}
+	c.born[key] = now
+	return true
+}
@@ -0,0 +1,76 @@
+package objcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRefreshAfter(t *testing.T) {
+	var mu sync.Mutex
+	version := 1
+	var fail bool
+	loaded := make(chan struct{}, 10)
+	g := NewGroup("refresh-group", 0, func(ctx Context, key Key) (Value, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		defer func() { loaded <- struct{}{} }()
+		if c, ok := ctx.(context.Context); ok && c.Done() != nil && version > 1 {
+			t.Error("a refresh can be canceled")
+		}
+		if fail {
+			return nil, errors.New("load failed")
+		}
+		return version, nil
+	})
+	t.Cleanup(func() { RemoveGroup("refresh-group") })
+	clock := &lockedClock{now: time.Unix(1000, 0)}
+	g.SetClock(clock)
+	advance := clock.advance
+	g.SetRefreshAfter(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if v, _ := g.Get(ctx, "k"); v != 1 {
+		t.Fatal("Get:", v)
+	}
+	<-loaded
+	mu.Lock()
+	version = 2
+	mu.Unlock()
+	advance(30 * time.Second)
+	if v, _ := g.Get(ctx, "k"); v != 1 || g.LoadStats().Refreshes != 0 {
+		t.Fatal("Get of a fresh entry:", v, g.LoadStats().Refreshes)
+	}
+
+	advance(time.Minute)
+	if v, _ := g.Get(ctx, "k"); v != 1 {
+		t.Fatal("Get of a stale entry didn't return the cached value:", v)
+	}
+	g.Get(ctx, "k") // the refresh is triggered once
+	<-loaded
+	for deadline := time.Now().Add(time.Second); ; {
+		if v, _ := g.TryGet("k"); v == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the stale entry was not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := g.LoadStats().Refreshes; n != 1 {
+		t.Fatal("refreshes:", n)
+	}
+
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	advance(time.Minute)
+	g.Get(ctx, "k")
+	<-loaded
+	if v, ok := g.TryGet("k"); !ok || v != 2 {
+		t.Fatal("a failed refresh dropped the cached value:", v, ok)
+	}
+}
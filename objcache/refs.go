@@ -0,0 +1,163 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"errors"
+	"sync"
+)
+
+// A Disposer is a value that releases its resources when it leaves the
+// cache, after the onEvicted func and the OnEvict callbacks of its group.
+// A reader that uses such a value after Get returns may see it disposed:
+// see Acquire.
+type Disposer interface {
+	Dispose() error
+}
+
+// ErrNotPinned is returned by Acquire when the value of a key keeps leaving
+// the cache before it can be acquired, e.g. because it is larger than the
+// byte budget of the cache.
+var ErrNotPinned = errors.New("objcache: value can't be pinned in the cache")
+
+// maxAcquireTries bounds the loads of an Acquire.
+const maxAcquireTries = 3
+
+// ref counts the handles of a cached Disposer.
+type ref struct {
+	n       int
+	evicted bool // the value left the cache: dispose it on the last release
+}
+
+// A Handle is a reference to a value acquired by Group.Acquire.
+type Handle struct {
+	val  Value
+	g    *Group
+	ref  *ref // nil if the value is not a Disposer
+	once sync.Once
+}
+
+// Value returns the acquired value. It must not be used after Release.
+func (h *Handle) Value() Value {
+	return h.val
+}
+
+// Release releases the value, which is disposed if it has left the cache
+// and this was its last handle. Calls after the first one do nothing.
+func (h *Handle) Release() {
+	h.once.Do(func() {
+		if h.ref == nil {
+			return
+		}
+		g := h.g
+		g.refsMu.Lock()
+		h.ref.n--
+		last := h.ref.n == 0 && h.ref.evicted
+		g.refsMu.Unlock()
+		if last {
+			h.val.(Disposer).Dispose()
+		}
+	})
+}
+
+// Acquire is Get for values that are Disposers: the returned value isn't
+// disposed until the handle is released, even if it leaves the cache
+// meanwhile. The caller must call Release once it is done with the value.
+func (g *Group) Acquire(ctx Context, key Key) (*Handle, error) {
+	for try := 0; try < maxAcquireTries; try++ {
+		if h, ok, err := g.acquireCached(key); ok {
+			return h, err
+		}
+		val, err := g.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := val.(Disposer); !ok {
+			return &Handle{val: val}, nil
+		}
+		// the value may have been disposed already: acquire the cached one
+	}
+	return nil, ErrNotPinned
+}
+
+// acquireCached acquires the cached value of key, if any.
+func (g *Group) acquireCached(key Key) (h *Handle, ok bool, err error) {
+	fn := func(value Value) {
+		if n, neg := value.(*negEntry); neg {
+			err = n.err
+			return
+		}
+		h = &Handle{val: value, g: g}
+		if _, ok := value.(Disposer); ok {
+			g.refsMu.Lock()
+			r := g.refs[key]
+			if r == nil {
+				if g.refs == nil {
+					g.refs = make(map[Key]*ref)
+				}
+				r = new(ref)
+				g.refs[key] = r
+			}
+			r.n++
+			g.refsMu.Unlock()
+			h.ref = r
+		}
+	}
+	if ok = g.mainCache.withValue(key, fn); !ok {
+		ok = g.hotCache.withValue(key, fn)
+	}
+	return
+}
+
+// dispose disposes value, which has just left the cache under key, unless
+// it has handles: then the last release does.
+func (g *Group) dispose(key Key, value Value) {
+	d, ok := value.(Disposer)
+	if !ok {
+		return
+	}
+	g.refsMu.Lock()
+	if r := g.refs[key]; r != nil {
+		delete(g.refs, key)
+		if r.n > 0 {
+			r.evicted = true
+			g.refsMu.Unlock()
+			return
+		}
+	}
+	g.refsMu.Unlock()
+	d.Dispose()
+}
+
+// -----------------------------------------------------------------------------
+
+// withValue calls fn with the value of key with the cache locked, so that the
+// value can't leave the cache meanwhile, and reports whether key is cached.
+func (c *shard) withValue(key Key, fn func(value Value)) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, pinned := c.pinned[key]; pinned {
+		fn(p.value)
+		return true
+	}
+	value, ok := c.lru.Get(key)
+	if !ok || c.expiredLocked(key) {
+		return false
+	}
+	fn(value)
+	return true
+}
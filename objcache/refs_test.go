@@ -0,0 +1,90 @@
+package objcache
+
+import (
+	"sync"
+	"testing"
+)
+
+type resource struct {
+	mu       sync.Mutex
+	disposed int
+}
+
+func (r *resource) Dispose() error {
+	r.mu.Lock()
+	r.disposed++
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *resource) disposals() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.disposed
+}
+
+func TestAcquire(t *testing.T) {
+	res := make(map[Key]*resource)
+	var mu sync.Mutex
+	g := NewGroup("acquire-group", 1, func(ctx Context, key Key) (Value, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		r := new(resource)
+		res[key] = r
+		return r, nil
+	})
+	t.Cleanup(func() { RemoveGroup("acquire-group") })
+	h1, err := g.Acquire(nil, "a")
+	if err != nil {
+		t.Fatal("Acquire:", err)
+	}
+	h2, _ := g.Acquire(nil, "a")
+	a := res["a"]
+	if h1.Value() != a || h2.Value() != a {
+		t.Fatal("Acquire: wrong value")
+	}
+
+	g.Get(nil, "b") // evicts a
+	if a.disposals() != 0 {
+		t.Fatal("disposed while acquired")
+	}
+	h1.Release()
+	h1.Release()
+	if a.disposals() != 0 {
+		t.Fatal("disposed before the last release")
+	}
+	h2.Release()
+	if a.disposals() != 1 {
+		t.Fatal("not disposed on the last release:", a.disposals())
+	}
+
+	b := res["b"]
+	h, _ := g.Acquire(nil, "b")
+	h.Release()
+	g.Remove("b")
+	if b.disposals() != 1 {
+		t.Fatal("released value not disposed when it left the cache:", b.disposals())
+	}
+
+}
+
+type bigResource struct {
+	resource
+}
+
+func (r *bigResource) Size() int64 { return 10 }
+
+func TestAcquireNotPinned(t *testing.T) {
+	var r bigResource
+	g := NewGroup("acquire-big-group", 0, func(ctx Context, key Key) (Value, error) {
+		return &r, nil
+	})
+	t.Cleanup(func() { RemoveGroup("acquire-big-group") })
+	g.SetMaxBytes(1)
+	if _, err := g.Acquire(nil, "big"); err != ErrNotPinned {
+		t.Fatal("Acquire of a value larger than the cache:", err)
+	}
+	if r.disposals() != maxAcquireTries {
+		t.Fatal("disposals:", r.disposals())
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package sampled implements an approximated LRU eviction policy for
+// objcache, in the way of Redis.
+//
+// Instead of keeping its entries in a recency list, the cache stores one
+// access stamp per entry, in a flat slice, and evicts the least recently
+// used of a few randomly sampled entries. This saves the two list pointers
+// and the list element of each entry of an exact LRU, and turns accesses
+// into a single store, which matters for caches of many tiny entries. With
+// the default of 5 samples, the evicted entry is older than about 5/6 of
+// the cache on average.
+package sampled
+
+import (
+	"github.com/qiniu/x/objcache"
+)
+
+// DefaultSamples is the default number of entries sampled per eviction.
+const DefaultSamples = 5
+
+type entry struct {
+	key   objcache.Key
+	value objcache.Value
+	stamp uint64 // logical time of the last access
+}
+
+// Cache is a sampled-eviction cache. It is not safe for concurrent use.
+type Cache struct {
+	maxEntries int
+	samples    int
+	onEvicted  objcache.OnEvictedFunc
+
+	entries []entry
+	index   map[objcache.Key]int
+	clock   uint64
+	rnd     uint64
+}
+
+// New creates a cache of up to maxEntries entries (zero means no limit)
+// that samples DefaultSamples entries per eviction.
+func New(maxEntries int, onEvicted objcache.OnEvictedFunc) *Cache {
+	return NewWithSamples(maxEntries, DefaultSamples, onEvicted)
+}
+
+// NewWithSamples is like New, but samples the given number of entries per
+// eviction: more samples approximate LRU better, and cost more.
+func NewWithSamples(maxEntries, samples int, onEvicted objcache.OnEvictedFunc) *Cache {
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	return &Cache{
+		maxEntries: maxEntries,
+		samples:    samples,
+		onEvicted:  onEvicted,
+		index:      make(map[objcache.Key]int),
+		rnd:        0x9e3779b97f4a7c15,
+	}
+}
+
+// Policy returns an objcache.NewPolicyFunc creating caches that sample the
+// given number of entries per eviction, to be passed to Group.SetPolicy.
+func Policy(samples int) objcache.NewPolicyFunc {
+	return func(maxEntries int, onEvicted objcache.OnEvictedFunc) objcache.Policy {
+		return NewWithSamples(maxEntries, samples, onEvicted)
+	}
+}
+
+func (c *Cache) tick() uint64 {
+	c.clock++
+	return c.clock
+}
+
+// random returns a pseudo-random number in [0, n), by xorshift64*.
+func (c *Cache) random(n int) int {
+	c.rnd ^= c.rnd >> 12
+	c.rnd ^= c.rnd << 25
+	c.rnd ^= c.rnd >> 27
+	return int((c.rnd * 2685821657736338717 >> 32) % uint64(n))
+}
+
+// Add implements objcache.Policy.
+func (c *Cache) Add(key objcache.Key, value objcache.Value) {
+	if i, ok := c.index[key]; ok {
+		e := &c.entries[i]
+		e.value, e.stamp = value, c.tick()
+		return
+	}
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.RemoveOldest()
+	}
+	c.index[key] = len(c.entries)
+	c.entries = append(c.entries, entry{key: key, value: value, stamp: c.tick()})
+}
+
+// Get implements objcache.Policy.
+func (c *Cache) Get(key objcache.Key) (value objcache.Value, ok bool) {
+	i, ok := c.index[key]
+	if !ok {
+		return
+	}
+	e := &c.entries[i]
+	e.stamp = c.tick()
+	return e.value, true
+}
+
+// Peek implements objcache.Peeker.
+func (c *Cache) Peek(key objcache.Key) (value objcache.Value, ok bool) {
+	i, ok := c.index[key]
+	if !ok {
+		return
+	}
+	return c.entries[i].value, true
+}
+
+// Remove implements objcache.Policy.
+func (c *Cache) Remove(key objcache.Key) {
+	if i, ok := c.index[key]; ok {
+		c.removeAt(i)
+	}
+}
+
+// RemoveOldest evicts the least recently used of the sampled entries.
+func (c *Cache) RemoveOldest() {
+	n := len(c.entries)
+	if n == 0 {
+		return
+	}
+	victim := c.random(n)
+	for s := 1; s < c.samples && s < n; s++ {
+		if i := c.random(n); c.entries[i].stamp < c.entries[victim].stamp {
+			victim = i
+		}
+	}
+	c.removeAt(victim)
+}
+
+func (c *Cache) removeAt(i int) {
+	e := c.entries[i]
+	last := len(c.entries) - 1
+	if i != last {
+		c.entries[i] = c.entries[last]
+		c.index[c.entries[i].key] = i
+	}
+	c.entries[last] = entry{}
+	c.entries = c.entries[:last]
+	delete(c.index, e.key)
+	if c.onEvicted != nil {
+		c.onEvicted(e.key, e.value)
+	}
+}
+
+// SetMaxEntries implements objcache.Resizer.
+func (c *Cache) SetMaxEntries(n int) {
+	c.maxEntries = n
+}
+
+// Len implements objcache.Policy.
+func (c *Cache) Len() int {
+	return len(c.entries)
+}
+
+// Clear implements objcache.Policy.
+func (c *Cache) Clear() {
+	entries := c.entries
+	c.entries = nil
+	c.index = make(map[objcache.Key]int)
+	if c.onEvicted != nil {
+		for _, e := range entries {
+			c.onEvicted(e.key, e.value)
+		}
+	}
+}
+
+// Range implements objcache.Ranger. The entries are enumerated in no
+// particular order.
+func (c *Cache) Range(fn func(key objcache.Key, value objcache.Value) bool) {
+	for _, e := range c.entries {
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+var (
+	_ objcache.Policy  = (*Cache)(nil)
+	_ objcache.Ranger  = (*Cache)(nil)
+	_ objcache.Peeker  = (*Cache)(nil)
+	_ objcache.Resizer = (*Cache)(nil)
+)
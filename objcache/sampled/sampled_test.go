@@ -0,0 +1,82 @@
+package sampled
+
+import (
+	"testing"
+
+	"github.com/qiniu/x/objcache"
+	"github.com/qiniu/x/objcache/cachetest"
+)
+
+func TestCache(t *testing.T) {
+	evicted := make(map[objcache.Key]int)
+	c := New(1000, func(key objcache.Key, value objcache.Value) { evicted[key]++ })
+	for i := 0; i < 1000; i++ {
+		c.Add(i, i)
+	}
+	for i := 0; i < 500; i++ {
+		if v, ok := c.Get(i); !ok || v != i {
+			t.Fatal("Get:", i, v, ok)
+		}
+	}
+	for i := 1000; i < 1500; i++ {
+		c.Add(i, i)
+	}
+	if c.Len() != 1000 || len(evicted) != 500 {
+		t.Fatal("Len:", c.Len(), len(evicted))
+	}
+	old := 0
+	for key, n := range evicted {
+		if n != 1 {
+			t.Fatal("evicted twice:", key)
+		}
+		if k := key.(int); k >= 500 && k < 1000 {
+			old++
+		}
+	}
+	// the recently read half should mostly survive
+	if old < 350 {
+		t.Fatal("poor approximation of LRU:", old)
+	}
+
+	c.Remove(1499)
+	if _, ok := c.Get(1499); ok || evicted[1499] != 1 {
+		t.Fatal("Remove")
+	}
+	n := 0
+	c.Range(func(key objcache.Key, value objcache.Value) bool {
+		if key != value {
+			t.Fatal("Range:", key, value)
+		}
+		n++
+		return true
+	})
+	if n != c.Len() {
+		t.Fatal("Range count:", n)
+	}
+	c.Clear()
+	if c.Len() != 0 || len(evicted) != 1500 {
+		t.Fatal("Clear:", c.Len(), len(evicted))
+	}
+}
+
+func TestGroupPolicy(t *testing.T) {
+	g := objcache.NewGroup("sampled-group", 100, func(ctx objcache.Context, key objcache.Key) (objcache.Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { objcache.RemoveGroup("sampled-group") })
+	g.Get(nil, "a")
+	g.SetPolicy(Policy(3))
+	if v, ok := g.TryGet("a"); !ok || v != "a" {
+		t.Fatal("entry not moved to the new policy")
+	}
+	for i := 0; i < 200; i++ {
+		g.Get(nil, i)
+	}
+	if n := g.CacheStats().Items; n != 100 {
+		t.Fatal("items:", n)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	cachetest.TestPolicy(t, Policy(DefaultSamples))
+}
@@ -0,0 +1,83 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+// SetSecondary makes store (e.g. Redis or memcached, shared by the
+// instances of a service) the second tier of the cache of the group: a key
+// missed by the cache, and not owned by a peer, is looked up in store before
+// it is loaded by the getter, and the values loaded by the getter are stored
+// there, encoded by EncodeValue. decode decodes the data of store into the
+// values of the group; if it is nil, the values are the data as []byte.
+//
+// Only string keys are stored. Set and SetWithTTL write their value through
+// to store, and Remove deletes it from store, so that a removed value isn't
+// served again by another instance; Purge and the evictions leave store as
+// is. The errors of store are counted in LoadStats.SecondaryErrors and
+// otherwise ignored. SetSecondary must be called before the group is used.
+func (g *Group) SetSecondary(store SecondaryStore, decode DecodeFunc) {
+	g.secondary, g.decodeSecondary = store, decode
+}
+
+// getSecondary looks up key in the secondary store.
+func (g *Group) getSecondary(key Key) (Value, bool) {
+	s, ok := key.(string)
+	if g.secondary == nil || !ok {
+		return nil, false
+	}
+	data, err := g.secondary.Get(s)
+	if err != nil {
+		if err != ErrNotFound {
+			g.loads.inc(&g.loads.stats.SecondaryErrors)
+		}
+		return nil, false
+	}
+	var val Value = data
+	if g.decodeSecondary != nil {
+		if val, err = g.decodeSecondary(s, data); err != nil {
+			g.loads.inc(&g.loads.stats.SecondaryErrors)
+			return nil, false
+		}
+	}
+	g.loads.inc(&g.loads.stats.SecondaryHits)
+	return val, true
+}
+
+// setSecondary stores the value of key in the secondary store, if it can be
+// encoded.
+func (g *Group) setSecondary(key Key, val Value) {
+	s, ok := key.(string)
+	if g.secondary == nil || !ok {
+		return
+	}
+	data, err := EncodeValue(val)
+	if err != nil {
+		return
+	}
+	if err = g.secondary.Set(s, data); err != nil {
+		g.loads.inc(&g.loads.stats.SecondaryErrors)
+	}
+}
+
+func (g *Group) deleteSecondary(key Key) {
+	s, ok := key.(string)
+	if g.secondary == nil || !ok {
+		return
+	}
+	if err := g.secondary.Delete(s); err != nil {
+		g.loads.inc(&g.loads.stats.SecondaryErrors)
+	}
+}
@@ -0,0 +1,60 @@
+package objcache
+
+import (
+	"errors"
+	"testing"
+)
+
+type mapStore struct {
+	m    map[string][]byte
+	fail bool
+}
+
+func (s *mapStore) Get(key string) ([]byte, error) {
+	if s.fail {
+		return nil, errors.New("store down")
+	}
+	if data, ok := s.m[key]; ok {
+		return data, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (s *mapStore) Set(key string, data []byte) error {
+	s.m[key] = data
+	return nil
+}
+
+func (s *mapStore) Delete(key string) error {
+	delete(s.m, key)
+	return nil
+}
+
+func TestSecondary(t *testing.T) {
+	loads := 0
+	store := &mapStore{m: map[string][]byte{"shared": []byte("from l2")}}
+	g := NewGroup("secondary-group", 0, func(ctx Context, key Key) (Value, error) {
+		loads++
+		return "loaded", nil
+	})
+	t.Cleanup(func() { RemoveGroup("secondary-group") })
+	g.SetSecondary(store, nil)
+	if v, err := g.Get(nil, "shared"); err != nil || string(v.([]byte)) != "from l2" || loads != 0 {
+		t.Fatal("Get from the secondary store:", v, err, loads)
+	}
+	if v, err := g.Get(nil, "k"); err != nil || v != "loaded" || string(store.m["k"]) != "loaded" {
+		t.Fatal("Get by the getter:", v, err, store.m)
+	}
+	g.Set("set", "value")
+	g.Remove("shared")
+	if string(store.m["set"]) != "value" || store.m["shared"] != nil {
+		t.Fatal("store:", store.m)
+	}
+	store.fail = true
+	if v, err := g.Get(nil, "other"); err != nil || v != "loaded" {
+		t.Fatal("Get with the store down:", v, err)
+	}
+	if s := g.LoadStats(); s.SecondaryHits != 1 || s.SecondaryErrors != 1 {
+		t.Fatal("LoadStats:", s)
+	}
+}
@@ -0,0 +1,301 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qiniu/x/hashx"
+)
+
+const (
+	// maxShards is the number of shards of an unbounded cache, and the max
+	// number of shards of a bounded one.
+	maxShards = 16
+
+	// minShardEntries is the min capacity of a shard: a smaller cache keeps
+	// a single shard, and so an exact eviction order.
+	minShardEntries = 64
+)
+
+// shardsOf returns the number of shards of a cache of cacheNum entries, a
+// power of two.
+func shardsOf(cacheNum int) int {
+	if cacheNum <= 0 {
+		return maxShards
+	}
+	n := 1
+	for n < maxShards && 2*n*minShardEntries <= cacheNum {
+		n *= 2
+	}
+	return n
+}
+
+// budget is the byte budget of a cache, shared by its shards.
+type budget struct {
+	nbytes int64   // atomic
+	max    int64   // atomic, 0 if none
+	shared *Budget // of several groups, see Group.SetBudget
+}
+
+func (b *budget) add(n int64) {
+	atomic.AddInt64(&b.nbytes, n)
+	if b.shared != nil {
+		b.shared.add(n)
+	}
+}
+
+func (b *budget) over() bool {
+	max := atomic.LoadInt64(&b.max)
+	return max > 0 && atomic.LoadInt64(&b.nbytes) > max
+}
+
+// -----------------------------------------------------------------------------
+
+// cache spreads the entries of a group over shards by the hash of their key,
+// so that concurrent accesses to different keys mostly take different locks.
+// The eviction order, and so the capacity, are per shard; the byte budget is
+// shared.
+type cache struct {
+	shards    []shard
+	budget    *budget
+	evictNext uint32 // atomic, the shard evicted from next for a Budget
+	epoch     uint64 // atomic, see Group.InvalidateAll
+
+	mu         sync.RWMutex
+	maxEntries int
+	negTTL     time.Duration // of cached errors, see Group.SetNegativeTTL
+}
+
+// init initializes a cache of cacheNum entries, telling the time by now. Its
+// number of shards is given by shardsOf, unless shards is positive: it is
+// then rounded up to a power of two.
+func (c *cache) init(cacheNum, shards int, now func() time.Time, onEvicted func(key Key, value Value, reason EvictReason)) {
+	n := shardsOf(cacheNum)
+	if shards > 0 {
+		for n = 1; n < shards; n *= 2 {
+		}
+	}
+	c.shards = make([]shard, n)
+	c.budget = new(budget)
+	c.maxEntries = cacheNum
+	per := c.perShard(cacheNum)
+	for i := range c.shards {
+		c.shards[i].init(per, c.budget, now, onEvicted)
+		c.shards[i].epoch = &c.epoch
+	}
+}
+
+// perShard returns the capacity of a shard of a cache of n entries.
+func (c *cache) perShard(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return (n + len(c.shards) - 1) / len(c.shards)
+}
+
+func (c *cache) indexOf(key Key) int {
+	if len(c.shards) == 1 {
+		return 0
+	}
+	return int(hashKey(key) & uint32(len(c.shards)-1))
+}
+
+func (c *cache) shardOf(key Key) *shard {
+	return &c.shards[c.indexOf(key)]
+}
+
+func hashKey(key Key) uint32 {
+	return uint32(HashKey(key))
+}
+
+// HashKey returns a 64-bit hash of key whose bits are all mixed, e.g. for
+// the policies indexing keys by hash (see package tinylfu). String and
+// integer keys are hashed by hashx, and the others by their fmt.Sprint form.
+func HashKey(key Key) uint64 {
+	switch k := key.(type) {
+	case string:
+		return hashx.Mix64(hashx.FNV1a(0).Sum64String(k))
+	case int:
+		return hashx.Mix64(uint64(k))
+	case int64:
+		return hashx.Mix64(uint64(k))
+	case uint64:
+		return hashx.Mix64(k)
+	case int32:
+		return hashx.Mix64(uint64(k))
+	case uint32:
+		return hashx.Mix64(uint64(k))
+	}
+	return hashx.Mix64(hashx.FNV1a(0).Sum64String(fmt.Sprint(key)))
+}
+
+// prune evicts entries until the byte budget is met: those of the shards
+// other than the i-th one first, as its most recently used entry was just
+// added, and then the others of the i-th shard. The shared budget, if any,
+// is then met by evicting entries of the groups sharing it.
+func (c *cache) prune(i int) {
+	for j := 1; j <= len(c.shards) && c.budget.over(); j++ {
+		c.shards[(i+j)%len(c.shards)].prune()
+	}
+	if b := c.budget.shared; b != nil && b.over() {
+		b.prune(c, i)
+	}
+}
+
+func (c *cache) add(key Key, value Value) {
+	i := c.indexOf(key)
+	c.shards[i].add(key, value)
+	c.prune(i)
+}
+
+func (c *cache) addTTL(key Key, value Value, ttl time.Duration) {
+	i := c.indexOf(key)
+	c.shards[i].addTTL(key, value, ttl)
+	c.prune(i)
+}
+
+func (c *cache) remove(key Key) bool {
+	return c.shardOf(key).remove(key)
+}
+
+func (c *cache) get(key Key) (Value, bool) {
+	return c.shardOf(key).get(key)
+}
+
+func (c *cache) peek(key Key) (Value, bool) {
+	return c.shardOf(key).peek(key)
+}
+
+func (c *cache) quietGet(key Key) (Value, bool) {
+	return c.shardOf(key).quietGet(key)
+}
+
+func (c *cache) withValue(key Key, fn func(value Value)) bool {
+	return c.shardOf(key).withValue(key, fn)
+}
+
+func (c *cache) dueForRefresh(key Key) bool {
+	return c.shardOf(key).dueForRefresh(key)
+}
+
+func (c *cache) pin(key Key) bool {
+	return c.shardOf(key).pin(key)
+}
+
+func (c *cache) unpin(key Key) bool {
+	i := c.indexOf(key)
+	ok := c.shards[i].unpin(key)
+	c.prune(i)
+	return ok
+}
+
+func (c *cache) clear() (n int) {
+	for i := range c.shards {
+		n += c.shards[i].clear()
+	}
+	return
+}
+
+func (c *cache) stats() (s CacheStats) {
+	for i := range c.shards {
+		t := c.shards[i].stats()
+		s.Items += t.Items
+		s.Gets += t.Gets
+		s.Hits += t.Hits
+		s.Evictions += t.Evictions
+		s.Expirations += t.Expirations
+		s.BytesEvicted += t.BytesEvicted
+	}
+	s.Bytes = atomic.LoadInt64(&c.budget.nbytes)
+	return
+}
+
+// entries returns the entries of the shards, one shard after the other.
+func (c *cache) entries() (ents []cacheEntry, ok bool) {
+	ok = true
+	for i := range c.shards {
+		e, ranged := c.shards[i].entries()
+		ents = append(ents, e...)
+		ok = ok && ranged
+	}
+	return
+}
+
+func (c *cache) capacity() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.maxEntries
+}
+
+// setCapacity changes the max number of entries, evicting the entries
+// beyond it.
+func (c *cache) setCapacity(n int) {
+	c.mu.Lock()
+	c.maxEntries = n
+	c.mu.Unlock()
+	per := c.perShard(n)
+	for i := range c.shards {
+		c.shards[i].setCapacity(per)
+	}
+}
+
+func (c *cache) getMaxBytes() int64 {
+	return atomic.LoadInt64(&c.budget.max)
+}
+
+func (c *cache) setMaxBytes(n int64) {
+	atomic.StoreInt64(&c.budget.max, n)
+	for i := range c.shards {
+		c.shards[i].prune()
+	}
+}
+
+func (c *cache) setPolicy(newPolicy NewPolicyFunc) {
+	for i := range c.shards {
+		c.shards[i].setPolicy(newPolicy)
+	}
+}
+
+func (c *cache) setTTL(ttl time.Duration) {
+	for i := range c.shards {
+		c.shards[i].setTTL(ttl)
+	}
+}
+
+func (c *cache) setRefresh(d time.Duration) {
+	for i := range c.shards {
+		c.shards[i].setRefresh(d)
+	}
+}
+
+func (c *cache) negativeTTL() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.negTTL
+}
+
+func (c *cache) setNegativeTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.negTTL = ttl
+	c.mu.Unlock()
+}
+
+// -----------------------------------------------------------------------------
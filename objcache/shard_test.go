@@ -0,0 +1,63 @@
+package objcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestShardsOf(t *testing.T) {
+	for _, c := range []struct{ cacheNum, shards int }{
+		{0, 16}, {1, 1}, {127, 1}, {128, 2}, {255, 2}, {256, 4}, {1024, 16}, {1 << 20, 16},
+	} {
+		if n := shardsOf(c.cacheNum); n != c.shards {
+			t.Fatal("shardsOf:", c.cacheNum, n)
+		}
+	}
+}
+
+func TestShardedCache(t *testing.T) {
+	g := NewGroup("sharded-group", 1024, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("sharded-group") })
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := strconv.Itoa(i*200 + j)
+				if v, err := g.Get(nil, key); err != nil || v != key {
+					t.Error("Get:", key, v, err)
+				}
+				g.Get(nil, key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	s := g.CacheStats()
+	if s.Gets != 3200 || s.Hits != 1600 || s.Items+s.Evictions != 1600 || s.Items > 1024 {
+		t.Fatal("CacheStats:", s)
+	}
+	if g.Capacity() != 1024 {
+		t.Fatal("Capacity:", g.Capacity())
+	}
+
+	// the byte budget is shared by the shards
+	g.SetCapacity(0)
+	g.Purge()
+	g.SetMaxBytes(100)
+	for i := 0; i < 100; i++ {
+		g.Set(i, blob(10))
+	}
+	if s := g.CacheStats(); s.Bytes != 100 || s.Items != 10 {
+		t.Fatal("byte budget:", s)
+	}
+	if _, ok := g.TryGet(99); !ok {
+		t.Fatal("the last value added was evicted")
+	}
+	if g.Purge() != 10 {
+		t.Fatal("Purge")
+	}
+}
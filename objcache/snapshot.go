@@ -0,0 +1,199 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// ErrBadSnapshot is returned by LoadFrom when its input is not a valid
+// snapshot.
+var ErrBadSnapshot = errors.New("objcache: bad snapshot")
+
+// ErrNoRange is returned by SaveTo when the policy of the group can't
+// enumerate its entries, see Ranger.
+var ErrNoRange = errors.New("objcache: the policy can't enumerate its entries")
+
+const (
+	snapshotMagic    = "OCS1"
+	maxSnapshotBytes = 1 << 30 // of a key or a value
+)
+
+// A DecodeFunc decodes a value of a snapshot, see Group.LoadFrom.
+type DecodeFunc = func(key string, data []byte) (Value, error)
+
+// SaveTo writes a snapshot of the main cache of the group to w, e.g. before
+// a restart, and returns the number of entries written. Only the entries of
+// string keys whose value can be encoded by EncodeValue are saved, with
+// their expiration time. Cached errors are not.
+//
+// A snapshot is the magic "OCS1", a record for each entry from the least
+// recently used one of each shard, an empty key, and the CRC32 (IEEE) of all
+// the preceding bytes. A record is
+//
+//	uvarint(len(key)) key varint(expiration in Unix ns, 0 for none) uvarint(len(data)) data
+//
+// with all integers in the encoding/binary varint format.
+func (g *Group) SaveTo(w io.Writer) (n int, err error) {
+	ents, ok := g.mainCache.entries()
+	if !ok {
+		return 0, ErrNoRange
+	}
+
+	h := crc32.NewIEEE()
+	b := bufio.NewWriter(io.MultiWriter(w, h))
+	b.WriteString(snapshotMagic)
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(x uint64) {
+		b.Write(buf[:binary.PutUvarint(buf[:], x)])
+	}
+	for i := len(ents) - 1; i >= 0; i-- {
+		e := &ents[i]
+		key, ok := e.key.(string)
+		if !ok || key == "" {
+			continue
+		}
+		data, err := EncodeValue(e.value)
+		if err != nil {
+			continue
+		}
+		var expire int64
+		if !e.expire.IsZero() {
+			expire = e.expire.UnixNano()
+		}
+		putUvarint(uint64(len(key)))
+		b.WriteString(key)
+		b.Write(buf[:binary.PutVarint(buf[:], expire)])
+		putUvarint(uint64(len(data)))
+		b.Write(data)
+		n++
+	}
+	putUvarint(0)
+	if err = b.Flush(); err != nil {
+		return
+	}
+	binary.LittleEndian.PutUint32(buf[:4], h.Sum32())
+	_, err = w.Write(buf[:4])
+	return
+}
+
+// LoadFrom adds the entries of a snapshot written by SaveTo to the main
+// cache of the group, e.g. to warm it up after a restart, and returns their
+// number. The values are decoded by decode (kept as []byte if it is nil);
+// the entries it fails to decode and the entries that have expired are
+// skipped. Nothing is added if the snapshot is invalid.
+func (g *Group) LoadFrom(r io.Reader, decode DecodeFunc) (n int, err error) {
+	type entry struct {
+		key    string
+		data   []byte
+		expire int64
+	}
+	b := &hashReader{r: bufio.NewReader(r), h: crc32.NewIEEE()}
+	magic := make([]byte, len(snapshotMagic))
+	if _, err = io.ReadFull(b, magic); err != nil || string(magic) != snapshotMagic {
+		return 0, ErrBadSnapshot
+	}
+	readBytes := func() ([]byte, error) {
+		size, err := binary.ReadUvarint(b)
+		if err != nil {
+			return nil, err
+		}
+		if size > maxSnapshotBytes {
+			return nil, ErrBadSnapshot
+		}
+		// the buffer grows with what is read, not with what size claims
+		var data bytes.Buffer
+		if _, err = io.CopyN(&data, b, int64(size)); err != nil {
+			return nil, err
+		}
+		return data.Bytes(), nil
+	}
+	var ents []entry
+	for {
+		key, err := readBytes()
+		if err != nil {
+			return 0, ErrBadSnapshot
+		}
+		if len(key) == 0 {
+			break
+		}
+		expire, err := binary.ReadVarint(b)
+		if err != nil {
+			return 0, ErrBadSnapshot
+		}
+		data, err := readBytes()
+		if err != nil {
+			return 0, ErrBadSnapshot
+		}
+		ents = append(ents, entry{string(key), data, expire})
+	}
+	sum := b.h.Sum32()
+	var crc [4]byte
+	if _, err = io.ReadFull(b, crc[:]); err != nil || binary.LittleEndian.Uint32(crc[:]) != sum {
+		return 0, ErrBadSnapshot
+	}
+
+	c := &g.mainCache
+	now := g.clock.Now()
+	for _, e := range ents {
+		var ttl time.Duration
+		if e.expire != 0 {
+			if ttl = time.Unix(0, e.expire).Sub(now); ttl <= 0 {
+				continue
+			}
+		}
+		var val Value = e.data
+		if decode != nil {
+			if val, err = decode(e.key, e.data); err != nil {
+				continue
+			}
+		}
+		if ttl > 0 {
+			c.addTTL(e.key, val, ttl)
+		} else {
+			c.add(e.key, val)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// hashReader hashes the bytes read from r.
+type hashReader struct {
+	r *bufio.Reader
+	h hash.Hash32
+}
+
+func (p *hashReader) Read(b []byte) (n int, err error) {
+	n, err = p.r.Read(b)
+	p.h.Write(b[:n])
+	return
+}
+
+func (p *hashReader) ReadByte() (c byte, err error) {
+	if c, err = p.r.ReadByte(); err == nil {
+		p.h.Write([]byte{c})
+	}
+	return
+}
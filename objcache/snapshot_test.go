@@ -0,0 +1,79 @@
+package objcache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	g := NewGroup("snapshot-group", 100, func(ctx Context, key Key) (Value, error) {
+		return "v" + key.(string), nil
+	})
+	t.Cleanup(func() { RemoveGroup("snapshot-group") })
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g.SetClock(clock)
+	for _, key := range []string{"a", "b", "c"} {
+		g.Get(nil, key)
+	}
+	g.SetWithTTL("short", "s", time.Second)
+	g.SetWithTTL("long", "l", time.Hour)
+	g.Set(42, "int key")
+	g.Set("struct", struct{}{})
+	g.Get(nil, "a") // the most recently used
+
+	var buf bytes.Buffer
+	n, err := g.SaveTo(&buf)
+	if err != nil || n != 5 {
+		t.Fatal("SaveTo:", n, err)
+	}
+	snapshot := buf.Bytes()
+
+	g2 := NewGroup("snapshot-group-2", 2, nil)
+	t.Cleanup(func() { RemoveGroup("snapshot-group-2") })
+	clock.now = clock.now.Add(time.Minute)
+	g2.SetClock(clock)
+	n, err = g2.LoadFrom(bytes.NewReader(snapshot), func(key string, data []byte) (Value, error) {
+		return strings.ToUpper(string(data)), nil
+	})
+	if err != nil || n != 4 {
+		t.Fatal("LoadFrom:", n, err)
+	}
+	// capacity 2: the most recently used entries won
+	if v, ok := g2.TryGet("a"); !ok || v != "VA" {
+		t.Fatal("TryGet(a):", v, ok)
+	}
+	if v, ok := g2.TryGet("long"); !ok || v != "L" {
+		t.Fatal("TryGet(long):", v, ok)
+	}
+	if g2.CacheStats().Items != 2 {
+		t.Fatal("items:", g2.CacheStats().Items)
+	}
+	clock.now = clock.now.Add(time.Hour)
+	if _, ok := g2.TryGet("long"); ok {
+		t.Fatal("the TTL of an entry was not restored")
+	}
+
+	snapshot[len(snapshot)-5] ^= 1
+	if _, err = g2.LoadFrom(bytes.NewReader(snapshot), nil); err != ErrBadSnapshot {
+		t.Fatal("LoadFrom of a corrupted snapshot:", err)
+	}
+	if _, err = g2.LoadFrom(strings.NewReader("OCS1"), nil); err != ErrBadSnapshot {
+		t.Fatal("LoadFrom of a truncated snapshot:", err)
+	}
+	huge := make([]byte, 4+binary.MaxVarintLen64)
+	copy(huge, "OCS1")
+	huge = huge[:4+binary.PutUvarint(huge[4:], maxSnapshotBytes)]
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err = g2.LoadFrom(bytes.NewReader(huge), nil); err != ErrBadSnapshot {
+		t.Fatal("LoadFrom of a lying size:", err)
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Fatal("LoadFrom of a lying size allocated", n)
+	}
+}
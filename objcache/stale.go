@@ -0,0 +1,78 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// SetMaxStale makes the group serve the value of an expired entry, up to d
+// after its expiration, when the getter fails to load a fresh one: Get then
+// returns the stale value instead of the error, e.g. while the backend is
+// briefly down. This keeps the expired entries in the cache for d, though
+// they are not hits anymore. Zero, the default, means that expired entries
+// are never served.
+func (g *Group) SetMaxStale(d time.Duration) {
+	g.mainCache.setMaxStale(d)
+}
+
+// serveStale returns the stale value of key, if it may be served after a
+// failed load.
+func (g *Group) serveStale(key Key) (Value, bool) {
+	val, ok := g.mainCache.stale(key)
+	if ok {
+		g.loads.inc(&g.loads.stats.StaleHits)
+	}
+	return val, ok
+}
+
+func (c *cache) setMaxStale(d time.Duration) {
+	for i := range c.shards {
+		c.shards[i].setMaxStale(d)
+	}
+}
+
+func (c *cache) stale(key Key) (Value, bool) {
+	return c.shardOf(key).stale(key)
+}
+
+func (c *shard) setMaxStale(d time.Duration) {
+	c.mu.Lock()
+	c.maxStale = d
+	c.mu.Unlock()
+}
+
+// stale returns the value of key if it has expired less than maxStale ago.
+// Cached errors are not served.
+func (c *shard) stale(key Key) (value Value, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	exp, ok := c.expires[key]
+	if !ok || c.oldLocked(key) {
+		return nil, false
+	}
+	if now := c.now(); now.Before(exp) || !now.Before(exp.Add(c.maxStale)) {
+		return nil, false
+	}
+	if value, ok = c.lookupLocked(key); !ok {
+		return
+	}
+	if _, neg := value.(*negEntry); neg {
+		return nil, false
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package objcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaxStale(t *testing.T) {
+	var down bool
+	loads := 0
+	g := NewGroup("stale-group", 0, func(ctx Context, key Key) (Value, error) {
+		if down {
+			return nil, errors.New("backend down")
+		}
+		loads++
+		return loads, nil
+	})
+	t.Cleanup(func() { RemoveGroup("stale-group") })
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g.SetClock(clock)
+	g.SetTTL(time.Minute)
+	g.SetMaxStale(time.Hour)
+
+	if v, err := g.Get(nil, "k"); err != nil || v != 1 {
+		t.Fatal("Get:", v, err)
+	}
+	clock.now = clock.now.Add(2 * time.Minute)
+	down = true
+	if v, err := g.Get(nil, "k"); err != nil || v != 1 {
+		t.Fatal("Get of a stale value:", v, err)
+	}
+	if _, ok := g.TryGet("k"); ok {
+		t.Fatal("stale value served as a hit")
+	}
+	if g.ReapExpired() != 0 {
+		t.Fatal("stale entry reaped")
+	}
+	down = false
+	if v, err := g.Get(nil, "k"); err != nil || v != 2 {
+		t.Fatal("Get after recovery:", v, err)
+	}
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	down = true
+	if _, err := g.Get(nil, "k"); err == nil {
+		t.Fatal("value served beyond the max staleness")
+	}
+	if s := g.LoadStats(); s.StaleHits != 1 {
+		t.Fatal("LoadStats:", s)
+	}
+}
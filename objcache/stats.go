@@ -0,0 +1,141 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+
+// ResetStats zeroes the counters of the stats of the group: those of both
+// tiers of its cache and of its loads, but not the gauges such as Items,
+// Bytes or Active. A StatsDelta reports the counters since the reset for the
+// interval of the reset.
+func (g *Group) ResetStats() {
+	g.loads.resetStats()
+	g.mainCache.resetStats()
+	g.hotCache.resetStats()
+	g.flights.resetStats()
+}
+
+func (c *cache) resetStats() {
+	for i := range c.shards {
+		c.shards[i].resetStats()
+	}
+}
+
+func (c *shard) resetStats() {
+	c.mu.Lock()
+	c.nget, c.nhit, c.nevict, c.nexpire, c.nbevict = 0, 0, 0, 0, 0
+	c.mu.Unlock()
+}
+
+func (l *loadLimiter) resetStats() {
+	l.mu.Lock()
+	l.stats = LoadStats{}
+	l.resets++
+	l.mu.Unlock()
+}
+
+func (fg *flightGroup) resetStats() {
+	fg.mu.Lock()
+	fg.deduped = 0
+	fg.mu.Unlock()
+}
+
+// Sub returns the counters of s since prev, an earlier snapshot, and the
+// gauges Items and Bytes of s.
+func (s CacheStats) Sub(prev CacheStats) CacheStats {
+	s.Gets -= prev.Gets
+	s.Hits -= prev.Hits
+	s.Evictions -= prev.Evictions
+	s.Expirations -= prev.Expirations
+	s.BytesEvicted -= prev.BytesEvicted
+	return s
+}
+
+// Sub returns the counters of s since prev, an earlier snapshot, and the
+// gauges Active, QueueDepth and MaxWait of s.
+func (s LoadStats) Sub(prev LoadStats) LoadStats {
+	s.Queued -= prev.Queued
+	s.Abandoned -= prev.Abandoned
+	s.QueueWait -= prev.QueueWait
+	s.Loads -= prev.Loads
+	s.Deduped -= prev.Deduped
+	s.LocalLoads -= prev.LocalLoads
+	s.LoadErrors -= prev.LoadErrors
+	s.PeerLoads -= prev.PeerLoads
+	s.PeerErrors -= prev.PeerErrors
+	s.Refreshes -= prev.Refreshes
+	s.Timeouts -= prev.Timeouts
+	s.StaleHits -= prev.StaleHits
+	s.Throttled -= prev.Throttled
+	s.SecondaryHits -= prev.SecondaryHits
+	s.SecondaryErrors -= prev.SecondaryErrors
+	return s
+}
+
+// Sub returns the counters of v since prev, an earlier snapshot of the same
+// group, and the gauges of v. If the stats of the group were reset in
+// between (see Group.ResetStats), the counters are those since the reset.
+func (v *GroupVar) Sub(prev *GroupVar) *GroupVar {
+	if v.resets != prev.resets {
+		ret := *v
+		return &ret
+	}
+	return &GroupVar{
+		Gets:      v.Gets - prev.Gets,
+		CacheHits: v.CacheHits - prev.CacheHits,
+		Items:     v.Items,
+		Evictions: v.Evictions - prev.Evictions,
+		MainCache: v.MainCache.Sub(prev.MainCache),
+		HotCache:  v.HotCache.Sub(prev.HotCache),
+		Loads:     v.Loads.Sub(prev.Loads),
+		resets:    v.resets,
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// StatsDelta takes snapshots of the stats of a group for periodic reporters,
+// e.g. per-minute dashboards, which want the counters of each interval
+// rather than since the start.
+type StatsDelta struct {
+	g *Group
+
+	mu   sync.Mutex
+	last *GroupVar
+}
+
+// NewStatsDelta creates a StatsDelta of g, taking its first snapshot.
+func NewStatsDelta(g *Group) *StatsDelta {
+	return &StatsDelta{g: g, last: g.Var()}
+}
+
+// SnapshotDelta returns the stats of the group since the previous snapshot,
+// see GroupVar.Sub, and takes a new one.
+func (d *StatsDelta) SnapshotDelta() *GroupVar {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cur := d.g.Var()
+	ret := cur.Sub(d.last)
+	d.last = cur
+	return ret
+}
+
+// -----------------------------------------------------------------------------
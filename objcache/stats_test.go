@@ -0,0 +1,36 @@
+package objcache
+
+import (
+	"testing"
+)
+
+func TestStatsDelta(t *testing.T) {
+	g := NewGroup("stats-delta-group", 0, func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("stats-delta-group") })
+	g.Get(nil, "a")
+	d := NewStatsDelta(g)
+	g.Get(nil, "a")
+	g.Get(nil, "b")
+	v := d.SnapshotDelta()
+	if v.Gets != 2 || v.CacheHits != 1 || v.Items != 2 || v.Loads.Loads != 1 || v.MainCache.Hits != 1 {
+		t.Fatal("SnapshotDelta:", v)
+	}
+	if v = d.SnapshotDelta(); v.Gets != 0 || v.Items != 2 {
+		t.Fatal("SnapshotDelta of an idle interval:", v)
+	}
+
+	g.Get(nil, "c")
+	g.ResetStats()
+	if s := g.CacheStats(); s.Gets != 0 || s.Hits != 0 || s.Items != 3 {
+		t.Fatal("CacheStats after ResetStats:", s)
+	}
+	if s := g.LoadStats(); s.Loads != 0 || s.LocalLoads != 0 {
+		t.Fatal("LoadStats after ResetStats:", s)
+	}
+	g.Get(nil, "a")
+	if v = d.SnapshotDelta(); v.Gets != 1 || v.CacheHits != 1 || v.Loads.Loads != 0 {
+		t.Fatal("SnapshotDelta across a reset:", v)
+	}
+}
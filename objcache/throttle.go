@@ -0,0 +1,109 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrThrottled is returned by Get when the getter of the group loaded the
+// key successfully less than the min reload interval ago, see
+// SetMinReloadInterval.
+var ErrThrottled = errors.New("objcache: load throttled")
+
+// SetMinReloadInterval makes the group call its getter for a key at most once
+// every d, so that a key that keeps failing, or that is evicted and missed
+// over and over, can't stampede the backend. Within d of a load, a miss of
+// the key returns the error of that load, or ErrThrottled if it succeeded
+// (the value may have been removed since). Zero, the default, means no limit.
+func (g *Group) SetMinReloadInterval(d time.Duration) {
+	g.throttle.setInterval(d)
+}
+
+// lastLoad is the start and the error of the last load of a key.
+type lastLoad struct {
+	at  time.Time
+	err error
+}
+
+// throttle enforces the min reload interval of the keys.
+type throttle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	loads    map[Key]lastLoad // of the keys loaded less than interval ago
+	sweepAt  int              // size of loads that triggers a sweep
+}
+
+func (t *throttle) setInterval(d time.Duration) {
+	t.mu.Lock()
+	t.interval = d
+	if d <= 0 {
+		t.loads = nil
+	}
+	t.mu.Unlock()
+}
+
+// check returns the error of the load of key if the last one is too recent,
+// and otherwise records that it starts at now.
+func (t *throttle) check(key Key, now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.interval <= 0 {
+		return nil
+	}
+	if l, ok := t.loads[key]; ok && now.Sub(l.at) < t.interval {
+		if l.err != nil {
+			return l.err
+		}
+		return ErrThrottled
+	}
+	if t.loads == nil {
+		t.loads = make(map[Key]lastLoad)
+	}
+	t.loads[key] = lastLoad{at: now}
+	if len(t.loads) >= t.sweepAt {
+		for k, l := range t.loads {
+			if now.Sub(l.at) >= t.interval {
+				delete(t.loads, k)
+			}
+		}
+		t.sweepAt = 2*len(t.loads) + 64
+	}
+	return nil
+}
+
+// done records the error of the load of key started at at. A load canceled
+// by its context isn't held against the next one.
+func (t *throttle) done(key Key, at time.Time, err error) {
+	if err == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l, ok := t.loads[key]
+	if !ok || !l.at.Equal(at) {
+		return
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		delete(t.loads, key)
+	} else {
+		t.loads[key] = lastLoad{at, err}
+	}
+}
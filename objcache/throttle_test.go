@@ -0,0 +1,44 @@
+package objcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMinReloadInterval(t *testing.T) {
+	errDown := errors.New("backend down")
+	loads := 0
+	g := NewGroup("throttle-group", 0, func(ctx Context, key Key) (Value, error) {
+		loads++
+		if key == "bad" {
+			return nil, errDown
+		}
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("throttle-group") })
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g.SetClock(clock)
+	g.SetMinReloadInterval(time.Second)
+
+	for i := 0; i < 3; i++ {
+		if _, err := g.Get(nil, "bad"); err != errDown {
+			t.Fatal("Get:", err)
+		}
+	}
+	if loads != 1 {
+		t.Fatal("loads of a failing key:", loads)
+	}
+	g.Get(nil, "good")
+	g.Remove("good")
+	if _, err := g.Get(nil, "good"); err != ErrThrottled {
+		t.Fatal("Get of a removed key:", err)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if v, err := g.Get(nil, "good"); err != nil || v != "good" || loads != 3 {
+		t.Fatal("Get after the interval:", v, err, loads)
+	}
+	if s := g.LoadStats(); s.Throttled != 3 || s.LoadErrors != 1 {
+		t.Fatal("LoadStats:", s)
+	}
+}
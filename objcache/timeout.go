@@ -0,0 +1,65 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTimeout is returned by GetWithTimeout when the value of the key isn't
+// loaded in time.
+var ErrTimeout = errors.New("objcache: load timed out")
+
+// GetWithTimeout is Get that waits at most d for the value of key to be
+// loaded, so that a slow getter can't hold the caller hostage: it then
+// returns ErrTimeout, and the load goes on in the background, caching the
+// value when it completes. If ctx is a context.Context its values are passed
+// to the getter, but not its cancellation nor its deadline, which only end
+// the wait. To cancel the load itself, pass a context with a deadline to Get
+// instead, and have the getter honor it.
+func (g *Group) GetWithTimeout(ctx Context, key Key, d time.Duration) (Value, error) {
+	if val, hit, err := g.getCached(ctx, key); hit {
+		return val, err
+	}
+	loadCtx := ctx
+	var done <-chan struct{}
+	if c, ok := ctx.(context.Context); ok {
+		loadCtx, done = detachedContext{c}, c.Done()
+	}
+	type result struct {
+		val Value
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		val, err := g.fill(loadCtx, key, nil)
+		ch <- result{val, err}
+	}()
+	timer := g.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		return r.val, r.err
+	case <-timer.C():
+		g.loads.inc(&g.loads.stats.Timeouts)
+		return nil, ErrTimeout
+	case <-done:
+		return nil, ctx.(context.Context).Err()
+	}
+}
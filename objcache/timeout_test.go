@@ -0,0 +1,49 @@
+package objcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	g := NewGroup("timeout-group", 0, func(ctx Context, key Key) (Value, error) {
+		if ctx.(context.Context).Err() != nil {
+			t.Error("the load was canceled")
+		}
+		if key == "slow" {
+			<-release
+		}
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("timeout-group") })
+	if v, err := g.GetWithTimeout(context.Background(), "fast", time.Second); err != nil || v != "fast" {
+		t.Fatal("GetWithTimeout:", v, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := g.GetWithTimeout(ctx, "slow", time.Millisecond); err != ErrTimeout {
+		t.Fatal("GetWithTimeout of a slow key:", err)
+	}
+	cancel()
+	if s := g.LoadStats(); s.Timeouts != 1 {
+		t.Fatal("LoadStats:", s)
+	}
+	close(release)
+	for i := 0; ; i++ {
+		if _, ok := g.TryGet("slow"); ok {
+			break
+		}
+		if i == 1000 {
+			t.Fatal("the background load wasn't cached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if v, err := g.GetWithTimeout(ctx, "slow", time.Second); err != nil || v != "slow" {
+		t.Fatal("GetWithTimeout of a cached key:", v, err)
+	}
+}
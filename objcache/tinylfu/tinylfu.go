@@ -0,0 +1,224 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package tinylfu implements an LRU eviction policy for objcache guarded by
+// a TinyLFU admission filter, which protects the cache from scans.
+//
+// The policy keeps an approximate access frequency of all the keys looked up,
+// cached or not, in a count-min sketch of 4-bit counters that are halved
+// periodically, so that the history fades. When the cache is full, a new key
+// is only admitted if it was accessed more often than the least recently used
+// entry, which it then evicts: otherwise the new entry is evicted right away.
+// Keys seen once, such as those of a bulk scan, thus don't flush the entries
+// that are used repeatedly.
+package tinylfu
+
+import (
+	"github.com/qiniu/x/objcache"
+	"github.com/qiniu/x/objcache/lru"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	depth      = 4  // rows of the sketch
+	maxCount   = 15 // of a 4-bit counter
+	widthRatio = 8  // counters per row per entry of the cache
+	resetRatio = 10 // accesses per entry of the cache before the counters are halved
+)
+
+// sketch is a count-min sketch of 4-bit counters, two per byte.
+type sketch struct {
+	rows    [depth][]byte
+	mask    uint32
+	adds    int
+	resetAt int
+}
+
+// widthOf returns the number of counters per row of the sketch of a cache
+// of n entries, a power of two.
+func widthOf(n int) int {
+	width := 16
+	for width < widthRatio*n {
+		width *= 2
+	}
+	return width
+}
+
+func newSketch(n int) *sketch {
+	width := widthOf(n)
+	s := &sketch{mask: uint32(width - 1), resetAt: resetRatio * width / widthRatio}
+	for i := range s.rows {
+		s.rows[i] = make([]byte, width/2)
+	}
+	return s
+}
+
+func (s *sketch) indexOf(h uint64, i int) uint32 {
+	h1, h2 := uint32(h), uint32(h>>32)
+	return (h1 + uint32(i)*h2) & s.mask
+}
+
+func (s *sketch) get(row []byte, j uint32) byte {
+	return row[j/2] >> (4 * (j & 1)) & 0xf
+}
+
+// add counts an access of h. Only the smallest counters of h are
+// incremented (a conservative update), which keeps the keys sharing a
+// counter from inflating each other's estimates.
+func (s *sketch) add(h uint64) {
+	n := s.estimate(h)
+	for i := range s.rows {
+		row, j := s.rows[i], s.indexOf(h, i)
+		if c := s.get(row, j); c == n && c < maxCount {
+			row[j/2] += 1 << (4 * (j & 1))
+		}
+	}
+	if s.adds++; s.adds >= s.resetAt {
+		s.reset()
+	}
+}
+
+func (s *sketch) estimate(h uint64) byte {
+	n := byte(maxCount)
+	for i := range s.rows {
+		if c := s.get(s.rows[i], s.indexOf(h, i)); c < n {
+			n = c
+		}
+	}
+	return n
+}
+
+// reset halves all the counters.
+func (s *sketch) reset() {
+	for _, row := range s.rows {
+		for k, b := range row {
+			row[k] = b >> 1 & 0x77
+		}
+	}
+	s.adds /= 2
+}
+
+func hashOf(key objcache.Key) uint64 {
+	return objcache.HashKey(key)
+}
+
+// -----------------------------------------------------------------------------
+
+// Cache is an LRU cache with a TinyLFU admission filter. It is not safe for
+// concurrent use.
+type Cache struct {
+	maxEntries int
+	onEvicted  objcache.OnEvictedFunc
+	lru        *lru.Cache
+	freq       *sketch
+}
+
+// New creates a cache of up to maxEntries entries. Zero means no limit: all
+// the keys are then admitted.
+func New(maxEntries int, onEvicted objcache.OnEvictedFunc) *Cache {
+	c := &Cache{maxEntries: maxEntries, onEvicted: onEvicted, lru: lru.New(0)}
+	c.lru.OnEvicted = onEvicted
+	c.freq = newSketch(maxEntries)
+	return c
+}
+
+// Policy returns an objcache.NewPolicyFunc creating TinyLFU caches, to be
+// passed to Group.SetPolicy.
+func Policy() objcache.NewPolicyFunc {
+	return func(maxEntries int, onEvicted objcache.OnEvictedFunc) objcache.Policy {
+		return New(maxEntries, onEvicted)
+	}
+}
+
+// Add implements objcache.Policy. If the cache is full, a new key less
+// frequently accessed than the least recently used entry is evicted right
+// away, which a group reports as objcache.EvictRejected, without disposing
+// of the value.
+func (c *Cache) Add(key objcache.Key, value objcache.Value) {
+	if _, ok := c.lru.Peek(key); ok || c.maxEntries <= 0 || c.lru.Len() < c.maxEntries {
+		c.lru.Add(key, value)
+		return
+	}
+	victim, _, _ := c.lru.Oldest()
+	if c.freq.estimate(hashOf(key)) <= c.freq.estimate(hashOf(victim)) {
+		if c.onEvicted != nil {
+			c.onEvicted(key, value)
+		}
+		return
+	}
+	c.lru.RemoveOldest()
+	c.lru.Add(key, value)
+}
+
+// Get implements objcache.Policy. The access is recorded even if key isn't
+// cached.
+func (c *Cache) Get(key objcache.Key) (value objcache.Value, ok bool) {
+	c.freq.add(hashOf(key))
+	v, ok := c.lru.Get(key)
+	return v, ok
+}
+
+// Peek implements objcache.Peeker.
+func (c *Cache) Peek(key objcache.Key) (value objcache.Value, ok bool) {
+	v, ok := c.lru.Peek(key)
+	return v, ok
+}
+
+// Remove implements objcache.Policy.
+func (c *Cache) Remove(key objcache.Key) {
+	c.lru.Remove(key)
+}
+
+// RemoveOldest implements objcache.Policy.
+func (c *Cache) RemoveOldest() {
+	c.lru.RemoveOldest()
+}
+
+// Len implements objcache.Policy.
+func (c *Cache) Len() int {
+	return c.lru.Len()
+}
+
+// Clear implements objcache.Policy.
+func (c *Cache) Clear() {
+	c.lru.Clear()
+}
+
+// Range implements objcache.Ranger, from the most recently used entry.
+func (c *Cache) Range(fn func(key objcache.Key, value objcache.Value) bool) {
+	c.lru.Range(func(key lru.Key, value interface{}) bool {
+		return fn(key, value)
+	})
+}
+
+// SetMaxEntries implements objcache.Resizer. The frequencies recorded so
+// far are dropped if the sketch is resized.
+func (c *Cache) SetMaxEntries(n int) {
+	if uint32(widthOf(n)-1) != c.freq.mask {
+		c.freq = newSketch(n)
+	}
+	c.maxEntries = n
+}
+
+var (
+	_ objcache.Policy  = (*Cache)(nil)
+	_ objcache.Ranger  = (*Cache)(nil)
+	_ objcache.Peeker  = (*Cache)(nil)
+	_ objcache.Resizer = (*Cache)(nil)
+)
+
+// -----------------------------------------------------------------------------
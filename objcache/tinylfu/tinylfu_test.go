@@ -0,0 +1,119 @@
+package tinylfu
+
+import (
+	"testing"
+
+	"github.com/qiniu/x/objcache"
+	"github.com/qiniu/x/objcache/cachetest"
+)
+
+func TestScanResistance(t *testing.T) {
+	evicted := 0
+	c := New(100, func(key objcache.Key, value objcache.Value) { evicted++ })
+	get := func(key int) {
+		if _, ok := c.Get(key); !ok {
+			c.Add(key, key)
+		}
+	}
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 100; i++ {
+			get(i)
+		}
+	}
+	for i := 1000; i < 3000; i++ { // a scan
+		get(i)
+	}
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if _, ok := c.Peek(i); ok {
+			kept++
+		}
+	}
+	if kept < 90 || c.Len() != 100 || evicted != 2000 {
+		t.Fatal("the scan flushed the working set:", kept, c.Len(), evicted)
+	}
+
+	// a key used repeatedly gets admitted
+	for i := 0; i < 5; i++ {
+		get(5000)
+	}
+	if _, ok := c.Peek(5000); !ok {
+		t.Fatal("frequent key not admitted")
+	}
+}
+
+func TestSketch(t *testing.T) {
+	s := newSketch(100)
+	h := hashOf("k")
+	for i := 0; i < 20; i++ {
+		s.add(h)
+	}
+	if n := s.estimate(h); n != maxCount {
+		t.Fatal("estimate:", n)
+	}
+	s.reset()
+	if n := s.estimate(h); n != maxCount/2 {
+		t.Fatal("estimate after reset:", n)
+	}
+	if n := s.estimate(hashOf("other")); n != 0 {
+		t.Fatal("estimate of an unseen key:", n)
+	}
+}
+
+func TestGroupPolicy(t *testing.T) {
+	g := objcache.NewGroup("tinylfu-group", 100, func(ctx objcache.Context, key objcache.Key) (objcache.Value, error) {
+		return key, nil
+	})
+	t.Cleanup(func() { objcache.RemoveGroup("tinylfu-group") })
+	g.SetPolicy(Policy())
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 100; i++ {
+			g.Get(nil, i)
+		}
+	}
+	for i := 1000; i < 2000; i++ {
+		g.Get(nil, i)
+	}
+	if s := g.CacheStats(); s.Items != 100 || s.Bytes != 0 {
+		t.Fatal("CacheStats:", s)
+	}
+	if _, ok := g.Peek(50); !ok {
+		t.Fatal("working set flushed")
+	}
+}
+
+type resource struct{ disposed bool }
+
+func (r *resource) Dispose() error {
+	r.disposed = true
+	return nil
+}
+
+func TestRejectedNotDisposed(t *testing.T) {
+	g := objcache.NewGroup("tinylfu-reject-group", 1, func(ctx objcache.Context, key objcache.Key) (objcache.Value, error) {
+		return new(resource), nil
+	})
+	t.Cleanup(func() { objcache.RemoveGroup("tinylfu-reject-group") })
+	g.SetPolicy(Policy())
+	var reasons []objcache.EvictReason
+	g.OnEvict(func(key objcache.Key, value objcache.Value, reason objcache.EvictReason) {
+		reasons = append(reasons, reason)
+	})
+	for i := 0; i < 3; i++ {
+		g.Get(nil, "hot")
+	}
+	v, _ := g.Get(nil, "cold")
+	if v.(*resource).disposed {
+		t.Fatal("rejected value disposed")
+	}
+	if len(reasons) != 1 || reasons[0] != objcache.EvictRejected || reasons[0].String() != "rejected" {
+		t.Fatal("reasons:", reasons)
+	}
+	if s := g.CacheStats(); s.Items != 1 || s.Evictions != 0 {
+		t.Fatal("CacheStats:", s)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	cachetest.TestPolicy(t, Policy())
+}
@@ -0,0 +1,85 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qiniu/x/hashx"
+	"github.com/qiniu/x/tracex"
+)
+
+// Names of the spans of cache fills, see Group.Get and Group.GetMulti.
+const (
+	loadSpanName  = "objcache.load"
+	batchSpanName = "objcache.load_multi"
+)
+
+// startLoadSpan starts the span of a cache fill of key, if ctx is a
+// context.Context. The span has the attributes:
+//
+//   - group: the name of the group;
+//   - key_hash: a hash of the key, rather than the key itself that may be
+//     large or sensitive;
+//   - hit: whether a concurrent fill had cached the key in the meantime;
+//   - source: "peer" or "getter", where the value came from.
+//
+// Its duration is the duration of the fill, and its error the one of the
+// getter. Spans are only recorded once an exporter is set by
+// tracex.SetExporter, which may forward them to e.g. OpenTelemetry.
+func (g *Group) startLoadSpan(ctx Context, key Key) (Context, *tracex.Span) {
+	c, ok := ctx.(context.Context)
+	if !ok || c == nil {
+		return ctx, nil
+	}
+	c, span := tracex.StartSpan(c, loadSpanName,
+		tracex.Attr{Key: "group", Value: g.name},
+		tracex.Attr{Key: "key_hash", Value: keyHash(key)},
+		tracex.Attr{Key: "hit", Value: false})
+	return c, span
+}
+
+// startBatchSpan starts the span of a cache fill of n keys by the batch
+// getter, see startLoadSpan. Its attributes are group and keys, the number
+// of keys.
+func (g *Group) startBatchSpan(ctx Context, n int) (Context, *tracex.Span) {
+	c, ok := ctx.(context.Context)
+	if !ok || c == nil {
+		return ctx, nil
+	}
+	c, span := tracex.StartSpan(c, batchSpanName,
+		tracex.Attr{Key: "group", Value: g.name},
+		tracex.Attr{Key: "keys", Value: n})
+	return c, span
+}
+
+// keyHash returns the FNV-1a hash of key in hex. String keys are hashed in
+// place.
+func keyHash(key Key) string {
+	s, ok := key.(string)
+	if !ok {
+		s = fmt.Sprint(key)
+	}
+	h := hashx.FNV1a(0).Sum64String(s)
+	var b [16]byte
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = "0123456789abcdef"[h&15]
+		h >>= 4
+	}
+	return string(b[:])
+}
@@ -0,0 +1,53 @@
+package objcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qiniu/x/tracex"
+)
+
+func TestLoadSpan(t *testing.T) {
+	ring := tracex.NewRing(10)
+	tracex.SetExporter(ring)
+	defer tracex.SetExporter(nil)
+
+	errFail := errors.New("load failed")
+	g := NewGroup("trace-group", 0, func(ctx Context, key Key) (Value, error) {
+		if c, ok := ctx.(context.Context); ok && tracex.FromContext(c) == nil {
+			t.Error("the getter has no span")
+		}
+		if key == "fail" {
+			return nil, errFail
+		}
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("trace-group") })
+	ctx := context.Background()
+	g.Get(ctx, "a")
+	g.Get(ctx, "a") // hit: no span
+	g.Get(ctx, "fail")
+	g.Get(nil, "b") // no context: no span
+
+	spans := ring.Spans()
+	if len(spans) != 2 {
+		t.Fatal("spans:", spans)
+	}
+	attrs := func(s *tracex.SpanData) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, a := range s.Attrs {
+			m[a.Key] = a.Value
+		}
+		return m
+	}
+	for i, s := range spans {
+		m := attrs(s)
+		if s.Name != loadSpanName || m["group"] != "trace-group" || m["hit"] != false || m["source"] != "getter" {
+			t.Fatal("span:", s)
+		}
+		if (s.Err == errFail) != (m["key_hash"] == keyHash("fail")) {
+			t.Fatal("span error:", i, s)
+		}
+	}
+}
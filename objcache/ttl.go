@@ -0,0 +1,101 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// A TTLer is a value with its own time to live. When it is added to the
+// cache of a group, a positive TTL overrides the default TTL of the group
+// (see Group.SetTTL), and a negative one means no expiration.
+type TTLer interface {
+	TTL() time.Duration
+}
+
+// SetTTL sets the default time to live of the entries added to the cache
+// of the group from now on. Zero means no expiration. Gets treat an expired
+// entry as a miss: it is removed, so its onEvicted func is called, and
+// loaded again.
+func (g *Group) SetTTL(ttl time.Duration) {
+	g.mainCache.setTTL(ttl)
+	g.hotCache.setTTL(ttl)
+}
+
+// SetWithTTL adds value to the cache under key, expiring after ttl (never
+// if it is not positive), whatever the default TTL of the group.
+func (g *Group) SetWithTTL(key Key, value Value, ttl time.Duration) {
+	g.deps.forget(key)
+	g.hotCache.remove(key)
+	g.mainCache.addTTL(key, value, ttl)
+	g.tag(key, value)
+	g.setSecondary(key, value)
+}
+
+// -----------------------------------------------------------------------------
+
+func (c *shard) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.ttl = ttl
+	c.mu.Unlock()
+}
+
+// ttlOfLocked returns the time to live of value.
+func (c *shard) ttlOfLocked(value Value) time.Duration {
+	if t, ok := value.(TTLer); ok {
+		if ttl := t.TTL(); ttl != 0 {
+			return ttl
+		}
+	}
+	return c.ttl
+}
+
+func (c *shard) addTTL(key Key, value Value, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addLocked(key, value, ttl)
+}
+
+// expiredLocked removes key if it has expired, or was invalidated by
+// Group.InvalidateAll, and reports whether it has. An expired entry is kept
+// while it may be served stale, see Group.SetMaxStale.
+func (c *shard) expiredLocked(key Key) bool {
+	if c.oldLocked(key) {
+		c.reason = EvictRemoved
+		c.lru.Remove(key)
+		c.reason = EvictCapacity
+		return true
+	}
+	exp, ok := c.expires[key]
+	if !ok {
+		return false
+	}
+	now := c.now()
+	if now.Before(exp) {
+		return false
+	}
+	if now.Before(exp.Add(c.maxStale)) {
+		return true
+	}
+	c.reason = EvictExpired
+	c.lru.Remove(key)
+	c.reason = EvictCapacity
+	c.nexpire++
+	return true
+}
+
+// -----------------------------------------------------------------------------
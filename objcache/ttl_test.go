@@ -0,0 +1,73 @@
+package objcache
+
+import (
+	"testing"
+	"time"
+)
+
+type ttlVal struct {
+	s   string
+	ttl time.Duration
+}
+
+func (v ttlVal) TTL() time.Duration { return v.ttl }
+
+func TestTTL(t *testing.T) {
+	var loads int
+	var evicted []Key
+	g := NewGroup("ttl-group", 0, func(ctx Context, key Key) (Value, error) {
+		loads++
+		switch key {
+		case "short":
+			return ttlVal{"short", time.Second}, nil
+		case "forever":
+			return ttlVal{"forever", -1}, nil
+		}
+		return key, nil
+	}, func(key Key, value Value) {
+		evicted = append(evicted, key)
+	})
+	t.Cleanup(func() { RemoveGroup("ttl-group") })
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g.SetClock(clock)
+	g.SetTTL(time.Minute)
+
+	for _, key := range []string{"a", "short", "forever"} {
+		if _, err := g.Get(nil, key); err != nil {
+			t.Fatal("Get:", err)
+		}
+	}
+	g.SetWithTTL("manual", "m", 2*time.Minute)
+	clock.now = clock.now.Add(time.Second)
+	if _, ok := g.TryGet("short"); ok {
+		t.Fatal("TryGet: entry with its own TTL not expired")
+	}
+	if _, ok := g.TryGet("a"); !ok {
+		t.Fatal("TryGet: entry expired early")
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if _, ok := g.TryGet("a"); ok {
+		t.Fatal("TryGet: entry with the default TTL not expired")
+	}
+	if _, ok := g.TryGet("forever"); !ok {
+		t.Fatal("TryGet: entry without TTL expired")
+	}
+	if _, ok := g.TryGet("manual"); !ok {
+		t.Fatal("TryGet: entry set with a TTL expired early")
+	}
+	if len(evicted) != 2 || evicted[0] != "short" || evicted[1] != "a" {
+		t.Fatal("evicted:", evicted)
+	}
+	if s := g.CacheStats(); s.Expirations != 2 || s.Evictions != 0 || s.Items != 2 {
+		t.Fatalf("CacheStats: %+v", s)
+	}
+
+	loads = 0
+	if v, err := g.Get(nil, "a"); err != nil || v != "a" || loads != 1 {
+		t.Fatal("Get after expiration:", v, err, loads)
+	}
+	clock.now = clock.now.Add(time.Hour)
+	if _, ok := g.TryGet("manual"); ok {
+		t.Fatal("TryGet: entry set with a TTL not expired")
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// TunerOptions configures a Tuner. Zero fields take their defaults.
+type TunerOptions struct {
+	// Min and Max bound the capacity chosen by the tuner. Max is required.
+	Min, Max int
+
+	// Interval is the period of the tuner's decisions. Default 1 minute.
+	Interval time.Duration
+
+	// TargetHitRatio is the hit ratio below which the tuner considers
+	// growing the cache. Default 0.9.
+	TargetHitRatio float64
+
+	// MinEvictionAge is the age of evicted entries below which the cache is
+	// considered too small: entries are evicted before they can be reused.
+	// Default 10 minutes.
+	MinEvictionAge time.Duration
+
+	// MemoryLimit, if not zero, is the heap size of the process (in bytes)
+	// the tuner must stay clear of.
+	MemoryLimit uint64
+
+	// MinHeadroom is the fraction of MemoryLimit that must stay free, below
+	// which the tuner shrinks the cache. Default 0.1.
+	MinHeadroom float64
+
+	// Step is the relative change of the capacity per decision. Default 0.25.
+	Step float64
+}
+
+// TunerDecision reports a change of the capacity of a group by a Tuner, to
+// the observer of the group (see EventObserver.OnTune).
+type TunerDecision struct {
+	Group    string
+	Old, New int
+	Reason   string // "grow", "memory" or "bounds" (capacity out of [Min, Max])
+
+	HitRatio    float64       // over the last interval
+	EvictionAge time.Duration // estimated age of evicted entries, 0 if none
+	Headroom    float64       // free fraction of MemoryLimit, 1 if no limit
+}
+
+// Tuner adjusts the capacity of a group to its workload. It grows the cache
+// while the hit ratio is below target and entries are evicted young, and
+// shrinks it when the heap gets close to the memory limit.
+//
+// The age of evicted entries is estimated by Little's law, as the number of
+// entries divided by the rate of evictions.
+type Tuner struct {
+	g    *Group
+	opts TunerOptions
+
+	mu       sync.Mutex
+	last     CacheStats
+	lastTime time.Time
+	done     chan struct{}
+
+	heapAlloc func() uint64
+	now       func() time.Time
+}
+
+// NewTuner creates a tuner of g. It does nothing until Start is called, or
+// Step is called by hand.
+func NewTuner(g *Group, opts TunerOptions) *Tuner {
+	if opts.Max <= 0 {
+		panic("objcache: Tuner needs a positive Max")
+	}
+	if opts.Min <= 0 {
+		opts.Min = 1
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.TargetHitRatio <= 0 {
+		opts.TargetHitRatio = 0.9
+	}
+	if opts.MinEvictionAge <= 0 {
+		opts.MinEvictionAge = 10 * time.Minute
+	}
+	if opts.MinHeadroom <= 0 {
+		opts.MinHeadroom = 0.1
+	}
+	if opts.Step <= 0 {
+		opts.Step = 0.25
+	}
+	t := &Tuner{g: g, opts: opts, heapAlloc: heapAlloc, now: time.Now}
+	t.last, t.lastTime = g.CacheStats(), t.now()
+	return t
+}
+
+func heapAlloc() uint64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
+
+// Start runs Step every Interval in a new goroutine, until Stop.
+func (t *Tuner) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done != nil {
+		return
+	}
+	t.done = make(chan struct{})
+	go t.run(t.done)
+}
+
+func (t *Tuner) run(done chan struct{}) {
+	ticker := time.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			t.Step()
+		}
+	}
+}
+
+// Stop stops the goroutine of Start.
+func (t *Tuner) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done != nil {
+		close(t.done)
+		t.done = nil
+	}
+}
+
+// Step observes the group since the last step and adjusts its capacity. It
+// reports whether the capacity changed, and why.
+func (t *Tuner) Step() (d TunerDecision, changed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	o := &t.opts
+	now, stats := t.now(), t.g.CacheStats()
+	gets, hits := stats.Gets-t.last.Gets, stats.Hits-t.last.Hits
+	evictions := stats.Evictions - t.last.Evictions
+	elapsed := now.Sub(t.lastTime)
+	t.last, t.lastTime = stats, now
+
+	d = TunerDecision{Group: t.g.Name(), Old: t.g.Capacity(), HitRatio: 1, Headroom: 1}
+	if gets > 0 {
+		d.HitRatio = float64(hits) / float64(gets)
+	}
+	if evictions > 0 {
+		d.EvictionAge = time.Duration(float64(elapsed) * float64(stats.Items) / float64(evictions))
+	}
+	if o.MemoryLimit > 0 {
+		d.Headroom = 1 - float64(t.heapAlloc())/float64(o.MemoryLimit)
+	}
+
+	capacity := d.Old
+	if capacity <= 0 { // no limit yet: start from what is cached
+		capacity = int(stats.Items)
+	}
+	switch {
+	case d.Headroom < o.MinHeadroom:
+		d.Reason, d.New = "memory", int(float64(capacity)*(1-o.Step))
+	case evictions > 0 && d.HitRatio < o.TargetHitRatio && d.EvictionAge < o.MinEvictionAge &&
+		d.Headroom >= 2*o.MinHeadroom:
+		d.Reason, d.New = "grow", int(float64(capacity)*(1+o.Step))+1
+	default:
+		d.New = capacity
+	}
+	if d.New < o.Min {
+		d.New = o.Min
+	}
+	if d.New > o.Max {
+		d.New = o.Max
+	}
+	if d.New == d.Old {
+		return d, false
+	}
+	if d.Reason == "" {
+		d.Reason = "bounds"
+	}
+	t.g.SetCapacity(d.New)
+	t.g.observeTune(d)
+	return d, true
+}
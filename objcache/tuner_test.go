@@ -0,0 +1,61 @@
+package objcache
+
+import (
+	"testing"
+	"time"
+)
+
+type tuneObserver struct {
+	NopObserver
+	decisions []TunerDecision
+}
+
+func (o *tuneObserver) OnTune(d TunerDecision) {
+	o.decisions = append(o.decisions, d)
+}
+
+func TestTuner(t *testing.T) {
+	o := new(tuneObserver)
+	g := NewGroupWith("tuner-group", func(ctx Context, key Key) (Value, error) {
+		return key, nil
+	}, WithMaxItems(10), WithObserver(o))
+	t.Cleanup(func() { RemoveGroup("tuner-group") })
+	tu := NewTuner(g, TunerOptions{Min: 5, Max: 40, MinEvictionAge: time.Hour, MemoryLimit: 1000})
+	now := time.Unix(1e9, 0)
+	heap := uint64(100)
+	tu.now = func() time.Time { return now }
+	tu.heapAlloc = func() uint64 { return heap }
+	tu.lastTime = now
+
+	// a working set of 30 keys doesn't fit: the tuner grows the cache
+	for round := 0; round < 10; round++ {
+		for i := 0; i < 30; i++ {
+			g.Get(nil, i)
+		}
+		now = now.Add(time.Minute)
+		tu.Step()
+	}
+	if c := g.Capacity(); c < 30 || c > 40 {
+		t.Fatal("capacity after growing:", c, o.decisions)
+	}
+	for _, d := range o.decisions {
+		if d.Reason != "grow" || d.New <= d.Old {
+			t.Fatalf("decision: %+v", d)
+		}
+	}
+	if d, changed := tu.Step(); changed {
+		t.Fatalf("no evictions, still changed: %+v", d)
+	}
+
+	// memory pressure shrinks it down to Min
+	heap = 950
+	for i := 0; i < 20; i++ {
+		tu.Step()
+	}
+	if c := g.Capacity(); c != 5 || g.CacheStats().Items > 5 {
+		t.Fatal("capacity after shrinking:", c, g.CacheStats().Items)
+	}
+	if d := o.decisions[len(o.decisions)-1]; d.Reason != "memory" || d.Headroom > 0.1 {
+		t.Fatalf("decision: %+v", d)
+	}
+}
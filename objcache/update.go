@@ -0,0 +1,83 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"time"
+)
+
+// Update replaces the cached value of key by the value returned by fn, if
+// key is cached: fn is called with the cached value and the cache locked, so
+// that no Get, Set or Remove of key can interleave, and returns the new value
+// and whether to replace the old one. Update reports whether the value was
+// replaced.
+//
+// A different old value leaves the cache as if it were removed, and is
+// disposed of once released. The entry keeps its expiry, and a load of key
+// in flight, e.g. a refresh, isn't cached over it. As the cache is locked, fn
+// must not call the methods of the group.
+func (g *Group) Update(key Key, fn func(old Value) (Value, bool)) bool {
+	g.flights.forget(key)
+	if val, ok := g.mainCache.update(key, fn); ok {
+		g.tag(key, val)
+		g.setSecondary(key, val)
+		return true
+	}
+	_, ok := g.hotCache.update(key, fn)
+	return ok
+}
+
+// -----------------------------------------------------------------------------
+
+func (c *cache) update(key Key, fn func(old Value) (Value, bool)) (Value, bool) {
+	i := c.indexOf(key)
+	val, ok := c.shards[i].update(key, fn)
+	if ok {
+		c.prune(i)
+	}
+	return val, ok
+}
+
+func (c *shard) update(key Key, fn func(old Value) (Value, bool)) (Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var old Value
+	if p, pinned := c.pinned[key]; pinned {
+		old = p.value
+	} else {
+		value, ok := c.lookupLocked(key)
+		if !ok || c.expiredLocked(key) {
+			return nil, false
+		}
+		old = value
+	}
+	if _, neg := old.(*negEntry); neg {
+		return nil, false
+	}
+	value, ok := fn(old)
+	if !ok {
+		return nil, false
+	}
+	var ttl time.Duration
+	if exp, ok := c.expires[key]; ok {
+		ttl = exp.Sub(c.now())
+	}
+	c.addLocked(key, value, ttl)
+	return value, true
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,79 @@
+package objcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdate(t *testing.T) {
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g := NewGroupWith("update-group", func(ctx Context, key Key) (Value, error) {
+		return 0, nil
+	}, WithClock(clock))
+	t.Cleanup(func() { RemoveGroup("update-group") })
+	called := false
+	if g.Update("a", func(old Value) (Value, bool) { called = true; return 1, true }) || called {
+		t.Fatal("Update of a missing key")
+	}
+
+	g.SetWithTTL("n", 0, time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.Update("n", func(old Value) (Value, bool) { return old.(int) + 1, true })
+		}()
+	}
+	wg.Wait()
+	if v, _ := g.Get(nil, "n"); v != 100 {
+		t.Fatal("concurrent Updates:", v)
+	}
+	if g.Update("n", func(old Value) (Value, bool) { return -1, false }) {
+		t.Fatal("Update not replacing")
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if v, _ := g.Get(nil, "n"); v != 0 {
+		t.Fatal("Update changed the expiry:", v)
+	}
+
+	res := new(resource)
+	g.Set("r", res)
+	if !g.Update("r", func(old Value) (Value, bool) { return new(resource), true }) {
+		t.Fatal("Update of r")
+	}
+	if res.disposals() != 1 {
+		t.Fatal("old value not disposed")
+	}
+}
+
+func TestUpdateDuringRefresh(t *testing.T) {
+	release := make(chan struct{})
+	loads := 0
+	clock := &fixedClock{time.Unix(1000, 0)}
+	g := NewGroupWith("update-refresh-group", func(ctx Context, key Key) (Value, error) {
+		if loads++; loads > 1 {
+			<-release
+		}
+		return loads, nil
+	}, WithClock(clock))
+	t.Cleanup(func() { RemoveGroup("update-refresh-group") })
+	g.SetRefreshAfter(time.Minute)
+	g.Get(nil, "k")
+	clock.now = clock.now.Add(time.Minute)
+	g.Get(nil, "k") // reloads k in the background
+	for g.LoadStats().Active != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if !g.Update("k", func(old Value) (Value, bool) { return 10, true }) {
+		t.Fatal("Update of k")
+	}
+	close(release)
+	for g.LoadStats().Active != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if v, _ := g.TryGet("k"); v != 10 {
+		t.Fatal("refresh cached over the Update:", v)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objcache
+
+import (
+	"context"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+
+// WarmupProgress is the progress of a Group.Warmup.
+type WarmupProgress struct {
+	Total  int // keys to load
+	Done   int // keys loaded or failed so far
+	Failed int
+}
+
+// Warmup loads keys into the cache of the group with Get, at most
+// parallelism (at least 1) of them at the same time, e.g. to pre-fill the
+// cache before taking traffic. The keys already cached are not loaded again.
+// If given, progress is called after each key with the progress so far; the
+// calls are serialized.
+//
+// A failed key doesn't stop the warmup: Warmup returns the final progress,
+// and the first error of the loads. If ctx is a context.Context, no more key
+// is loaded once it is done, and Warmup returns its error.
+func (g *Group) Warmup(ctx Context, keys []Key, parallelism int, progress ...func(p WarmupProgress)) (WarmupProgress, error) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	var done <-chan struct{}
+	if c, ok := ctx.(context.Context); ok {
+		done = c.Done()
+	}
+	var (
+		mu    sync.Mutex
+		p     = WarmupProgress{Total: len(keys)}
+		first error
+		wg    sync.WaitGroup
+	)
+	sem := make(chan struct{}, parallelism)
+loop:
+	for _, key := range keys {
+		select {
+		case <-done:
+			break loop
+		default:
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-done:
+			break loop
+		}
+		wg.Add(1)
+		go func(key Key) {
+			defer wg.Done()
+			_, err := g.Get(ctx, key)
+			mu.Lock()
+			p.Done++
+			if err != nil {
+				p.Failed++
+				if first == nil {
+					first = err
+				}
+			}
+			for _, fn := range progress {
+				fn(p)
+			}
+			mu.Unlock()
+			<-sem
+		}(key)
+	}
+	wg.Wait()
+	if c, ok := ctx.(context.Context); ok && c.Err() != nil {
+		return p, c.Err()
+	}
+	return p, first
+}
+
+// -----------------------------------------------------------------------------
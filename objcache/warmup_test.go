@@ -0,0 +1,57 @@
+package objcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	errOdd := errors.New("odd")
+	var active, maxActive int32
+	g := NewGroup("warmup-group", 0, func(ctx Context, key Key) (Value, error) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		if key.(int)%2 == 1 {
+			return nil, errOdd
+		}
+		return key, nil
+	})
+	t.Cleanup(func() { RemoveGroup("warmup-group") })
+	g.Set(0, 0)
+	keys := make([]Key, 20)
+	for i := range keys {
+		keys[i] = i
+	}
+	var last WarmupProgress
+	calls := 0
+	p, err := g.Warmup(context.Background(), keys, 3, func(p WarmupProgress) {
+		calls++
+		if p.Done != calls {
+			t.Error("progress:", p)
+		}
+		last = p
+	})
+	if err != errOdd || p != (WarmupProgress{Total: 20, Done: 20, Failed: 10}) || last != p {
+		t.Fatal("Warmup:", p, err)
+	}
+	if maxActive > 3 {
+		t.Fatal("parallelism:", maxActive)
+	}
+	if _, ok := g.TryGet(18); !ok {
+		t.Fatal("key not cached")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if p, err = g.Warmup(ctx, []Key{100}, 1); err != context.Canceled || p.Done != 0 {
+		t.Fatal("canceled Warmup:", p, err)
+	}
+}
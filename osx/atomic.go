@@ -0,0 +1,154 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package osx extends the os package.
+package osx
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// AtomicOptions configures WriteAtomic.
+type AtomicOptions struct {
+	// Keep is the number of previous versions of the file to keep, named
+	// "<name>.1" (the most recent) to "<name>.<Keep>".
+	Keep int
+
+	// NoSync skips the fsyncs of the file and its directory: the file is
+	// still replaced atomically, but may be lost on a power failure.
+	NoSync bool
+}
+
+// WriteFileAtomic is like os.WriteFile, but readers of filename observe
+// either the old content or the new one, never a partially written file,
+// even if the process crashes in the middle, see WriteAtomic.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	return WriteAtomic(filename, bytes.NewReader(data), perm, nil)
+}
+
+// WriteAtomic replaces filename with the content read from r. The content
+// is written to a temporary file in the same directory, which is synced and
+// then renamed over filename; finally the directory is synced, so the
+// rename itself is durable. On error filename is left untouched. opts may
+// be nil.
+func WriteAtomic(filename string, r io.Reader, perm os.FileMode, opts *AtomicOptions) (err error) {
+	var o AtomicOptions
+	if opts != nil {
+		o = *opts
+	}
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := ioutil.TempFile(dir, "."+base+".*.tmp")
+	if err != nil {
+		return
+	}
+	tmp := f.Name()
+	defer func() {
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+	_, err = io.Copy(f, r)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if err == nil && !o.NoSync {
+		err = f.Sync()
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return
+	}
+	if o.Keep > 0 {
+		if err = rotate(filename, o.Keep); err != nil {
+			return
+		}
+	}
+	if err = os.Rename(tmp, filename); err != nil {
+		return
+	}
+	if !o.NoSync {
+		err = syncDir(dir)
+	}
+	return
+}
+
+// rotate shifts the previous versions of filename by one, and makes the
+// current file the version ".1". The current file stays in place, so that
+// filename never disappears.
+func rotate(filename string, keep int) error {
+	if _, err := os.Lstat(filename); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	version := func(i int) string { return filename + "." + strconv.Itoa(i) }
+	os.Remove(version(keep))
+	for i := keep - 1; i >= 1; i-- {
+		if err := os.Rename(version(i), version(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if os.Link(filename, version(1)) == nil {
+		return nil
+	}
+	return copyFile(filename, version(1))
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err1 := out.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" { // directories can't be synced
+		return nil
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	f.Close()
+	return err
+}
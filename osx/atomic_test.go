@@ -0,0 +1,56 @@
+package osx
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "conf.json")
+	for _, s := range []string{"v1", "v2", "v3", "v4"} {
+		if err := WriteAtomic(name, strings.NewReader(s), 0600, &AtomicOptions{Keep: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for file, want := range map[string]string{name: "v4", name + ".1": "v3", name + ".2": "v2"} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil || string(b) != want {
+			t.Fatal(file, string(b), err)
+		}
+	}
+	if _, err := os.Stat(name + ".3"); !os.IsNotExist(err) {
+		t.Fatal("too many versions kept")
+	}
+	if err := WriteFileAtomic(name, []byte("v5"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fis, _ := ioutil.ReadDir(dir)
+	if len(fis) != 3 {
+		t.Fatal("temp files left:", fis)
+	}
+}
+
+type failReader struct{}
+
+func (failReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestWriteAtomicFail(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "data")
+	WriteFileAtomic(name, []byte("old"), 0644)
+	if err := WriteAtomic(name, failReader{}, 0644, nil); err == nil {
+		t.Fatal("no error")
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "old" {
+		t.Fatal("file changed:", string(b))
+	}
+	fis, _ := ioutil.ReadDir(dir)
+	if len(fis) != 1 {
+		t.Fatal("temp files left:", fis)
+	}
+}
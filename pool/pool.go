@@ -0,0 +1,342 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package pool implements a generic pool of reusable objects, such as
+// buffers, codecs and connections.
+//
+// Unlike sync.Pool, a Pool bounds the number of live objects, keeps idle
+// objects until they time out rather than until the next GC, checks their
+// health on checkout, and destroys the objects it drops.
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by Get when the pool is closed.
+var ErrClosed = errors.New("pool: closed")
+
+// DefaultMaxIdle is the default maximum number of idle objects.
+const DefaultMaxIdle = 8
+
+// Stats are the statistics of a Pool.
+type Stats struct {
+	Active    int   // live objects: idle and in use
+	Idle      int   // idle objects
+	Gets      int64 // successful Gets
+	Hits      int64 // Gets that reused an idle object
+	Waits     int64 // Gets that waited for an object because of MaxActive
+	WaitTime  time.Duration
+	Created   int64
+	Destroyed int64
+	Unhealthy int64 // idle objects that failed the health check
+	Expired   int64 // idle objects reaped after IdleTimeout
+}
+
+type idleItem[T any] struct {
+	v     T
+	since time.Time
+}
+
+// Pool is a pool of objects of type T. It is safe for concurrent use.
+type Pool[T any] struct {
+	factory func(ctx context.Context) (T, error)
+	reset   func(T)
+	destroy func(T)
+
+	mu          sync.Mutex
+	idle        []idleItem[T] // the most recently used last
+	active      int
+	maxIdle     int
+	maxActive   int
+	idleTimeout time.Duration
+	check       func(v T, idle time.Duration) bool
+	waiters     []chan struct{}
+	reaper      chan struct{} // closed to stop the reaper
+	closed      bool
+	stats       Stats
+}
+
+// New creates a pool of the objects made by factory. reset, if not nil, is
+// called on the objects that are put back; destroy, if not nil, is called on
+// the objects that the pool drops.
+func New[T any](factory func(ctx context.Context) (T, error), reset, destroy func(T)) *Pool[T] {
+	return &Pool[T]{factory: factory, reset: reset, destroy: destroy, maxIdle: DefaultMaxIdle}
+}
+
+// SetMaxIdle sets the maximum number of idle objects. Extra idle objects
+// are destroyed.
+func (p *Pool[T]) SetMaxIdle(n int) {
+	p.mu.Lock()
+	p.maxIdle = n
+	var drop []T
+	for len(p.idle) > n && len(p.idle) > 0 {
+		drop = append(drop, p.idle[0].v)
+		p.idle = p.idle[1:]
+	}
+	p.active -= len(drop)
+	p.stats.Destroyed += int64(len(drop))
+	p.wakeLocked(len(drop))
+	p.mu.Unlock()
+	p.destroyAll(drop)
+}
+
+// SetMaxActive sets the maximum number of live objects, idle ones
+// included. When it is reached, Get waits for an object to be put back or
+// discarded. Zero means no limit.
+func (p *Pool[T]) SetMaxActive(n int) {
+	p.mu.Lock()
+	p.maxActive = n
+	p.wakeLocked(len(p.waiters))
+	p.mu.Unlock()
+}
+
+// SetIdleTimeout makes the pool destroy the objects that stay idle for more
+// than d. Zero means idle objects never time out.
+func (p *Pool[T]) SetIdleTimeout(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idleTimeout = d
+	if p.reaper != nil {
+		close(p.reaper)
+		p.reaper = nil
+	}
+	if d > 0 && !p.closed {
+		p.reaper = make(chan struct{})
+		go p.reap(d, p.reaper)
+	}
+}
+
+// SetHealthCheck sets the check of the idle objects on checkout: an object
+// for which check returns false is destroyed. check receives how long the
+// object has been idle, so that cheap objects can skip recent ones.
+func (p *Pool[T]) SetHealthCheck(check func(v T, idle time.Duration) bool) {
+	p.mu.Lock()
+	p.check = check
+	p.mu.Unlock()
+}
+
+// Get returns an idle object, or a new one from the factory.
+func (p *Pool[T]) Get(ctx context.Context) (v T, err error) {
+	var start time.Time
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return v, ErrClosed
+		}
+		if n := len(p.idle); n > 0 {
+			it := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			check, timeout := p.check, p.idleTimeout
+			p.mu.Unlock()
+			idle := time.Since(it.since)
+			expired := timeout > 0 && idle > timeout
+			if !expired && (check == nil || check(it.v, idle)) {
+				p.mu.Lock()
+				p.stats.Gets++
+				p.stats.Hits++
+				p.mu.Unlock()
+				return it.v, nil
+			}
+			p.drop(it.v, func(s *Stats) {
+				if expired {
+					s.Expired++
+				} else {
+					s.Unhealthy++
+				}
+			})
+			continue
+		}
+		if p.maxActive <= 0 || p.active < p.maxActive {
+			p.active++
+			p.mu.Unlock()
+			if v, err = p.factory(ctx); err != nil {
+				p.mu.Lock()
+				p.active--
+				p.wakeLocked(1)
+				p.mu.Unlock()
+				return
+			}
+			p.mu.Lock()
+			p.stats.Gets++
+			p.stats.Created++
+			p.mu.Unlock()
+			return v, nil
+		}
+		wait := make(chan struct{})
+		p.waiters = append(p.waiters, wait)
+		if start.IsZero() {
+			start = time.Now()
+			p.stats.Waits++
+		}
+		p.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			p.mu.Lock()
+			p.removeWaiterLocked(wait)
+			p.stats.WaitTime += time.Since(start)
+			p.mu.Unlock()
+			return v, ctx.Err()
+		}
+		p.mu.Lock()
+		p.stats.WaitTime += time.Since(start)
+		start = time.Now()
+		p.mu.Unlock()
+	}
+}
+
+// Put puts v, which was returned by Get, back into the pool.
+func (p *Pool[T]) Put(v T) {
+	if p.reset != nil {
+		p.reset(v)
+	}
+	p.mu.Lock()
+	if p.closed || len(p.idle) >= p.maxIdle {
+		p.mu.Unlock()
+		p.drop(v, nil)
+		return
+	}
+	p.idle = append(p.idle, idleItem[T]{v: v, since: time.Now()})
+	p.wakeLocked(1)
+	p.mu.Unlock()
+}
+
+// Discard destroys v, which was returned by Get, instead of putting it
+// back: for instance, a connection that failed.
+func (p *Pool[T]) Discard(v T) {
+	p.drop(v, nil)
+}
+
+// drop destroys v, a live object that isn't idle.
+func (p *Pool[T]) drop(v T, count func(s *Stats)) {
+	p.mu.Lock()
+	p.active--
+	p.stats.Destroyed++
+	if count != nil {
+		count(&p.stats)
+	}
+	p.wakeLocked(1)
+	p.mu.Unlock()
+	if p.destroy != nil {
+		p.destroy(v)
+	}
+}
+
+func (p *Pool[T]) destroyAll(vs []T) {
+	if p.destroy != nil {
+		for _, v := range vs {
+			p.destroy(v)
+		}
+	}
+}
+
+// wakeLocked wakes n waiting Gets up.
+func (p *Pool[T]) wakeLocked(n int) {
+	for ; n > 0 && len(p.waiters) > 0; n-- {
+		close(p.waiters[0])
+		p.waiters = p.waiters[1:]
+	}
+}
+
+func (p *Pool[T]) removeWaiterLocked(wait chan struct{}) {
+	for i, w := range p.waiters {
+		if w == wait {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			return
+		}
+	}
+	// woken up already: pass the wakeup on
+	p.wakeLocked(1)
+}
+
+func (p *Pool[T]) reap(timeout time.Duration, stop chan struct{}) {
+	interval := timeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	if interval > timeout {
+		interval = timeout
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			p.reapIdle(now)
+		}
+	}
+}
+
+// reapIdle destroys the objects that have been idle for longer than the
+// idle timeout at now.
+func (p *Pool[T]) reapIdle(now time.Time) {
+	p.mu.Lock()
+	var drop []T
+	i := 0
+	for i < len(p.idle) && now.Sub(p.idle[i].since) > p.idleTimeout { // the oldest first
+		drop = append(drop, p.idle[i].v)
+		i++
+	}
+	p.idle = append(p.idle[:0], p.idle[i:]...)
+	p.active -= len(drop)
+	p.stats.Destroyed += int64(len(drop))
+	p.stats.Expired += int64(len(drop))
+	p.wakeLocked(len(drop))
+	p.mu.Unlock()
+	p.destroyAll(drop)
+}
+
+// Stats returns the statistics of the pool.
+func (p *Pool[T]) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Active, s.Idle = p.active, len(p.idle)
+	return s
+}
+
+// Close destroys the idle objects and stops the pool: Get then fails, and
+// objects put back are destroyed.
+func (p *Pool[T]) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	if p.reaper != nil {
+		close(p.reaper)
+		p.reaper = nil
+	}
+	drop := make([]T, len(p.idle))
+	for i, it := range p.idle {
+		drop[i] = it.v
+	}
+	p.idle = nil
+	p.active -= len(drop)
+	p.stats.Destroyed += int64(len(drop))
+	p.wakeLocked(len(p.waiters))
+	p.mu.Unlock()
+	p.destroyAll(drop)
+	return nil
+}
@@ -0,0 +1,113 @@
+package pool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type conn struct {
+	id      int
+	healthy bool
+	resets  int
+}
+
+func newTestPool() (*Pool[*conn], *int32) {
+	var destroyed int32
+	n := 0
+	p := New(func(ctx context.Context) (*conn, error) {
+		n++
+		return &conn{id: n, healthy: true}, nil
+	}, func(c *conn) {
+		c.resets++
+	}, func(c *conn) {
+		atomic.AddInt32(&destroyed, 1)
+	})
+	return p, &destroyed
+}
+
+func TestPool(t *testing.T) {
+	p, destroyed := newTestPool()
+	ctx := context.Background()
+	c1, _ := p.Get(ctx)
+	c2, _ := p.Get(ctx)
+	p.Put(c1)
+	if c, _ := p.Get(ctx); c != c1 || c.resets != 1 {
+		t.Fatal("idle object not reused")
+	}
+	p.Put(c1)
+	p.Put(c2)
+	c1.healthy = false
+	p.SetHealthCheck(func(c *conn, idle time.Duration) bool { return c.healthy })
+	// c2 is the most recently used, then c1 fails the check
+	if c, _ := p.Get(ctx); c != c2 {
+		t.Fatal("not LIFO")
+	}
+	if c, _ := p.Get(ctx); c.id != 3 || *destroyed != 1 {
+		t.Fatal("unhealthy object reused:", c.id, *destroyed)
+	}
+	s := p.Stats()
+	if s.Active != 2 || s.Idle != 0 || s.Gets != 5 || s.Hits != 2 || s.Created != 3 || s.Unhealthy != 1 {
+		t.Fatalf("Stats: %+v", s)
+	}
+	p.SetMaxIdle(1)
+	p.Put(c2)
+	p.Discard(&conn{})
+	if *destroyed != 2 || p.Stats().Idle != 1 {
+		t.Fatal("MaxIdle not enforced:", *destroyed)
+	}
+	p.Close()
+	if _, err := p.Get(ctx); err != ErrClosed || *destroyed != 3 {
+		t.Fatal("Close:", err, *destroyed)
+	}
+}
+
+func TestPoolMaxActive(t *testing.T) {
+	p, _ := newTestPool()
+	defer p.Close()
+	p.SetMaxActive(1)
+	ctx := context.Background()
+	c, _ := p.Get(ctx)
+	got := make(chan *conn)
+	go func() {
+		c, _ := p.Get(ctx)
+		got <- c
+	}()
+	time.Sleep(10 * time.Millisecond)
+	p.Put(c)
+	if <-got != c {
+		t.Fatal("waiter didn't get the object put back")
+	}
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Get(tctx); err != context.DeadlineExceeded {
+		t.Fatal("Get beyond MaxActive:", err)
+	}
+	if s := p.Stats(); s.Waits != 2 || s.WaitTime <= 0 || s.Active != 1 {
+		t.Fatalf("Stats: %+v", s)
+	}
+}
+
+func TestPoolIdleTimeout(t *testing.T) {
+	p, destroyed := newTestPool()
+	defer p.Close()
+	ctx := context.Background()
+	c1, _ := p.Get(ctx)
+	c2, _ := p.Get(ctx)
+	p.Put(c1)
+	p.Put(c2)
+	p.mu.Lock()
+	p.idleTimeout = time.Minute
+	p.idle[0].since = time.Now().Add(-2 * time.Minute)
+	p.mu.Unlock()
+	p.reapIdle(time.Now())
+	if *destroyed != 1 || p.Stats().Expired != 1 || p.Stats().Idle != 1 {
+		t.Fatalf("reap: %d destroyed, %+v", *destroyed, p.Stats())
+	}
+	p.SetIdleTimeout(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if c, _ := p.Get(ctx); c == c2 {
+		t.Fatal("expired object reused")
+	}
+}
@@ -0,0 +1,568 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package reflectutil provides reflection-based helpers, such as computing
+// the difference between two values of the same type and applying it to
+// another one.
+package reflectutil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// Op is the kind of a Change.
+type Op int
+
+const (
+	// OpUpdate changes the value at Path from Old to New.
+	OpUpdate Op = iota
+
+	// OpAdd adds New as the map entry at Path.
+	OpAdd
+
+	// OpRemove removes the map entry at Path, whose value was Old.
+	OpRemove
+)
+
+var opNames = [...]string{"update", "add", "remove"}
+
+func (op Op) String() string {
+	if op >= 0 && int(op) < len(opNames) {
+		return opNames[op]
+	}
+	return "Op(" + strconv.Itoa(int(op)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (op Op) MarshalText() ([]byte, error) {
+	return []byte(op.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (op *Op) UnmarshalText(b []byte) error {
+	for i, name := range opNames {
+		if string(b) == name {
+			*op = Op(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("reflectutil: unknown op %q", b)
+}
+
+// Change is a difference between two values.
+//
+// Path locates the changed value from the root: struct fields are named by
+// their Go names and separated by ".", slice and array elements are indexed
+// as "[3]", and map entries are keyed as `["key"]` (string keys) or "[42]"
+// (integer keys). For instance `Spec.Ports[0].Labels["env"]`. The empty
+// path is the root itself.
+type Change struct {
+	Op   Op          `json:"op"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Patch is a list of changes, as returned by Diff.
+type Patch []Change
+
+// ConflictError is returned by ApplyPatch when the current value at the path
+// of a change isn't the old value of the change.
+type ConflictError struct {
+	Path string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("reflectutil: conflict at %q", e.Path)
+}
+
+// -----------------------------------------------------------------------------
+
+// Diff returns the changes that turn old into new, which must be of the same
+// type. Structs, pointers, interfaces, slices, arrays and maps are compared
+// recursively; unexported struct fields, funcs and channels are ignored.
+// Structs without exported fields, and types with an Equal(T) bool method
+// (such as time.Time) are compared as a whole. Slices of different lengths
+// and maps with keys other than strings and integers are changed as a whole
+// too.
+//
+// The Old and New values of the changes are not copied: they share the
+// memory of old and new.
+func Diff(old, new interface{}) (Patch, error) {
+	a, b := reflect.ValueOf(old), reflect.ValueOf(new)
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() || b.IsValid() {
+			return Patch{{Op: OpUpdate, Old: old, New: new}}, nil
+		}
+		return nil, nil
+	}
+	if a.Type() != b.Type() {
+		return nil, fmt.Errorf("reflectutil: diff of %v and %v", a.Type(), b.Type())
+	}
+	var p Patch
+	diff(&p, "", a, b)
+	return p, nil
+}
+
+func diff(p *Patch, path string, a, b reflect.Value) {
+	t := a.Type()
+	if isLeaf(t) {
+		if !equal(a, b) {
+			*p = append(*p, Change{Op: OpUpdate, Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+		return
+	}
+	switch a.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() || a.Elem().Type() != b.Elem().Type() {
+			if a.IsNil() != b.IsNil() || !a.IsNil() {
+				*p = append(*p, Change{Op: OpUpdate, Path: path, Old: a.Interface(), New: b.Interface()})
+			}
+			return
+		}
+		diff(p, path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if sf := t.Field(i); sf.PkgPath == "" {
+				diff(p, joinField(path, sf.Name), a.Field(i), b.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if a.IsNil() != b.IsNil() || a.Len() != b.Len() {
+			*p = append(*p, Change{Op: OpUpdate, Path: path, Old: a.Interface(), New: b.Interface()})
+			return
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			diff(p, path+"["+strconv.Itoa(i)+"]", a.Index(i), b.Index(i))
+		}
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() || !isKeyKind(t.Key().Kind()) {
+			if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+				*p = append(*p, Change{Op: OpUpdate, Path: path, Old: a.Interface(), New: b.Interface()})
+			}
+			return
+		}
+		for _, k := range sortedKeys(a, b) {
+			kpath := path + "[" + formatKey(k) + "]"
+			x, y := a.MapIndex(k), b.MapIndex(k)
+			switch {
+			case !y.IsValid():
+				*p = append(*p, Change{Op: OpRemove, Path: kpath, Old: x.Interface()})
+			case !x.IsValid():
+				*p = append(*p, Change{Op: OpAdd, Path: kpath, New: y.Interface()})
+			default:
+				diff(p, kpath, x, y)
+			}
+		}
+	default:
+		if !equal(a, b) {
+			*p = append(*p, Change{Op: OpUpdate, Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// isLeaf reports whether the values of t are compared as a whole.
+func isLeaf(t reflect.Type) bool {
+	if _, ok := equalMethod(t); ok {
+		return true
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func equalMethod(t reflect.Type) (reflect.Method, bool) {
+	m, ok := t.MethodByName("Equal")
+	if !ok || m.Type.NumIn() != 2 || m.Type.In(1) != t ||
+		m.Type.NumOut() != 1 || m.Type.Out(0).Kind() != reflect.Bool {
+		return m, false
+	}
+	return m, true
+}
+
+func equal(a, b reflect.Value) bool {
+	if m, ok := equalMethod(a.Type()); ok {
+		return m.Func.Call([]reflect.Value{a, b})[0].Bool()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func isKeyKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func formatKey(k reflect.Value) string {
+	switch k.Kind() {
+	case reflect.String:
+		return strconv.Quote(k.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	default:
+		return strconv.FormatUint(k.Uint(), 10)
+	}
+}
+
+// sortedKeys returns the keys of a and b, in a deterministic order.
+func sortedKeys(a, b reflect.Value) []reflect.Value {
+	keys := a.MapKeys()
+	for _, k := range b.MapKeys() {
+		if !a.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		switch x, y := keys[i], keys[j]; x.Kind() {
+		case reflect.String:
+			return x.String() < y.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return x.Int() < y.Int()
+		default:
+			return x.Uint() < y.Uint()
+		}
+	})
+	return keys
+}
+
+// -----------------------------------------------------------------------------
+
+// ApplyPatch applies patch to the value dst points to. Nil pointers and maps
+// on the paths are allocated as needed. The New values must be assignable or
+// convertible to the types at their paths.
+//
+// Before changing anything, ApplyPatch checks that the value at the path of
+// each change is its Old value (or that the map entry to add is absent), so
+// that a patch computed from a stale copy is rejected with a *ConflictError
+// rather than overwriting concurrent changes.
+func ApplyPatch(dst interface{}, patch Patch) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("reflectutil: ApplyPatch of a non-pointer")
+	}
+	root := v.Elem()
+	paths := make([][]step, len(patch))
+	for i := range patch {
+		c := &patch[i]
+		steps, err := parsePath(c.Path)
+		if err != nil {
+			return err
+		}
+		paths[i] = steps
+		cur, found, err := lookup(root, steps)
+		if err != nil {
+			return err
+		}
+		if c.Op == OpAdd {
+			if found {
+				return &ConflictError{Path: c.Path}
+			}
+		} else if !found || !matches(cur, c.Old) {
+			return &ConflictError{Path: c.Path}
+		}
+	}
+	for i := range patch {
+		if err := set(root, paths[i], &patch[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type step struct {
+	field string // for struct fields
+	key   string // for indexes and map keys, unquoted
+}
+
+func parsePath(path string) (steps []step, err error) {
+	s := path
+	for s != "" {
+		switch s[0] {
+		case '.':
+			if s = s[1:]; s == "" || s[0] == '.' || s[0] == '[' {
+				return nil, fmt.Errorf("reflectutil: invalid path %q", path)
+			}
+		case '[':
+			var key string
+			if strings.HasPrefix(s, `["`) {
+				i := 2
+				for i < len(s) && s[i] != '"' {
+					if s[i] == '\\' {
+						i++
+					}
+					i++
+				}
+				if i+1 >= len(s) || s[i+1] != ']' {
+					return nil, fmt.Errorf("reflectutil: invalid path %q", path)
+				}
+				if key, err = strconv.Unquote(s[1 : i+1]); err != nil {
+					return nil, fmt.Errorf("reflectutil: invalid path %q", path)
+				}
+				s = s[i+2:]
+			} else {
+				i := strings.IndexByte(s, ']')
+				if i < 0 {
+					return nil, fmt.Errorf("reflectutil: invalid path %q", path)
+				}
+				key, s = s[1:i], s[i+1:]
+			}
+			steps = append(steps, step{key: key})
+			continue
+		}
+		i := strings.IndexAny(s, ".[")
+		if i < 0 {
+			i = len(s)
+		}
+		if i == 0 {
+			return nil, fmt.Errorf("reflectutil: invalid path %q", path)
+		}
+		steps = append(steps, step{field: s[:i]})
+		s = s[i:]
+	}
+	return
+}
+
+func (s step) index() (int, error) {
+	i, err := strconv.Atoi(s.key)
+	if s.field != "" || err != nil || i < 0 {
+		return 0, fmt.Errorf("reflectutil: invalid index %q", s.key)
+	}
+	return i, nil
+}
+
+func (s step) mapKey(t reflect.Type) (k reflect.Value, err error) {
+	if s.field != "" {
+		return k, fmt.Errorf("reflectutil: field %s of a map", s.field)
+	}
+	k = reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		k.SetString(s.key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, e := strconv.ParseInt(s.key, 10, t.Bits())
+		if e != nil {
+			return k, fmt.Errorf("reflectutil: invalid map key %q", s.key)
+		}
+		k.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, e := strconv.ParseUint(s.key, 10, t.Bits())
+		if e != nil {
+			return k, fmt.Errorf("reflectutil: invalid map key %q", s.key)
+		}
+		k.SetUint(n)
+	default:
+		return k, fmt.Errorf("reflectutil: unsupported map key type %v", t)
+	}
+	return k, nil
+}
+
+// lookup returns the value at steps from v. found is false if it is absent:
+// a missing map entry, an index out of range, or a nil pointer on the way.
+func lookup(v reflect.Value, steps []step) (cur reflect.Value, found bool, err error) {
+	for _, s := range steps {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return v, false, nil
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			if v, err = field(v, s); err != nil {
+				return
+			}
+		case reflect.Slice, reflect.Array:
+			i, e := s.index()
+			if e != nil {
+				return v, false, e
+			}
+			if i >= v.Len() {
+				return v, false, nil
+			}
+			v = v.Index(i)
+		case reflect.Map:
+			k, e := s.mapKey(v.Type().Key())
+			if e != nil {
+				return v, false, e
+			}
+			if v = v.MapIndex(k); !v.IsValid() {
+				return v, false, nil
+			}
+		default:
+			return v, false, fmt.Errorf("reflectutil: can't step into %v", v.Type())
+		}
+	}
+	return v, true, nil
+}
+
+func field(v reflect.Value, s step) (reflect.Value, error) {
+	if s.field == "" {
+		return v, fmt.Errorf("reflectutil: index [%s] of a struct", s.key)
+	}
+	sf, ok := v.Type().FieldByName(s.field)
+	if !ok || sf.PkgPath != "" {
+		return v, fmt.Errorf("reflectutil: no field %s in %v", s.field, v.Type())
+	}
+	return v.FieldByIndex(sf.Index), nil
+}
+
+// matches reports whether cur is old. The changes of Diff go through
+// pointers, so cur may be a pointer to the value old.
+func matches(cur reflect.Value, old interface{}) bool {
+	for {
+		if x, ok := convert(old, cur.Type()); ok && equal(cur, x) {
+			return true
+		}
+		if cur.Kind() != reflect.Ptr || cur.IsNil() {
+			return false
+		}
+		cur = cur.Elem()
+	}
+}
+
+// convert returns x as a value of type t.
+func convert(x interface{}, t reflect.Type) (reflect.Value, bool) {
+	if x == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+			return reflect.Zero(t), true
+		}
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(x)
+	if v.Type().AssignableTo(t) {
+		r := reflect.New(t).Elem()
+		r.Set(v)
+		return r, true
+	}
+	if isConvertible(v.Type(), t) {
+		return v.Convert(t), true
+	}
+	return reflect.Value{}, false
+}
+
+// isConvertible is reflect's ConvertibleTo restricted to conversions that
+// keep the meaning of values, such as between numeric types.
+func isConvertible(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	fk, tk := from.Kind(), to.Kind()
+	isNum := func(k reflect.Kind) bool {
+		return k >= reflect.Int && k <= reflect.Float64
+	}
+	return fk == tk || isNum(fk) && isNum(tk)
+}
+
+func set(v reflect.Value, steps []step, c *Change) error {
+	if len(steps) == 0 {
+		if x, ok := convert(c.New, v.Type()); ok {
+			v.Set(x)
+			return nil
+		}
+		if v.Kind() != reflect.Ptr {
+			return fmt.Errorf("reflectutil: can't set %v at %q to %T", v.Type(), c.Path, c.New)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return set(v.Elem(), steps, c)
+	case reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("reflectutil: nil interface at %q", c.Path)
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		if err := set(e, steps, c); err != nil {
+			return err
+		}
+		v.Set(e)
+		return nil
+	}
+	s := steps[0]
+	switch v.Kind() {
+	case reflect.Struct:
+		f, err := field(v, s)
+		if err != nil {
+			return err
+		}
+		return set(f, steps[1:], c)
+	case reflect.Slice, reflect.Array:
+		i, err := s.index()
+		if err != nil {
+			return err
+		}
+		if i >= v.Len() {
+			return fmt.Errorf("reflectutil: index out of range at %q", c.Path)
+		}
+		return set(v.Index(i), steps[1:], c)
+	case reflect.Map:
+		k, err := s.mapKey(v.Type().Key())
+		if err != nil {
+			return err
+		}
+		if len(steps) == 1 && c.Op == OpRemove {
+			v.SetMapIndex(k, reflect.Value{})
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		// map entries aren't addressable: change a copy and store it back
+		e := reflect.New(v.Type().Elem()).Elem()
+		if cur := v.MapIndex(k); cur.IsValid() {
+			e.Set(cur)
+		}
+		if err = set(e, steps[1:], c); err != nil {
+			return err
+		}
+		v.SetMapIndex(k, e)
+		return nil
+	}
+	return fmt.Errorf("reflectutil: can't step into %v at %q", v.Type(), c.Path)
+}
+
+// -----------------------------------------------------------------------------
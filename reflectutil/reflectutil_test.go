@@ -0,0 +1,164 @@
+package reflectutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type port struct {
+	Name   string
+	Number int
+}
+
+type spec struct {
+	Replicas *int
+	Ports    []port
+	Labels   map[string]string
+	Limits   map[int]port
+	Updated  time.Time
+	Any      interface{}
+	Tags     []string
+	hidden   int
+}
+
+func intPtr(n int) *int { return &n }
+
+func newSpec() *spec {
+	return &spec{
+		Replicas: intPtr(1),
+		Ports:    []port{{"http", 80}, {"grpc", 9000}},
+		Labels:   map[string]string{"env": "dev", "team": "cache"},
+		Limits:   map[int]port{1: {"a", 1}},
+		Updated:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Any:      "x",
+		Tags:     []string{"a"},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old, new := newSpec(), newSpec()
+	*new.Replicas = 3
+	new.Ports[1].Number = 9090
+	new.Labels["env"] = "prod"
+	delete(new.Labels, "team")
+	new.Labels["owner"] = "ops"
+	new.Limits[1] = port{"a", 2}
+	new.Updated = old.Updated.In(time.FixedZone("X", 3600)) // same instant
+	new.Any = 42
+	new.Tags = append(new.Tags, "b")
+	new.hidden = 1
+
+	p, err := Diff(old, new)
+	if err != nil {
+		t.Fatal("Diff:", err)
+	}
+	want := Patch{
+		{Op: OpUpdate, Path: "Replicas", Old: 1, New: 3},
+		{Op: OpUpdate, Path: "Ports[1].Number", Old: 9000, New: 9090},
+		{Op: OpUpdate, Path: `Labels["env"]`, Old: "dev", New: "prod"},
+		{Op: OpAdd, Path: `Labels["owner"]`, New: "ops"},
+		{Op: OpRemove, Path: `Labels["team"]`, Old: "cache"},
+		{Op: OpUpdate, Path: "Limits[1].Number", Old: 1, New: 2},
+		{Op: OpUpdate, Path: "Any", Old: "x", New: 42},
+		{Op: OpUpdate, Path: "Tags", Old: []string{"a"}, New: []string{"a", "b"}},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("Diff:\n%v\nwant\n%v", p, want)
+	}
+
+	if p, err = Diff(1, 1); err != nil || p != nil {
+		t.Fatal("Diff equal:", p, err)
+	}
+	if p, err = Diff(1, 2); err != nil || len(p) != 1 || p[0].Path != "" {
+		t.Fatal("Diff root:", p, err)
+	}
+	if _, err = Diff(1, "1"); err == nil {
+		t.Fatal("Diff of different types: no error")
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	old, new := newSpec(), newSpec()
+	*new.Replicas = 3
+	new.Ports[0].Name = "https"
+	new.Labels["env"] = "prod"
+	delete(new.Labels, "team")
+	new.Limits[2] = port{"b", 2}
+	new.Limits[1] = port{"a", 5}
+	new.Any = 42
+	new.Tags = nil
+	p, err := Diff(old, new)
+	if err != nil {
+		t.Fatal("Diff:", err)
+	}
+
+	dst := newSpec()
+	if err = ApplyPatch(dst, p); err != nil {
+		t.Fatal("ApplyPatch:", err)
+	}
+	if !reflect.DeepEqual(dst, new) {
+		t.Fatalf("ApplyPatch: %+v", dst)
+	}
+
+	// a patch computed from a stale copy
+	if err = ApplyPatch(dst, p); err == nil {
+		t.Fatal("ApplyPatch twice: no conflict")
+	} else if _, ok := err.(*ConflictError); !ok {
+		t.Fatal("ApplyPatch twice:", err)
+	}
+	if *dst.Replicas != 3 {
+		t.Fatal("conflicting patch partly applied")
+	}
+
+	// nil pointers and maps are allocated
+	var empty spec
+	err = ApplyPatch(&empty, Patch{
+		{Op: OpUpdate, Path: "Replicas", Old: (*int)(nil), New: intPtr(2)},
+		{Op: OpAdd, Path: `Labels["a \"b\""]`, New: "c"},
+	})
+	if err != nil || *empty.Replicas != 2 || empty.Labels[`a "b"`] != "c" {
+		t.Fatal("ApplyPatch to empty:", err, empty)
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	old, new := newSpec(), newSpec()
+	*new.Replicas = 3
+	new.Ports[1].Number = 9090
+	p, err := Diff(old, new)
+	if err != nil {
+		t.Fatal("Diff:", err)
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal("Marshal:", err)
+	}
+	var decoded Patch
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	if decoded[0].Op != OpUpdate || decoded[0].New != 3.0 {
+		t.Fatal("decoded:", decoded)
+	}
+	if err = ApplyPatch(old, decoded); err != nil {
+		t.Fatal("ApplyPatch:", err)
+	}
+	if *old.Replicas != 3 || old.Ports[1].Number != 9090 {
+		t.Fatal("ApplyPatch:", *old.Replicas, old.Ports)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	steps, err := parsePath(`A.B[3]["x]\"y"].C`)
+	want := []step{{field: "A"}, {field: "B"}, {key: "3"}, {key: `x]"y`}, {field: "C"}}
+	if err != nil || !reflect.DeepEqual(steps, want) {
+		t.Fatal("parsePath:", steps, err)
+	}
+	for _, path := range []string{`A[`, `A["x`, `A["x"`, `A..B`, `A.`} {
+		if _, err = parsePath(path); err == nil {
+			t.Fatal("parsePath: no error for", path)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package reqid
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// --------------------------------------------------------------------
+
+// ID is a 12 bytes sortable, globally unique identifier:
+//
+//	6 bytes unix time in milliseconds (big endian)
+//	3 bytes machine id (hash of the host name and the process id)
+//	3 bytes counter, starting at a random value
+//
+// Its string form is the base32 (extended hex alphabet, lower case, no
+// padding) encoding of the bytes, so the lexical order of IDs follows their
+// creation time.
+type ID [12]byte
+
+// ErrInvalidID is returned when parsing a malformed ID.
+var ErrInvalidID = errors.New("reqid: invalid id")
+
+const idLen = 20 // base32 length of 12 bytes without padding
+
+var (
+	idEncoding = base32.NewEncoding("0123456789abcdefghijklmnopqrstuv").WithPadding(base32.NoPadding)
+	machineID  = readMachineID()
+	idCounter  = randUint32()
+)
+
+func readMachineID() (id [3]byte) {
+	h := fnv.New32a()
+	host, _ := os.Hostname()
+	h.Write([]byte(host))
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(os.Getpid()))
+	h.Write(b[:])
+	sum := h.Sum32()
+	id[0], id[1], id[2] = byte(sum>>16), byte(sum>>8), byte(sum)
+	return
+}
+
+func randUint32() uint32 {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return uint32(time.Now().UnixNano())
+	}
+	return binary.BigEndian.Uint32(b[:])
+}
+
+// NewID generates a new ID.
+func NewID() ID {
+	return newIDAt(time.Now())
+}
+
+func newIDAt(t time.Time) (id ID) {
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+	id[0], id[1] = byte(ms>>40), byte(ms>>32)
+	binary.BigEndian.PutUint32(id[2:], uint32(ms))
+	copy(id[6:9], machineID[:])
+	n := atomic.AddUint32(&idCounter, 1)
+	id[9], id[10], id[11] = byte(n>>16), byte(n>>8), byte(n)
+	return
+}
+
+// ParseID parses the string form of an ID.
+func ParseID(s string) (id ID, err error) {
+	if len(s) != idLen {
+		return id, ErrInvalidID
+	}
+	n, err := idEncoding.Decode(id[:], []byte(s))
+	if err != nil || n != len(id) {
+		return ID{}, ErrInvalidID
+	}
+	return
+}
+
+// String returns the string form of id.
+func (id ID) String() string {
+	return idEncoding.EncodeToString(id[:])
+}
+
+// Time returns the creation time of id, in milliseconds precision.
+func (id ID) Time() time.Time {
+	ms := uint64(id[0])<<40 | uint64(id[1])<<32 | uint64(binary.BigEndian.Uint32(id[2:]))
+	return time.Unix(int64(ms/1000), int64(ms%1000)*int64(time.Millisecond))
+}
+
+// Machine returns the machine id part of id.
+func (id ID) Machine() [3]byte {
+	return [3]byte{id[6], id[7], id[8]}
+}
+
+// Counter returns the counter part of id.
+func (id ID) Counter() uint32 {
+	return uint32(id[9])<<16 | uint32(id[10])<<8 | uint32(id[11])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *ID) UnmarshalText(text []byte) (err error) {
+	*id, err = ParseID(string(text))
+	return
+}
+
+// --------------------------------------------------------------------
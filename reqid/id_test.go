@@ -0,0 +1,57 @@
+package reqid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestID(t *testing.T) {
+	now := time.Unix(1700000000, 123*int64(time.Millisecond))
+	id := newIDAt(now)
+	s := id.String()
+	if len(s) != 20 {
+		t.Fatal("String:", s)
+	}
+	id2, err := ParseID(s)
+	if err != nil || id2 != id {
+		t.Fatal("ParseID:", id2, err)
+	}
+	if !id.Time().Equal(now) {
+		t.Fatal("Time:", id.Time(), now)
+	}
+	if id.Machine() != machineID {
+		t.Fatal("Machine:", id.Machine())
+	}
+	if later := newIDAt(now.Add(time.Millisecond)).String(); later <= s {
+		t.Fatal("ids aren't sortable:", s, later)
+	}
+	if _, err = ParseID("not an id"); err != ErrInvalidID {
+		t.Fatal("ParseID invalid:", err)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	seen := make(map[ID]bool)
+	for i := 0; i < 10000; i++ {
+		id := NewID()
+		if seen[id] {
+			t.Fatal("duplicated id:", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestNewContextWith(t *testing.T) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	ctx := NewContextWith(req.Context(), w, req)
+	reqid, ok := FromContext(ctx)
+	if !ok || reqid != w.Header().Get("X-Reqid") {
+		t.Fatal("FromContext:", reqid, ok)
+	}
+	if _, err := ParseID(reqid); err != nil {
+		t.Fatal("reqid isn't an ID:", reqid)
+	}
+}
@@ -0,0 +1,300 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package streamx builds io.Reader and io.Writer pipelines out of common
+// transforms (compression, hashing, throttling, progress reporting, size
+// limiting), with a single Close that closes every stage in order:
+//
+//	w, err := streamx.NewWriter(f).
+//		Limit(maxSize).
+//		Hash(sha).
+//		Gzip(gzip.DefaultCompression).
+//		Progress(report).
+//		Build()
+//
+// Stages are listed in the order the data flows through them: above, the
+// bytes written to w are counted against maxSize and hashed, then
+// compressed, and the compressed bytes are reported and written to f.
+package streamx
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+
+	"github.com/qiniu/x/iox"
+)
+
+// ErrLimitExceeded is returned when a stream is longer than the limit of
+// its Limit stage.
+var ErrLimitExceeded = iox.ErrLimitExceeded
+
+// A ProgressFunc is called with the number of bytes that went through a
+// Progress stage so far.
+type ProgressFunc = func(total int64)
+
+// -----------------------------------------------------------------------------
+
+// A WriteStage wraps the writer w of the next stage. The Close of the
+// returned writer flushes the stage but must not close w.
+type WriteStage = func(w io.Writer) (io.WriteCloser, error)
+
+// WriterBuilder builds a writer pipeline.
+type WriterBuilder struct {
+	dst    io.Writer
+	stages []WriteStage
+}
+
+// NewWriter starts a pipeline that ends in dst. If dst is an io.Closer, it is
+// closed by the Close of the pipeline, after all the stages.
+func NewWriter(dst io.Writer) *WriterBuilder {
+	return &WriterBuilder{dst: dst}
+}
+
+// Then appends a custom stage, such as a zstd encoder.
+func (p *WriterBuilder) Then(stage WriteStage) *WriterBuilder {
+	p.stages = append(p.stages, stage)
+	return p
+}
+
+// Gzip appends a gzip compression stage of the given level.
+func (p *WriterBuilder) Gzip(level int) *WriterBuilder {
+	return p.Then(func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriterLevel(w, level)
+	})
+}
+
+// Hash appends a stage that hashes the bytes by h.
+func (p *WriterBuilder) Hash(h hash.Hash) *WriterBuilder {
+	return p.Then(func(w io.Writer) (io.WriteCloser, error) {
+		return nopWriteCloser{io.MultiWriter(h, w)}, nil
+	})
+}
+
+// Limit appends a stage that fails with ErrLimitExceeded past n bytes.
+func (p *WriterBuilder) Limit(n int64) *WriterBuilder {
+	return p.Then(func(w io.Writer) (io.WriteCloser, error) {
+		return nopWriteCloser{iox.NewLimitWriter(w, n)}, nil
+	})
+}
+
+// Throttle appends a stage that throttles the bytes by the limiters.
+func (p *WriterBuilder) Throttle(ctx context.Context, lims ...*iox.Limiter) *WriterBuilder {
+	return p.Then(func(w io.Writer) (io.WriteCloser, error) {
+		return nopWriteCloser{iox.NewThrottledWriter(ctx, w, lims...)}, nil
+	})
+}
+
+// Progress appends a stage that reports the bytes written through it.
+func (p *WriterBuilder) Progress(fn ProgressFunc) *WriterBuilder {
+	return p.Then(func(w io.Writer) (io.WriteCloser, error) {
+		return nopWriteCloser{&progressWriter{w: w, fn: fn}}, nil
+	})
+}
+
+// Build builds the pipeline. On error, the stages built so far are closed
+// but dst is not.
+func (p *WriterBuilder) Build() (io.WriteCloser, error) {
+	w := p.dst
+	closers := make([]io.Closer, 0, len(p.stages)+1)
+	if c, ok := p.dst.(io.Closer); ok {
+		closers = append(closers, c)
+	}
+	base := len(closers)
+	for i := len(p.stages) - 1; i >= 0; i-- {
+		sw, err := p.stages[i](w)
+		if err != nil {
+			iox.MultiCloser(closers[base:]).Close()
+			return nil, err
+		}
+		closers = append(closers, sw)
+		w = sw
+	}
+	// MultiCloser closes in reverse: the first stage first, dst last.
+	return &pipeWriter{Writer: w, closers: closers}, nil
+}
+
+type pipeWriter struct {
+	io.Writer
+	closers iox.MultiCloser
+	closed  bool
+}
+
+func (p *pipeWriter) Close() error {
+	if p.closed {
+		return errClosed
+	}
+	p.closed = true
+	return p.closers.Close()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+type progressWriter struct {
+	w     io.Writer
+	fn    ProgressFunc
+	total int64
+}
+
+func (p *progressWriter) Write(b []byte) (n int, err error) {
+	n, err = p.w.Write(b)
+	if n > 0 {
+		p.total += int64(n)
+		p.fn(p.total)
+	}
+	return
+}
+
+var errClosed = errors.New("streamx: pipeline already closed")
+
+// -----------------------------------------------------------------------------
+
+// A ReadStage wraps the reader r of the previous stage. The Close of the
+// returned reader releases the stage but must not close r.
+type ReadStage = func(r io.Reader) (io.ReadCloser, error)
+
+// ReaderBuilder builds a reader pipeline.
+type ReaderBuilder struct {
+	src    io.Reader
+	stages []ReadStage
+}
+
+// NewReader starts a pipeline that reads from src. If src is an io.Closer,
+// it is closed by the Close of the pipeline, after all the stages.
+func NewReader(src io.Reader) *ReaderBuilder {
+	return &ReaderBuilder{src: src}
+}
+
+// Then appends a custom stage, such as a zstd decoder.
+func (p *ReaderBuilder) Then(stage ReadStage) *ReaderBuilder {
+	p.stages = append(p.stages, stage)
+	return p
+}
+
+// Gunzip appends a gzip decompression stage.
+func (p *ReaderBuilder) Gunzip() *ReaderBuilder {
+	return p.Then(func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	})
+}
+
+// Hash appends a stage that hashes the bytes by h.
+func (p *ReaderBuilder) Hash(h hash.Hash) *ReaderBuilder {
+	return p.Then(func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(io.TeeReader(r, h)), nil
+	})
+}
+
+// Limit appends a stage that fails with ErrLimitExceeded if the stream is
+// longer than n bytes. Unlike io.LimitReader, it never truncates silently.
+func (p *ReaderBuilder) Limit(n int64) *ReaderBuilder {
+	return p.Then(func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(&limitReader{r: r, n: n}), nil
+	})
+}
+
+// Throttle appends a stage that throttles the bytes by the limiters.
+func (p *ReaderBuilder) Throttle(ctx context.Context, lims ...*iox.Limiter) *ReaderBuilder {
+	return p.Then(func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(iox.NewThrottledReader(ctx, r, lims...)), nil
+	})
+}
+
+// Progress appends a stage that reports the bytes read through it.
+func (p *ReaderBuilder) Progress(fn ProgressFunc) *ReaderBuilder {
+	return p.Then(func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(&progressReader{r: r, fn: fn}), nil
+	})
+}
+
+// Build builds the pipeline. On error, the stages built so far are closed
+// but src is not.
+func (p *ReaderBuilder) Build() (io.ReadCloser, error) {
+	r := p.src
+	closers := make([]io.Closer, 0, len(p.stages)+1)
+	if c, ok := p.src.(io.Closer); ok {
+		closers = append(closers, c)
+	}
+	base := len(closers)
+	for _, stage := range p.stages {
+		sr, err := stage(r)
+		if err != nil {
+			iox.MultiCloser(closers[base:]).Close()
+			return nil, err
+		}
+		closers = append(closers, sr)
+		r = sr
+	}
+	// MultiCloser closes in reverse: the last stage first, src last.
+	return &pipeReader{Reader: r, closers: closers}, nil
+}
+
+type pipeReader struct {
+	io.Reader
+	closers iox.MultiCloser
+	closed  bool
+}
+
+func (p *pipeReader) Close() error {
+	if p.closed {
+		return errClosed
+	}
+	p.closed = true
+	return p.closers.Close()
+}
+
+type limitReader struct {
+	r io.Reader
+	n int64 // bytes left
+}
+
+func (p *limitReader) Read(b []byte) (n int, err error) {
+	if p.n < 0 {
+		return 0, ErrLimitExceeded
+	}
+	if int64(len(b)) > p.n+1 {
+		b = b[:p.n+1] // one more byte to detect an overflow
+	}
+	n, err = p.r.Read(b)
+	if p.n -= int64(n); p.n < 0 {
+		return n + int(p.n), ErrLimitExceeded
+	}
+	return
+}
+
+type progressReader struct {
+	r     io.Reader
+	fn    ProgressFunc
+	total int64
+}
+
+func (p *progressReader) Read(b []byte) (n int, err error) {
+	n, err = p.r.Read(b)
+	if n > 0 {
+		p.total += int64(n)
+		p.fn(p.total)
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
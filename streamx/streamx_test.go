@@ -0,0 +1,96 @@
+package streamx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type closeRecorder struct {
+	bytes.Buffer
+	closed *[]string
+	name   string
+}
+
+func (p *closeRecorder) Close() error {
+	*p.closed = append(*p.closed, p.name)
+	return errors.New(p.name + " close error")
+}
+
+func TestWriter(t *testing.T) {
+	var closed []string
+	dst := &closeRecorder{closed: &closed, name: "dst"}
+	plain, packed := sha1.New(), int64(0)
+	w, err := NewWriter(dst).
+		Limit(1000).
+		Hash(plain).
+		Gzip(gzip.BestSpeed).
+		Progress(func(n int64) { packed = n }).
+		Build()
+	if err != nil {
+		t.Fatal("Build:", err)
+	}
+	data := strings.Repeat("hello ", 100)
+	if _, err = io.WriteString(w, data); err != nil {
+		t.Fatal("Write:", err)
+	}
+	if err = w.Close(); err == nil || err.Error() != "dst close error" || len(closed) != 1 {
+		t.Fatal("Close:", err, closed)
+	}
+	if packed != int64(dst.Len()) || packed == 0 {
+		t.Fatal("Progress:", packed, dst.Len())
+	}
+	sum := sha1.Sum([]byte(data))
+	if !bytes.Equal(plain.Sum(nil), sum[:]) {
+		t.Fatal("Hash mismatch")
+	}
+
+	r, err := NewReader(bytes.NewReader(dst.Bytes())).Gunzip().Limit(600).Build()
+	if err != nil {
+		t.Fatal("reader Build:", err)
+	}
+	if got, err := ioutil.ReadAll(r); err != nil || string(got) != data {
+		t.Fatal("read back:", err)
+	}
+	r.Close()
+	if r.Close() == nil {
+		t.Fatal("double Close not reported")
+	}
+}
+
+func TestLimit(t *testing.T) {
+	w, _ := NewWriter(ioutil.Discard).Limit(4).Build()
+	if n, err := w.Write([]byte("hello")); n != 4 || err != ErrLimitExceeded {
+		t.Fatal("writer Limit:", n, err)
+	}
+	r, _ := NewReader(strings.NewReader("hello")).Limit(4).Build()
+	if got, err := ioutil.ReadAll(r); err != ErrLimitExceeded || string(got) != "hell" {
+		t.Fatalf("reader Limit: %q, %v", got, err)
+	}
+	r, _ = NewReader(strings.NewReader("hell")).Limit(4).Build()
+	if got, err := ioutil.ReadAll(r); err != nil || string(got) != "hell" {
+		t.Fatalf("reader at the limit: %q, %v", got, err)
+	}
+	if _, err := NewReader(strings.NewReader("not gzip")).Gunzip().Build(); err == nil {
+		t.Fatal("Gunzip of garbage")
+	}
+}
+
+func TestCloseOrder(t *testing.T) {
+	var closed []string
+	stage := func(name string) WriteStage {
+		return func(w io.Writer) (io.WriteCloser, error) {
+			return &closeRecorder{closed: &closed, name: name}, nil
+		}
+	}
+	w, _ := NewWriter(&closeRecorder{closed: &closed, name: "dst"}).Then(stage("a")).Then(stage("b")).Build()
+	err := w.Close()
+	if strings.Join(closed, ",") != "a,b,dst" || !strings.Contains(err.Error(), "a close error") {
+		t.Fatal("close order:", closed, err)
+	}
+}
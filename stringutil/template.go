@@ -0,0 +1,200 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package stringutil provides string helpers, such as the expansion of
+// named placeholders.
+package stringutil
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// Missing is the policy of a template expansion for keys without a value.
+type Missing int
+
+const (
+	// MissingError fails the expansion with a *MissingKeyError.
+	MissingError Missing = iota
+
+	// MissingKeep keeps the placeholder as is.
+	MissingKeep
+
+	// MissingEmpty expands the placeholder to the empty string.
+	MissingEmpty
+)
+
+// MissingKeyError is returned by the MissingError policy.
+type MissingKeyError struct {
+	Key string
+}
+
+func (e *MissingKeyError) Error() string {
+	return "stringutil: no value for key " + strconv.Quote(e.Key)
+}
+
+// ErrSyntax is returned for an unterminated or empty placeholder.
+var ErrSyntax = errors.New("stringutil: invalid placeholder")
+
+// -----------------------------------------------------------------------------
+
+// Template is a compiled template. A template is text with placeholders
+// ${name}; "$$" stands for a single "$", and a "$" followed by anything else
+// is kept literally. A Template is safe for concurrent use.
+type Template struct {
+	text  string
+	parts []part
+	size  int // of the literal text
+}
+
+type part struct {
+	lit string // literal text, when key == ""
+	key string
+	raw string // the placeholder as written, for MissingKeep
+}
+
+// Compile compiles the template tmpl.
+func Compile(tmpl string) (*Template, error) {
+	t := &Template{text: tmpl}
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			t.parts = append(t.parts, part{lit: lit.String()})
+			t.size += lit.Len()
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(tmpl); {
+		pos := strings.IndexByte(tmpl[i:], '$')
+		if pos < 0 {
+			lit.WriteString(tmpl[i:])
+			break
+		}
+		lit.WriteString(tmpl[i : i+pos])
+		i += pos
+		switch {
+		case strings.HasPrefix(tmpl[i:], "$$"):
+			lit.WriteByte('$')
+			i += 2
+		case strings.HasPrefix(tmpl[i:], "${"):
+			end := strings.IndexByte(tmpl[i+2:], '}')
+			if end <= 0 {
+				return nil, ErrSyntax
+			}
+			flush()
+			key := tmpl[i+2 : i+2+end]
+			t.parts = append(t.parts, part{key: key, raw: tmpl[i : i+3+end]})
+			i += 3 + end
+		default:
+			lit.WriteByte('$')
+			i++
+		}
+	}
+	flush()
+	return t, nil
+}
+
+// MustCompile is like Compile but panics if tmpl is invalid.
+func MustCompile(tmpl string) *Template {
+	t, err := Compile(tmpl)
+	if err != nil {
+		panic(err.Error() + " in " + strconv.Quote(tmpl))
+	}
+	return t
+}
+
+// String returns the source text of t.
+func (t *Template) String() string {
+	return t.text
+}
+
+// Keys returns the keys of the placeholders of t, in order of appearance,
+// with duplicates.
+func (t *Template) Keys() []string {
+	var keys []string
+	for _, p := range t.parts {
+		if p.key != "" {
+			keys = append(keys, p.key)
+		}
+	}
+	return keys
+}
+
+// Expand expands the placeholders of t with the values of vars.
+func (t *Template) Expand(vars map[string]string, missing Missing) (string, error) {
+	b, err := t.appendExpand(make([]byte, 0, t.size+16*len(t.parts)), func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}, missing)
+	return string(b), err
+}
+
+// ExpandFunc expands the placeholders of t with the values returned by
+// lookup.
+func (t *Template) ExpandFunc(lookup func(key string) (string, bool), missing Missing) (string, error) {
+	b, err := t.appendExpand(make([]byte, 0, t.size+16*len(t.parts)), lookup, missing)
+	return string(b), err
+}
+
+// AppendExpand appends the expansion of t with the values returned by lookup
+// to dst. It doesn't allocate if dst has enough capacity.
+func (t *Template) AppendExpand(dst []byte, lookup func(key string) (string, bool), missing Missing) ([]byte, error) {
+	return t.appendExpand(dst, lookup, missing)
+}
+
+func (t *Template) appendExpand(dst []byte, lookup func(key string) (string, bool), missing Missing) ([]byte, error) {
+	for _, p := range t.parts {
+		if p.key == "" {
+			dst = append(dst, p.lit...)
+			continue
+		}
+		if v, ok := lookup(p.key); ok {
+			dst = append(dst, v...)
+			continue
+		}
+		switch missing {
+		case MissingKeep:
+			dst = append(dst, p.raw...)
+		case MissingEmpty:
+		default:
+			return dst, &MissingKeyError{Key: p.key}
+		}
+	}
+	return dst, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// Expand expands the placeholders ${name} of tmpl with the values of vars,
+// failing for keys without a value. See Template for the syntax.
+func Expand(tmpl string, vars map[string]string) (string, error) {
+	return ExpandWith(tmpl, vars, MissingError)
+}
+
+// ExpandWith is like Expand, with the missing policy for keys without a
+// value.
+func ExpandWith(tmpl string, vars map[string]string, missing Missing) (string, error) {
+	t, err := Compile(tmpl)
+	if err != nil {
+		return "", err
+	}
+	return t.Expand(vars, missing)
+}
+
+// -----------------------------------------------------------------------------
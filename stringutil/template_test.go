@@ -0,0 +1,55 @@
+package stringutil
+
+import (
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{"name": "world", "n": "3"}
+	cases := []struct {
+		tmpl    string
+		missing Missing
+		want    string
+		err     bool
+	}{
+		{"hello ${name}", MissingError, "hello world", false},
+		{"${n}${n}$$${name}$", MissingError, "33$world$", false},
+		{"cost $5 ${none}", MissingKeep, "cost $5 ${none}", false},
+		{"[${none}]", MissingEmpty, "[]", false},
+		{"[${none}]", MissingError, "", true},
+		{"${unterminated", MissingError, "", true},
+		{"${}", MissingError, "", true},
+		{"", MissingError, "", false},
+	}
+	for _, c := range cases {
+		got, err := ExpandWith(c.tmpl, vars, c.missing)
+		if (err != nil) != c.err || (err == nil && got != c.want) {
+			t.Fatalf("ExpandWith(%q, %d) = %q, %v", c.tmpl, c.missing, got, err)
+		}
+	}
+	if _, err := Expand("${x}", nil); err == nil || err.(*MissingKeyError).Key != "x" {
+		t.Fatal("Expand:", err)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl := MustCompile("/v1/${bucket}/${key}?v=${bucket}")
+	if keys := tmpl.Keys(); len(keys) != 3 || keys[1] != "key" {
+		t.Fatal("Keys:", keys)
+	}
+	vars := map[string]string{"bucket": "<bucket>", "key": "<key>"}
+	lookup := func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = tmpl.AppendExpand(buf[:0], lookup, MissingError)
+	})
+	if string(buf) != "/v1/<bucket>/<key>?v=<bucket>" || allocs != 0 {
+		t.Fatalf("AppendExpand = %q (%v allocs)", buf, allocs)
+	}
+	if s, _ := tmpl.ExpandFunc(lookup, MissingError); s != string(buf) || tmpl.String() != "/v1/${bucket}/${key}?v=${bucket}" {
+		t.Fatal("ExpandFunc:", s)
+	}
+}
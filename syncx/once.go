@@ -0,0 +1,144 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package syncx provides synchronization primitives that complement package
+// sync.
+package syncx
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// -----------------------------------------------------------------------------
+
+// Policy tells what a OnceError does with a failed init.
+type Policy int
+
+const (
+	// CacheError memoizes the error too: Get returns it until Reset.
+	CacheError Policy = iota
+
+	// RetryError memoizes nothing on error: the next Get calls init again.
+	RetryError
+)
+
+// PanicError is returned to the callers that were waiting for an init that
+// panicked. The panic itself is raised in the goroutine that ran init, and
+// is never memoized.
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("syncx: init panicked: %v", e.Value)
+}
+
+type onceCall[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// OnceError lazily runs an init function and memoizes its result, for lazy
+// singletons such as shared clients and loaded configs. Concurrent callers
+// of Get share a single call of init. A OnceError is safe for concurrent
+// use.
+type OnceError[T any] struct {
+	init   func() (T, error)
+	policy Policy
+
+	res  atomic.Value // *onceCall[T], the memoized call
+	mu   sync.Mutex
+	gen  uint64 // incremented by Reset
+	call *onceCall[T]
+}
+
+// NewOnceError returns a OnceError of init, which handles the errors of
+// init according to policy.
+func NewOnceError[T any](init func() (T, error), policy Policy) *OnceError[T] {
+	return &OnceError[T]{init: init, policy: policy}
+}
+
+// Get returns the memoized result, calling init if there is none.
+func (o *OnceError[T]) Get() (T, error) {
+	if r := o.memoized(); r != nil {
+		return r.val, r.err
+	}
+	o.mu.Lock()
+	if r := o.memoized(); r != nil {
+		o.mu.Unlock()
+		return r.val, r.err
+	}
+	if c := o.call; c != nil {
+		o.mu.Unlock()
+		<-c.done
+		return c.val, c.err
+	}
+	c := &onceCall[T]{done: make(chan struct{})}
+	o.call = c
+	gen := o.gen
+	o.mu.Unlock()
+
+	normal := false
+	defer func() {
+		if !normal {
+			c.err = &PanicError{Value: recover()}
+			o.finish(c, gen, false)
+			panic(c.err.(*PanicError).Value)
+		}
+	}()
+	c.val, c.err = o.init()
+	normal = true
+	o.finish(c, gen, c.err == nil || o.policy == CacheError)
+	return c.val, c.err
+}
+
+func (o *OnceError[T]) finish(c *onceCall[T], gen uint64, memoize bool) {
+	o.mu.Lock()
+	if o.call == c {
+		o.call = nil
+	}
+	if memoize && gen == o.gen {
+		o.res.Store(c)
+	}
+	o.mu.Unlock()
+	close(c.done)
+}
+
+func (o *OnceError[T]) memoized() *onceCall[T] {
+	r, _ := o.res.Load().(*onceCall[T])
+	return r
+}
+
+// Done reports whether a result is memoized.
+func (o *OnceError[T]) Done() bool {
+	return o.memoized() != nil
+}
+
+// Reset forgets the memoized result, so that the next Get calls init again.
+// The result of an init in progress is returned to its callers but not
+// memoized.
+func (o *OnceError[T]) Reset() {
+	o.mu.Lock()
+	o.res.Store((*onceCall[T])(nil))
+	o.gen++
+	o.call = nil
+	o.mu.Unlock()
+}
+
+// -----------------------------------------------------------------------------
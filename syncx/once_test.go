@@ -0,0 +1,84 @@
+package syncx
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOnceError(t *testing.T) {
+	var calls int32
+	o := NewOnceError(func() (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}, CacheError)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := o.Get(); v != 1 || err != nil {
+				t.Error("Get:", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 || !o.Done() {
+		t.Fatal("init called", calls, "times")
+	}
+	o.Reset()
+	if v, _ := o.Get(); v != 2 {
+		t.Fatal("Get after Reset:", v)
+	}
+}
+
+func TestOnceErrorPolicy(t *testing.T) {
+	errInit := errors.New("init failed")
+	fail := true
+	newInit := func() func() (string, error) {
+		return func() (string, error) {
+			if fail {
+				return "", errInit
+			}
+			return "ok", nil
+		}
+	}
+	cached := NewOnceError(newInit(), CacheError)
+	retried := NewOnceError(newInit(), RetryError)
+	if _, err := cached.Get(); err != errInit {
+		t.Fatal("CacheError:", err)
+	}
+	if _, err := retried.Get(); err != errInit || retried.Done() {
+		t.Fatal("RetryError:", err)
+	}
+	fail = false
+	if _, err := cached.Get(); err != errInit {
+		t.Fatal("CacheError didn't memoize the error:", err)
+	}
+	if v, err := retried.Get(); v != "ok" || err != nil {
+		t.Fatal("RetryError didn't retry:", v, err)
+	}
+}
+
+func TestOnceErrorPanic(t *testing.T) {
+	n := 0
+	o := NewOnceError(func() (int, error) {
+		if n++; n == 1 {
+			panic("boom")
+		}
+		return n, nil
+	}, CacheError)
+	func() {
+		defer func() {
+			if recover() != "boom" {
+				t.Fatal("panic not propagated")
+			}
+		}()
+		o.Get()
+	}()
+	if v, err := o.Get(); v != 2 || err != nil {
+		t.Fatal("panic memoized:", v, err)
+	}
+}
@@ -0,0 +1,382 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package taskqueue is an in-process queue of background tasks.
+//
+// Tasks run on a bounded number of workers. Among the tasks that are due,
+// higher priorities run first, and tasks of the same priority run in the order
+// they were pushed. A task may be scheduled to run later, and a failing task
+// is retried with a backoff. An optional Store persists pending tasks, so that
+// they can be restored after a restart.
+package taskqueue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qiniu/x/reqid"
+)
+
+var (
+	// ErrStopped is returned by Push after the queue is stopped.
+	ErrStopped = errors.New("taskqueue: queue stopped")
+)
+
+// Task is a unit of background work.
+type Task struct {
+	// ID identifies the task. Push assigns a unique one if it is empty.
+	ID string
+
+	// Priority of the task. Higher priorities run first.
+	Priority int
+
+	// RunAt is the earliest time the task runs. Zero means now.
+	RunAt time.Time
+
+	// MaxRetries is the number of retries after a failure. Zero means
+	// Options.MaxRetries, negative means no retry.
+	MaxRetries int
+
+	// Attempts is the number of failed runs so far.
+	Attempts int
+
+	// Payload is the data of the task, interpreted by the Handler.
+	Payload interface{}
+
+	seq   uint64
+	index int // index in the heap
+}
+
+// Handler runs a task.
+type Handler = func(ctx context.Context, t *Task) error
+
+// Store persists pending tasks. Its methods are called with no lock held,
+// possibly concurrently.
+type Store interface {
+	// Save stores t when it is pushed or rescheduled for a retry.
+	Save(t *Task) error
+
+	// Delete removes t when it succeeded or was given up.
+	Delete(t *Task) error
+
+	// Load returns the pending tasks saved before; it is called by Start.
+	Load() ([]*Task, error)
+}
+
+// Options configures a Queue.
+type Options struct {
+	// Concurrency is the maximum number of tasks running at once. Zero
+	// means 1.
+	Concurrency int
+
+	// MaxRetries is the default number of retries of a failed task.
+	MaxRetries int
+
+	// Backoff returns the delay before the retry that follows the given
+	// failed attempt (1 for the first failure). Nil means
+	// ExponentialBackoff(time.Second, time.Minute).
+	Backoff func(attempt int) time.Duration
+
+	// Store, if not nil, persists pending tasks.
+	Store Store
+
+	// OnGiveUp, if not nil, is called when a task failed its last attempt.
+	OnGiveUp func(t *Task, err error)
+}
+
+// ExponentialBackoff returns a backoff that doubles from base up to max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// byRunAt orders delayed tasks by the time they become due.
+type byRunAt []*Task
+
+func (h byRunAt) Len() int { return len(h) }
+func (h byRunAt) Less(i, j int) bool {
+	if h[i].RunAt.Equal(h[j].RunAt) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].RunAt.Before(h[j].RunAt)
+}
+func (h byRunAt) Swap(i, j int)       { h[i], h[j] = h[j], h[i]; h[i].index = i; h[j].index = j }
+func (h *byRunAt) Push(x interface{}) { t := x.(*Task); t.index = len(*h); *h = append(*h, t) }
+func (h *byRunAt) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return t
+}
+
+// byPriority orders due tasks by priority, then by push order.
+type byPriority struct{ byRunAt }
+
+func (h byPriority) Less(i, j int) bool {
+	a, b := h.byRunAt[i], h.byRunAt[j]
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.seq < b.seq
+}
+
+// Queue is a task queue.
+type Queue struct {
+	handler Handler
+	opts    Options
+
+	mu      sync.Mutex
+	delayed byRunAt
+	ready   byPriority
+	seq     uint64
+	running int
+	started bool
+	stopped bool
+
+	wake    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	nowFunc func() time.Time
+}
+
+// New creates a queue running its tasks with handler.
+func New(handler Handler, opts *Options) *Queue {
+	q := &Queue{
+		handler: handler,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		nowFunc: time.Now,
+	}
+	if opts != nil {
+		q.opts = *opts
+	}
+	if q.opts.Concurrency <= 0 {
+		q.opts.Concurrency = 1
+	}
+	if q.opts.Backoff == nil {
+		q.opts.Backoff = ExponentialBackoff(time.Second, time.Minute)
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	return q
+}
+
+// Push adds a task to the queue.
+func (q *Queue) Push(t *Task) error {
+	if t.ID == "" {
+		t.ID = reqid.NewID().String()
+	}
+	if q.opts.Store != nil {
+		if err := q.opts.Store.Save(t); err != nil {
+			return err
+		}
+	}
+	return q.push(t)
+}
+
+func (q *Queue) push(t *Task) error {
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return ErrStopped
+	}
+	q.seq++
+	t.seq = q.seq
+	heap.Push(&q.delayed, t)
+	q.mu.Unlock()
+	q.signal()
+	return nil
+}
+
+// Len returns the number of pending (not running) tasks.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.delayed) + len(q.ready.byRunAt)
+}
+
+// Running returns the number of running tasks.
+func (q *Queue) Running() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.running
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start restores the tasks of the Store, if any, and starts running tasks.
+func (q *Queue) Start() error {
+	q.mu.Lock()
+	if q.started {
+		q.mu.Unlock()
+		return errors.New("taskqueue: queue already started")
+	}
+	q.started = true
+	q.mu.Unlock()
+	if q.opts.Store != nil {
+		tasks, err := q.opts.Store.Load()
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			q.push(t)
+		}
+	}
+	go q.dispatch()
+	return nil
+}
+
+// Stop stops dispatching tasks and waits for the running ones to finish. If
+// ctx is done first, the contexts of the running tasks are canceled and Stop
+// returns ctx.Err(). Pending tasks are dropped from memory, but remain in the
+// Store.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return nil
+	}
+	q.stopped = true
+	started := q.started
+	q.mu.Unlock()
+	if started {
+		close(q.done)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) dispatch() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		q.mu.Lock()
+		if q.stopped {
+			q.mu.Unlock()
+			return
+		}
+		now := q.nowFunc()
+		for len(q.delayed) > 0 && !q.delayed[0].RunAt.After(now) {
+			heap.Push(&q.ready, heap.Pop(&q.delayed))
+		}
+		if len(q.ready.byRunAt) > 0 && q.running < q.opts.Concurrency {
+			t := heap.Pop(&q.ready).(*Task)
+			q.running++
+			q.wg.Add(1)
+			q.mu.Unlock()
+			go q.run(t)
+			continue
+		}
+		wait := time.Hour
+		if len(q.delayed) > 0 {
+			wait = q.delayed[0].RunAt.Sub(now)
+		}
+		q.mu.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-q.wake:
+		case <-timer.C:
+		case <-q.done:
+			return
+		}
+	}
+}
+
+func (q *Queue) run(t *Task) {
+	defer func() {
+		q.mu.Lock()
+		q.running--
+		q.mu.Unlock()
+		q.wg.Done()
+		q.signal()
+	}()
+	err := q.call(t)
+	if err == nil {
+		if q.opts.Store != nil {
+			q.opts.Store.Delete(t)
+		}
+		return
+	}
+	t.Attempts++
+	maxRetries := t.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = q.opts.MaxRetries
+	}
+	if t.Attempts <= maxRetries {
+		t.RunAt = q.nowFunc().Add(q.opts.Backoff(t.Attempts))
+		if q.opts.Store != nil {
+			q.opts.Store.Save(t)
+		}
+		q.push(t)
+		return
+	}
+	if q.opts.Store != nil {
+		q.opts.Store.Delete(t)
+	}
+	if q.opts.OnGiveUp != nil {
+		q.opts.OnGiveUp(t, err)
+	}
+}
+
+func (q *Queue) call(t *Task) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("taskqueue: task %s panicked: %v", t.ID, e)
+		}
+	}()
+	return q.handler(q.ctx, t)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,164 @@
+package taskqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriority(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	done := make(chan struct{})
+	q := New(func(ctx context.Context, t *Task) error {
+		mu.Lock()
+		order = append(order, t.ID)
+		n := len(order)
+		mu.Unlock()
+		if n == 4 {
+			close(done)
+		}
+		return nil
+	}, nil)
+	q.Push(&Task{ID: "low", Priority: 1})
+	q.Push(&Task{ID: "high", Priority: 9})
+	q.Push(&Task{ID: "mid1", Priority: 5})
+	q.Push(&Task{ID: "mid2", Priority: 5})
+	q.Start()
+	<-done
+	q.Stop(context.Background())
+	if got := order[0] + "," + order[1] + "," + order[2] + "," + order[3]; got != "high,mid1,mid2,low" {
+		t.Fatal("order:", got)
+	}
+}
+
+func TestDelayAndRetry(t *testing.T) {
+	var mu sync.Mutex
+	var runs []time.Time
+	gaveUp := make(chan error, 1)
+	q := New(func(ctx context.Context, t *Task) error {
+		mu.Lock()
+		runs = append(runs, time.Now())
+		mu.Unlock()
+		panic("boom")
+	}, &Options{
+		MaxRetries: 2,
+		Backoff:    func(attempt int) time.Duration { return 5 * time.Millisecond },
+		OnGiveUp:   func(t *Task, err error) { gaveUp <- err },
+	})
+	q.Start()
+	start := time.Now()
+	q.Push(&Task{RunAt: start.Add(20 * time.Millisecond)})
+	select {
+	case err := <-gaveUp:
+		if err == nil {
+			t.Fatal("OnGiveUp: nil error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("task is not given up")
+	}
+	q.Stop(context.Background())
+	if len(runs) != 3 {
+		t.Fatal("runs:", len(runs))
+	}
+	if runs[0].Sub(start) < 20*time.Millisecond {
+		t.Fatal("task ran too early:", runs[0].Sub(start))
+	}
+}
+
+type memStore struct {
+	mu    sync.Mutex
+	tasks map[string]*Task
+}
+
+func (p *memStore) Save(t *Task) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tasks[t.ID] = t
+	return nil
+}
+
+func (p *memStore) Delete(t *Task) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tasks, t.ID)
+	return nil
+}
+
+func (p *memStore) Load() (tasks []*Task, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range p.tasks {
+		tasks = append(tasks, &Task{ID: t.ID, Payload: t.Payload})
+	}
+	return
+}
+
+func TestStoreAndConcurrency(t *testing.T) {
+	store := &memStore{tasks: make(map[string]*Task)}
+	q := New(func(ctx context.Context, t *Task) error { return nil }, &Options{Store: store})
+	for i := 0; i < 3; i++ {
+		q.Push(&Task{})
+	}
+	q.Stop(context.Background()) // never started: the tasks stay in the store
+	if len(store.tasks) != 3 {
+		t.Fatal("saved tasks:", len(store.tasks))
+	}
+	if err := q.Push(&Task{}); err != ErrStopped {
+		t.Fatal("Push after Stop:", err)
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	q = New(func(ctx context.Context, t *Task) error {
+		mu.Lock()
+		if running++; running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}, &Options{Store: store, Concurrency: 2})
+	if err := q.Start(); err != nil {
+		t.Fatal("Start:", err)
+	}
+	for i := 0; i < 100 && (q.Len() > 0 || q.Running() > 0); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	q.Stop(context.Background())
+	if len(store.tasks) != 0 || maxRunning != 2 {
+		t.Fatal("remaining tasks:", len(store.tasks), "max running:", maxRunning)
+	}
+}
+
+func TestPushDuringStop(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		q := New(func(ctx context.Context, t *Task) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		}, &Options{Concurrency: 4})
+		q.Start()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if q.Push(&Task{}) == ErrStopped {
+					return
+				}
+			}
+		}()
+		time.Sleep(time.Millisecond)
+		if err := q.Stop(context.Background()); err != nil {
+			t.Fatal("Stop:", err)
+		}
+		if n := q.Running(); n != 0 {
+			t.Fatal("running after Stop:", n)
+		}
+		wg.Wait()
+	}
+}
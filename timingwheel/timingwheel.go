@@ -0,0 +1,242 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package timingwheel implements a hierarchical timing wheel.
+//
+// A TimingWheel schedules a very large number of timeouts at the cost of a
+// fixed precision (the tick): starting and stopping a timer are O(1), and a
+// single goroutine drives all timers. It suits timeouts that are mostly
+// canceled or that don't need a precise deadline, such as TTL janitors,
+// idle-connection reaping and retry scheduling.
+//
+// The wheel has 11 levels of 64 slots each. A timer is placed at the lowest
+// level whose span covers its delay, and is moved ("cascaded") down a level
+// each time the lower level wraps around, until it expires in level 0.
+package timingwheel
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	slotBits  = 6
+	numSlots  = 1 << slotBits
+	slotMask  = numSlots - 1
+	numLevels = (64 + slotBits - 1) / slotBits
+)
+
+// Timer is a timer of a TimingWheel.
+type Timer struct {
+	tw     *TimingWheel
+	expire uint64 // in ticks
+	f      func()
+
+	prev, next *Timer
+	slot       *slot // nil if not pending
+}
+
+// Stop prevents the timer from firing. It returns false if the timer has
+// already expired or been stopped.
+func (t *Timer) Stop() bool {
+	tw := t.tw
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if t.slot == nil {
+		return false
+	}
+	t.slot.remove(t)
+	tw.count--
+	return true
+}
+
+// Reset changes the timer to expire after duration d. It returns true if the
+// timer had been pending.
+func (t *Timer) Reset(d time.Duration) bool {
+	tw := t.tw
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	pending := t.slot != nil
+	if pending {
+		t.slot.remove(t)
+	} else {
+		tw.count++
+	}
+	t.expire = tw.expireOf(d)
+	tw.add(t)
+	return pending
+}
+
+type slot struct {
+	head Timer // sentinel of a circular list
+}
+
+func (s *slot) init() {
+	s.head.next, s.head.prev = &s.head, &s.head
+}
+
+func (s *slot) push(t *Timer) {
+	t.prev, t.next = s.head.prev, &s.head
+	t.prev.next, s.head.prev = t, t
+	t.slot = s
+}
+
+func (s *slot) remove(t *Timer) {
+	t.prev.next, t.next.prev = t.next, t.prev
+	t.prev, t.next, t.slot = nil, nil, nil
+}
+
+// takeAll detaches and returns the timers of the slot.
+func (s *slot) takeAll() []*Timer {
+	var ts []*Timer
+	for t := s.head.next; t != &s.head; {
+		next := t.next
+		t.prev, t.next, t.slot = nil, nil, nil
+		ts = append(ts, t)
+		t = next
+	}
+	s.init()
+	return ts
+}
+
+// -----------------------------------------------------------------------------
+
+// TimingWheel is a hierarchical timing wheel.
+type TimingWheel struct {
+	tick  time.Duration
+	start time.Time
+
+	mu     sync.Mutex
+	now    uint64 // current tick
+	count  int
+	levels [numLevels][numSlots]slot
+
+	done chan struct{}
+	once sync.Once
+}
+
+// New creates and starts a timing wheel of the given precision.
+func New(tick time.Duration) *TimingWheel {
+	tw := newWheel(tick, time.Now())
+	go tw.run()
+	return tw
+}
+
+func newWheel(tick time.Duration, start time.Time) *TimingWheel {
+	if tick <= 0 {
+		panic("timingwheel: non-positive tick")
+	}
+	tw := &TimingWheel{tick: tick, start: start, done: make(chan struct{})}
+	for l := range tw.levels {
+		for s := range tw.levels[l] {
+			tw.levels[l][s].init()
+		}
+	}
+	return tw
+}
+
+// Tick returns the precision of tw.
+func (tw *TimingWheel) Tick() time.Duration {
+	return tw.tick
+}
+
+// Len returns the number of pending timers.
+func (tw *TimingWheel) Len() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.count
+}
+
+// Stop stops the wheel: pending timers never fire.
+func (tw *TimingWheel) Stop() {
+	tw.once.Do(func() { close(tw.done) })
+}
+
+// AfterFunc waits for at least the duration d (rounded up to the tick) and
+// then calls f in its own goroutine.
+func (tw *TimingWheel) AfterFunc(d time.Duration, f func()) *Timer {
+	t := &Timer{tw: tw, f: f}
+	tw.mu.Lock()
+	t.expire = tw.expireOf(d)
+	tw.add(t)
+	tw.count++
+	tw.mu.Unlock()
+	return t
+}
+
+func (tw *TimingWheel) expireOf(d time.Duration) uint64 {
+	ticks := uint64((d + tw.tick - 1) / tw.tick)
+	if d <= 0 || ticks == 0 {
+		ticks = 1
+	}
+	return tw.now + ticks
+}
+
+// add places t according to its expiration; the caller holds the lock.
+// A cascaded timer may expire at the current tick: it then lands in the
+// level 0 slot that is about to fire.
+func (tw *TimingWheel) add(t *Timer) {
+	// the lowest level above which expire and now share all the digits
+	l := 0
+	for l < numLevels-1 && t.expire>>(slotBits*uint(l+1)) != tw.now>>(slotBits*uint(l+1)) {
+		l++
+	}
+	tw.levels[l][(t.expire>>(slotBits*uint(l)))&slotMask].push(t)
+}
+
+func (tw *TimingWheel) run() {
+	ticker := time.NewTicker(tw.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-tw.done:
+			return
+		case now := <-ticker.C:
+			tw.advanceTo(uint64(now.Sub(tw.start) / tw.tick))
+		}
+	}
+}
+
+// advanceTo moves the wheel forward to tick target, firing expired timers.
+func (tw *TimingWheel) advanceTo(target uint64) {
+	for {
+		tw.mu.Lock()
+		if tw.now >= target {
+			tw.mu.Unlock()
+			return
+		}
+		tw.now++
+		now := tw.now
+		// cascade the levels that wrapped around, from the highest one
+		l := 1
+		for l < numLevels && now&(1<<(slotBits*uint(l))-1) == 0 {
+			l++
+		}
+		for l--; l >= 1; l-- {
+			for _, t := range tw.levels[l][(now>>(slotBits*uint(l)))&slotMask].takeAll() {
+				tw.add(t)
+			}
+		}
+		expired := tw.levels[0][now&slotMask].takeAll()
+		tw.count -= len(expired)
+		tw.mu.Unlock()
+		for _, t := range expired {
+			go t.f()
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
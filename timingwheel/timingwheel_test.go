@@ -0,0 +1,86 @@
+package timingwheel
+
+import (
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCascade(t *testing.T) {
+	tw := newWheel(time.Millisecond, time.Now())
+	delays := []int{1, 2, 63, 64, 65, 100, 4095, 4096, 4097, 300000}
+	for i := 0; i < 100; i++ {
+		delays = append(delays, rand.Intn(1<<20)+1)
+	}
+	sort.Ints(delays)
+	var fired int32
+	for _, d := range delays {
+		tw.AfterFunc(time.Duration(d)*time.Millisecond, func() { atomic.AddInt32(&fired, 1) })
+	}
+	if tw.Len() != len(delays) {
+		t.Fatal("Len:", tw.Len())
+	}
+	for i, d := range delays {
+		tw.advanceTo(uint64(d - 1))
+		if n := atomic.LoadInt32(&fired); int(n) > i {
+			t.Fatalf("timer of %d ticks fired early: %d fired", d, n)
+		}
+		tw.advanceTo(uint64(d))
+		for deadline := time.Now().Add(time.Second); int(atomic.LoadInt32(&fired)) <= i; {
+			if time.Now().After(deadline) {
+				t.Fatalf("timer of %d ticks doesn't fire", d)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if tw.Len() != 0 {
+		t.Fatal("Len after firing:", tw.Len())
+	}
+}
+
+func TestStopReset(t *testing.T) {
+	tw := newWheel(time.Millisecond, time.Now())
+	var n int32
+	t1 := tw.AfterFunc(5*time.Millisecond, func() { atomic.AddInt32(&n, 1) })
+	t2 := tw.AfterFunc(5*time.Millisecond, func() { atomic.AddInt32(&n, 10) })
+	if !t1.Stop() || t1.Stop() {
+		t.Fatal("Stop")
+	}
+	if !t2.Reset(100 * time.Millisecond) {
+		t.Fatal("Reset pending timer")
+	}
+	tw.advanceTo(50)
+	if tw.Len() != 1 {
+		t.Fatal("Len:", tw.Len())
+	}
+	tw.advanceTo(100)
+	time.Sleep(10 * time.Millisecond)
+	if atomic.LoadInt32(&n) != 10 {
+		t.Fatal("fired:", n)
+	}
+}
+
+func TestAfterFunc(t *testing.T) {
+	tw := New(time.Millisecond)
+	defer tw.Stop()
+	done := make(chan time.Time, 1)
+	start := time.Now()
+	tw.AfterFunc(20*time.Millisecond, func() { done <- time.Now() })
+	select {
+	case at := <-done:
+		if at.Sub(start) < 20*time.Millisecond {
+			t.Fatal("fired too early:", at.Sub(start))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timer doesn't fire")
+	}
+}
+
+func BenchmarkAfterFuncStop(b *testing.B) {
+	tw := newWheel(time.Millisecond, time.Now())
+	for i := 0; i < b.N; i++ {
+		tw.AfterFunc(time.Duration(i%100000)*time.Millisecond, nil).Stop()
+	}
+}
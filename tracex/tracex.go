@@ -0,0 +1,281 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package tracex is a minimal tracing facade.
+//
+// Code starts spans with StartSpan and ends them with Span.End. Finished
+// spans are handed to the Exporter set by SetExporter: a Ring keeps the
+// latest ones in memory (e.g. for a debug endpoint), LogExporter writes them
+// to a log, and adapters to a full tracing stack such as OpenTelemetry only
+// need to implement Exporter. When no exporter is set, spans cost little
+// more than two time.Now calls.
+//
+// The trace ID of a span is the request ID of its context (see the reqid
+// package) if there is one, so spans and logs of a request share their ID.
+package tracex
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qiniu/x/log"
+	"github.com/qiniu/x/reqid"
+)
+
+// Attr is an attribute of a span.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// SpanData is the record of a finished span.
+type SpanData struct {
+	TraceID  string
+	SpanID   uint64
+	ParentID uint64 // 0 for a root span
+	Name     string
+	Start    time.Time
+	End      time.Time
+	Attrs    []Attr
+	Err      error
+}
+
+// Duration returns the duration of the span.
+func (s *SpanData) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+func (s *SpanData) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "span %s trace=%s id=%016x", s.Name, s.TraceID, s.SpanID)
+	if s.ParentID != 0 {
+		fmt.Fprintf(&b, " parent=%016x", s.ParentID)
+	}
+	fmt.Fprintf(&b, " dur=%v", s.Duration())
+	for _, a := range s.Attrs {
+		fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+	}
+	if s.Err != nil {
+		fmt.Fprintf(&b, " err=%q", s.Err.Error())
+	}
+	return b.String()
+}
+
+// -----------------------------------------------------------------------------
+
+// Span is a span in progress. A nil *Span is valid and does nothing. Its
+// methods are safe for concurrent use.
+type Span struct {
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+type spanKey struct{}
+
+// StartSpan starts a span named name, child of the span of ctx if there is
+// one, and returns a context carrying the new span.
+func StartSpan(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	s := &Span{data: SpanData{Name: name, SpanID: newSpanID(), Start: time.Now()}}
+	if len(attrs) > 0 {
+		s.data.Attrs = append(s.data.Attrs, attrs...)
+	}
+	if parent := FromContext(ctx); parent != nil {
+		s.data.TraceID, s.data.ParentID = parent.data.TraceID, parent.data.SpanID
+	} else if id, ok := reqid.FromContext(ctx); ok {
+		s.data.TraceID = id
+	} else {
+		s.data.TraceID = reqid.NewID().String()
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// FromContext returns the span of ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+var spanSeq uint64
+
+func init() {
+	spanSeq = rand.New(rand.NewSource(time.Now().UnixNano())).Uint64()
+}
+
+func newSpanID() uint64 {
+	for {
+		// a Weyl sequence: unique for 2^64 spans, and never 0.
+		if id := atomic.AddUint64(&spanSeq, 0x9e3779b97f4a7c15); id != 0 {
+			return id
+		}
+	}
+}
+
+// TraceID returns the trace ID of the span.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.data.TraceID
+}
+
+// SetAttr sets the attribute key of the span.
+func (s *Span) SetAttr(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, a := range s.data.Attrs {
+		if a.Key == key {
+			s.data.Attrs[i].Value = value
+			return
+		}
+	}
+	s.data.Attrs = append(s.data.Attrs, Attr{key, value})
+}
+
+// SetError records the error of the span's operation.
+func (s *Span) SetError(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Err = err
+	s.mu.Unlock()
+}
+
+// End ends the span and exports it. Calls after the first one do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	if e := exporter(); e != nil {
+		e.Export(&data)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// Exporter receives the finished spans. Export must not keep s after it
+// returns unless it owns it: each call gets its own copy of the span.
+type Exporter interface {
+	Export(s *SpanData)
+}
+
+// ExporterFunc adapts a function to the Exporter interface.
+type ExporterFunc func(s *SpanData)
+
+// Export implements the Exporter interface.
+func (f ExporterFunc) Export(s *SpanData) { f(s) }
+
+type exporterBox struct{ Exporter }
+
+var currentExporter atomic.Value // exporterBox
+
+// SetExporter sets the exporter of all spans. Nil disables exporting.
+func SetExporter(e Exporter) {
+	currentExporter.Store(exporterBox{e})
+}
+
+func exporter() Exporter {
+	b, _ := currentExporter.Load().(exporterBox)
+	return b.Exporter
+}
+
+// Tee returns an exporter exporting spans to all of exps.
+func Tee(exps ...Exporter) Exporter {
+	return ExporterFunc(func(s *SpanData) {
+		for _, e := range exps {
+			e.Export(s)
+		}
+	})
+}
+
+// LogExporter returns an exporter writing spans to l at level lvl (e.g.
+// log.Ldebug), with their trace ID as the request ID of the log entries.
+func LogExporter(l *log.Logger, lvl int) Exporter {
+	return ExporterFunc(func(s *SpanData) {
+		l.Output(s.TraceID, lvl, 2, s.String())
+	})
+}
+
+// -----------------------------------------------------------------------------
+
+// Ring is an Exporter that keeps the latest spans in memory.
+type Ring struct {
+	mu    sync.Mutex
+	spans []*SpanData
+	next  int
+	full  bool
+}
+
+// NewRing returns a ring keeping the latest n spans.
+func NewRing(n int) *Ring {
+	if n <= 0 {
+		panic("tracex: non-positive ring size")
+	}
+	return &Ring{spans: make([]*SpanData, n)}
+}
+
+// Export implements the Exporter interface.
+func (r *Ring) Export(s *SpanData) {
+	r.mu.Lock()
+	r.spans[r.next] = s
+	if r.next++; r.next == len(r.spans) {
+		r.next, r.full = 0, true
+	}
+	r.mu.Unlock()
+}
+
+// Spans returns the spans of the ring, oldest first.
+func (r *Ring) Spans() []*SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]*SpanData(nil), r.spans[:r.next]...)
+	}
+	ret := make([]*SpanData, 0, len(r.spans))
+	ret = append(ret, r.spans[r.next:]...)
+	return append(ret, r.spans[:r.next]...)
+}
+
+// Trace returns the spans of the ring of the trace traceID, oldest first.
+func (r *Ring) Trace(traceID string) []*SpanData {
+	var ret []*SpanData
+	for _, s := range r.Spans() {
+		if s.TraceID == traceID {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,63 @@
+package tracex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/qiniu/x/log"
+	"github.com/qiniu/x/reqid"
+)
+
+func TestSpans(t *testing.T) {
+	ring := NewRing(3)
+	SetExporter(ring)
+	defer SetExporter(nil)
+
+	ctx := reqid.NewContext(context.Background(), "req-1")
+	ctx, root := StartSpan(ctx, "root", Attr{"k", 1})
+	_, child := StartSpan(ctx, "child")
+	child.SetAttr("n", 1)
+	child.SetAttr("n", 2)
+	child.SetError(errors.New("boom"))
+	child.End()
+	child.End()
+	root.End()
+
+	spans := ring.Trace("req-1")
+	if len(spans) != 2 || spans[0].Name != "child" || spans[1].Name != "root" {
+		t.Fatal("spans:", spans)
+	}
+	c := spans[0]
+	if c.ParentID != spans[1].SpanID || c.SpanID == 0 || len(c.Attrs) != 1 || c.Attrs[0].Value != 2 || c.Err == nil {
+		t.Fatalf("child: %+v", c)
+	}
+	if root.TraceID() != "req-1" {
+		t.Fatal("trace id:", root.TraceID())
+	}
+
+	for i := 0; i < 4; i++ {
+		_, s := StartSpan(context.Background(), "s")
+		s.End()
+	}
+	if spans := ring.Spans(); len(spans) != 3 || spans[0].TraceID == spans[1].TraceID {
+		t.Fatal("ring:", spans)
+	}
+
+	var nilSpan *Span
+	nilSpan.SetAttr("x", 1)
+	nilSpan.End()
+}
+
+func TestLogExporter(t *testing.T) {
+	var buf bytes.Buffer
+	SetExporter(Tee(LogExporter(log.New(&buf, "", 0), log.Linfo)))
+	defer SetExporter(nil)
+	_, s := StartSpan(context.Background(), "op", Attr{"key", "v"})
+	s.End()
+	if out := buf.String(); !strings.Contains(out, "span op") || !strings.Contains(out, "key=v") {
+		t.Fatal(out)
+	}
+}
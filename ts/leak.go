@@ -0,0 +1,188 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package ts
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+
+// LeakWait is how long the leak checks wait for goroutines and files to go
+// away before reporting them as leaked: background goroutines often exit
+// shortly after the Close or cancel that stops them.
+var LeakWait = 2 * time.Second
+
+// goroutine is a goroutine of a stack dump.
+type goroutine struct {
+	id    string
+	stack string // with the header line
+}
+
+func goroutines() map[string]goroutine {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	ret := make(map[string]goroutine)
+	for _, block := range bytes.Split(buf, []byte("\n\n")) {
+		s := string(bytes.TrimSpace(block))
+		if !strings.HasPrefix(s, "goroutine ") {
+			continue
+		}
+		id := s[len("goroutine "):]
+		if pos := strings.IndexByte(id, ' '); pos >= 0 {
+			id = id[:pos]
+		}
+		ret[id] = goroutine{id: id, stack: s}
+	}
+	return ret
+}
+
+// snapshot is the set of goroutines and open files at some point.
+type snapshot struct {
+	goroutines map[string]goroutine
+	files      map[string]string // fd => target, nil if not supported
+}
+
+func takeSnapshot() snapshot {
+	return snapshot{goroutines: goroutines(), files: openFiles()}
+}
+
+// leaks returns the goroutines and open files that are not in base, nor
+// allowed by ignore, nor the current goroutine.
+func (base snapshot) leaks(ignore []string) (leaks []string) {
+	cur := takeSnapshot()
+	for id, g := range cur.goroutines {
+		if _, ok := base.goroutines[id]; ok || isIgnored(g.stack, ignore) {
+			continue
+		}
+		if strings.Contains(g.stack, "[running]") && strings.Contains(g.stack, "ts.takeSnapshot") {
+			continue // the checking goroutine itself
+		}
+		leaks = append(leaks, g.stack)
+	}
+	if base.files != nil {
+		for fd, target := range cur.files {
+			if old, ok := base.files[fd]; ok && old == target || isIgnored(target, ignore) {
+				continue
+			}
+			leaks = append(leaks, "open file "+fd+": "+target)
+		}
+	}
+	sort.Strings(leaks)
+	return
+}
+
+// defaultIgnores are the goroutines of the runtime and of the testing
+// package that come and go by themselves.
+var defaultIgnores = []string{
+	"testing.(*T).Run",
+	"testing.(*M).",
+	"testing.runTests",
+	"testing.tRunner.func",
+	"runtime.goexit0",
+	"created by runtime.gc",
+	"runtime.ReadTrace",
+	"signal.signal_recv",
+	"signal.loop",
+}
+
+func isIgnored(stack string, ignore []string) bool {
+	for _, s := range defaultIgnores {
+		if strings.Contains(stack, s) {
+			return true
+		}
+	}
+	for _, s := range ignore {
+		if strings.Contains(stack, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitLeaks returns the leaks against base that remain after LeakWait.
+func (base snapshot) waitLeaks(ignore []string) []string {
+	deadline := time.Now().Add(LeakWait)
+	for delay := time.Millisecond; ; delay *= 2 {
+		leaks := base.leaks(ignore)
+		if len(leaks) == 0 || time.Now().After(deadline) {
+			return leaks
+		}
+		if delay > 100*time.Millisecond {
+			delay = 100 * time.Millisecond
+		}
+		time.Sleep(delay)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// VerifyNoLeaks takes a snapshot of the goroutines (and, on Linux, of the
+// open file descriptors) and returns a function that fails t if there are
+// goroutines or open files that were not in the snapshot:
+//
+//	func TestWatcher(t *testing.T) {
+//		defer ts.VerifyNoLeaks(t)()
+//		...
+//	}
+//
+// Goroutines whose stack, and files whose path, contain one of the ignore
+// strings are allowed (e.g. "mypkg.(*Pool).janitor").
+func VerifyNoLeaks(t testing.TB, ignore ...string) func() {
+	base := takeSnapshot()
+	return func() {
+		t.Helper()
+		if leaks := base.waitLeaks(ignore); len(leaks) > 0 {
+			t.Errorf("found %d leaks:\n\n%s", len(leaks), strings.Join(leaks, "\n\n"))
+		}
+	}
+}
+
+// VerifyTestMain runs the tests of m and then checks that they leaked no
+// goroutines or open files, as VerifyNoLeaks does. It exits the process
+// with the status of the tests, or 1 if something leaked:
+//
+//	func TestMain(m *testing.M) {
+//		ts.VerifyTestMain(m)
+//	}
+func VerifyTestMain(m *testing.M, ignore ...string) {
+	base := takeSnapshot()
+	code := m.Run()
+	if code == 0 {
+		if leaks := base.waitLeaks(ignore); len(leaks) > 0 {
+			fmt.Fprintf(os.Stderr, "ts: found %d leaks after the tests:\n\n%s\n", len(leaks), strings.Join(leaks, "\n\n"))
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// ----------------------------------------------------------------------------
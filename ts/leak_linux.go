@@ -0,0 +1,44 @@
+//go:build linux
+// +build linux
+
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package ts
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// openFiles returns the open file descriptors of the process with their
+// targets.
+func openFiles() map[string]string {
+	fis, err := ioutil.ReadDir("/proc/self/fd")
+	if err != nil {
+		return nil
+	}
+	files := make(map[string]string, len(fis))
+	for _, fi := range fis {
+		target, err := os.Readlink("/proc/self/fd/" + fi.Name())
+		if err != nil || strings.HasPrefix(target, "/proc/") {
+			continue // the descriptor of the listing itself
+		}
+		files[fi.Name()] = target
+	}
+	return files
+}
@@ -0,0 +1,68 @@
+package ts
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordTB struct {
+	testing.TB
+	msg string
+}
+
+func (p *recordTB) Helper() {}
+
+func (p *recordTB) Errorf(format string, args ...interface{}) {
+	p.msg = format
+	if len(args) > 1 {
+		p.msg = args[1].(string)
+	}
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	old := LeakWait
+	LeakWait = 50 * time.Millisecond
+	defer func() { LeakWait = old }()
+
+	done := make(chan struct{})
+	tb := &recordTB{TB: t}
+	check := VerifyNoLeaks(tb)
+	go leakyLoop(done)
+	f, err := os.Open("leak_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check()
+	if !strings.Contains(tb.msg, "ts.leakyLoop") {
+		t.Fatal("leaked goroutine not found:", tb.msg)
+	}
+	if openFiles() != nil && !strings.Contains(tb.msg, "leak_test.go") {
+		t.Fatal("leaked file not found:", tb.msg)
+	}
+
+	tb = &recordTB{TB: t}
+	check = VerifyNoLeaks(tb, "ts.leakyLoop")
+	go leakyLoop(done)
+	check()
+	if tb.msg != "" {
+		t.Fatal("ignored goroutine reported:", tb.msg)
+	}
+	close(done)
+	f.Close()
+
+	tb = &recordTB{TB: t}
+	check = VerifyNoLeaks(tb)
+	done2 := make(chan struct{})
+	go leakyLoop(done2)
+	close(done2) // exits shortly
+	check()
+	if tb.msg != "" {
+		t.Fatal("exited goroutine reported:", tb.msg)
+	}
+}
+
+func leakyLoop(done chan struct{}) {
+	<-done
+}
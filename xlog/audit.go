@@ -0,0 +1,225 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package xlog
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+
+// AuditRecord is an entry of an audit log. Entries are hash-chained: Hash
+// covers the entry and the Hash of the previous entry (Prev), so that
+// changing, removing or reordering entries breaks the chain.
+type AuditRecord struct {
+	Seq    uint64            `json:"seq"`
+	Time   time.Time         `json:"time"`
+	Actor  string            `json:"actor,omitempty"`
+	Action string            `json:"action"`
+	Target string            `json:"target,omitempty"`
+	Detail map[string]string `json:"detail,omitempty"`
+	Prev   string            `json:"prev"`
+	Hash   string            `json:"hash"`
+}
+
+// AuditCheckpoint is the Action of the checkpoint entries. A checkpoint
+// records the number of entries so far in its Detail.
+const AuditCheckpoint = "audit.checkpoint"
+
+// AuditOptions configures an AuditWriter.
+type AuditOptions struct {
+	// Key, if not nil, makes the hashes HMAC-SHA256 with this key, so
+	// that the chain can't be recomputed without it. Otherwise they are
+	// SHA-256.
+	Key []byte
+
+	// CheckpointEvery writes a checkpoint entry after every that many
+	// entries. Zero means no checkpoints.
+	CheckpointEvery int
+
+	// OnCheckpoint is called with each checkpoint entry. Storing the
+	// checkpoint hashes elsewhere anchors the chain: a rewritten log then
+	// can't match them.
+	OnCheckpoint func(rec *AuditRecord)
+
+	// Seq and Prev continue an existing log: they are the Seq and Hash of
+	// its last entry (see AuditReport).
+	Seq  uint64
+	Prev string
+}
+
+// AuditWriter writes an audit log of JSON lines. It is safe for concurrent
+// use.
+type AuditWriter struct {
+	opts AuditOptions
+
+	mu    sync.Mutex
+	w     io.Writer
+	seq   uint64
+	prev  string
+	count int // since the last checkpoint
+	now   func() time.Time
+}
+
+// NewAuditWriter creates an audit log writer that writes to w.
+func NewAuditWriter(w io.Writer, opts *AuditOptions) *AuditWriter {
+	a := &AuditWriter{w: w, now: time.Now}
+	if opts != nil {
+		a.opts = *opts
+	}
+	a.seq, a.prev = a.opts.Seq, a.opts.Prev
+	return a
+}
+
+// Log writes an entry for action done by actor on target.
+func (a *AuditWriter) Log(actor, action, target string, detail map[string]string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.writeLocked(&AuditRecord{Actor: actor, Action: action, Target: target, Detail: detail}); err != nil {
+		return err
+	}
+	if a.count++; a.opts.CheckpointEvery > 0 && a.count >= a.opts.CheckpointEvery {
+		return a.checkpointLocked()
+	}
+	return nil
+}
+
+// Checkpoint writes a checkpoint entry now.
+func (a *AuditWriter) Checkpoint() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.checkpointLocked()
+}
+
+func (a *AuditWriter) checkpointLocked() error {
+	rec := &AuditRecord{
+		Action: AuditCheckpoint,
+		Detail: map[string]string{"entries": fmt.Sprint(a.seq)},
+	}
+	if err := a.writeLocked(rec); err != nil {
+		return err
+	}
+	a.count = 0
+	if a.opts.OnCheckpoint != nil {
+		a.opts.OnCheckpoint(rec)
+	}
+	return nil
+}
+
+func (a *AuditWriter) writeLocked(rec *AuditRecord) error {
+	rec.Seq, rec.Time, rec.Prev = a.seq+1, a.now().UTC(), a.prev
+	sum, err := auditHash(a.opts.Key, rec)
+	if err != nil {
+		return err
+	}
+	rec.Hash = sum
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err = a.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	a.seq, a.prev = rec.Seq, rec.Hash
+	return nil
+}
+
+// auditHash returns the hash of rec, its Hash field excluded.
+func auditHash(key []byte, rec *AuditRecord) (string, error) {
+	r := *rec
+	r.Hash = ""
+	b, err := json.Marshal(&r) // map keys are sorted: the encoding is canonical
+	if err != nil {
+		return "", err
+	}
+	var h hash.Hash
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ============================================================================
+
+// ErrAuditTampered is returned by VerifyAudit for a broken chain.
+var ErrAuditTampered = errors.New("xlog: audit log tampered")
+
+// AuditReport is the result of VerifyAudit.
+type AuditReport struct {
+	Entries     int            // checkpoints included
+	Seq         uint64         // of the last entry
+	Hash        string         // of the last entry
+	Checkpoints []*AuditRecord // in order
+}
+
+// VerifyAudit checks the hash chain of the audit log read from r, hashed
+// with key (nil for SHA-256). The log must start with its first entry, so
+// that removing the leading entries breaks the chain too. It returns the
+// report of the entries before the first broken one; the error then wraps
+// ErrAuditTampered and tells the line of the entry.
+func VerifyAudit(r io.Reader, key []byte) (*AuditReport, error) {
+	return VerifyAuditFrom(r, key, 0, "")
+}
+
+// VerifyAuditFrom is like VerifyAudit for a log continuing another one (see
+// AuditOptions): its first entry must follow the entry of the other log
+// whose Seq and Hash are seq and prev.
+func VerifyAuditFrom(r io.Reader, key []byte, seq uint64, prev string) (*AuditReport, error) {
+	rep := &AuditReport{Seq: seq, Hash: prev}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		rec := new(AuditRecord)
+		if err := json.Unmarshal(sc.Bytes(), rec); err != nil {
+			return rep, fmt.Errorf("%w: line %d: %v", ErrAuditTampered, line, err)
+		}
+		if rec.Seq != rep.Seq+1 || rec.Prev != rep.Hash {
+			return rep, fmt.Errorf("%w: line %d: entry %d doesn't follow entry %d", ErrAuditTampered, line, rec.Seq, rep.Seq)
+		}
+		sum, err := auditHash(key, rec)
+		if err != nil {
+			return rep, err
+		}
+		if !hmac.Equal([]byte(sum), []byte(rec.Hash)) {
+			return rep, fmt.Errorf("%w: line %d: hash mismatch of entry %d", ErrAuditTampered, line, rec.Seq)
+		}
+		rep.Entries++
+		rep.Seq, rep.Hash = rec.Seq, rec.Hash
+		if rec.Action == AuditCheckpoint {
+			rep.Checkpoints = append(rep.Checkpoints, rec)
+		}
+	}
+	return rep, sc.Err()
+}
+
+// ============================================================================
@@ -0,0 +1,98 @@
+package xlog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func writeAudit(t *testing.T, opts *AuditOptions, n int) *bytes.Buffer {
+	var buf bytes.Buffer
+	a := NewAuditWriter(&buf, opts)
+	for i := 0; i < n; i++ {
+		if err := a.Log("admin", "cache.purge", "group-a", map[string]string{"reason": "deploy"}); err != nil {
+			t.Fatal("Log:", err)
+		}
+	}
+	return &buf
+}
+
+func TestAuditVerify(t *testing.T) {
+	var cps []string
+	opts := &AuditOptions{CheckpointEvery: 2, OnCheckpoint: func(rec *AuditRecord) { cps = append(cps, rec.Hash) }}
+	buf := writeAudit(t, opts, 5)
+	rep, err := VerifyAudit(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatal("VerifyAudit:", err)
+	}
+	if rep.Entries != 7 || rep.Seq != 7 || len(rep.Checkpoints) != 2 {
+		t.Fatal("VerifyAudit:", rep.Entries, rep.Seq, len(rep.Checkpoints))
+	}
+	if len(cps) != 2 || cps[1] != rep.Checkpoints[1].Hash {
+		t.Fatal("OnCheckpoint:", cps)
+	}
+	if rep.Checkpoints[0].Detail["entries"] != "2" {
+		t.Fatal("checkpoint:", rep.Checkpoints[0].Detail)
+	}
+
+	// continue the log
+	a := NewAuditWriter(buf, &AuditOptions{Seq: rep.Seq, Prev: rep.Hash})
+	if err = a.Log("admin", "config.change", "timeout", nil); err != nil {
+		t.Fatal("Log:", err)
+	}
+	if rep, err = VerifyAudit(bytes.NewReader(buf.Bytes()), nil); err != nil || rep.Seq != 8 {
+		t.Fatal("VerifyAudit resumed:", rep.Seq, err)
+	}
+
+	// continue the log in another file
+	var next bytes.Buffer
+	a = NewAuditWriter(&next, &AuditOptions{Seq: rep.Seq, Prev: rep.Hash})
+	if err = a.Log("admin", "config.change", "retries", nil); err != nil {
+		t.Fatal("Log:", err)
+	}
+	if _, err = VerifyAudit(bytes.NewReader(next.Bytes()), nil); !errors.Is(err, ErrAuditTampered) {
+		t.Fatal("VerifyAudit of a continued log:", err)
+	}
+	if rep, err = VerifyAuditFrom(bytes.NewReader(next.Bytes()), nil, rep.Seq, rep.Hash); err != nil || rep.Seq != 9 || rep.Entries != 1 {
+		t.Fatal("VerifyAuditFrom:", rep.Seq, rep.Entries, err)
+	}
+}
+
+func TestAuditTampered(t *testing.T) {
+	lines := strings.SplitAfter(writeAudit(t, nil, 4).String(), "\n")
+	cases := map[string]string{
+		"modified": strings.Join(lines[:1], "") + strings.Replace(lines[1], "group-a", "group-b", 1) + strings.Join(lines[2:], ""),
+		"removed":  lines[0] + strings.Join(lines[2:], ""),
+		"swapped":  lines[0] + lines[2] + lines[1] + lines[3],
+		"garbage":  lines[0] + "{\n",
+	}
+	for _, log := range []string{strings.Join(lines[1:], ""), strings.Join(lines[3:], "")} {
+		if rep, err := VerifyAudit(strings.NewReader(log), nil); !errors.Is(err, ErrAuditTampered) || rep.Entries != 0 {
+			t.Fatal("VerifyAudit of a truncated log:", rep.Entries, err)
+		}
+	}
+	for name, log := range cases {
+		rep, err := VerifyAudit(strings.NewReader(log), nil)
+		if !errors.Is(err, ErrAuditTampered) {
+			t.Fatal(name, "VerifyAudit:", err)
+		}
+		if rep.Entries != 1 {
+			t.Fatal(name, "entries:", rep.Entries)
+		}
+	}
+}
+
+func TestAuditKey(t *testing.T) {
+	key := []byte("secret")
+	buf := writeAudit(t, &AuditOptions{Key: key}, 3)
+	if _, err := VerifyAudit(bytes.NewReader(buf.Bytes()), key); err != nil {
+		t.Fatal("VerifyAudit:", err)
+	}
+	if _, err := VerifyAudit(bytes.NewReader(buf.Bytes()), []byte("other")); !errors.Is(err, ErrAuditTampered) {
+		t.Fatal("VerifyAudit wrong key:", err)
+	}
+	if _, err := VerifyAudit(bytes.NewReader(buf.Bytes()), nil); !errors.Is(err, ErrAuditTampered) {
+		t.Fatal("VerifyAudit no key:", err)
+	}
+}
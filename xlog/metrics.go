@@ -0,0 +1,143 @@
+/*
+ Copyright 2026 Qiniu Limited (qiniu.com)
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package xlog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qiniu/x/log"
+	"github.com/qiniu/x/metrics"
+)
+
+// ============================================================================
+
+var levelNames = [...]string{"debug", "info", "warn", "error", "panic", "fatal"}
+
+type recorder struct {
+	vec     metrics.CounterVec
+	modules sync.Map // module => *[len(levelNames)]*metrics.Counter
+}
+
+var theRecorder atomic.Value // *recorder
+
+// EnableMetrics counts the records written by loggers of this package in the
+// counter family xlog_records_total of reg, labeled by level and module.
+// Records below the output level aren't counted.
+func EnableMetrics(reg *metrics.Registry) {
+	vec := reg.CounterVec("xlog_records_total", "Log records written, by level and module.", "level", "module")
+	theRecorder.Store(&recorder{vec: vec})
+}
+
+func (r *recorder) counter(module string, lvl int) *metrics.Counter {
+	if v, ok := r.modules.Load(module); ok {
+		return v.(*[len(levelNames)]*metrics.Counter)[lvl]
+	}
+	var cs [len(levelNames)]*metrics.Counter
+	for i, name := range levelNames {
+		cs[i] = r.vec.With(name, module)
+	}
+	v, _ := r.modules.LoadOrStore(module, &cs)
+	return v.(*[len(levelNames)]*metrics.Counter)[lvl]
+}
+
+func countRecord(module string, lvl int) {
+	if r, ok := theRecorder.Load().(*recorder); ok {
+		r.counter(module, lvl).Inc()
+	}
+	if lvl >= log.Lerror {
+		if h, ok := theErrorHook.Load().(*errorHook); ok {
+			h.record(time.Now())
+		}
+	}
+}
+
+// ============================================================================
+
+const hookBuckets = 10
+
+type errorHook struct {
+	threshold int
+	width     time.Duration // of a bucket
+	fn        func(n int)
+
+	mu     sync.Mutex
+	counts [hookBuckets]int
+	start  time.Time // of the current bucket
+	cur    int
+	firing bool
+}
+
+var theErrorHook atomic.Value // *errorHook
+
+// SetErrorHook arranges for fn to be called, in its own goroutine, when the
+// number of error-level (and above) records written within the sliding
+// window reaches threshold. fn receives that number. It is called once per
+// crossing: it fires again only after the volume has dropped back below the
+// threshold. A nil fn removes the hook.
+func SetErrorHook(threshold int, window time.Duration, fn func(n int)) {
+	if fn == nil {
+		theErrorHook.Store((*errorHook)(nil))
+		return
+	}
+	if threshold <= 0 || window <= 0 {
+		panic("xlog.SetErrorHook: non-positive threshold or window")
+	}
+	theErrorHook.Store(&errorHook{threshold: threshold, width: window / hookBuckets, fn: fn})
+}
+
+func (h *errorHook) record(now time.Time) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.advance(now)
+	h.counts[h.cur]++
+	n := 0
+	for _, c := range h.counts {
+		n += c
+	}
+	fire := false
+	if n >= h.threshold {
+		fire, h.firing = !h.firing, true
+	} else {
+		h.firing = false
+	}
+	h.mu.Unlock()
+	if fire {
+		go h.fn(n)
+	}
+}
+
+// advance rotates the buckets up to now; the caller holds the lock.
+func (h *errorHook) advance(now time.Time) {
+	if h.start.IsZero() {
+		h.start = now
+		return
+	}
+	for i := 0; i < hookBuckets && now.Sub(h.start) >= h.width; i++ {
+		h.cur = (h.cur + 1) % hookBuckets
+		h.counts[h.cur] = 0
+		h.start = h.start.Add(h.width)
+	}
+	if now.Sub(h.start) >= h.width { // idle for more than the window
+		h.start = now
+	}
+}
+
+// ============================================================================
@@ -0,0 +1,59 @@
+package xlog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/qiniu/x/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+	reg := metrics.NewRegistry()
+	EnableMetrics(reg)
+	defer theRecorder.Store((*recorder)(nil))
+
+	xl := New("req1").WithModule("store")
+	xl.Info("hello")
+	xl.Debug("not written")
+	xl.Spawn("sub").Errorf("failed: %d", 1)
+	New("req2").Warn("no module")
+
+	vec := reg.CounterVec("xlog_records_total", "", "level", "module")
+	if vec.With("info", "store").Value() != 1 || vec.With("error", "store").Value() != 1 ||
+		vec.With("debug", "store").Value() != 0 || vec.With("warn", "").Value() != 1 {
+		t.Fatal("unexpected counts")
+	}
+}
+
+func TestErrorHook(t *testing.T) {
+	fired := make(chan int, 10)
+	SetErrorHook(3, time.Second, func(n int) { fired <- n })
+	defer SetErrorHook(0, 0, nil)
+	h := theErrorHook.Load().(*errorHook)
+
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		h.record(base.Add(time.Duration(i) * time.Millisecond))
+	}
+	if n := <-fired; n != 3 {
+		t.Fatal("fired with", n)
+	}
+	// the window has slid past the burst: the volume dropped, then rises again
+	later := base.Add(2 * time.Second)
+	for i := 0; i < 3; i++ {
+		h.record(later.Add(time.Duration(i) * time.Millisecond))
+	}
+	if n := <-fired; n != 3 {
+		t.Fatal("fired again with", n)
+	}
+	select {
+	case n := <-fired:
+		t.Fatal("fired more than once per crossing:", n)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -37,11 +37,15 @@ const (
 
 type Logger struct {
 	ReqId string
+
+	// Module is the module the records are counted under by the metrics
+	// (see EnableMetrics).
+	Module string
 }
 
 func New(reqId string) *Logger {
 
-	return &Logger{reqId}
+	return &Logger{ReqId: reqId}
 }
 
 func NewWith(ctx Context) *Logger {
@@ -50,12 +54,26 @@ func NewWith(ctx Context) *Logger {
 	if !ok {
 		log.Debug("xlog.New: reqid isn't find in context")
 	}
-	return &Logger{reqId}
+	return &Logger{ReqId: reqId}
 }
 
 func (xlog *Logger) Spawn(child string) *Logger {
 
-	return &Logger{xlog.ReqId + "." + child}
+	return &Logger{ReqId: xlog.ReqId + "." + child, Module: xlog.Module}
+}
+
+// WithModule returns a logger of the same request that counts its records
+// under module.
+func (xlog *Logger) WithModule(module string) *Logger {
+
+	return &Logger{ReqId: xlog.ReqId, Module: module}
+}
+
+func (xlog *Logger) output(lvl int, s string) {
+	log.Std.Output(xlog.ReqId, lvl, 3, s)
+	if lvl >= log.Std.Level {
+		countRecord(xlog.Module, lvl)
+	}
 }
 
 // ============================================================================
@@ -63,19 +81,19 @@ func (xlog *Logger) Spawn(child string) *Logger {
 // Print calls Output to print to the standard Logger.
 // Arguments are handled in the manner of fmt.Print.
 func (xlog *Logger) Print(v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Linfo, 2, fmt.Sprint(v...))
+	xlog.output(log.Linfo, fmt.Sprint(v...))
 }
 
 // Printf calls Output to print to the standard Logger.
 // Arguments are handled in the manner of fmt.Printf.
 func (xlog *Logger) Printf(format string, v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Linfo, 2, fmt.Sprintf(format, v...))
+	xlog.output(log.Linfo, fmt.Sprintf(format, v...))
 }
 
 // Println calls Output to print to the standard Logger.
 // Arguments are handled in the manner of fmt.Println.
 func (xlog *Logger) Println(v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Linfo, 2, fmt.Sprintln(v...))
+	xlog.output(log.Linfo, fmt.Sprintln(v...))
 }
 
 // -----------------------------------------
@@ -84,14 +102,14 @@ func (xlog *Logger) Debugf(format string, v ...interface{}) {
 	if log.Ldebug < log.Std.Level {
 		return
 	}
-	log.Std.Output(xlog.ReqId, log.Ldebug, 2, fmt.Sprintf(format, v...))
+	xlog.output(log.Ldebug, fmt.Sprintf(format, v...))
 }
 
 func (xlog *Logger) Debug(v ...interface{}) {
 	if log.Ldebug < log.Std.Level {
 		return
 	}
-	log.Std.Output(xlog.ReqId, log.Ldebug, 2, fmt.Sprintln(v...))
+	xlog.output(log.Ldebug, fmt.Sprintln(v...))
 }
 
 // -----------------------------------------
@@ -100,53 +118,53 @@ func (xlog *Logger) Infof(format string, v ...interface{}) {
 	if log.Linfo < log.Std.Level {
 		return
 	}
-	log.Std.Output(xlog.ReqId, log.Linfo, 2, fmt.Sprintf(format, v...))
+	xlog.output(log.Linfo, fmt.Sprintf(format, v...))
 }
 
 func (xlog *Logger) Info(v ...interface{}) {
 	if log.Linfo < log.Std.Level {
 		return
 	}
-	log.Std.Output(xlog.ReqId, log.Linfo, 2, fmt.Sprintln(v...))
+	xlog.output(log.Linfo, fmt.Sprintln(v...))
 }
 
 // -----------------------------------------
 
 func (xlog *Logger) Warnf(format string, v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Lwarn, 2, fmt.Sprintf(format, v...))
+	xlog.output(log.Lwarn, fmt.Sprintf(format, v...))
 }
 
 func (xlog *Logger) Warn(v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Lwarn, 2, fmt.Sprintln(v...))
+	xlog.output(log.Lwarn, fmt.Sprintln(v...))
 }
 
 // -----------------------------------------
 
 func (xlog *Logger) Errorf(format string, v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Lerror, 2, fmt.Sprintf(format, v...))
+	xlog.output(log.Lerror, fmt.Sprintf(format, v...))
 }
 
 func (xlog *Logger) Error(v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Lerror, 2, fmt.Sprintln(v...))
+	xlog.output(log.Lerror, fmt.Sprintln(v...))
 }
 
 // -----------------------------------------
 
 // Fatal is equivalent to Print() followed by a call to os.Exit(1).
 func (xlog *Logger) Fatal(v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Lfatal, 2, fmt.Sprint(v...))
+	xlog.output(log.Lfatal, fmt.Sprint(v...))
 	os.Exit(1)
 }
 
 // Fatalf is equivalent to Printf() followed by a call to os.Exit(1).
 func (xlog *Logger) Fatalf(format string, v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Lfatal, 2, fmt.Sprintf(format, v...))
+	xlog.output(log.Lfatal, fmt.Sprintf(format, v...))
 	os.Exit(1)
 }
 
 // Fatalln is equivalent to Println() followed by a call to os.Exit(1).
 func (xlog *Logger) Fatalln(v ...interface{}) {
-	log.Std.Output(xlog.ReqId, log.Lfatal, 2, fmt.Sprintln(v...))
+	xlog.output(log.Lfatal, fmt.Sprintln(v...))
 	os.Exit(1)
 }
 
@@ -155,21 +173,21 @@ func (xlog *Logger) Fatalln(v ...interface{}) {
 // Panic is equivalent to Print() followed by a call to panic().
 func (xlog *Logger) Panic(v ...interface{}) {
 	s := fmt.Sprint(v...)
-	log.Std.Output(xlog.ReqId, log.Lpanic, 2, s)
+	xlog.output(log.Lpanic, s)
 	panic(s)
 }
 
 // Panicf is equivalent to Printf() followed by a call to panic().
 func (xlog *Logger) Panicf(format string, v ...interface{}) {
 	s := fmt.Sprintf(format, v...)
-	log.Std.Output(xlog.ReqId, log.Lpanic, 2, s)
+	xlog.output(log.Lpanic, s)
 	panic(s)
 }
 
 // Panicln is equivalent to Println() followed by a call to panic().
 func (xlog *Logger) Panicln(v ...interface{}) {
 	s := fmt.Sprintln(v...)
-	log.Std.Output(xlog.ReqId, log.Lpanic, 2, s)
+	xlog.output(log.Lpanic, s)
 	panic(s)
 }
 
@@ -180,7 +198,7 @@ func (xlog *Logger) Stack(v ...interface{}) {
 	n := runtime.Stack(buf, true)
 	s += string(buf[:n])
 	s += "\n"
-	log.Std.Output(xlog.ReqId, log.Lerror, 2, s)
+	xlog.output(log.Lerror, s)
 }
 
 func (xlog *Logger) SingleStack(v ...interface{}) {
@@ -190,7 +208,7 @@ func (xlog *Logger) SingleStack(v ...interface{}) {
 	n := runtime.Stack(buf, false)
 	s += string(buf[:n])
 	s += "\n"
-	log.Std.Output(xlog.ReqId, log.Lerror, 2, s)
+	xlog.output(log.Lerror, s)
 }
 
 // ============================================================================